@@ -57,6 +57,9 @@ type FullNode interface {
 	// ChainNotify returns channel with chain head updates.
 	// First message is guaranteed to be of len == 1, and type == 'current'.
 	ChainNotify(context.Context) (<-chan []*HeadChange, error)
+	// ChainDataIndexNotify returns channel with the data indexes that become final
+	// as tipsets are applied, and that are undone as tipsets are reverted.
+	ChainDataIndexNotify(ctx context.Context) (<-chan []*DataIndexChange, error)
 
 	// ChainHead returns the current head of the chain.
 	ChainHead(context.Context) (*types.TipSet, error)
@@ -283,6 +286,14 @@ type FullNode interface {
 	WalletDelete(context.Context, address.Address) error
 	// WalletValidateAddress validates whether a given string can be decoded as a well-formed address
 	WalletValidateAddress(context.Context, string) (address.Address, error)
+	// WalletSetPassphrase encrypts the local wallet keys at rest with a new passphrase.
+	// It fails if the keys are already encrypted. The wallet stays unlocked.
+	WalletSetPassphrase(ctx context.Context, passphrase string) error
+	// WalletUnlock unlocks the local wallet keys, which are encrypted at rest, with the passphrase.
+	// A non-zero timeout locks the wallet again once it passes.
+	WalletUnlock(ctx context.Context, passphrase string, timeout time.Duration) error
+	// WalletLock locks the local wallet keys until the wallet is unlocked again.
+	WalletLock(context.Context) error
 
 	// Other
 
@@ -300,6 +311,10 @@ type FullNode interface {
 	ClientImportAndDeal(ctx context.Context, params *ImportAndDealParams) (*ImportRes, error)
 	// ClientExpertRegisterFile registers new piece.
 	ClientExpertRegisterFile(ctx context.Context, params *ExpertRegisterFileParams) (*cid.Cid, error)
+	// ClientExpertRegisterFiles registers pieces in batch messages sized to fit the
+	// block gas limit. Pieces already registered on chain are skipped. If a batch
+	// can't be sent, the last batch returned holds the error and the files left.
+	ClientExpertRegisterFiles(ctx context.Context, params *ExpertRegisterFilesParams) ([]ExpertRegisterBatch, error)
 	// ClientGetDealInfo returns the latest information about a given deal.
 	ClientGetDealInfo(context.Context, cid.Cid) (*DealInfo, error)
 	// ClientListDeals returns information about the deals made by the local client.
@@ -515,17 +530,34 @@ type FullNode interface {
 	StateListExperts(context.Context, types.TipSetKey) ([]address.Address, error)
 	// StateExpertInfo returns info about the indicated expert.
 	StateExpertInfo(context.Context, address.Address, types.TipSetKey) (*ExpertInfo, error)
+	// StateExpertRewards projects the vesting schedule of the rewards of the
+	// expert, with the amounts unlocked each day and claimable by then.
+	StateExpertRewards(context.Context, address.Address, types.TipSetKey) (*ExpertRewards, error)
 	// StateExpertInfo lists expert's data
 	StateExpertDatas(context.Context, address.Address, *bitfield.BitField, bool, types.TipSetKey) ([]*expert.DataOnChainInfo, error)
 	// StateExpertFileInfo returns expert's file
 	StateExpertFileInfo(context.Context, cid.Cid, types.TipSetKey) (*ExpertFileInfo, error)
+	// StateExpertFileRedundancy returns the miners storing a registered file, with the
+	// deals and expiry epochs of every copy
+	StateExpertFileRedundancy(ctx context.Context, pieceCID cid.Cid, tsk types.TipSetKey) (*ExpertFileStorage, error)
+	// StateExpertDataList returns a page of the files registered by an expert with
+	// their storage state, in piece CID order, starting after the cursor piece CID;
+	// a nil cursor starts at the first file and limit 0 returns all files
+	StateExpertDataList(ctx context.Context, expert address.Address, cursor *cid.Cid, limit uint64, tsk types.TipSetKey) (*ExpertDataPage, error)
 
 	// StateVoteTally returns voting result at given tipset
 	StateVoteTally(context.Context, types.TipSetKey) (*vote.Tally, error)
+	// StateVoteTallyHistory returns the votes of the candidate sampled every step
+	// epochs in [from, to] of the chain ending at tsk. All candidates are
+	// returned if candidate is undefined.
+	StateVoteTallyHistory(ctx context.Context, candidate address.Address, from abi.ChainEpoch, to abi.ChainEpoch, step abi.ChainEpoch, tsk types.TipSetKey) (*VoteTallyHistory, error)
 	// StateVoterInfo returns voter info at given tipset
 	StateVoterInfo(context.Context, address.Address, types.TipSetKey) (*vote.VoterInfo, error)
 	// StateKnowledgeInfo returns knowledge fund info at given tipset
 	StateKnowledgeInfo(context.Context, types.TipSetKey) (*knowledge.Info, error)
+	// StateKnowledgePayouts returns the amounts paid to the knowledge fund payees,
+	// and the payee changes, in [from, to] of the chain ending at tsk.
+	StateKnowledgePayouts(ctx context.Context, from abi.ChainEpoch, to abi.ChainEpoch, tsk types.TipSetKey) (*KnowledgePayouts, error)
 
 	// StateGovernSupervisor returns authorities of given governor
 	StateGovernSupervisor(context.Context, types.TipSetKey) (address.Address, error)
@@ -533,6 +565,10 @@ type FullNode interface {
 	StateGovernorList(context.Context, types.TipSetKey) ([]*govern.GovernorInfo, error)
 
 	StateGovernParams(context.Context, types.TipSetKey) (*govern.GovParams, error)
+	// StateGovernParamsHistory returns the changes of the governance params and
+	// the authority grants and revocations in [from, to], as recorded by the
+	// governance index (see GovernIndex in the node config)
+	StateGovernParamsHistory(ctx context.Context, from abi.ChainEpoch, to abi.ChainEpoch) ([]*GovernChange, error)
 
 	// StateRetrievalInfo retrieval pledge info
 	StateRetrievalInfo(context.Context, types.TipSetKey) (*RetrievalInfo, error)
@@ -542,12 +578,19 @@ type FullNode interface {
 
 	// StateRetrievalPledge retrieval from address
 	StateRetrievalPledgeFrom(context.Context, address.Address, types.TipSetKey) (*RetrievalPledgeInfo, error)
+	// StateRetrievalLocked returns the retrieval pledge funds of addr in the withdrawal
+	// lock, and the epoch they unlock at
+	StateRetrievalLocked(ctx context.Context, addr address.Address, tsk types.TipSetKey) (*RetrievalLocked, error)
 
 	// StateRetrievalPledgeList retrieval pledge list
 	StateRetrievalPledgeList(context.Context, types.TipSetKey) (map[address.Address]*RetrievalState, error)
 
 	// StateDataIndex data index
 	StateDataIndex(context.Context, abi.ChainEpoch, types.TipSetKey) ([]*DataIndex, error)
+	// StateDataIndexRange returns a page of the data indexes of the epochs in
+	// [from, to], skipping the first offset indexes of epoch from; limit 0 returns
+	// all indexes
+	StateDataIndexRange(ctx context.Context, from abi.ChainEpoch, to abi.ChainEpoch, offset uint64, limit uint64, tsk types.TipSetKey) (*DataIndexPage, error)
 
 	// StateMinerStoredAnyPiece check miner has storage the data by pieceIDs
 	StateMinerStoredAnyPiece(context.Context, address.Address, []cid.Cid, types.TipSetKey) (bool, error)
@@ -671,6 +714,16 @@ type FullNode interface {
 	FlowchStatus(context.Context, address.Address) (*FlowchStatus, error)
 	FlowchSettle(context.Context, address.Address) (cid.Cid, error)
 	FlowchCollect(context.Context, address.Address) (cid.Cid, error)
+	// FlowchAutoStatus returns the progress of the automatic settlement and
+	// collection of the inbound channels (see FlowchAuto in the node config)
+	FlowchAutoStatus(ctx context.Context) ([]*FlowchAutoChannel, error)
+	// FlowchExport exports the given tracked channels, or all of them if none is
+	// given, with their lanes and vouchers into a bundle signed by signer
+	FlowchExport(ctx context.Context, signer address.Address, chs []address.Address) (*FlowchBundle, error)
+	// FlowchImport verifies a bundle exported by FlowchExport and tracks its
+	// channels, merging their vouchers into the already tracked ones. It returns
+	// the imported channels
+	FlowchImport(ctx context.Context, bundle *FlowchBundle) ([]address.Address, error)
 	FlowchAllocateLane(ctx context.Context, ch address.Address) (uint64, error)
 	FlowchNewPayment(ctx context.Context, from, to address.Address, vouchers []FlowVoucherSpec) (*FlowInfo, error)
 	FlowchVoucherCheckValid(context.Context, address.Address, *flowch.SignedVoucher) error
@@ -857,6 +910,61 @@ type FlowchStatus struct {
 	Direction   PCHDir
 }
 
+// FlowchAutoChannel is the progress of the automatic settlement of an
+// inbound flow channel.
+type FlowchAutoChannel struct {
+	Channel address.Address
+	// Value is the sum of the best spendable vouchers of each lane
+	Value abi.TokenAmount
+	// LastActivity is the height the value last changed at
+	LastActivity abi.ChainEpoch
+	SettleMsg    *cid.Cid
+	// SettlingAt is the height the channel can be collected from, 0 until
+	// it is settled
+	SettlingAt abi.ChainEpoch
+	CollectMsg *cid.Cid
+	Collected  bool
+	// Error is the last error settling or collecting the channel
+	Error string
+}
+
+// FlowchBundle is a portable export of tracked flow channels with their lanes
+// and vouchers, signed by the exporting wallet.
+type FlowchBundle struct {
+	Version uint64
+	// Timestamp is the unix time of the export
+	Timestamp int64
+	Signer    address.Address
+	Channels  []*FlowchBundleChannel
+	// Signature signs the JSON encoding of the bundle without the signature
+	Signature *crypto.Signature
+}
+
+type FlowchBundleChannel struct {
+	Channel   address.Address
+	Control   address.Address
+	Target    address.Address
+	Direction PCHDir
+	NextLane  uint64
+	Amount    types.BigInt
+	Settling  bool
+	// Lanes are the lane states on chain at the export, empty if the channel
+	// was collected
+	Lanes    []FlowchBundleLane
+	Vouchers []FlowchBundleVoucher
+}
+
+type FlowchBundleLane struct {
+	Lane     uint64
+	Nonce    uint64
+	Redeemed types.BigInt
+}
+
+type FlowchBundleVoucher struct {
+	Voucher   *flowch.SignedVoucher
+	Submitted bool
+}
+
 type FlowInfo struct {
 	Channel      address.Address
 	WaitSentinel cid.Cid
@@ -937,11 +1045,20 @@ type MarketDeal struct {
 }
 
 type RetrievalOrder struct {
-	// TODO: make this less unixfs specific
 	Root  cid.Cid
 	Piece *cid.Cid
+	// DatamodelPath restricts the retrieval to the node found at this IPLD
+	// data model path below Root, e.g. "Links/2/Hash" for the third entry of a
+	// UnixFS directory. Only the Links/<index>/Hash steps of dag-pb nodes are
+	// supported. The whole DAG is retrieved if empty.
+	DatamodelPath string
+	// Range restricts the retrieval of the UnixFS file found at DatamodelPath
+	// to the children of its root node holding these bytes, whole: ranges are
+	// only as fine as the top-level chunks of the file. Only these bytes are
+	// exported to a regular file, while a CAR export holds the retrieved blocks.
+	Range *RetrievalRange
 	Size  uint64
-	// TODO: support offset
+
 	Total                   types.BigInt
 	UnsealPrice             types.BigInt
 	PaymentInterval         uint64
@@ -951,6 +1068,13 @@ type RetrievalOrder struct {
 	MinerPeer               retrievalmarket.RetrievalPeer
 }
 
+// RetrievalRange is a byte range of a UnixFS file.
+type RetrievalRange struct {
+	Offset uint64
+	// Length of the range, up to the end of the file if zero
+	Length uint64
+}
+
 type InvocResult struct {
 	MsgCid         cid.Cid
 	Msg            *types.Message
@@ -1177,6 +1301,26 @@ type ExpertRegisterFileParams struct {
 	PieceSize abi.PaddedPieceSize
 }
 
+type ExpertRegisterFilesParams struct {
+	Expert address.Address
+	Files  []ExpertFileImport
+	// MaxBatchSize caps the number of files per message, 0 for the default.
+	MaxBatchSize int
+}
+
+type ExpertFileImport struct {
+	RootID    cid.Cid
+	PieceID   cid.Cid
+	PieceSize abi.PaddedPieceSize
+}
+
+type ExpertRegisterBatch struct {
+	Message cid.Cid
+	Files   []ExpertFileImport
+	// Error is set when the files weren't sent, and Message is undefined then.
+	Error string
+}
+
 type ImportAndDealParams struct {
 	Ref    FileRef
 	From   address.Address
@@ -1191,6 +1335,93 @@ type ExpertFileInfo struct {
 	Redundancy uint64
 }
 
+// ExpertFileStorage describes a file registered by an expert and the miners
+// storing it.
+type ExpertFileStorage struct {
+	Expert     address.Address
+	RootID     cid.Cid
+	PieceID    cid.Cid
+	PieceSize  abi.PaddedPieceSize
+	Redundancy uint64
+
+	// Miners storing the piece in their sectors, according to the chain
+	// state; deals not yet in a sector are only listed in Deals
+	Miners []address.Address
+	Deals  []ExpertFileDeal
+}
+
+type ExpertFileDeal struct {
+	DealID   abi.DealID
+	Provider address.Address
+	Client   address.Address
+
+	StartEpoch       abi.ChainEpoch
+	SectorStartEpoch abi.ChainEpoch // -1 if not yet included in proven sector
+	SlashEpoch       abi.ChainEpoch // -1 if deal never slashed
+
+	// Sector storing the deal and its on-time expiration, -1 if the deal is
+	// not in a proven sector
+	Sector     abi.SectorNumber
+	Expiration abi.ChainEpoch
+}
+
+type ExpertDataPage struct {
+	Files []*ExpertFileStorage
+	// Next is the cursor of the following page, nil when this is the last page
+	Next *cid.Cid
+}
+
+type VoteTallyPoint struct {
+	Epoch   abi.ChainEpoch
+	Votes   abi.TokenAmount
+	Blocked bool
+}
+
+type VoteTallyHistory struct {
+	// Epochs are the sampled epochs
+	Epochs []abi.ChainEpoch
+	// Candidates maps candidate addresses to their votes at the sampled
+	// epochs they existed at
+	Candidates map[string][]VoteTallyPoint
+}
+
+type KnowledgePayout struct {
+	// Epoch is the height of the first tipset whose parent state includes the payout
+	Epoch  abi.ChainEpoch
+	Payee  address.Address
+	Amount abi.TokenAmount
+}
+
+type KnowledgePayeeChange struct {
+	Epoch abi.ChainEpoch
+	From  address.Address
+	To    address.Address
+	// Message is the ChangePayee message sent to the knowledge fund, undefined
+	// if the payee was changed by an inner call (e.g. through a multisig)
+	Message cid.Cid
+}
+
+type KnowledgePayouts struct {
+	Payouts      []KnowledgePayout
+	PayeeChanges []KnowledgePayeeChange
+}
+
+type GovernChange struct {
+	// Epoch is the epoch of the tipset whose execution made the change
+	Epoch abi.ChainEpoch
+	// Actor is the actor storing the param
+	Actor address.Address
+	Param string
+	Old   string
+	New   string
+	// Grantee is the governor whose authorities changed, for Authority changes
+	Grantee address.Address
+	// Message and Governor are the message and the governor, or multisig
+	// governor, which made the change; undefined if it couldn't be found
+	Message  cid.Cid
+	Governor address.Address
+}
+
 type RetrievalInfo struct {
 	TotalPledge   abi.TokenAmount
 	TotalReward   abi.TokenAmount
@@ -1203,6 +1434,15 @@ type RetrievalPledgeInfo struct {
 	UnlockedEpoch abi.ChainEpoch
 }
 
+// RetrievalLocked are the retrieval pledge funds of a wallet applied for
+// withdrawal, which can be withdrawn from UnlockEpoch.
+type RetrievalLocked struct {
+	Address     address.Address
+	Amount      abi.TokenAmount
+	ApplyEpoch  abi.ChainEpoch
+	UnlockEpoch abi.ChainEpoch
+}
+
 type RetrievalState struct {
 	BindMiners []address.Address
 	Balance    abi.TokenAmount
@@ -1210,11 +1450,27 @@ type RetrievalState struct {
 }
 
 type DataIndex struct {
+	Epoch    abi.ChainEpoch
 	Miner    address.Address
 	RootCID  cid.Cid
 	PieceCID cid.Cid
 }
 
+type DataIndexPage struct {
+	Indexes []*DataIndex
+	// NextEpoch and NextOffset are the from and offset of the following page
+	NextEpoch  abi.ChainEpoch
+	NextOffset uint64
+	// Done is set when the page reaches the end of the range
+	Done bool
+}
+
+type DataIndexChange struct {
+	// Type is the type of the head change, apply or revert
+	Type  string
+	Index *DataIndex
+}
+
 type RetrievalDeal struct {
 	DealID       retrievalmarket.DealID
 	RootCID      cid.Cid
@@ -1225,6 +1481,8 @@ type RetrievalDeal struct {
 	Status       retrievalmarket.DealStatus
 	Message      string
 	WaitMsgCID   *cid.Cid // the CID of any message the client deal is waiting for
+
+	TotalReceived uint64
 }
 
 type MsigTransaction struct {
@@ -1246,6 +1504,36 @@ type ExpertInfo struct {
 	DataSize     abi.PaddedPieceSize
 }
 
+// ExpertRewards is the projected vesting schedule of the rewards of an expert.
+type ExpertRewards struct {
+	Expert address.Address
+	Height abi.ChainEpoch
+
+	LockedFunds   abi.TokenAmount
+	UnlockedFunds abi.TokenAmount // claimable at Height
+
+	// DisqualifiedAt is the epoch the expert was disqualified at, -1 if it
+	// wasn't. A disqualified expert earns no new rewards, and loses its
+	// contribution at ContributionClearedAt, while its locked rewards keep
+	// vesting.
+	DisqualifiedAt        abi.ChainEpoch
+	ContributionClearedAt abi.ChainEpoch
+
+	// Schedule holds a day for each day some rewards unlock, in order.
+	Schedule []ExpertRewardsDay
+}
+
+// ExpertRewardsDay is a day of the vesting schedule of the rewards of an
+// expert.
+type ExpertRewardsDay struct {
+	// Epoch is the first epoch of the day
+	Epoch abi.ChainEpoch
+	// Unlocked is the amount unlocked during the day
+	Unlocked abi.TokenAmount
+	// Claimable is the amount claimable at the end of the day
+	Claimable abi.TokenAmount
+}
+
 type ExportRef struct {
 	Root cid.Cid
 
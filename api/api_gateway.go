@@ -10,7 +10,10 @@ import (
 	"github.com/filecoin-project/go-state-types/dline"
 	"github.com/filecoin-project/go-state-types/network"
 
+	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/govern"
+	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/knowledge"
 	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/miner"
+	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/vote"
 	"github.com/EpiK-Protocol/go-epik/chain/types"
 )
 
@@ -42,5 +45,14 @@ type GatewayAPI interface {
 	StateNetworkVersion(context.Context, types.TipSetKey) (network.Version, error)
 	StateSectorGetInfo(ctx context.Context, maddr address.Address, n abi.SectorNumber, tsk types.TipSetKey) (*miner.SectorOnChainInfo, error)
 	// StateVerifiedClientStatus(ctx context.Context, addr address.Address, tsk types.TipSetKey) (*abi.StoragePower, error)
+
+	// EpiK actors
+	StateExpertInfo(ctx context.Context, addr address.Address, tsk types.TipSetKey) (*ExpertInfo, error)
+	StateGovernParams(ctx context.Context, tsk types.TipSetKey) (*govern.GovParams, error)
+	StateKnowledgeInfo(ctx context.Context, tsk types.TipSetKey) (*knowledge.Info, error)
+	StateListExperts(ctx context.Context, tsk types.TipSetKey) ([]address.Address, error)
+	StateRetrievalPledge(ctx context.Context, addr address.Address, tsk types.TipSetKey) (*RetrievalState, error)
+	StateVoteTally(ctx context.Context, tsk types.TipSetKey) (*vote.Tally, error)
+	StateVoterInfo(ctx context.Context, addr address.Address, tsk types.TipSetKey) (*vote.VoterInfo, error)
 	StateWaitMsg(ctx context.Context, msg cid.Cid, confidence uint64) (*MsgLookup, error)
 }
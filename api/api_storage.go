@@ -140,6 +140,13 @@ type StorageMiner interface {
 	CreateBackup(ctx context.Context, fpath string) error
 
 	CheckProvable(ctx context.Context, pp abi.RegisteredPoStProof, sectors []storage.SectorRef, expensive bool) (map[abi.SectorNumber]string, error)
+
+	// MinerDataStatus returns the persisted state of the chain-data
+	// replication loop, optionally including per-piece records
+	MinerDataStatus(ctx context.Context, pieces bool) (MinerDataStatus, error)
+	// MinerDataSources returns the retrieval history of the miners chain data
+	// was retrieved from, ranked by score
+	MinerDataSources(ctx context.Context) ([]RetrievalSourceStats, error)
 }
 
 type SealRes struct {
@@ -248,3 +255,85 @@ type PendingDealInfo struct {
 	PublishPeriodStart time.Time
 	PublishPeriod      time.Duration
 }
+
+// MinerDataState is the replication state of a piece tracked by the miner
+// chain-data replication loop.
+type MinerDataState string
+
+const (
+	MinerDataDiscovered MinerDataState = "discovered"
+	MinerDataRetrieving MinerDataState = "retrieving"
+	MinerDataRetrieved  MinerDataState = "retrieved"
+	MinerDataDealing    MinerDataState = "dealing"
+	MinerDataStored     MinerDataState = "stored"
+	MinerDataFailed     MinerDataState = "failed"
+)
+
+// MinerDataPiece is the persisted replication record of a single piece.
+type MinerDataPiece struct {
+	PieceCID cid.Cid
+	RootCID  cid.Cid
+
+	State  MinerDataState
+	Reason string // set when State is MinerDataFailed
+
+	TryCount  int
+	RetryTime time.Time
+
+	// Sources maps the source miners seen in the data index to their current score
+	Sources map[string]int
+}
+
+// MinerDataStatus summarizes the replication ledger of the miner.
+type MinerDataStatus struct {
+	CheckHeight abi.ChainEpoch
+
+	States map[MinerDataState]int
+	Pieces []MinerDataPiece
+}
+
+// RetrievalSourceStats is the retrieval history of a miner the chain-data
+// replication loop retrieves from.
+type RetrievalSourceStats struct {
+	Miner address.Address
+
+	Successes uint64
+	Failures  uint64
+	// Errors counts failures by class, e.g. "query" or the deal status
+	Errors map[string]uint64
+
+	// TotalBytes and TotalTime cover successful retrievals only
+	TotalBytes uint64
+	TotalTime  time.Duration
+	// TotalTTFB sums the time-to-first-byte of TTFBSamples retrievals
+	TotalTTFB   time.Duration
+	TTFBSamples uint64
+
+	LastSuccess time.Time
+	LastFailure time.Time
+
+	// Score ranks the miner against the other known sources, from 0 to 100
+	Score float64
+}
+
+// SuccessRate returns the share of successful retrievals, smoothed so that
+// unknown miners start at 0.5.
+func (s *RetrievalSourceStats) SuccessRate() float64 {
+	return float64(s.Successes+1) / float64(s.Successes+s.Failures+2)
+}
+
+// BytesPerSecond returns the average throughput of successful retrievals.
+func (s *RetrievalSourceStats) BytesPerSecond() float64 {
+	if s.TotalTime <= 0 {
+		return 0
+	}
+	return float64(s.TotalBytes) / s.TotalTime.Seconds()
+}
+
+// AvgTTFB returns the average time-to-first-byte.
+func (s *RetrievalSourceStats) AvgTTFB() time.Duration {
+	if s.TTFBSamples == 0 {
+		return 0
+	}
+	return s.TotalTTFB / time.Duration(s.TTFBSamples)
+}
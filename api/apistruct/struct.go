@@ -91,6 +91,7 @@ type FullNodeStruct struct {
 
 	Internal struct {
 		ChainNotify                   func(context.Context) (<-chan []*api.HeadChange, error)                                                            `perm:"read"`
+		ChainDataIndexNotify          func(ctx context.Context) (<-chan []*api.DataIndexChange, error)                                                   `perm:"read"`
 		ChainHead                     func(context.Context) (*types.TipSet, error)                                                                       `perm:"read"`
 		ChainGetRandomnessFromTickets func(context.Context, types.TipSetKey, crypto.DomainSeparationTag, abi.ChainEpoch, []byte) (abi.Randomness, error) `perm:"read"`
 		ChainGetRandomnessFromBeacon  func(context.Context, types.TipSetKey, crypto.DomainSeparationTag, abi.ChainEpoch, []byte) (abi.Randomness, error) `perm:"read"`
@@ -165,6 +166,9 @@ type FullNodeStruct struct {
 		WalletImport          func(context.Context, *types.KeyInfo) (address.Address, error)                       `perm:"admin"`
 		WalletDelete          func(context.Context, address.Address) error                                         `perm:"write"`
 		WalletValidateAddress func(context.Context, string) (address.Address, error)                               `perm:"read"`
+		WalletSetPassphrase   func(context.Context, string) error                                                  `perm:"admin"`
+		WalletUnlock          func(context.Context, string, time.Duration) error                                   `perm:"admin"`
+		WalletLock            func(context.Context) error                                                          `perm:"admin"`
 
 		ClientImport                              func(ctx context.Context, ref api.FileRef) (*api.ImportRes, error)                                                                           `perm:"admin"`
 		ClientListImports                         func(ctx context.Context) ([]api.Import, error)                                                                                              `perm:"write"`
@@ -176,6 +180,7 @@ type FullNodeStruct struct {
 		ClientStartDeal                           func(ctx context.Context, params *api.StartDealParams) (*cid.Cid, error)                                                                     `perm:"admin"`
 		ClientImportAndDeal                       func(ctx context.Context, params *api.ImportAndDealParams) (*api.ImportRes, error)                                                           `perm:"admin"`
 		ClientExpertRegisterFile                  func(ctx context.Context, params *api.ExpertRegisterFileParams) (*cid.Cid, error)                                                            `perm:"admin"`
+		ClientExpertRegisterFiles                 func(ctx context.Context, params *api.ExpertRegisterFilesParams) ([]api.ExpertRegisterBatch, error)                                          `perm:"admin"`
 		ClientGetDealInfo                         func(context.Context, cid.Cid) (*api.DealInfo, error)                                                                                        `perm:"read"`
 		ClientGetDealStatus                       func(context.Context, uint64) (string, error)                                                                                                `perm:"read"`
 		ClientListDeals                           func(ctx context.Context) ([]api.DealInfo, error)                                                                                            `perm:"write"`
@@ -252,27 +257,35 @@ type FullNodeStruct struct {
 		StateVerifiedClientStatus         func(context.Context, address.Address, types.TipSetKey) (*abi.StoragePower, error)                                   `perm:"read"`
 		StateVerifiedRegistryRootKey      func(ctx context.Context, tsk types.TipSetKey) (address.Address, error)                                              `perm:"read"`
 		StateDealProviderCollateralBounds func(context.Context, abi.PaddedPieceSize, bool, types.TipSetKey) (api.DealCollateralBounds, error)                 `perm:"read"`  */
-		StateTotalMinedDetail            func(ctx context.Context, tsk types.TipSetKey) (*reward.TotalMinedDetail, error)                                     `perm:"read"`
-		StateCirculatingSupply           func(context.Context, types.TipSetKey) (abi.TokenAmount, error)                                                      `perm:"read"`
-		StateVMCirculatingSupplyInternal func(context.Context, types.TipSetKey) (api.CirculatingSupply, error)                                                `perm:"read"`
-		StateNetworkVersion              func(context.Context, types.TipSetKey) (stnetwork.Version, error)                                                    `perm:"read"`
-		StateListExperts                 func(context.Context, types.TipSetKey) ([]address.Address, error)                                                    `perm:"read"`
-		StateExpertInfo                  func(context.Context, address.Address, types.TipSetKey) (*api.ExpertInfo, error)                                     `perm:"read"`
-		StateExpertDatas                 func(context.Context, address.Address, *bitfield.BitField, bool, types.TipSetKey) ([]*expert.DataOnChainInfo, error) `perm:"read"`
-		StateExpertFileInfo              func(context.Context, cid.Cid, types.TipSetKey) (*api.ExpertFileInfo, error)                                         `perm:"read"`
-		StateVoteTally                   func(context.Context, types.TipSetKey) (*vote.Tally, error)                                                          `perm:"read"`
-		StateVoterInfo                   func(context.Context, address.Address, types.TipSetKey) (*vote.VoterInfo, error)                                     `perm:"read"`
-		StateKnowledgeInfo               func(context.Context, types.TipSetKey) (*knowledge.Info, error)                                                      `perm:"read"`
-		StateGovernSupervisor            func(context.Context, types.TipSetKey) (address.Address, error)                                                      `perm:"read"`
-		StateGovernorList                func(context.Context, types.TipSetKey) ([]*govern.GovernorInfo, error)                                               `perm:"read"`
-		StateGovernParams                func(context.Context, types.TipSetKey) (*govern.GovParams, error)                                                    `perm:"read"`
-		StateRetrievalInfo               func(context.Context, types.TipSetKey) (*api.RetrievalInfo, error)                                                   `perm:"read"`
-		StateRetrievalPledge             func(context.Context, address.Address, types.TipSetKey) (*api.RetrievalState, error)                                 `perm:"read"`
-		StateRetrievalPledgeFrom         func(context.Context, address.Address, types.TipSetKey) (*api.RetrievalPledgeInfo, error)                            `perm:"read"`
-		StateRetrievalPledgeList         func(context.Context, types.TipSetKey) (map[address.Address]*api.RetrievalState, error)                              `perm:"read"`
-		StateDataIndex                   func(context.Context, abi.ChainEpoch, types.TipSetKey) ([]*api.DataIndex, error)                                     `perm:"read"`
-		StateMinerStoredAnyPiece         func(context.Context, address.Address, []cid.Cid, types.TipSetKey) (bool, error)                                     `perm:"read"`
-		StateTotalID                     func(context.Context, types.TipSetKey) (uint64, error)                                                               `perm:"read"`
+		StateTotalMinedDetail            func(ctx context.Context, tsk types.TipSetKey) (*reward.TotalMinedDetail, error)                                                                                      `perm:"read"`
+		StateCirculatingSupply           func(context.Context, types.TipSetKey) (abi.TokenAmount, error)                                                                                                       `perm:"read"`
+		StateVMCirculatingSupplyInternal func(context.Context, types.TipSetKey) (api.CirculatingSupply, error)                                                                                                 `perm:"read"`
+		StateNetworkVersion              func(context.Context, types.TipSetKey) (stnetwork.Version, error)                                                                                                     `perm:"read"`
+		StateListExperts                 func(context.Context, types.TipSetKey) ([]address.Address, error)                                                                                                     `perm:"read"`
+		StateExpertInfo                  func(context.Context, address.Address, types.TipSetKey) (*api.ExpertInfo, error)                                                                                      `perm:"read"`
+		StateExpertRewards               func(ctx context.Context, addr address.Address, tsk types.TipSetKey) (*api.ExpertRewards, error)                                                                      `perm:"read"`
+		StateExpertDatas                 func(context.Context, address.Address, *bitfield.BitField, bool, types.TipSetKey) ([]*expert.DataOnChainInfo, error)                                                  `perm:"read"`
+		StateExpertFileInfo              func(context.Context, cid.Cid, types.TipSetKey) (*api.ExpertFileInfo, error)                                                                                          `perm:"read"`
+		StateExpertFileRedundancy        func(ctx context.Context, pieceCID cid.Cid, tsk types.TipSetKey) (*api.ExpertFileStorage, error)                                                                      `perm:"read"`
+		StateExpertDataList              func(ctx context.Context, expert address.Address, cursor *cid.Cid, limit uint64, tsk types.TipSetKey) (*api.ExpertDataPage, error)                                    `perm:"read"`
+		StateVoteTally                   func(context.Context, types.TipSetKey) (*vote.Tally, error)                                                                                                           `perm:"read"`
+		StateVoteTallyHistory            func(ctx context.Context, candidate address.Address, from abi.ChainEpoch, to abi.ChainEpoch, step abi.ChainEpoch, tsk types.TipSetKey) (*api.VoteTallyHistory, error) `perm:"read"`
+		StateVoterInfo                   func(context.Context, address.Address, types.TipSetKey) (*vote.VoterInfo, error)                                                                                      `perm:"read"`
+		StateKnowledgeInfo               func(context.Context, types.TipSetKey) (*knowledge.Info, error)                                                                                                       `perm:"read"`
+		StateKnowledgePayouts            func(ctx context.Context, from abi.ChainEpoch, to abi.ChainEpoch, tsk types.TipSetKey) (*api.KnowledgePayouts, error)                                                 `perm:"read"`
+		StateGovernSupervisor            func(context.Context, types.TipSetKey) (address.Address, error)                                                                                                       `perm:"read"`
+		StateGovernorList                func(context.Context, types.TipSetKey) ([]*govern.GovernorInfo, error)                                                                                                `perm:"read"`
+		StateGovernParams                func(context.Context, types.TipSetKey) (*govern.GovParams, error)                                                                                                     `perm:"read"`
+		StateGovernParamsHistory         func(ctx context.Context, from abi.ChainEpoch, to abi.ChainEpoch) ([]*api.GovernChange, error)                                                                        `perm:"read"`
+		StateRetrievalInfo               func(context.Context, types.TipSetKey) (*api.RetrievalInfo, error)                                                                                                    `perm:"read"`
+		StateRetrievalPledge             func(context.Context, address.Address, types.TipSetKey) (*api.RetrievalState, error)                                                                                  `perm:"read"`
+		StateRetrievalPledgeFrom         func(context.Context, address.Address, types.TipSetKey) (*api.RetrievalPledgeInfo, error)                                                                             `perm:"read"`
+		StateRetrievalLocked             func(ctx context.Context, addr address.Address, tsk types.TipSetKey) (*api.RetrievalLocked, error)                                                                    `perm:"read"`
+		StateRetrievalPledgeList         func(context.Context, types.TipSetKey) (map[address.Address]*api.RetrievalState, error)                                                                               `perm:"read"`
+		StateDataIndex                   func(context.Context, abi.ChainEpoch, types.TipSetKey) ([]*api.DataIndex, error)                                                                                      `perm:"read"`
+		StateDataIndexRange              func(ctx context.Context, from abi.ChainEpoch, to abi.ChainEpoch, offset uint64, limit uint64, tsk types.TipSetKey) (*api.DataIndexPage, error)                       `perm:"read"`
+		StateMinerStoredAnyPiece         func(context.Context, address.Address, []cid.Cid, types.TipSetKey) (bool, error)                                                                                      `perm:"read"`
+		StateTotalID                     func(context.Context, types.TipSetKey) (uint64, error)                                                                                                                `perm:"read"`
 
 		MsigGetAvailableBalance func(context.Context, address.Address, types.TipSetKey) (types.BigInt, error)                                                                    `perm:"read"`
 		MsigGetVestingSchedule  func(context.Context, address.Address, types.TipSetKey) (api.MsigVesting, error)                                                                 `perm:"read"`
@@ -323,6 +336,9 @@ type FullNodeStruct struct {
 		FlowchStatus                 func(context.Context, address.Address) (*api.FlowchStatus, error)                                          `perm:"read"`
 		FlowchSettle                 func(context.Context, address.Address) (cid.Cid, error)                                                    `perm:"sign"`
 		FlowchCollect                func(context.Context, address.Address) (cid.Cid, error)                                                    `perm:"sign"`
+		FlowchAutoStatus             func(ctx context.Context) ([]*api.FlowchAutoChannel, error)                                                `perm:"read"`
+		FlowchExport                 func(ctx context.Context, signer address.Address, chs []address.Address) (*api.FlowchBundle, error)        `perm:"sign"`
+		FlowchImport                 func(ctx context.Context, bundle *api.FlowchBundle) ([]address.Address, error)                             `perm:"write"`
 		FlowchAllocateLane           func(context.Context, address.Address) (uint64, error)                                                     `perm:"sign"`
 		FlowchNewPayment             func(ctx context.Context, from, to address.Address, vouchers []api.FlowVoucherSpec) (*api.FlowInfo, error) `perm:"sign"`
 		FlowchVoucherCheck           func(context.Context, *flowch.SignedVoucher) error                                                         `perm:"read"`
@@ -440,6 +456,9 @@ type StorageMinerStruct struct {
 		CreateBackup func(ctx context.Context, fpath string) error `perm:"admin"`
 
 		CheckProvable func(ctx context.Context, pp abi.RegisteredPoStProof, sectors []storage.SectorRef, expensive bool) (map[abi.SectorNumber]string, error) `perm:"admin"`
+
+		MinerDataStatus  func(ctx context.Context, pieces bool) (api.MinerDataStatus, error) `perm:"read"`
+		MinerDataSources func(ctx context.Context) ([]api.RetrievalSourceStats, error)       `perm:"read"`
 	}
 }
 
@@ -513,6 +532,14 @@ type GatewayStruct struct {
 		StateSectorGetInfo     func(ctx context.Context, maddr address.Address, n abi.SectorNumber, tsk types.TipSetKey) (*miner.SectorOnChainInfo, error)
 		// StateVerifiedClientStatus         func(ctx context.Context, addr address.Address, tsk types.TipSetKey) (*abi.StoragePower, error)
 		StateWaitMsg func(ctx context.Context, msg cid.Cid, confidence uint64) (*api.MsgLookup, error)
+
+		StateExpertInfo      func(ctx context.Context, addr address.Address, tsk types.TipSetKey) (*api.ExpertInfo, error)
+		StateGovernParams    func(ctx context.Context, tsk types.TipSetKey) (*govern.GovParams, error)
+		StateKnowledgeInfo   func(ctx context.Context, tsk types.TipSetKey) (*knowledge.Info, error)
+		StateListExperts     func(ctx context.Context, tsk types.TipSetKey) ([]address.Address, error)
+		StateRetrievalPledge func(ctx context.Context, addr address.Address, tsk types.TipSetKey) (*api.RetrievalState, error)
+		StateVoteTally       func(ctx context.Context, tsk types.TipSetKey) (*vote.Tally, error)
+		StateVoterInfo       func(ctx context.Context, addr address.Address, tsk types.TipSetKey) (*vote.VoterInfo, error)
 	}
 }
 
@@ -678,6 +705,10 @@ func (c *FullNodeStruct) ClientExpertRegisterFile(ctx context.Context, params *a
 	return c.Internal.ClientExpertRegisterFile(ctx, params)
 }
 
+func (c *FullNodeStruct) ClientExpertRegisterFiles(ctx context.Context, params *api.ExpertRegisterFilesParams) ([]api.ExpertRegisterBatch, error) {
+	return c.Internal.ClientExpertRegisterFiles(ctx, params)
+}
+
 func (c *FullNodeStruct) ClientGetDealInfo(ctx context.Context, deal cid.Cid) (*api.DealInfo, error) {
 	return c.Internal.ClientGetDealInfo(ctx, deal)
 }
@@ -922,6 +953,18 @@ func (c *FullNodeStruct) WalletValidateAddress(ctx context.Context, str string)
 	return c.Internal.WalletValidateAddress(ctx, str)
 }
 
+func (c *FullNodeStruct) WalletSetPassphrase(ctx context.Context, passphrase string) error {
+	return c.Internal.WalletSetPassphrase(ctx, passphrase)
+}
+
+func (c *FullNodeStruct) WalletUnlock(ctx context.Context, passphrase string, timeout time.Duration) error {
+	return c.Internal.WalletUnlock(ctx, passphrase, timeout)
+}
+
+func (c *FullNodeStruct) WalletLock(ctx context.Context) error {
+	return c.Internal.WalletLock(ctx)
+}
+
 func (c *FullNodeStruct) MpoolGetNonce(ctx context.Context, addr address.Address) (uint64, error) {
 	return c.Internal.MpoolGetNonce(ctx, addr)
 }
@@ -950,6 +993,10 @@ func (c *FullNodeStruct) ChainNotify(ctx context.Context) (<-chan []*api.HeadCha
 	return c.Internal.ChainNotify(ctx)
 }
 
+func (c *FullNodeStruct) ChainDataIndexNotify(ctx context.Context) (<-chan []*api.DataIndexChange, error) {
+	return c.Internal.ChainDataIndexNotify(ctx)
+}
+
 func (c *FullNodeStruct) ChainReadObj(ctx context.Context, obj cid.Cid) ([]byte, error) {
 	return c.Internal.ChainReadObj(ctx, obj)
 }
@@ -1254,6 +1301,10 @@ func (c *FullNodeStruct) StateExpertInfo(ctx context.Context, addr address.Addre
 	return c.Internal.StateExpertInfo(ctx, addr, tsk)
 }
 
+func (c *FullNodeStruct) StateExpertRewards(ctx context.Context, addr address.Address, tsk types.TipSetKey) (*api.ExpertRewards, error) {
+	return c.Internal.StateExpertRewards(ctx, addr, tsk)
+}
+
 func (c *FullNodeStruct) StateExpertDatas(ctx context.Context, addr address.Address, filter *bitfield.BitField, filterOut bool, tsk types.TipSetKey) ([]*expert.DataOnChainInfo, error) {
 	return c.Internal.StateExpertDatas(ctx, addr, filter, filterOut, tsk)
 }
@@ -1262,10 +1313,22 @@ func (c *FullNodeStruct) StateExpertFileInfo(ctx context.Context, pieceCID cid.C
 	return c.Internal.StateExpertFileInfo(ctx, pieceCID, tsk)
 }
 
+func (c *FullNodeStruct) StateExpertFileRedundancy(ctx context.Context, pieceCID cid.Cid, tsk types.TipSetKey) (*api.ExpertFileStorage, error) {
+	return c.Internal.StateExpertFileRedundancy(ctx, pieceCID, tsk)
+}
+
+func (c *FullNodeStruct) StateExpertDataList(ctx context.Context, expert address.Address, cursor *cid.Cid, limit uint64, tsk types.TipSetKey) (*api.ExpertDataPage, error) {
+	return c.Internal.StateExpertDataList(ctx, expert, cursor, limit, tsk)
+}
+
 func (c *FullNodeStruct) StateVoteTally(ctx context.Context, tsk types.TipSetKey) (*vote.Tally, error) {
 	return c.Internal.StateVoteTally(ctx, tsk)
 }
 
+func (c *FullNodeStruct) StateVoteTallyHistory(ctx context.Context, candidate address.Address, from abi.ChainEpoch, to abi.ChainEpoch, step abi.ChainEpoch, tsk types.TipSetKey) (*api.VoteTallyHistory, error) {
+	return c.Internal.StateVoteTallyHistory(ctx, candidate, from, to, step, tsk)
+}
+
 func (c *FullNodeStruct) StateVoterInfo(ctx context.Context, addr address.Address, tsk types.TipSetKey) (*vote.VoterInfo, error) {
 	return c.Internal.StateVoterInfo(ctx, addr, tsk)
 }
@@ -1274,6 +1337,10 @@ func (c *FullNodeStruct) StateKnowledgeInfo(ctx context.Context, tsk types.TipSe
 	return c.Internal.StateKnowledgeInfo(ctx, tsk)
 }
 
+func (c *FullNodeStruct) StateKnowledgePayouts(ctx context.Context, from abi.ChainEpoch, to abi.ChainEpoch, tsk types.TipSetKey) (*api.KnowledgePayouts, error) {
+	return c.Internal.StateKnowledgePayouts(ctx, from, to, tsk)
+}
+
 func (c *FullNodeStruct) StateGovernSupervisor(ctx context.Context, tsk types.TipSetKey) (address.Address, error) {
 	return c.Internal.StateGovernSupervisor(ctx, tsk)
 }
@@ -1286,6 +1353,10 @@ func (c *FullNodeStruct) StateGovernParams(ctx context.Context, tsk types.TipSet
 	return c.Internal.StateGovernParams(ctx, tsk)
 }
 
+func (c *FullNodeStruct) StateGovernParamsHistory(ctx context.Context, from abi.ChainEpoch, to abi.ChainEpoch) ([]*api.GovernChange, error) {
+	return c.Internal.StateGovernParamsHistory(ctx, from, to)
+}
+
 func (c *FullNodeStruct) StateRetrievalInfo(ctx context.Context, tsk types.TipSetKey) (*api.RetrievalInfo, error) {
 	return c.Internal.StateRetrievalInfo(ctx, tsk)
 }
@@ -1298,6 +1369,10 @@ func (c *FullNodeStruct) StateRetrievalPledgeFrom(ctx context.Context, addr addr
 	return c.Internal.StateRetrievalPledgeFrom(ctx, addr, tsk)
 }
 
+func (c *FullNodeStruct) StateRetrievalLocked(ctx context.Context, addr address.Address, tsk types.TipSetKey) (*api.RetrievalLocked, error) {
+	return c.Internal.StateRetrievalLocked(ctx, addr, tsk)
+}
+
 func (c *FullNodeStruct) StateRetrievalPledgeList(ctx context.Context, tsk types.TipSetKey) (map[address.Address]*api.RetrievalState, error) {
 	return c.Internal.StateRetrievalPledgeList(ctx, tsk)
 }
@@ -1306,6 +1381,10 @@ func (c *FullNodeStruct) StateDataIndex(ctx context.Context, epoch abi.ChainEpoc
 	return c.Internal.StateDataIndex(ctx, epoch, tsk)
 }
 
+func (c *FullNodeStruct) StateDataIndexRange(ctx context.Context, from abi.ChainEpoch, to abi.ChainEpoch, offset uint64, limit uint64, tsk types.TipSetKey) (*api.DataIndexPage, error) {
+	return c.Internal.StateDataIndexRange(ctx, from, to, offset, limit, tsk)
+}
+
 func (c *FullNodeStruct) StateMinerStoredAnyPiece(ctx context.Context, addr address.Address, pieceIDs []cid.Cid, tsk types.TipSetKey) (bool, error) {
 	return c.Internal.StateMinerStoredAnyPiece(ctx, addr, pieceIDs, tsk)
 }
@@ -1514,6 +1593,18 @@ func (c *FullNodeStruct) FlowchCollect(ctx context.Context, a address.Address) (
 	return c.Internal.FlowchCollect(ctx, a)
 }
 
+func (c *FullNodeStruct) FlowchAutoStatus(ctx context.Context) ([]*api.FlowchAutoChannel, error) {
+	return c.Internal.FlowchAutoStatus(ctx)
+}
+
+func (c *FullNodeStruct) FlowchExport(ctx context.Context, signer address.Address, chs []address.Address) (*api.FlowchBundle, error) {
+	return c.Internal.FlowchExport(ctx, signer, chs)
+}
+
+func (c *FullNodeStruct) FlowchImport(ctx context.Context, bundle *api.FlowchBundle) ([]address.Address, error) {
+	return c.Internal.FlowchImport(ctx, bundle)
+}
+
 func (c *FullNodeStruct) FlowchAllocateLane(ctx context.Context, ch address.Address) (uint64, error) {
 	return c.Internal.FlowchAllocateLane(ctx, ch)
 }
@@ -1862,6 +1953,14 @@ func (c *StorageMinerStruct) CheckProvable(ctx context.Context, pp abi.Registere
 	return c.Internal.CheckProvable(ctx, pp, sectors, expensive)
 }
 
+func (c *StorageMinerStruct) MinerDataStatus(ctx context.Context, pieces bool) (api.MinerDataStatus, error) {
+	return c.Internal.MinerDataStatus(ctx, pieces)
+}
+
+func (c *StorageMinerStruct) MinerDataSources(ctx context.Context) ([]api.RetrievalSourceStats, error) {
+	return c.Internal.MinerDataSources(ctx)
+}
+
 // WorkerStruct
 
 func (w *WorkerStruct) Version(ctx context.Context) (api.Version, error) {
@@ -2076,6 +2175,34 @@ func (g GatewayStruct) StateReadState(ctx context.Context, addr address.Address,
 	return g.Internal.StateReadState(ctx, addr, ts)
 }
 
+func (g GatewayStruct) StateExpertInfo(ctx context.Context, addr address.Address, tsk types.TipSetKey) (*api.ExpertInfo, error) {
+	return g.Internal.StateExpertInfo(ctx, addr, tsk)
+}
+
+func (g GatewayStruct) StateGovernParams(ctx context.Context, tsk types.TipSetKey) (*govern.GovParams, error) {
+	return g.Internal.StateGovernParams(ctx, tsk)
+}
+
+func (g GatewayStruct) StateKnowledgeInfo(ctx context.Context, tsk types.TipSetKey) (*knowledge.Info, error) {
+	return g.Internal.StateKnowledgeInfo(ctx, tsk)
+}
+
+func (g GatewayStruct) StateListExperts(ctx context.Context, tsk types.TipSetKey) ([]address.Address, error) {
+	return g.Internal.StateListExperts(ctx, tsk)
+}
+
+func (g GatewayStruct) StateRetrievalPledge(ctx context.Context, addr address.Address, tsk types.TipSetKey) (*api.RetrievalState, error) {
+	return g.Internal.StateRetrievalPledge(ctx, addr, tsk)
+}
+
+func (g GatewayStruct) StateVoteTally(ctx context.Context, tsk types.TipSetKey) (*vote.Tally, error) {
+	return g.Internal.StateVoteTally(ctx, tsk)
+}
+
+func (g GatewayStruct) StateVoterInfo(ctx context.Context, addr address.Address, tsk types.TipSetKey) (*vote.VoterInfo, error) {
+	return g.Internal.StateVoterInfo(ctx, addr, tsk)
+}
+
 func (c *WalletStruct) WalletNew(ctx context.Context, typ types.KeyType) (address.Address, error) {
 	return c.Internal.WalletNew(ctx, typ)
 }
@@ -39,6 +39,7 @@ import (
 	peer "github.com/libp2p/go-libp2p-core/peer"
 	protocol "github.com/libp2p/go-libp2p-core/protocol"
 	reflect "reflect"
+	time "time"
 )
 
 // MockFullNode is a mock of FullNode interface
@@ -124,6 +125,21 @@ func (mr *MockFullNodeMockRecorder) ChainAllowNoWindowPoSt(arg0, arg1, arg2, arg
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChainAllowNoWindowPoSt", reflect.TypeOf((*MockFullNode)(nil).ChainAllowNoWindowPoSt), arg0, arg1, arg2, arg3)
 }
 
+// ChainDataIndexNotify mocks base method
+func (m *MockFullNode) ChainDataIndexNotify(arg0 context.Context) (<-chan []*api.DataIndexChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChainDataIndexNotify", arg0)
+	ret0, _ := ret[0].(<-chan []*api.DataIndexChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChainDataIndexNotify indicates an expected call of ChainDataIndexNotify
+func (mr *MockFullNodeMockRecorder) ChainDataIndexNotify(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChainDataIndexNotify", reflect.TypeOf((*MockFullNode)(nil).ChainDataIndexNotify), arg0)
+}
+
 // ChainDeleteObj mocks base method
 func (m *MockFullNode) ChainDeleteObj(arg0 context.Context, arg1 cid.Cid) error {
 	m.ctrl.T.Helper()
@@ -541,6 +557,21 @@ func (mr *MockFullNodeMockRecorder) ClientExpertRegisterFile(arg0, arg1 interfac
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClientExpertRegisterFile", reflect.TypeOf((*MockFullNode)(nil).ClientExpertRegisterFile), arg0, arg1)
 }
 
+// ClientExpertRegisterFiles mocks base method
+func (m *MockFullNode) ClientExpertRegisterFiles(arg0 context.Context, arg1 *api.ExpertRegisterFilesParams) ([]api.ExpertRegisterBatch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClientExpertRegisterFiles", arg0, arg1)
+	ret0, _ := ret[0].([]api.ExpertRegisterBatch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClientExpertRegisterFiles indicates an expected call of ClientExpertRegisterFiles
+func (mr *MockFullNodeMockRecorder) ClientExpertRegisterFiles(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClientExpertRegisterFiles", reflect.TypeOf((*MockFullNode)(nil).ClientExpertRegisterFiles), arg0, arg1)
+}
+
 // ClientFindData mocks base method
 func (m *MockFullNode) ClientFindData(arg0 context.Context, arg1 cid.Cid, arg2 *cid.Cid) ([]api.QueryOffer, error) {
 	m.ctrl.T.Helper()
@@ -985,6 +1016,21 @@ func (mr *MockFullNodeMockRecorder) FlowchAllocateLane(arg0, arg1 interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FlowchAllocateLane", reflect.TypeOf((*MockFullNode)(nil).FlowchAllocateLane), arg0, arg1)
 }
 
+// FlowchAutoStatus mocks base method
+func (m *MockFullNode) FlowchAutoStatus(arg0 context.Context) ([]*api.FlowchAutoChannel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FlowchAutoStatus", arg0)
+	ret0, _ := ret[0].([]*api.FlowchAutoChannel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FlowchAutoStatus indicates an expected call of FlowchAutoStatus
+func (mr *MockFullNodeMockRecorder) FlowchAutoStatus(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FlowchAutoStatus", reflect.TypeOf((*MockFullNode)(nil).FlowchAutoStatus), arg0)
+}
+
 // FlowchAvailableFunds mocks base method
 func (m *MockFullNode) FlowchAvailableFunds(arg0 context.Context, arg1 address.Address) (*api.ChannelAvailableFunds, error) {
 	m.ctrl.T.Helper()
@@ -1030,6 +1076,21 @@ func (mr *MockFullNodeMockRecorder) FlowchCollect(arg0, arg1 interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FlowchCollect", reflect.TypeOf((*MockFullNode)(nil).FlowchCollect), arg0, arg1)
 }
 
+// FlowchExport mocks base method
+func (m *MockFullNode) FlowchExport(arg0 context.Context, arg1 address.Address, arg2 []address.Address) (*api.FlowchBundle, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FlowchExport", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*api.FlowchBundle)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FlowchExport indicates an expected call of FlowchExport
+func (mr *MockFullNodeMockRecorder) FlowchExport(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FlowchExport", reflect.TypeOf((*MockFullNode)(nil).FlowchExport), arg0, arg1, arg2)
+}
+
 // FlowchGet mocks base method
 func (m *MockFullNode) FlowchGet(arg0 context.Context, arg1, arg2 address.Address, arg3 big.Int) (*api.ChannelInfo, error) {
 	m.ctrl.T.Helper()
@@ -1060,6 +1121,21 @@ func (mr *MockFullNodeMockRecorder) FlowchGetWaitReady(arg0, arg1 interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FlowchGetWaitReady", reflect.TypeOf((*MockFullNode)(nil).FlowchGetWaitReady), arg0, arg1)
 }
 
+// FlowchImport mocks base method
+func (m *MockFullNode) FlowchImport(arg0 context.Context, arg1 *api.FlowchBundle) ([]address.Address, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FlowchImport", arg0, arg1)
+	ret0, _ := ret[0].([]address.Address)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FlowchImport indicates an expected call of FlowchImport
+func (mr *MockFullNodeMockRecorder) FlowchImport(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FlowchImport", reflect.TypeOf((*MockFullNode)(nil).FlowchImport), arg0, arg1)
+}
+
 // FlowchList mocks base method
 func (m *MockFullNode) FlowchList(arg0 context.Context) ([]address.Address, error) {
 	m.ctrl.T.Helper()
@@ -2429,6 +2505,21 @@ func (mr *MockFullNodeMockRecorder) StateDataIndex(arg0, arg1, arg2 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StateDataIndex", reflect.TypeOf((*MockFullNode)(nil).StateDataIndex), arg0, arg1, arg2)
 }
 
+// StateDataIndexRange mocks base method
+func (m *MockFullNode) StateDataIndexRange(arg0 context.Context, arg1 abi.ChainEpoch, arg2 abi.ChainEpoch, arg3 uint64, arg4 uint64, arg5 types.TipSetKey) (*api.DataIndexPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StateDataIndexRange", arg0, arg1, arg2, arg3, arg4, arg5)
+	ret0, _ := ret[0].(*api.DataIndexPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StateDataIndexRange indicates an expected call of StateDataIndexRange
+func (mr *MockFullNodeMockRecorder) StateDataIndexRange(arg0, arg1, arg2, arg3, arg4, arg5 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StateDataIndexRange", reflect.TypeOf((*MockFullNode)(nil).StateDataIndexRange), arg0, arg1, arg2, arg3, arg4, arg5)
+}
+
 // StateDecodeParams mocks base method
 func (m *MockFullNode) StateDecodeParams(arg0 context.Context, arg1 address.Address, arg2 abi.MethodNum, arg3 []byte, arg4 types.TipSetKey) (interface{}, error) {
 	m.ctrl.T.Helper()
@@ -2444,6 +2535,21 @@ func (mr *MockFullNodeMockRecorder) StateDecodeParams(arg0, arg1, arg2, arg3, ar
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StateDecodeParams", reflect.TypeOf((*MockFullNode)(nil).StateDecodeParams), arg0, arg1, arg2, arg3, arg4)
 }
 
+// StateExpertDataList mocks base method
+func (m *MockFullNode) StateExpertDataList(arg0 context.Context, arg1 address.Address, arg2 *cid.Cid, arg3 uint64, arg4 types.TipSetKey) (*api.ExpertDataPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StateExpertDataList", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(*api.ExpertDataPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StateExpertDataList indicates an expected call of StateExpertDataList
+func (mr *MockFullNodeMockRecorder) StateExpertDataList(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StateExpertDataList", reflect.TypeOf((*MockFullNode)(nil).StateExpertDataList), arg0, arg1, arg2, arg3, arg4)
+}
+
 // StateExpertDatas mocks base method
 func (m *MockFullNode) StateExpertDatas(arg0 context.Context, arg1 address.Address, arg2 *bitfield.BitField, arg3 bool, arg4 types.TipSetKey) ([]*expert.DataOnChainInfo, error) {
 	m.ctrl.T.Helper()
@@ -2474,6 +2580,21 @@ func (mr *MockFullNodeMockRecorder) StateExpertFileInfo(arg0, arg1, arg2 interfa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StateExpertFileInfo", reflect.TypeOf((*MockFullNode)(nil).StateExpertFileInfo), arg0, arg1, arg2)
 }
 
+// StateExpertFileRedundancy mocks base method
+func (m *MockFullNode) StateExpertFileRedundancy(arg0 context.Context, arg1 cid.Cid, arg2 types.TipSetKey) (*api.ExpertFileStorage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StateExpertFileRedundancy", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*api.ExpertFileStorage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StateExpertFileRedundancy indicates an expected call of StateExpertFileRedundancy
+func (mr *MockFullNodeMockRecorder) StateExpertFileRedundancy(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StateExpertFileRedundancy", reflect.TypeOf((*MockFullNode)(nil).StateExpertFileRedundancy), arg0, arg1, arg2)
+}
+
 // StateExpertInfo mocks base method
 func (m *MockFullNode) StateExpertInfo(arg0 context.Context, arg1 address.Address, arg2 types.TipSetKey) (*api.ExpertInfo, error) {
 	m.ctrl.T.Helper()
@@ -2489,6 +2610,21 @@ func (mr *MockFullNodeMockRecorder) StateExpertInfo(arg0, arg1, arg2 interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StateExpertInfo", reflect.TypeOf((*MockFullNode)(nil).StateExpertInfo), arg0, arg1, arg2)
 }
 
+// StateExpertRewards mocks base method
+func (m *MockFullNode) StateExpertRewards(arg0 context.Context, arg1 address.Address, arg2 types.TipSetKey) (*api.ExpertRewards, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StateExpertRewards", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*api.ExpertRewards)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StateExpertRewards indicates an expected call of StateExpertRewards
+func (mr *MockFullNodeMockRecorder) StateExpertRewards(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StateExpertRewards", reflect.TypeOf((*MockFullNode)(nil).StateExpertRewards), arg0, arg1, arg2)
+}
+
 // StateGetActor mocks base method
 func (m *MockFullNode) StateGetActor(arg0 context.Context, arg1 address.Address, arg2 types.TipSetKey) (*types.Actor, error) {
 	m.ctrl.T.Helper()
@@ -2534,6 +2670,21 @@ func (mr *MockFullNodeMockRecorder) StateGovernParams(arg0, arg1 interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StateGovernParams", reflect.TypeOf((*MockFullNode)(nil).StateGovernParams), arg0, arg1)
 }
 
+// StateGovernParamsHistory mocks base method
+func (m *MockFullNode) StateGovernParamsHistory(arg0 context.Context, arg1 abi.ChainEpoch, arg2 abi.ChainEpoch) ([]*api.GovernChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StateGovernParamsHistory", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*api.GovernChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StateGovernParamsHistory indicates an expected call of StateGovernParamsHistory
+func (mr *MockFullNodeMockRecorder) StateGovernParamsHistory(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StateGovernParamsHistory", reflect.TypeOf((*MockFullNode)(nil).StateGovernParamsHistory), arg0, arg1, arg2)
+}
+
 // StateGovernSupervisor mocks base method
 func (m *MockFullNode) StateGovernSupervisor(arg0 context.Context, arg1 types.TipSetKey) (address.Address, error) {
 	m.ctrl.T.Helper()
@@ -2579,6 +2730,21 @@ func (mr *MockFullNodeMockRecorder) StateKnowledgeInfo(arg0, arg1 interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StateKnowledgeInfo", reflect.TypeOf((*MockFullNode)(nil).StateKnowledgeInfo), arg0, arg1)
 }
 
+// StateKnowledgePayouts mocks base method
+func (m *MockFullNode) StateKnowledgePayouts(arg0 context.Context, arg1 abi.ChainEpoch, arg2 abi.ChainEpoch, arg3 types.TipSetKey) (*api.KnowledgePayouts, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StateKnowledgePayouts", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*api.KnowledgePayouts)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StateKnowledgePayouts indicates an expected call of StateKnowledgePayouts
+func (mr *MockFullNodeMockRecorder) StateKnowledgePayouts(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StateKnowledgePayouts", reflect.TypeOf((*MockFullNode)(nil).StateKnowledgePayouts), arg0, arg1, arg2, arg3)
+}
+
 // StateListActors mocks base method
 func (m *MockFullNode) StateListActors(arg0 context.Context, arg1 types.TipSetKey) ([]address.Address, error) {
 	m.ctrl.T.Helper()
@@ -2984,6 +3150,21 @@ func (mr *MockFullNodeMockRecorder) StateRetrievalInfo(arg0, arg1 interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StateRetrievalInfo", reflect.TypeOf((*MockFullNode)(nil).StateRetrievalInfo), arg0, arg1)
 }
 
+// StateRetrievalLocked mocks base method
+func (m *MockFullNode) StateRetrievalLocked(arg0 context.Context, arg1 address.Address, arg2 types.TipSetKey) (*api.RetrievalLocked, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StateRetrievalLocked", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*api.RetrievalLocked)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StateRetrievalLocked indicates an expected call of StateRetrievalLocked
+func (mr *MockFullNodeMockRecorder) StateRetrievalLocked(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StateRetrievalLocked", reflect.TypeOf((*MockFullNode)(nil).StateRetrievalLocked), arg0, arg1, arg2)
+}
+
 // StateRetrievalPledge mocks base method
 func (m *MockFullNode) StateRetrievalPledge(arg0 context.Context, arg1 address.Address, arg2 types.TipSetKey) (*api.RetrievalState, error) {
 	m.ctrl.T.Helper()
@@ -3134,6 +3315,21 @@ func (mr *MockFullNodeMockRecorder) StateVoteTally(arg0, arg1 interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StateVoteTally", reflect.TypeOf((*MockFullNode)(nil).StateVoteTally), arg0, arg1)
 }
 
+// StateVoteTallyHistory mocks base method
+func (m *MockFullNode) StateVoteTallyHistory(arg0 context.Context, arg1 address.Address, arg2 abi.ChainEpoch, arg3 abi.ChainEpoch, arg4 abi.ChainEpoch, arg5 types.TipSetKey) (*api.VoteTallyHistory, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StateVoteTallyHistory", arg0, arg1, arg2, arg3, arg4, arg5)
+	ret0, _ := ret[0].(*api.VoteTallyHistory)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StateVoteTallyHistory indicates an expected call of StateVoteTallyHistory
+func (mr *MockFullNodeMockRecorder) StateVoteTallyHistory(arg0, arg1, arg2, arg3, arg4, arg5 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StateVoteTallyHistory", reflect.TypeOf((*MockFullNode)(nil).StateVoteTallyHistory), arg0, arg1, arg2, arg3, arg4, arg5)
+}
+
 // StateVoterInfo mocks base method
 func (m *MockFullNode) StateVoterInfo(arg0 context.Context, arg1 address.Address, arg2 types.TipSetKey) (*vote.VoterInfo, error) {
 	m.ctrl.T.Helper()
@@ -3428,6 +3624,20 @@ func (mr *MockFullNodeMockRecorder) WalletList(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WalletList", reflect.TypeOf((*MockFullNode)(nil).WalletList), arg0)
 }
 
+// WalletLock mocks base method
+func (m *MockFullNode) WalletLock(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WalletLock", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WalletLock indicates an expected call of WalletLock
+func (mr *MockFullNodeMockRecorder) WalletLock(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WalletLock", reflect.TypeOf((*MockFullNode)(nil).WalletLock), arg0)
+}
+
 // WalletNew mocks base method
 func (m *MockFullNode) WalletNew(arg0 context.Context, arg1 types.KeyType) (address.Address, error) {
 	m.ctrl.T.Helper()
@@ -3457,6 +3667,20 @@ func (mr *MockFullNodeMockRecorder) WalletSetDefault(arg0, arg1 interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WalletSetDefault", reflect.TypeOf((*MockFullNode)(nil).WalletSetDefault), arg0, arg1)
 }
 
+// WalletSetPassphrase mocks base method
+func (m *MockFullNode) WalletSetPassphrase(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WalletSetPassphrase", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WalletSetPassphrase indicates an expected call of WalletSetPassphrase
+func (mr *MockFullNodeMockRecorder) WalletSetPassphrase(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WalletSetPassphrase", reflect.TypeOf((*MockFullNode)(nil).WalletSetPassphrase), arg0, arg1)
+}
+
 // WalletSign mocks base method
 func (m *MockFullNode) WalletSign(arg0 context.Context, arg1 address.Address, arg2 []byte) (*crypto.Signature, error) {
 	m.ctrl.T.Helper()
@@ -3487,6 +3711,20 @@ func (mr *MockFullNodeMockRecorder) WalletSignMessage(arg0, arg1, arg2 interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WalletSignMessage", reflect.TypeOf((*MockFullNode)(nil).WalletSignMessage), arg0, arg1, arg2)
 }
 
+// WalletUnlock mocks base method
+func (m *MockFullNode) WalletUnlock(arg0 context.Context, arg1 string, arg2 time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WalletUnlock", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WalletUnlock indicates an expected call of WalletUnlock
+func (mr *MockFullNodeMockRecorder) WalletUnlock(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WalletUnlock", reflect.TypeOf((*MockFullNode)(nil).WalletUnlock), arg0, arg1, arg2)
+}
+
 // WalletValidateAddress mocks base method
 func (m *MockFullNode) WalletValidateAddress(arg0 context.Context, arg1 string) (address.Address, error) {
 	m.ctrl.T.Helper()
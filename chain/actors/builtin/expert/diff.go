@@ -0,0 +1,91 @@
+package expert
+
+import (
+	"github.com/filecoin-project/go-state-types/abi"
+	cbg "github.com/whyrusleeping/cbor-gen"
+
+	"github.com/EpiK-Protocol/go-epik/chain/actors/adt"
+)
+
+type DataChanges struct {
+	Added    []DataOnChainInfo
+	Modified []DataModification
+	Removed  []DataOnChainInfo
+}
+
+type DataModification struct {
+	From DataOnChainInfo
+	To   DataOnChainInfo
+}
+
+func DiffDatas(pre, cur State) (*DataChanges, error) {
+	results := new(DataChanges)
+
+	if changed, err := pre.DatasChanged(cur); err != nil || !changed {
+		return results, err
+	}
+
+	pred, err := pre.datas()
+	if err != nil {
+		return nil, err
+	}
+
+	curd, err := cur.datas()
+	if err != nil {
+		return nil, err
+	}
+
+	err = adt.DiffAdtMap(pred, curd, &dataDiffer{results, pre, cur})
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// datas are keyed by the string form of their piece CID
+type stringKey string
+
+func (k stringKey) Key() string {
+	return string(k)
+}
+
+type dataDiffer struct {
+	Results    *DataChanges
+	pre, after State
+}
+
+func (m *dataDiffer) AsKey(key string) (abi.Keyer, error) {
+	return stringKey(key), nil
+}
+
+func (m *dataDiffer) Add(key string, val *cbg.Deferred) error {
+	d, err := m.after.decodeData(val)
+	if err != nil {
+		return err
+	}
+	m.Results.Added = append(m.Results.Added, *d)
+	return nil
+}
+
+func (m *dataDiffer) Modify(key string, from, to *cbg.Deferred) error {
+	fromD, err := m.pre.decodeData(from)
+	if err != nil {
+		return err
+	}
+	toD, err := m.after.decodeData(to)
+	if err != nil {
+		return err
+	}
+	m.Results.Modified = append(m.Results.Modified, DataModification{*fromD, *toD})
+	return nil
+}
+
+func (m *dataDiffer) Remove(key string, val *cbg.Deferred) error {
+	d, err := m.pre.decodeData(val)
+	if err != nil {
+		return err
+	}
+	m.Results.Removed = append(m.Results.Removed, *d)
+	return nil
+}
@@ -2,6 +2,7 @@ package expert
 
 import (
 	"github.com/ipfs/go-cid"
+	cbg "github.com/whyrusleeping/cbor-gen"
 	"golang.org/x/xerrors"
 
 	"github.com/filecoin-project/go-state-types/abi"
@@ -39,6 +40,10 @@ type State interface {
 	Info() (*ExpertInfo, error)
 	Datas() ([]*DataOnChainInfo, error)
 	Data(cid.Cid) (*DataOnChainInfo, error)
+	DatasChanged(State) (bool, error)
+
+	datas() (adt.Map, error)
+	decodeData(*cbg.Deferred) (*DataOnChainInfo, error)
 }
 
 type BatchImportDataParams = expert2.BatchImportDataParams
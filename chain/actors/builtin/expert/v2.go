@@ -1,7 +1,10 @@
 package expert
 
 import (
+	"bytes"
+
 	"github.com/ipfs/go-cid"
+	cbg "github.com/whyrusleeping/cbor-gen"
 	"golang.org/x/xerrors"
 
 	"github.com/EpiK-Protocol/go-epik/chain/actors/adt"
@@ -81,3 +84,25 @@ func (s *state2) Data(pieceCID cid.Cid) (*DataOnChainInfo, error) {
 	}
 	return &info, nil
 }
+
+func (s *state2) DatasChanged(otherState State) (bool, error) {
+	other, ok := otherState.(*state2)
+	if !ok {
+		// there's no way to compare different versions of the state, so let's
+		// just say that means the state of datas has changed
+		return true, nil
+	}
+	return !s.State.Datas.Equals(other.State.Datas), nil
+}
+
+func (s *state2) datas() (adt.Map, error) {
+	return adt2.AsMap(s.store, s.State.Datas, builtin.DefaultHamtBitwidth)
+}
+
+func (s *state2) decodeData(val *cbg.Deferred) (*DataOnChainInfo, error) {
+	var info DataOnChainInfo
+	if err := info.UnmarshalCBOR(bytes.NewReader(val.Raw)); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
@@ -0,0 +1,107 @@
+package expertfund
+
+import (
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	cbg "github.com/whyrusleeping/cbor-gen"
+
+	"github.com/EpiK-Protocol/go-epik/chain/actors/adt"
+)
+
+type ExpertChanges struct {
+	Added    []ExpertChange
+	Modified []ExpertModification
+	Removed  []ExpertChange
+}
+
+type ExpertChange struct {
+	Expert address.Address
+	Info   ExpertInfo
+}
+
+type ExpertModification struct {
+	Expert address.Address
+	From   ExpertInfo
+	To     ExpertInfo
+}
+
+func DiffExperts(pre, cur State) (*ExpertChanges, error) {
+	results := new(ExpertChanges)
+
+	if changed, err := pre.ExpertsChanged(cur); err != nil || !changed {
+		return results, err
+	}
+
+	pree, err := pre.experts()
+	if err != nil {
+		return nil, err
+	}
+
+	cure, err := cur.experts()
+	if err != nil {
+		return nil, err
+	}
+
+	err = adt.DiffAdtMap(pree, cure, &expertDiffer{results, pre, cur})
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+type expertDiffer struct {
+	Results    *ExpertChanges
+	pre, after State
+}
+
+func (m *expertDiffer) AsKey(key string) (abi.Keyer, error) {
+	addr, err := address.NewFromBytes([]byte(key))
+	if err != nil {
+		return nil, err
+	}
+	return abi.AddrKey(addr), nil
+}
+
+func (m *expertDiffer) Add(key string, val *cbg.Deferred) error {
+	addr, err := address.NewFromBytes([]byte(key))
+	if err != nil {
+		return err
+	}
+	info, err := m.after.decodeExpert(val)
+	if err != nil {
+		return err
+	}
+	m.Results.Added = append(m.Results.Added, ExpertChange{addr, *info})
+	return nil
+}
+
+func (m *expertDiffer) Modify(key string, from, to *cbg.Deferred) error {
+	addr, err := address.NewFromBytes([]byte(key))
+	if err != nil {
+		return err
+	}
+	fromInfo, err := m.pre.decodeExpert(from)
+	if err != nil {
+		return err
+	}
+	toInfo, err := m.after.decodeExpert(to)
+	if err != nil {
+		return err
+	}
+	m.Results.Modified = append(m.Results.Modified, ExpertModification{addr, *fromInfo, *toInfo})
+	return nil
+}
+
+func (m *expertDiffer) Remove(key string, val *cbg.Deferred) error {
+	addr, err := address.NewFromBytes([]byte(key))
+	if err != nil {
+		return err
+	}
+	info, err := m.pre.decodeExpert(val)
+	if err != nil {
+		return err
+	}
+	m.Results.Removed = append(m.Results.Removed, ExpertChange{addr, *info})
+	return nil
+}
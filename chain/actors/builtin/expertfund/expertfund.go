@@ -2,6 +2,7 @@ package expertfund
 
 import (
 	"github.com/ipfs/go-cid"
+	cbg "github.com/whyrusleeping/cbor-gen"
 	"golang.org/x/xerrors"
 
 	"github.com/filecoin-project/go-address"
@@ -45,6 +46,10 @@ type State interface {
 	Reward(abi.ChainEpoch, address.Address) (*ExpertReward, error)
 	DataThreshold() uint64
 	DailyThreshold() uint64
+	ExpertsChanged(State) (bool, error)
+
+	experts() (adt.Map, error)
+	decodeExpert(*cbg.Deferred) (*ExpertInfo, error)
 }
 
 type ExpertInfo = expertfund2.ExpertInfo
@@ -1,8 +1,11 @@
 package expertfund
 
 import (
+	"bytes"
+
 	"github.com/EpiK-Protocol/go-epik/chain/actors/adt"
 	"github.com/ipfs/go-cid"
+	cbg "github.com/whyrusleeping/cbor-gen"
 	"golang.org/x/xerrors"
 
 	"github.com/filecoin-project/go-address"
@@ -59,10 +62,28 @@ func (s *state3) ListAllExperts() ([]address.Address, error) {
 	return experts, nil
 }
 
+func (s *state3) ExpertsChanged(otherState State) (bool, error) {
+	other, ok := otherState.(*state3)
+	if !ok {
+		// there's no way to compare different versions of the state, so let's
+		// just say that means the state of experts has changed
+		return true, nil
+	}
+	return !s.State.Experts.Equals(other.State.Experts), nil
+}
+
 func (s *state3) experts() (adt.Map, error) {
 	return adt3.AsMap(s.store, s.Experts, builtin3.DefaultHamtBitwidth)
 }
 
+func (s *state3) decodeExpert(val *cbg.Deferred) (*ExpertInfo, error) {
+	var info ExpertInfo
+	if err := info.UnmarshalCBOR(bytes.NewReader(val.Raw)); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
 func (s *state3) ExpertInfo(a address.Address) (*ExpertInfo, error) {
 	return s.State.GetExpert(s.store, a)
 }
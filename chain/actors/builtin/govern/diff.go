@@ -0,0 +1,102 @@
+package govern
+
+import (
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	cbg "github.com/whyrusleeping/cbor-gen"
+
+	"github.com/EpiK-Protocol/go-epik/chain/actors/adt"
+)
+
+type GovernorChanges struct {
+	Added    []GovernorInfo
+	Modified []GovernorModification
+	Removed  []GovernorInfo
+}
+
+type GovernorModification struct {
+	From GovernorInfo
+	To   GovernorInfo
+}
+
+// DiffGovernors diffs the governors and their granted authorities.
+func DiffGovernors(pre, cur State) (*GovernorChanges, error) {
+	results := new(GovernorChanges)
+
+	if changed, err := pre.GovernorsChanged(cur); err != nil || !changed {
+		return results, err
+	}
+
+	preg, err := pre.governors()
+	if err != nil {
+		return nil, err
+	}
+
+	curg, err := cur.governors()
+	if err != nil {
+		return nil, err
+	}
+
+	err = adt.DiffAdtMap(preg, curg, &governorDiffer{results, pre, cur})
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+type governorDiffer struct {
+	Results    *GovernorChanges
+	pre, after State
+}
+
+func (m *governorDiffer) AsKey(key string) (abi.Keyer, error) {
+	addr, err := address.NewFromBytes([]byte(key))
+	if err != nil {
+		return nil, err
+	}
+	return abi.AddrKey(addr), nil
+}
+
+func (m *governorDiffer) Add(key string, val *cbg.Deferred) error {
+	addr, err := address.NewFromBytes([]byte(key))
+	if err != nil {
+		return err
+	}
+	gi, err := m.after.decodeGovernor(addr, val)
+	if err != nil {
+		return err
+	}
+	m.Results.Added = append(m.Results.Added, *gi)
+	return nil
+}
+
+func (m *governorDiffer) Modify(key string, from, to *cbg.Deferred) error {
+	addr, err := address.NewFromBytes([]byte(key))
+	if err != nil {
+		return err
+	}
+	fromGi, err := m.pre.decodeGovernor(addr, from)
+	if err != nil {
+		return err
+	}
+	toGi, err := m.after.decodeGovernor(addr, to)
+	if err != nil {
+		return err
+	}
+	m.Results.Modified = append(m.Results.Modified, GovernorModification{*fromGi, *toGi})
+	return nil
+}
+
+func (m *governorDiffer) Remove(key string, val *cbg.Deferred) error {
+	addr, err := address.NewFromBytes([]byte(key))
+	if err != nil {
+		return err
+	}
+	gi, err := m.pre.decodeGovernor(addr, val)
+	if err != nil {
+		return err
+	}
+	m.Results.Removed = append(m.Results.Removed, *gi)
+	return nil
+}
@@ -10,6 +10,7 @@ import (
 	builtin2 "github.com/filecoin-project/specs-actors/v2/actors/builtin"
 	power3 "github.com/filecoin-project/specs-actors/v2/actors/builtin/power"
 	"github.com/ipfs/go-cid"
+	cbg "github.com/whyrusleeping/cbor-gen"
 	"golang.org/x/xerrors"
 )
 
@@ -38,6 +39,10 @@ type State interface {
 	Supervior() address.Address
 	Governor(address.Address) (*GovernorInfo, error)
 	ListGovrnors() ([]*GovernorInfo, error)
+	GovernorsChanged(State) (bool, error)
+
+	governors() (adt.Map, error)
+	decodeGovernor(address.Address, *cbg.Deferred) (*GovernorInfo, error)
 }
 
 type GovernorInfo struct {
@@ -1,6 +1,7 @@
 package govern
 
 import (
+	"bytes"
 	"fmt"
 
 	"github.com/EpiK-Protocol/go-epik/chain/actors/adt"
@@ -10,6 +11,7 @@ import (
 	"github.com/filecoin-project/specs-actors/v2/actors/builtin"
 	"github.com/filecoin-project/specs-actors/v2/actors/builtin/govern"
 	"github.com/ipfs/go-cid"
+	cbg "github.com/whyrusleeping/cbor-gen"
 
 	adt2 "github.com/filecoin-project/specs-actors/v2/actors/util/adt"
 )
@@ -94,6 +96,36 @@ func (s *state) ListGovrnors() ([]*GovernorInfo, error) {
 	return ret, nil
 }
 
+func (s *state) GovernorsChanged(otherState State) (bool, error) {
+	other, ok := otherState.(*state)
+	if !ok {
+		// there's no way to compare different versions of the state, so let's
+		// just say that means the state of governors has changed
+		return true, nil
+	}
+	return !s.State.Governors.Equals(other.State.Governors), nil
+}
+
+func (s *state) governors() (adt.Map, error) {
+	return adt2.AsMap(s.store, s.Governors, builtin.DefaultHamtBitwidth)
+}
+
+func (s *state) decodeGovernor(addr address.Address, val *cbg.Deferred) (*GovernorInfo, error) {
+	var ga govern.GrantedAuthorities
+	if err := ga.UnmarshalCBOR(bytes.NewReader(val.Raw)); err != nil {
+		return nil, err
+	}
+
+	authorities, err := convert(s.store, ga)
+	if err != nil {
+		return nil, err
+	}
+	return &GovernorInfo{
+		Address:     addr,
+		Authorities: authorities,
+	}, nil
+}
+
 func convert(store adt.Store, ga govern.GrantedAuthorities) ([]Authority, error) {
 	codeMethods, err := adt2.AsMap(store, ga.CodeMethods, builtin.DefaultHamtBitwidth)
 	if err != nil {
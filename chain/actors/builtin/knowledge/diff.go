@@ -0,0 +1,115 @@
+package knowledge
+
+import (
+	"bytes"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	cbg "github.com/whyrusleeping/cbor-gen"
+
+	"github.com/EpiK-Protocol/go-epik/chain/actors/adt"
+)
+
+type TallyChanges struct {
+	Added    []PayeeAmount
+	Modified []PayeeAmountChange
+	Removed  []PayeeAmount
+}
+
+type PayeeAmount struct {
+	Payee  address.Address
+	Amount abi.TokenAmount
+}
+
+type PayeeAmountChange struct {
+	Payee address.Address
+	From  abi.TokenAmount
+	To    abi.TokenAmount
+}
+
+// DiffTally diffs the amounts paid to the payees of the knowledge fund.
+func DiffTally(pre, cur State) (*TallyChanges, error) {
+	results := new(TallyChanges)
+
+	if changed, err := pre.TallyChanged(cur); err != nil || !changed {
+		return results, err
+	}
+
+	pret, err := pre.tally()
+	if err != nil {
+		return nil, err
+	}
+
+	curt, err := cur.tally()
+	if err != nil {
+		return nil, err
+	}
+
+	err = adt.DiffAdtMap(pret, curt, &tallyDiffer{results})
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+type tallyDiffer struct {
+	Results *TallyChanges
+}
+
+func decodeAmount(val *cbg.Deferred) (abi.TokenAmount, error) {
+	var amt abi.TokenAmount
+	err := amt.UnmarshalCBOR(bytes.NewReader(val.Raw))
+	return amt, err
+}
+
+func (m *tallyDiffer) AsKey(key string) (abi.Keyer, error) {
+	addr, err := address.NewFromBytes([]byte(key))
+	if err != nil {
+		return nil, err
+	}
+	return abi.AddrKey(addr), nil
+}
+
+func (m *tallyDiffer) Add(key string, val *cbg.Deferred) error {
+	addr, err := address.NewFromBytes([]byte(key))
+	if err != nil {
+		return err
+	}
+	amt, err := decodeAmount(val)
+	if err != nil {
+		return err
+	}
+	m.Results.Added = append(m.Results.Added, PayeeAmount{addr, amt})
+	return nil
+}
+
+func (m *tallyDiffer) Modify(key string, from, to *cbg.Deferred) error {
+	addr, err := address.NewFromBytes([]byte(key))
+	if err != nil {
+		return err
+	}
+	fromAmt, err := decodeAmount(from)
+	if err != nil {
+		return err
+	}
+	toAmt, err := decodeAmount(to)
+	if err != nil {
+		return err
+	}
+	m.Results.Modified = append(m.Results.Modified, PayeeAmountChange{addr, fromAmt, toAmt})
+	return nil
+}
+
+func (m *tallyDiffer) Remove(key string, val *cbg.Deferred) error {
+	addr, err := address.NewFromBytes([]byte(key))
+	if err != nil {
+		return err
+	}
+	amt, err := decodeAmount(val)
+	if err != nil {
+		return err
+	}
+	m.Results.Removed = append(m.Results.Removed, PayeeAmount{addr, amt})
+	return nil
+}
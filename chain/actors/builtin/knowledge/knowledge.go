@@ -35,6 +35,9 @@ type State interface {
 	cbor.Marshaler
 
 	Info() (*Info, error)
+	TallyChanged(State) (bool, error)
+
+	tally() (adt.Map, error)
 }
 
 type Info struct {
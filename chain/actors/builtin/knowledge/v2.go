@@ -27,8 +27,22 @@ type state struct {
 	store adt.Store
 }
 
+func (s *state) TallyChanged(otherState State) (bool, error) {
+	other, ok := otherState.(*state)
+	if !ok {
+		// there's no way to compare different versions of the state, so let's
+		// just say that means the state of tally has changed
+		return true, nil
+	}
+	return !s.State.Tally.Equals(other.State.Tally), nil
+}
+
+func (s *state) tally() (adt.Map, error) {
+	return adt2.AsMap(s.store, s.Tally, builtin.DefaultHamtBitwidth)
+}
+
 func (s *state) Info() (*Info, error) {
-	tally, err := adt2.AsMap(s.store, s.Tally, builtin.DefaultHamtBitwidth)
+	tally, err := s.tally()
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,214 @@
+package retrieval
+
+import (
+	"bytes"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	cbg "github.com/whyrusleeping/cbor-gen"
+
+	"github.com/EpiK-Protocol/go-epik/chain/actors/adt"
+)
+
+type PledgeChanges struct {
+	Added    []PledgeChange
+	Modified []PledgeModification
+	Removed  []PledgeChange
+}
+
+// PledgeChange holds the amounts pledged by a pledger to its targets.
+type PledgeChange struct {
+	Pledger address.Address
+	Targets map[address.Address]abi.TokenAmount
+}
+
+type PledgeModification struct {
+	Pledger address.Address
+	From    map[address.Address]abi.TokenAmount
+	To      map[address.Address]abi.TokenAmount
+}
+
+func DiffPledges(pre, cur State) (*PledgeChanges, error) {
+	results := new(PledgeChanges)
+
+	if changed, err := pre.PledgesChanged(cur); err != nil || !changed {
+		return results, err
+	}
+
+	prep, err := pre.pledges()
+	if err != nil {
+		return nil, err
+	}
+
+	curp, err := cur.pledges()
+	if err != nil {
+		return nil, err
+	}
+
+	err = adt.DiffAdtMap(prep, curp, &pledgeDiffer{results, pre, cur})
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+type pledgeDiffer struct {
+	Results    *PledgeChanges
+	pre, after State
+}
+
+func (m *pledgeDiffer) AsKey(key string) (abi.Keyer, error) {
+	addr, err := address.NewFromBytes([]byte(key))
+	if err != nil {
+		return nil, err
+	}
+	return abi.AddrKey(addr), nil
+}
+
+func (m *pledgeDiffer) Add(key string, val *cbg.Deferred) error {
+	addr, err := address.NewFromBytes([]byte(key))
+	if err != nil {
+		return err
+	}
+	targets, err := m.after.decodePledge(val)
+	if err != nil {
+		return err
+	}
+	m.Results.Added = append(m.Results.Added, PledgeChange{addr, targets})
+	return nil
+}
+
+func (m *pledgeDiffer) Modify(key string, from, to *cbg.Deferred) error {
+	addr, err := address.NewFromBytes([]byte(key))
+	if err != nil {
+		return err
+	}
+	fromTargets, err := m.pre.decodePledge(from)
+	if err != nil {
+		return err
+	}
+	toTargets, err := m.after.decodePledge(to)
+	if err != nil {
+		return err
+	}
+	m.Results.Modified = append(m.Results.Modified, PledgeModification{addr, fromTargets, toTargets})
+	return nil
+}
+
+func (m *pledgeDiffer) Remove(key string, val *cbg.Deferred) error {
+	addr, err := address.NewFromBytes([]byte(key))
+	if err != nil {
+		return err
+	}
+	targets, err := m.pre.decodePledge(val)
+	if err != nil {
+		return err
+	}
+	m.Results.Removed = append(m.Results.Removed, PledgeChange{addr, targets})
+	return nil
+}
+
+type LockedChanges struct {
+	Added    []LockedChange
+	Modified []LockedModification
+	Removed  []LockedChange
+}
+
+// LockedChange holds the funds a pledger applied to withdraw.
+type LockedChange struct {
+	Pledger address.Address
+	Locked  LockedState
+}
+
+type LockedModification struct {
+	Pledger address.Address
+	From    LockedState
+	To      LockedState
+}
+
+func DiffLocked(pre, cur State) (*LockedChanges, error) {
+	results := new(LockedChanges)
+
+	if changed, err := pre.LockedChanged(cur); err != nil || !changed {
+		return results, err
+	}
+
+	prel, err := pre.lockedTable()
+	if err != nil {
+		return nil, err
+	}
+
+	curl, err := cur.lockedTable()
+	if err != nil {
+		return nil, err
+	}
+
+	err = adt.DiffAdtMap(prel, curl, &lockedDiffer{results})
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+type lockedDiffer struct {
+	Results *LockedChanges
+}
+
+func decodeLocked(val *cbg.Deferred) (LockedState, error) {
+	var ls LockedState
+	err := ls.UnmarshalCBOR(bytes.NewReader(val.Raw))
+	return ls, err
+}
+
+func (m *lockedDiffer) AsKey(key string) (abi.Keyer, error) {
+	addr, err := address.NewFromBytes([]byte(key))
+	if err != nil {
+		return nil, err
+	}
+	return abi.AddrKey(addr), nil
+}
+
+func (m *lockedDiffer) Add(key string, val *cbg.Deferred) error {
+	addr, err := address.NewFromBytes([]byte(key))
+	if err != nil {
+		return err
+	}
+	ls, err := decodeLocked(val)
+	if err != nil {
+		return err
+	}
+	m.Results.Added = append(m.Results.Added, LockedChange{addr, ls})
+	return nil
+}
+
+func (m *lockedDiffer) Modify(key string, from, to *cbg.Deferred) error {
+	addr, err := address.NewFromBytes([]byte(key))
+	if err != nil {
+		return err
+	}
+	fromLs, err := decodeLocked(from)
+	if err != nil {
+		return err
+	}
+	toLs, err := decodeLocked(to)
+	if err != nil {
+		return err
+	}
+	m.Results.Modified = append(m.Results.Modified, LockedModification{addr, fromLs, toLs})
+	return nil
+}
+
+func (m *lockedDiffer) Remove(key string, val *cbg.Deferred) error {
+	addr, err := address.NewFromBytes([]byte(key))
+	if err != nil {
+		return err
+	}
+	ls, err := decodeLocked(val)
+	if err != nil {
+		return err
+	}
+	m.Results.Removed = append(m.Results.Removed, LockedChange{addr, ls})
+	return nil
+}
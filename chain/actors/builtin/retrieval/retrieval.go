@@ -10,6 +10,7 @@ import (
 	builtin2 "github.com/filecoin-project/specs-actors/v2/actors/builtin"
 	retrieval2 "github.com/filecoin-project/specs-actors/v2/actors/builtin/retrieval"
 	"github.com/ipfs/go-cid"
+	cbg "github.com/whyrusleeping/cbor-gen"
 	"golang.org/x/xerrors"
 )
 
@@ -58,4 +59,10 @@ type State interface {
 	TotalRetrievalReward() (abi.TokenAmount, error)
 	PendingReward() (abi.TokenAmount, error)
 	ForEachState(func(addr address.Address, state *RetrievalState) error) error
+	PledgesChanged(State) (bool, error)
+	LockedChanged(State) (bool, error)
+
+	pledges() (adt.Map, error)
+	lockedTable() (adt.Map, error)
+	decodePledge(*cbg.Deferred) (map[address.Address]abi.TokenAmount, error)
 }
@@ -1,12 +1,16 @@
 package retrieval
 
 import (
+	"bytes"
+
+	cadt "github.com/EpiK-Protocol/go-epik/chain/actors/adt"
 	"github.com/filecoin-project/go-address"
 	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/filecoin-project/specs-actors/v2/actors/builtin"
 	retrieval2 "github.com/filecoin-project/specs-actors/v2/actors/builtin/retrieval"
 	"github.com/filecoin-project/specs-actors/v2/actors/util/adt"
 	"github.com/ipfs/go-cid"
+	cbg "github.com/whyrusleeping/cbor-gen"
 	"golang.org/x/xerrors"
 )
 
@@ -40,6 +44,10 @@ func (s *state) PledgesInfo(addr address.Address) (map[address.Address]abi.Token
 	if !found {
 		return nil, xerrors.Errorf("failed to find the pledge:%s", addr)
 	}
+	return s.pledgeTargets(&pledge)
+}
+
+func (s *state) pledgeTargets(pledge *PledgeState) (map[address.Address]abi.TokenAmount, error) {
 	tmap, err := adt.AsMap(s.store, pledge.Targets, builtin.DefaultHamtBitwidth)
 	if err != nil {
 		return nil, xerrors.Errorf("failed to load pledge target:%v", err)
@@ -144,3 +152,39 @@ func (s *state) ForEachState(cb func(addr address.Address, state *RetrievalState
 	}
 	return nil
 }
+
+func (s *state) PledgesChanged(otherState State) (bool, error) {
+	other, ok := otherState.(*state)
+	if !ok {
+		// there's no way to compare different versions of the state, so let's
+		// just say that means the state of pledges has changed
+		return true, nil
+	}
+	return !s.State.Pledges.Equals(other.State.Pledges), nil
+}
+
+func (s *state) pledges() (cadt.Map, error) {
+	return adt.AsMap(s.store, s.Pledges, builtin.DefaultHamtBitwidth)
+}
+
+func (s *state) LockedChanged(otherState State) (bool, error) {
+	other, ok := otherState.(*state)
+	if !ok {
+		// there's no way to compare different versions of the state, so let's
+		// just say that means the state of locked funds has changed
+		return true, nil
+	}
+	return !s.State.LockedTable.Equals(other.State.LockedTable), nil
+}
+
+func (s *state) lockedTable() (cadt.Map, error) {
+	return adt.AsMap(s.store, s.State.LockedTable, builtin.DefaultHamtBitwidth)
+}
+
+func (s *state) decodePledge(val *cbg.Deferred) (map[address.Address]abi.TokenAmount, error) {
+	var pledge PledgeState
+	if err := pledge.UnmarshalCBOR(bytes.NewReader(val.Raw)); err != nil {
+		return nil, err
+	}
+	return s.pledgeTargets(&pledge)
+}
@@ -0,0 +1,179 @@
+package vote
+
+import (
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	cbg "github.com/whyrusleeping/cbor-gen"
+
+	"github.com/EpiK-Protocol/go-epik/chain/actors/adt"
+)
+
+type CandidateChanges struct {
+	Added    []CandidateChange
+	Modified []CandidateModification
+	Removed  []CandidateChange
+}
+
+type CandidateChange struct {
+	Candidate address.Address
+	Info      CandidateInfo
+}
+
+type CandidateModification struct {
+	Candidate address.Address
+	From      CandidateInfo
+	To        CandidateInfo
+}
+
+func DiffCandidates(pre, cur State) (*CandidateChanges, error) {
+	results := new(CandidateChanges)
+
+	if changed, err := pre.CandidatesChanged(cur); err != nil || !changed {
+		return results, err
+	}
+
+	prec, err := pre.candidates()
+	if err != nil {
+		return nil, err
+	}
+
+	curc, err := cur.candidates()
+	if err != nil {
+		return nil, err
+	}
+
+	err = adt.DiffAdtMap(prec, curc, &candidateDiffer{results, pre, cur})
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+type candidateDiffer struct {
+	Results    *CandidateChanges
+	pre, after State
+}
+
+func (m *candidateDiffer) AsKey(key string) (abi.Keyer, error) {
+	addr, err := address.NewFromBytes([]byte(key))
+	if err != nil {
+		return nil, err
+	}
+	return abi.AddrKey(addr), nil
+}
+
+func (m *candidateDiffer) Add(key string, val *cbg.Deferred) error {
+	addr, err := address.NewFromBytes([]byte(key))
+	if err != nil {
+		return err
+	}
+	c, err := m.after.decodeCandidate(val)
+	if err != nil {
+		return err
+	}
+	m.Results.Added = append(m.Results.Added, CandidateChange{addr, *c})
+	return nil
+}
+
+func (m *candidateDiffer) Modify(key string, from, to *cbg.Deferred) error {
+	addr, err := address.NewFromBytes([]byte(key))
+	if err != nil {
+		return err
+	}
+	fromC, err := m.pre.decodeCandidate(from)
+	if err != nil {
+		return err
+	}
+	toC, err := m.after.decodeCandidate(to)
+	if err != nil {
+		return err
+	}
+	m.Results.Modified = append(m.Results.Modified, CandidateModification{addr, *fromC, *toC})
+	return nil
+}
+
+func (m *candidateDiffer) Remove(key string, val *cbg.Deferred) error {
+	addr, err := address.NewFromBytes([]byte(key))
+	if err != nil {
+		return err
+	}
+	c, err := m.pre.decodeCandidate(val)
+	if err != nil {
+		return err
+	}
+	m.Results.Removed = append(m.Results.Removed, CandidateChange{addr, *c})
+	return nil
+}
+
+// VoterChanges lists the ID addresses of the voters whose votes, rewards or
+// rescinding votes changed.
+type VoterChanges struct {
+	Added    []address.Address
+	Modified []address.Address
+	Removed  []address.Address
+}
+
+func DiffVoters(pre, cur State) (*VoterChanges, error) {
+	results := new(VoterChanges)
+
+	if changed, err := pre.VotersChanged(cur); err != nil || !changed {
+		return results, err
+	}
+
+	prev, err := pre.voters()
+	if err != nil {
+		return nil, err
+	}
+
+	curv, err := cur.voters()
+	if err != nil {
+		return nil, err
+	}
+
+	err = adt.DiffAdtMap(prev, curv, &voterDiffer{results})
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+type voterDiffer struct {
+	Results *VoterChanges
+}
+
+func (m *voterDiffer) AsKey(key string) (abi.Keyer, error) {
+	addr, err := address.NewFromBytes([]byte(key))
+	if err != nil {
+		return nil, err
+	}
+	return abi.AddrKey(addr), nil
+}
+
+func (m *voterDiffer) Add(key string, val *cbg.Deferred) error {
+	addr, err := address.NewFromBytes([]byte(key))
+	if err != nil {
+		return err
+	}
+	m.Results.Added = append(m.Results.Added, addr)
+	return nil
+}
+
+func (m *voterDiffer) Modify(key string, from, to *cbg.Deferred) error {
+	addr, err := address.NewFromBytes([]byte(key))
+	if err != nil {
+		return err
+	}
+	m.Results.Modified = append(m.Results.Modified, addr)
+	return nil
+}
+
+func (m *voterDiffer) Remove(key string, val *cbg.Deferred) error {
+	addr, err := address.NewFromBytes([]byte(key))
+	if err != nil {
+		return err
+	}
+	m.Results.Removed = append(m.Results.Removed, addr)
+	return nil
+}
@@ -1,6 +1,8 @@
 package vote
 
 import (
+	"bytes"
+
 	"github.com/EpiK-Protocol/go-epik/chain/actors/adt"
 	"github.com/filecoin-project/go-address"
 	"github.com/filecoin-project/go-state-types/abi"
@@ -8,6 +10,7 @@ import (
 	"github.com/filecoin-project/specs-actors/v2/actors/builtin"
 	"github.com/filecoin-project/specs-actors/v2/actors/builtin/vote"
 	"github.com/ipfs/go-cid"
+	cbg "github.com/whyrusleeping/cbor-gen"
 	"golang.org/x/xerrors"
 
 	adt2 "github.com/filecoin-project/specs-actors/v2/actors/util/adt"
@@ -131,3 +134,42 @@ func (s *state) VoterInfo(vaddr address.Address, currEpoch abi.ChainEpoch, actor
 		Candidates:          cands,
 	}, nil
 }
+
+func (s *state) CandidatesChanged(otherState State) (bool, error) {
+	other, ok := otherState.(*state)
+	if !ok {
+		// there's no way to compare different versions of the state, so let's
+		// just say that means the state of candidates has changed
+		return true, nil
+	}
+	return !s.State.Candidates.Equals(other.State.Candidates), nil
+}
+
+func (s *state) candidates() (adt.Map, error) {
+	return adt2.AsMap(s.store, s.Candidates, builtin.DefaultHamtBitwidth)
+}
+
+func (s *state) VotersChanged(otherState State) (bool, error) {
+	other, ok := otherState.(*state)
+	if !ok {
+		// there's no way to compare different versions of the state, so let's
+		// just say that means the state of voters has changed
+		return true, nil
+	}
+	return !s.State.Voters.Equals(other.State.Voters), nil
+}
+
+func (s *state) voters() (adt.Map, error) {
+	return adt2.AsMap(s.store, s.Voters, builtin.DefaultHamtBitwidth)
+}
+
+func (s *state) decodeCandidate(val *cbg.Deferred) (*CandidateInfo, error) {
+	var c vote.Candidate
+	if err := c.UnmarshalCBOR(bytes.NewReader(val.Raw)); err != nil {
+		return nil, err
+	}
+	return &CandidateInfo{
+		Votes:   c.Votes,
+		Blocked: c.IsBlocked(),
+	}, nil
+}
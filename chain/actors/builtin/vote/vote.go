@@ -9,6 +9,7 @@ import (
 	"github.com/filecoin-project/go-state-types/cbor"
 	builtin2 "github.com/filecoin-project/specs-actors/v2/actors/builtin"
 	"github.com/ipfs/go-cid"
+	cbg "github.com/whyrusleeping/cbor-gen"
 	"golang.org/x/xerrors"
 )
 
@@ -37,6 +38,12 @@ type State interface {
 	Tally() (*Tally, error)
 	VoterInfo(addr address.Address, currEpoch abi.ChainEpoch, actorBalance abi.TokenAmount) (*VoterInfo, error)
 	ListVoterInfos(currEpoch abi.ChainEpoch, actorBalance abi.TokenAmount) ([]*VoterInfo, error)
+	CandidatesChanged(State) (bool, error)
+	VotersChanged(State) (bool, error)
+
+	candidates() (adt.Map, error)
+	voters() (adt.Map, error)
+	decodeCandidate(*cbg.Deferred) (*CandidateInfo, error)
 }
 
 type Tally struct {
@@ -47,6 +54,11 @@ type Tally struct {
 	Blocked          map[string]bool
 }
 
+type CandidateInfo struct {
+	Votes   abi.TokenAmount
+	Blocked bool
+}
+
 type VoterInfo struct {
 	Voter               address.Address
 	UnlockingVotes      abi.TokenAmount
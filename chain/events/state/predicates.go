@@ -13,9 +13,15 @@ import (
 
 	"github.com/EpiK-Protocol/go-epik/blockstore"
 	"github.com/EpiK-Protocol/go-epik/chain/actors/adt"
+	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/expert"
+	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/expertfund"
+	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/govern"
 	init_ "github.com/EpiK-Protocol/go-epik/chain/actors/builtin/init"
+	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/knowledge"
 	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/market"
 	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/paych"
+	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/retrieval"
+	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/vote"
 	"github.com/EpiK-Protocol/go-epik/chain/types"
 )
 
@@ -432,3 +438,271 @@ func (sp *StatePredicates) OnAddressMapChange() DiffInitActorStateFunc {
 		return true, addressChanges, nil
 	}
 }
+
+// DiffExpertStateFunc is function that compares two states of an expert actor
+type DiffExpertStateFunc func(ctx context.Context, oldState expert.State, newState expert.State) (changed bool, user UserData, err error)
+
+// OnExpertActorChanged calls diffExpertState when the state changes for the expert actor
+func (sp *StatePredicates) OnExpertActorChanged(expertAddr address.Address, diffExpertState DiffExpertStateFunc) DiffTipSetKeyFunc {
+	return sp.OnActorStateChanged(expertAddr, func(ctx context.Context, oldActorState, newActorState *types.Actor) (changed bool, user UserData, err error) {
+		oldState, err := expert.Load(adt.WrapStore(ctx, sp.cst), oldActorState)
+		if err != nil {
+			return false, nil, err
+		}
+		newState, err := expert.Load(adt.WrapStore(ctx, sp.cst), newActorState)
+		if err != nil {
+			return false, nil, err
+		}
+		return diffExpertState(ctx, oldState, newState)
+	})
+}
+
+// OnExpertDataAdded reports the datas newly registered by an expert
+func (sp *StatePredicates) OnExpertDataAdded() DiffExpertStateFunc {
+	return func(ctx context.Context, oldState, newState expert.State) (changed bool, user UserData, err error) {
+		dataChanges, err := expert.DiffDatas(oldState, newState)
+		if err != nil {
+			return false, nil, err
+		}
+		if len(dataChanges.Added) == 0 {
+			return false, nil, nil
+		}
+		return true, dataChanges.Added, nil
+	}
+}
+
+// ExpertInfoChange is a change of the status or votes of an expert
+type ExpertInfoChange struct {
+	From *expert.ExpertInfo
+	To   *expert.ExpertInfo
+}
+
+// OnExpertInfoChanged reports changes of the status, implications or votes of an expert
+func (sp *StatePredicates) OnExpertInfoChanged() DiffExpertStateFunc {
+	return func(ctx context.Context, oldState, newState expert.State) (changed bool, user UserData, err error) {
+		from, err := oldState.Info()
+		if err != nil {
+			return false, nil, err
+		}
+		to, err := newState.Info()
+		if err != nil {
+			return false, nil, err
+		}
+		if from.Status == to.Status &&
+			from.ImplicatedTimes == to.ImplicatedTimes &&
+			from.CurrentVotes.Equals(to.CurrentVotes) &&
+			from.Owner == to.Owner {
+			return false, nil, nil
+		}
+		return true, &ExpertInfoChange{From: from, To: to}, nil
+	}
+}
+
+// DiffExpertFundStateFunc is function that compares two states of the expertfund actor
+type DiffExpertFundStateFunc func(ctx context.Context, oldState expertfund.State, newState expertfund.State) (changed bool, user UserData, err error)
+
+// OnExpertFundActorChanged calls diffExpertFundState when the state changes for the expertfund actor
+func (sp *StatePredicates) OnExpertFundActorChanged(diffExpertFundState DiffExpertFundStateFunc) DiffTipSetKeyFunc {
+	return sp.OnActorStateChanged(expertfund.Address, func(ctx context.Context, oldActorState, newActorState *types.Actor) (changed bool, user UserData, err error) {
+		oldState, err := expertfund.Load(adt.WrapStore(ctx, sp.cst), oldActorState)
+		if err != nil {
+			return false, nil, err
+		}
+		newState, err := expertfund.Load(adt.WrapStore(ctx, sp.cst), newActorState)
+		if err != nil {
+			return false, nil, err
+		}
+		return diffExpertFundState(ctx, oldState, newState)
+	})
+}
+
+// OnFundedExpertsChanged reports experts added to, changed or removed from the expertfund
+func (sp *StatePredicates) OnFundedExpertsChanged() DiffExpertFundStateFunc {
+	return func(ctx context.Context, oldState, newState expertfund.State) (changed bool, user UserData, err error) {
+		expertChanges, err := expertfund.DiffExperts(oldState, newState)
+		if err != nil {
+			return false, nil, err
+		}
+		if len(expertChanges.Added)+len(expertChanges.Modified)+len(expertChanges.Removed) == 0 {
+			return false, nil, nil
+		}
+		return true, expertChanges, nil
+	}
+}
+
+// DiffVoteStateFunc is function that compares two states of the vote actor
+type DiffVoteStateFunc func(ctx context.Context, oldState vote.State, newState vote.State) (changed bool, user UserData, err error)
+
+// OnVoteActorChanged calls diffVoteState when the state changes for the vote actor
+func (sp *StatePredicates) OnVoteActorChanged(diffVoteState DiffVoteStateFunc) DiffTipSetKeyFunc {
+	return sp.OnActorStateChanged(vote.Address, func(ctx context.Context, oldActorState, newActorState *types.Actor) (changed bool, user UserData, err error) {
+		oldState, err := vote.Load(adt.WrapStore(ctx, sp.cst), oldActorState)
+		if err != nil {
+			return false, nil, err
+		}
+		newState, err := vote.Load(adt.WrapStore(ctx, sp.cst), newActorState)
+		if err != nil {
+			return false, nil, err
+		}
+		return diffVoteState(ctx, oldState, newState)
+	})
+}
+
+// OnCandidatesChanged reports changes of the votes of the candidates
+func (sp *StatePredicates) OnCandidatesChanged() DiffVoteStateFunc {
+	return func(ctx context.Context, oldState, newState vote.State) (changed bool, user UserData, err error) {
+		candidateChanges, err := vote.DiffCandidates(oldState, newState)
+		if err != nil {
+			return false, nil, err
+		}
+		if len(candidateChanges.Added)+len(candidateChanges.Modified)+len(candidateChanges.Removed) == 0 {
+			return false, nil, nil
+		}
+		return true, candidateChanges, nil
+	}
+}
+
+// OnVoterChanged reports changes of the given voters, identified by their ID
+// addresses, or of all voters if none is given
+func (sp *StatePredicates) OnVoterChanged(voters ...address.Address) DiffVoteStateFunc {
+	watched := make(map[address.Address]struct{}, len(voters))
+	for _, v := range voters {
+		watched[v] = struct{}{}
+	}
+	filter := func(addrs []address.Address) []address.Address {
+		if len(watched) == 0 {
+			return addrs
+		}
+		var out []address.Address
+		for _, a := range addrs {
+			if _, ok := watched[a]; ok {
+				out = append(out, a)
+			}
+		}
+		return out
+	}
+
+	return func(ctx context.Context, oldState, newState vote.State) (changed bool, user UserData, err error) {
+		voterChanges, err := vote.DiffVoters(oldState, newState)
+		if err != nil {
+			return false, nil, err
+		}
+		voterChanges.Added = filter(voterChanges.Added)
+		voterChanges.Modified = filter(voterChanges.Modified)
+		voterChanges.Removed = filter(voterChanges.Removed)
+		if len(voterChanges.Added)+len(voterChanges.Modified)+len(voterChanges.Removed) == 0 {
+			return false, nil, nil
+		}
+		return true, voterChanges, nil
+	}
+}
+
+// DiffKnowledgeStateFunc is function that compares two states of the knowledge fund actor
+type DiffKnowledgeStateFunc func(ctx context.Context, oldState knowledge.State, newState knowledge.State) (changed bool, user UserData, err error)
+
+// OnKnowledgeActorChanged calls diffKnowledgeState when the state changes for the knowledge fund actor
+func (sp *StatePredicates) OnKnowledgeActorChanged(diffKnowledgeState DiffKnowledgeStateFunc) DiffTipSetKeyFunc {
+	return sp.OnActorStateChanged(knowledge.Address, func(ctx context.Context, oldActorState, newActorState *types.Actor) (changed bool, user UserData, err error) {
+		oldState, err := knowledge.Load(adt.WrapStore(ctx, sp.cst), oldActorState)
+		if err != nil {
+			return false, nil, err
+		}
+		newState, err := knowledge.Load(adt.WrapStore(ctx, sp.cst), newActorState)
+		if err != nil {
+			return false, nil, err
+		}
+		return diffKnowledgeState(ctx, oldState, newState)
+	})
+}
+
+// OnKnowledgeTallyChanged reports changes of the amounts paid to knowledge fund payees
+func (sp *StatePredicates) OnKnowledgeTallyChanged() DiffKnowledgeStateFunc {
+	return func(ctx context.Context, oldState, newState knowledge.State) (changed bool, user UserData, err error) {
+		tallyChanges, err := knowledge.DiffTally(oldState, newState)
+		if err != nil {
+			return false, nil, err
+		}
+		if len(tallyChanges.Added)+len(tallyChanges.Modified)+len(tallyChanges.Removed) == 0 {
+			return false, nil, nil
+		}
+		return true, tallyChanges, nil
+	}
+}
+
+// DiffGovernStateFunc is function that compares two states of the govern actor
+type DiffGovernStateFunc func(ctx context.Context, oldState govern.State, newState govern.State) (changed bool, user UserData, err error)
+
+// OnGovernActorChanged calls diffGovernState when the state changes for the govern actor
+func (sp *StatePredicates) OnGovernActorChanged(diffGovernState DiffGovernStateFunc) DiffTipSetKeyFunc {
+	return sp.OnActorStateChanged(govern.Address, func(ctx context.Context, oldActorState, newActorState *types.Actor) (changed bool, user UserData, err error) {
+		oldState, err := govern.Load(adt.WrapStore(ctx, sp.cst), oldActorState)
+		if err != nil {
+			return false, nil, err
+		}
+		newState, err := govern.Load(adt.WrapStore(ctx, sp.cst), newActorState)
+		if err != nil {
+			return false, nil, err
+		}
+		return diffGovernState(ctx, oldState, newState)
+	})
+}
+
+// OnGovernorAuthorityChanged reports governors granted, changed or revoked
+func (sp *StatePredicates) OnGovernorAuthorityChanged() DiffGovernStateFunc {
+	return func(ctx context.Context, oldState, newState govern.State) (changed bool, user UserData, err error) {
+		governorChanges, err := govern.DiffGovernors(oldState, newState)
+		if err != nil {
+			return false, nil, err
+		}
+		if len(governorChanges.Added)+len(governorChanges.Modified)+len(governorChanges.Removed) == 0 {
+			return false, nil, nil
+		}
+		return true, governorChanges, nil
+	}
+}
+
+// DiffRetrievalStateFunc is function that compares two states of the retrieval fund actor
+type DiffRetrievalStateFunc func(ctx context.Context, oldState retrieval.State, newState retrieval.State) (changed bool, user UserData, err error)
+
+// OnRetrievalActorChanged calls diffRetrievalState when the state changes for the retrieval fund actor
+func (sp *StatePredicates) OnRetrievalActorChanged(diffRetrievalState DiffRetrievalStateFunc) DiffTipSetKeyFunc {
+	return sp.OnActorStateChanged(retrieval.Address, func(ctx context.Context, oldActorState, newActorState *types.Actor) (changed bool, user UserData, err error) {
+		oldState, err := retrieval.Load(adt.WrapStore(ctx, sp.cst), oldActorState)
+		if err != nil {
+			return false, nil, err
+		}
+		newState, err := retrieval.Load(adt.WrapStore(ctx, sp.cst), newActorState)
+		if err != nil {
+			return false, nil, err
+		}
+		return diffRetrievalState(ctx, oldState, newState)
+	})
+}
+
+// OnRetrievalPledgeChanged reports changes of the retrieval pledges
+func (sp *StatePredicates) OnRetrievalPledgeChanged() DiffRetrievalStateFunc {
+	return func(ctx context.Context, oldState, newState retrieval.State) (changed bool, user UserData, err error) {
+		pledgeChanges, err := retrieval.DiffPledges(oldState, newState)
+		if err != nil {
+			return false, nil, err
+		}
+		if len(pledgeChanges.Added)+len(pledgeChanges.Modified)+len(pledgeChanges.Removed) == 0 {
+			return false, nil, nil
+		}
+		return true, pledgeChanges, nil
+	}
+}
+
+// OnRetrievalLockedChanged reports changes of the funds locked for withdrawal
+func (sp *StatePredicates) OnRetrievalLockedChanged() DiffRetrievalStateFunc {
+	return func(ctx context.Context, oldState, newState retrieval.State) (changed bool, user UserData, err error) {
+		lockedChanges, err := retrieval.DiffLocked(oldState, newState)
+		if err != nil {
+			return false, nil, err
+		}
+		if len(lockedChanges.Added)+len(lockedChanges.Modified)+len(lockedChanges.Removed) == 0 {
+			return false, nil, nil
+		}
+		return true, lockedChanges, nil
+	}
+}
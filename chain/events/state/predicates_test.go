@@ -6,18 +6,30 @@ import (
 
 	test "github.com/EpiK-Protocol/go-epik/chain/events/state/mock"
 
+	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/expert"
+	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/govern"
+	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/knowledge"
 	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/miner"
+	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/retrieval"
+	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/vote"
 
 	"github.com/ipfs/go-cid"
 	cbornode "github.com/ipfs/go-ipld-cbor"
 	"github.com/stretchr/testify/require"
 
 	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-bitfield"
 	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/cbor"
 	builtin2 "github.com/filecoin-project/specs-actors/v2/actors/builtin"
+	expert2 "github.com/filecoin-project/specs-actors/v2/actors/builtin/expert"
+	govern2 "github.com/filecoin-project/specs-actors/v2/actors/builtin/govern"
+	knowledge2 "github.com/filecoin-project/specs-actors/v2/actors/builtin/knowledge"
 	market2 "github.com/filecoin-project/specs-actors/v2/actors/builtin/market"
 	miner2 "github.com/filecoin-project/specs-actors/v2/actors/builtin/miner"
+	retrieval2 "github.com/filecoin-project/specs-actors/v2/actors/builtin/retrieval"
+	vote2 "github.com/filecoin-project/specs-actors/v2/actors/builtin/vote"
 	adt2 "github.com/filecoin-project/specs-actors/v2/actors/util/adt"
 	tutils "github.com/filecoin-project/specs-actors/v2/support/testing"
 
@@ -412,6 +424,338 @@ func TestMinerSectorChange(t *testing.T) {
 	require.Equal(t, si1Ext, sectorChanges.Extended[0].From) */
 }
 
+// predicateCase expects pred to report want between the states at two
+// tipsets, or no change if want is nil.
+type predicateCase struct {
+	name     string
+	pred     DiffTipSetKeyFunc
+	from, to types.TipSetKey
+	want     UserData
+}
+
+func runPredicateCases(ctx context.Context, t *testing.T, cases []predicateCase) {
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			changed, val, err := tc.pred(ctx, tc.from, tc.to)
+			require.NoError(t, err)
+			require.Equal(t, tc.want != nil, changed)
+			require.Equal(t, tc.want, val)
+		})
+	}
+}
+
+func TestVotePredicates(t *testing.T) {
+	ctx := context.Background()
+	bs := bstore.NewMemorySync()
+	store := adt2.WrapStore(ctx, cbornode.NewCborStore(bs))
+
+	c1, c2 := tutils.NewIDAddr(t, 101), tutils.NewIDAddr(t, 102)
+	v1, v2 := tutils.NewIDAddr(t, 201), tutils.NewIDAddr(t, 202)
+
+	candidate := func(votes int64, blockEpoch abi.ChainEpoch) cbor.Marshaler {
+		return &vote2.Candidate{
+			BlockEpoch:              blockEpoch,
+			BlockCumEarningsPerVote: big.Zero(),
+			Votes:                   abi.NewTokenAmount(votes),
+		}
+	}
+	emptyTally := createMap(t, store, nil)
+	voter := func(withdrawable int64) cbor.Marshaler {
+		return &vote2.Voter{
+			SettleCumEarningsPerVote: big.Zero(),
+			Withdrawable:             abi.NewTokenAmount(withdrawable),
+			Tally:                    emptyTally,
+			PrevTally:                emptyTally,
+		}
+	}
+
+	// 0 -> 1: c2 added, c1 voted, v2 added, v1 withdrew
+	// 1 -> 2: c1 and v1 removed
+	// 2 -> 3: only v2 changed
+	api := test.NewMockAPI(bs)
+	keys := mockActorStates(t, api, builtin2.VoteFundActorCodeID,
+		createVoteState(ctx, t, store,
+			map[abi.Keyer]cbor.Marshaler{abi.AddrKey(c1): candidate(10, 0)},
+			map[abi.Keyer]cbor.Marshaler{abi.AddrKey(v1): voter(1)}),
+		createVoteState(ctx, t, store,
+			map[abi.Keyer]cbor.Marshaler{abi.AddrKey(c1): candidate(15, 0), abi.AddrKey(c2): candidate(5, 3)},
+			map[abi.Keyer]cbor.Marshaler{abi.AddrKey(v1): voter(2), abi.AddrKey(v2): voter(1)}),
+		createVoteState(ctx, t, store,
+			map[abi.Keyer]cbor.Marshaler{abi.AddrKey(c2): candidate(5, 3)},
+			map[abi.Keyer]cbor.Marshaler{abi.AddrKey(v2): voter(1)}),
+		createVoteState(ctx, t, store,
+			map[abi.Keyer]cbor.Marshaler{abi.AddrKey(c2): candidate(5, 3)},
+			map[abi.Keyer]cbor.Marshaler{abi.AddrKey(v2): voter(2)}),
+	)
+
+	preds := NewStatePredicates(api)
+	candidates := preds.OnVoteActorChanged(preds.OnCandidatesChanged())
+	voters := preds.OnVoteActorChanged(preds.OnVoterChanged())
+	voter1 := preds.OnVoteActorChanged(preds.OnVoterChanged(v1))
+	voter2 := preds.OnVoteActorChanged(preds.OnVoterChanged(v2))
+
+	runPredicateCases(ctx, t, []predicateCase{
+		{"same tipset", candidates, keys[0], keys[0], nil},
+		{"candidate added and voted", candidates, keys[0], keys[1], &vote.CandidateChanges{
+			Added: []vote.CandidateChange{
+				{Candidate: c2, Info: vote.CandidateInfo{Votes: abi.NewTokenAmount(5), Blocked: true}},
+			},
+			Modified: []vote.CandidateModification{
+				{Candidate: c1, From: vote.CandidateInfo{Votes: abi.NewTokenAmount(10)}, To: vote.CandidateInfo{Votes: abi.NewTokenAmount(15)}},
+			},
+		}},
+		{"candidate removed", candidates, keys[1], keys[2], &vote.CandidateChanges{
+			Removed: []vote.CandidateChange{
+				{Candidate: c1, Info: vote.CandidateInfo{Votes: abi.NewTokenAmount(15)}},
+			},
+		}},
+		{"candidates unchanged", candidates, keys[2], keys[3], nil},
+		{"voters changed", voters, keys[0], keys[1], &vote.VoterChanges{
+			Added:    []address.Address{v2},
+			Modified: []address.Address{v1},
+		}},
+		{"watched voter added", voter2, keys[0], keys[1], &vote.VoterChanges{
+			Added: []address.Address{v2},
+		}},
+		{"watched voter removed", voter1, keys[1], keys[2], &vote.VoterChanges{
+			Removed: []address.Address{v1},
+		}},
+		{"other voter changed", voter1, keys[2], keys[3], nil},
+	})
+}
+
+func TestRetrievalPredicates(t *testing.T) {
+	ctx := context.Background()
+	bs := bstore.NewMemorySync()
+	store := adt2.WrapStore(ctx, cbornode.NewCborStore(bs))
+
+	p1, p2 := tutils.NewIDAddr(t, 101), tutils.NewIDAddr(t, 102)
+	t1, t2 := tutils.NewIDAddr(t, 201), tutils.NewIDAddr(t, 202)
+
+	pledge := func(targets map[address.Address]int64) cbor.Marshaler {
+		entries := make(map[abi.Keyer]cbor.Marshaler)
+		total := big.Zero()
+		for target, amount := range targets {
+			a := abi.NewTokenAmount(amount)
+			entries[abi.AddrKey(target)] = &a
+			total = big.Add(total, a)
+		}
+		return &retrieval2.PledgeState{
+			Targets: createMap(t, store, entries),
+			Amount:  total,
+		}
+	}
+	locked := func(amount int64, epoch abi.ChainEpoch) cbor.Marshaler {
+		return &retrieval2.LockedState{Amount: abi.NewTokenAmount(amount), ApplyEpoch: epoch}
+	}
+
+	// 0 -> 1: p1 pledged to t2 too, p2 pledged, p1 applied for withdrawal
+	// 1 -> 2: p1 pledges removed
+	// 2 -> 3: p1 applied for withdrawal again
+	api := test.NewMockAPI(bs)
+	keys := mockActorStates(t, api, builtin2.RetrievalFundActorCodeID,
+		createRetrievalState(ctx, t, store,
+			map[abi.Keyer]cbor.Marshaler{abi.AddrKey(p1): pledge(map[address.Address]int64{t1: 10})},
+			nil),
+		createRetrievalState(ctx, t, store,
+			map[abi.Keyer]cbor.Marshaler{
+				abi.AddrKey(p1): pledge(map[address.Address]int64{t1: 10, t2: 5}),
+				abi.AddrKey(p2): pledge(map[address.Address]int64{t1: 7}),
+			},
+			map[abi.Keyer]cbor.Marshaler{abi.AddrKey(p1): locked(3, 100)}),
+		createRetrievalState(ctx, t, store,
+			map[abi.Keyer]cbor.Marshaler{abi.AddrKey(p2): pledge(map[address.Address]int64{t1: 7})},
+			map[abi.Keyer]cbor.Marshaler{abi.AddrKey(p1): locked(3, 100)}),
+		createRetrievalState(ctx, t, store,
+			map[abi.Keyer]cbor.Marshaler{abi.AddrKey(p2): pledge(map[address.Address]int64{t1: 7})},
+			map[abi.Keyer]cbor.Marshaler{abi.AddrKey(p1): locked(4, 120)}),
+	)
+
+	preds := NewStatePredicates(api)
+	pledges := preds.OnRetrievalActorChanged(preds.OnRetrievalPledgeChanged())
+	lockeds := preds.OnRetrievalActorChanged(preds.OnRetrievalLockedChanged())
+
+	runPredicateCases(ctx, t, []predicateCase{
+		{"same tipset", pledges, keys[0], keys[0], nil},
+		{"pledges added", pledges, keys[0], keys[1], &retrieval.PledgeChanges{
+			Added: []retrieval.PledgeChange{
+				{Pledger: p2, Targets: map[address.Address]abi.TokenAmount{t1: abi.NewTokenAmount(7)}},
+			},
+			Modified: []retrieval.PledgeModification{{
+				Pledger: p1,
+				From:    map[address.Address]abi.TokenAmount{t1: abi.NewTokenAmount(10)},
+				To:      map[address.Address]abi.TokenAmount{t1: abi.NewTokenAmount(10), t2: abi.NewTokenAmount(5)},
+			}},
+		}},
+		{"pledges removed", pledges, keys[1], keys[2], &retrieval.PledgeChanges{
+			Removed: []retrieval.PledgeChange{{
+				Pledger: p1,
+				Targets: map[address.Address]abi.TokenAmount{t1: abi.NewTokenAmount(10), t2: abi.NewTokenAmount(5)},
+			}},
+		}},
+		{"pledges unchanged", pledges, keys[2], keys[3], nil},
+		{"funds locked", lockeds, keys[0], keys[1], &retrieval.LockedChanges{
+			Added: []retrieval.LockedChange{
+				{Pledger: p1, Locked: retrieval.LockedState{Amount: abi.NewTokenAmount(3), ApplyEpoch: 100}},
+			},
+		}},
+		{"locked unchanged", lockeds, keys[1], keys[2], nil},
+		{"funds locked again", lockeds, keys[2], keys[3], &retrieval.LockedChanges{
+			Modified: []retrieval.LockedModification{{
+				Pledger: p1,
+				From:    retrieval.LockedState{Amount: abi.NewTokenAmount(3), ApplyEpoch: 100},
+				To:      retrieval.LockedState{Amount: abi.NewTokenAmount(4), ApplyEpoch: 120},
+			}},
+		}},
+		{"funds withdrawn", lockeds, keys[3], keys[0], &retrieval.LockedChanges{
+			Removed: []retrieval.LockedChange{
+				{Pledger: p1, Locked: retrieval.LockedState{Amount: abi.NewTokenAmount(4), ApplyEpoch: 120}},
+			},
+		}},
+	})
+}
+
+func TestExpertPredicates(t *testing.T) {
+	ctx := context.Background()
+	bs := bstore.NewMemorySync()
+	store := adt2.WrapStore(ctx, cbornode.NewCborStore(bs))
+
+	data := func(piece string, redundancy uint64) expert.DataOnChainInfo {
+		return expert.DataOnChainInfo{RootID: "root" + piece, PieceID: piece, PieceSize: 2048, Redundancy: redundancy}
+	}
+	d1, d2, d2r := data("1", 1), data("2", 1), data("2", 2)
+
+	// 0 -> 1: d2 added
+	// 1 -> 2: d1 removed
+	// 2 -> 3: d2 stored again
+	api := test.NewMockAPI(bs)
+	keys := mockActorStates(t, api, builtin2.ExpertActorCodeID,
+		createExpertState(ctx, t, store, d1),
+		createExpertState(ctx, t, store, d1, d2),
+		createExpertState(ctx, t, store, d2),
+		createExpertState(ctx, t, store, d2r),
+	)
+
+	expertAddr := tutils.NewIDAddr(t, 101)
+	preds := NewStatePredicates(api)
+	added := preds.OnExpertActorChanged(expertAddr, preds.OnExpertDataAdded())
+	datas := preds.OnExpertActorChanged(expertAddr, func(ctx context.Context, oldState, newState expert.State) (bool, UserData, error) {
+		changes, err := expert.DiffDatas(oldState, newState)
+		return true, changes, err
+	})
+
+	runPredicateCases(ctx, t, []predicateCase{
+		{"same tipset", added, keys[0], keys[0], nil},
+		{"data added", added, keys[0], keys[1], []expert.DataOnChainInfo{d2}},
+		{"data removed", added, keys[1], keys[2], nil},
+		{"data modified", added, keys[2], keys[3], nil},
+		{"diff removed", datas, keys[1], keys[2], &expert.DataChanges{
+			Removed: []expert.DataOnChainInfo{d1},
+		}},
+		{"diff modified", datas, keys[2], keys[3], &expert.DataChanges{
+			Modified: []expert.DataModification{{From: d2, To: d2r}},
+		}},
+	})
+}
+
+func TestKnowledgePredicates(t *testing.T) {
+	ctx := context.Background()
+	bs := bstore.NewMemorySync()
+	store := adt2.WrapStore(ctx, cbornode.NewCborStore(bs))
+
+	a, b := tutils.NewIDAddr(t, 101), tutils.NewIDAddr(t, 102)
+	amount := func(v int64) cbor.Marshaler {
+		amt := abi.NewTokenAmount(v)
+		return &amt
+	}
+
+	// 0 -> 1: b paid, a paid more
+	// 1 -> 2: a removed
+	// 2 -> 3: only the payee changed
+	api := test.NewMockAPI(bs)
+	keys := mockActorStates(t, api, builtin2.KnowledgeFundActorCodeID,
+		createKnowledgeState(ctx, t, store, a, map[abi.Keyer]cbor.Marshaler{abi.AddrKey(a): amount(10)}),
+		createKnowledgeState(ctx, t, store, b, map[abi.Keyer]cbor.Marshaler{abi.AddrKey(a): amount(15), abi.AddrKey(b): amount(5)}),
+		createKnowledgeState(ctx, t, store, b, map[abi.Keyer]cbor.Marshaler{abi.AddrKey(b): amount(5)}),
+		createKnowledgeState(ctx, t, store, a, map[abi.Keyer]cbor.Marshaler{abi.AddrKey(b): amount(5)}),
+	)
+
+	preds := NewStatePredicates(api)
+	tally := preds.OnKnowledgeActorChanged(preds.OnKnowledgeTallyChanged())
+
+	runPredicateCases(ctx, t, []predicateCase{
+		{"same tipset", tally, keys[0], keys[0], nil},
+		{"payees paid", tally, keys[0], keys[1], &knowledge.TallyChanges{
+			Added:    []knowledge.PayeeAmount{{Payee: b, Amount: abi.NewTokenAmount(5)}},
+			Modified: []knowledge.PayeeAmountChange{{Payee: a, From: abi.NewTokenAmount(10), To: abi.NewTokenAmount(15)}},
+		}},
+		{"payee removed", tally, keys[1], keys[2], &knowledge.TallyChanges{
+			Removed: []knowledge.PayeeAmount{{Payee: a, Amount: abi.NewTokenAmount(15)}},
+		}},
+		{"tally unchanged", tally, keys[2], keys[3], nil},
+	})
+}
+
+func TestGovernPredicates(t *testing.T) {
+	ctx := context.Background()
+	bs := bstore.NewMemorySync()
+	store := adt2.WrapStore(ctx, cbornode.NewCborStore(bs))
+
+	g1, g2 := tutils.NewIDAddr(t, 101), tutils.NewIDAddr(t, 102)
+	supervisor, other := tutils.NewIDAddr(t, 201), tutils.NewIDAddr(t, 202)
+
+	granted := func(code cid.Cid, methods ...uint64) cbor.Marshaler {
+		bf := bitfield.NewFromSet(methods)
+		return &govern2.GrantedAuthorities{
+			CodeMethods: createMap(t, store, map[abi.Keyer]cbor.Marshaler{abi.CidKey(code): &bf}),
+		}
+	}
+	info := func(governor address.Address, code cid.Cid, methods ...abi.MethodNum) govern.GovernorInfo {
+		return govern.GovernorInfo{
+			Address:     governor,
+			Authorities: []govern.Authority{{ActorCodeID: code, Methods: methods}},
+		}
+	}
+
+	// 0 -> 1: g2 granted, g1 granted one more method
+	// 1 -> 2: g1 revoked
+	// 2 -> 3: only the supervisor changed
+	api := test.NewMockAPI(bs)
+	keys := mockActorStates(t, api, builtin2.GovernActorCodeID,
+		createGovernState(ctx, t, store, supervisor, map[abi.Keyer]cbor.Marshaler{
+			abi.AddrKey(g1): granted(builtin2.StorageMinerActorCodeID, 1),
+		}),
+		createGovernState(ctx, t, store, supervisor, map[abi.Keyer]cbor.Marshaler{
+			abi.AddrKey(g1): granted(builtin2.StorageMinerActorCodeID, 1, 2),
+			abi.AddrKey(g2): granted(builtin2.StoragePowerActorCodeID, 3),
+		}),
+		createGovernState(ctx, t, store, supervisor, map[abi.Keyer]cbor.Marshaler{
+			abi.AddrKey(g2): granted(builtin2.StoragePowerActorCodeID, 3),
+		}),
+		createGovernState(ctx, t, store, other, map[abi.Keyer]cbor.Marshaler{
+			abi.AddrKey(g2): granted(builtin2.StoragePowerActorCodeID, 3),
+		}),
+	)
+
+	preds := NewStatePredicates(api)
+	governors := preds.OnGovernActorChanged(preds.OnGovernorAuthorityChanged())
+
+	runPredicateCases(ctx, t, []predicateCase{
+		{"same tipset", governors, keys[0], keys[0], nil},
+		{"governors granted", governors, keys[0], keys[1], &govern.GovernorChanges{
+			Added: []govern.GovernorInfo{info(g2, builtin2.StoragePowerActorCodeID, 3)},
+			Modified: []govern.GovernorModification{{
+				From: info(g1, builtin2.StorageMinerActorCodeID, 1),
+				To:   info(g1, builtin2.StorageMinerActorCodeID, 1, 2),
+			}},
+		}},
+		{"governor revoked", governors, keys[1], keys[2], &govern.GovernorChanges{
+			Removed: []govern.GovernorInfo{info(g1, builtin2.StorageMinerActorCodeID, 1, 2)},
+		}},
+		{"governors unchanged", governors, keys[2], keys[3], nil},
+	})
+}
+
 type balance struct {
 	available abi.TokenAmount
 	locked    abi.TokenAmount
@@ -583,3 +927,82 @@ func dealEquality(expected market2.DealState, actual market.DealState) bool {
 		expected.SectorStartEpoch == actual.SectorStartEpoch &&
 		expected.SlashEpoch == actual.SlashEpoch
 }
+
+// mockActorStates stores each state of an actor at a tipset of its own
+func mockActorStates(t *testing.T, api *test.MockAPI, code cid.Cid, heads ...cid.Cid) []types.TipSetKey {
+	keys := make([]types.TipSetKey, len(heads))
+	for i, head := range heads {
+		ts, err := test.MockTipset(tutils.NewIDAddr(t, 1000), uint64(i+1))
+		require.NoError(t, err)
+		api.SetActor(ts.Key(), &types.Actor{Head: head, Code: code})
+		keys[i] = ts.Key()
+	}
+	return keys
+}
+
+func createMap(t *testing.T, store adt2.Store, entries map[abi.Keyer]cbor.Marshaler) cid.Cid {
+	m, err := adt2.MakeEmptyMap(store, builtin2.DefaultHamtBitwidth)
+	require.NoError(t, err)
+	for k, v := range entries {
+		require.NoError(t, m.Put(k, v))
+	}
+	root, err := m.Root()
+	require.NoError(t, err)
+	return root
+}
+
+func createVoteState(ctx context.Context, t *testing.T, store adt2.Store, candidates, voters map[abi.Keyer]cbor.Marshaler) cid.Cid {
+	state, err := vote2.ConstructState(store, tutils.NewIDAddr(t, 1))
+	require.NoError(t, err)
+	state.Candidates = createMap(t, store, candidates)
+	state.Voters = createMap(t, store, voters)
+
+	stateC, err := store.Put(ctx, state)
+	require.NoError(t, err)
+	return stateC
+}
+
+func createRetrievalState(ctx context.Context, t *testing.T, store adt2.Store, pledges, locked map[abi.Keyer]cbor.Marshaler) cid.Cid {
+	state, err := retrieval2.ConstructState(store)
+	require.NoError(t, err)
+	state.Pledges = createMap(t, store, pledges)
+	state.LockedTable = createMap(t, store, locked)
+
+	stateC, err := store.Put(ctx, state)
+	require.NoError(t, err)
+	return stateC
+}
+
+func createExpertState(ctx context.Context, t *testing.T, store adt2.Store, datas ...expert.DataOnChainInfo) cid.Cid {
+	state, err := expert2.ConstructState(store, dummyCid, expert2.ExpertStateRegistered)
+	require.NoError(t, err)
+	entries := make(map[abi.Keyer]cbor.Marshaler)
+	for i := range datas {
+		entries[adt2.StringKey(datas[i].PieceID)] = &datas[i]
+	}
+	state.Datas = createMap(t, store, entries)
+
+	stateC, err := store.Put(ctx, state)
+	require.NoError(t, err)
+	return stateC
+}
+
+func createKnowledgeState(ctx context.Context, t *testing.T, store adt2.Store, payee address.Address, tally map[abi.Keyer]cbor.Marshaler) cid.Cid {
+	state, err := knowledge2.ConstructState(store, payee)
+	require.NoError(t, err)
+	state.Tally = createMap(t, store, tally)
+
+	stateC, err := store.Put(ctx, state)
+	require.NoError(t, err)
+	return stateC
+}
+
+func createGovernState(ctx context.Context, t *testing.T, store adt2.Store, supervisor address.Address, governors map[abi.Keyer]cbor.Marshaler) cid.Cid {
+	state, err := govern2.ConstructState(store, supervisor)
+	require.NoError(t, err)
+	state.Governors = createMap(t, store, governors)
+
+	stateC, err := store.Put(ctx, state)
+	require.NoError(t, err)
+	return stateC
+}
@@ -0,0 +1,380 @@
+package wallet
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/xerrors"
+
+	"github.com/EpiK-Protocol/go-epik/chain/types"
+)
+
+const (
+	// KEncryption is the keystore entry holding the key derivation parameters,
+	// present once the wallet keys are encrypted.
+	KEncryption = "keystore-encryption"
+	// kEncryptingPrefix marks the copy of a key kept while it's encrypted,
+	// until the plaintext entry is replaced.
+	kEncryptingPrefix = "encrypting-"
+
+	KTEncrypted        types.KeyType = "encrypted"
+	KTEncryptionParams types.KeyType = "encryption-params"
+)
+
+// scrypt parameters of newly encrypted keystores, as recommended for
+// interactive logins.
+const (
+	scryptN      = 1 << 15
+	scryptR      = 8
+	scryptP      = 1
+	scryptKeyLen = chacha20poly1305.KeySize
+	saltLen      = 32
+)
+
+var (
+	ErrWalletLocked     = xerrors.New("wallet is locked")
+	ErrWrongPassphrase  = xerrors.New("wrong passphrase")
+	ErrNotEncrypted     = xerrors.New("wallet keys are not encrypted; set a passphrase first")
+	ErrAlreadyEncrypted = xerrors.New("wallet keys are already encrypted")
+)
+
+type encryptionParams struct {
+	Salt []byte
+	N    int
+	R    int
+	P    int
+	// Check is a value sealed with the derived key, to tell a wrong
+	// passphrase apart.
+	Check []byte
+}
+
+// sealedKey is stored as the PrivateKey of KTEncrypted entries.
+type sealedKey struct {
+	// Address of the key, so that the default address is known while the
+	// wallet is locked.
+	Address    string
+	Nonce      []byte
+	Ciphertext []byte
+}
+
+// encryptedKeyStore encrypts the wallet keys, the entries named with
+// KNamePrefix, KTrashPrefix and KDefault, in the underlying keystore. Other
+// entries, like the libp2p host key, are passed through.
+//
+// Until a passphrase is set with setPassphrase, nothing is encrypted.
+// Plaintext keys left in an encrypted keystore are still readable, and get
+// encrypted on the next unlock.
+type encryptedKeyStore struct {
+	types.KeyStore
+
+	lk     sync.RWMutex
+	params *encryptionParams // nil when not encrypted
+	key    []byte            // nil when locked
+}
+
+func newEncryptedKeyStore(ks types.KeyStore) (*encryptedKeyStore, error) {
+	eks := &encryptedKeyStore{KeyStore: ks}
+
+	ki, err := ks.Get(KEncryption)
+	switch {
+	case err == nil:
+		var params encryptionParams
+		if err := json.Unmarshal(ki.PrivateKey, &params); err != nil {
+			return nil, xerrors.Errorf("decoding keystore encryption params: %w", err)
+		}
+		eks.params = &params
+	case xerrors.Is(err, types.ErrKeyInfoNotFound):
+	default:
+		return nil, xerrors.Errorf("getting keystore encryption params: %w", err)
+	}
+
+	return eks, nil
+}
+
+func isWalletKey(name string) bool {
+	return strings.HasPrefix(name, KNamePrefix) ||
+		strings.HasPrefix(name, KTrashPrefix) ||
+		name == KDefault
+}
+
+func (eks *encryptedKeyStore) encrypted() bool {
+	eks.lk.RLock()
+	defer eks.lk.RUnlock()
+	return eks.params != nil
+}
+
+func (eks *encryptedKeyStore) locked() bool {
+	eks.lk.RLock()
+	defer eks.lk.RUnlock()
+	return eks.params != nil && eks.key == nil
+}
+
+func (eks *encryptedKeyStore) Get(name string) (types.KeyInfo, error) {
+	ki, err := eks.KeyStore.Get(name)
+	if err != nil || ki.Type != KTEncrypted || !isWalletKey(name) {
+		return ki, err
+	}
+
+	eks.lk.RLock()
+	defer eks.lk.RUnlock()
+
+	if eks.key == nil {
+		return types.KeyInfo{}, xerrors.Errorf("getting key '%s': %w", name, ErrWalletLocked)
+	}
+	return open(eks.key, name, ki)
+}
+
+func (eks *encryptedKeyStore) Put(name string, ki types.KeyInfo) error {
+	if !isWalletKey(name) {
+		return eks.KeyStore.Put(name, ki)
+	}
+
+	eks.lk.RLock()
+	defer eks.lk.RUnlock()
+
+	if eks.params == nil {
+		return eks.KeyStore.Put(name, ki)
+	}
+	if eks.key == nil {
+		return xerrors.Errorf("saving key '%s': %w", name, ErrWalletLocked)
+	}
+
+	sealed, err := seal(eks.key, name, ki)
+	if err != nil {
+		return err
+	}
+	return eks.KeyStore.Put(name, sealed)
+}
+
+// address returns the address stored alongside an encrypted key, which is
+// readable while the wallet is locked.
+func (eks *encryptedKeyStore) address(name string) (string, error) {
+	ki, err := eks.KeyStore.Get(name)
+	if err != nil {
+		return "", err
+	}
+	if ki.Type != KTEncrypted {
+		k, err := NewKey(ki)
+		if err != nil {
+			return "", err
+		}
+		return k.Address.String(), nil
+	}
+
+	var sk sealedKey
+	if err := json.Unmarshal(ki.PrivateKey, &sk); err != nil {
+		return "", xerrors.Errorf("decoding encrypted key '%s': %w", name, err)
+	}
+	if sk.Address == "" {
+		return "", xerrors.Errorf("getting key '%s': %w", name, ErrWalletLocked)
+	}
+	return sk.Address, nil
+}
+
+// setPassphrase encrypts the plaintext wallet keys with a key derived from a
+// new passphrase, leaving the keystore unlocked.
+func (eks *encryptedKeyStore) setPassphrase(passphrase string) error {
+	eks.lk.Lock()
+	defer eks.lk.Unlock()
+
+	if eks.params != nil {
+		return ErrAlreadyEncrypted
+	}
+
+	params := encryptionParams{
+		Salt: make([]byte, saltLen),
+		N:    scryptN,
+		R:    scryptR,
+		P:    scryptP,
+	}
+	if _, err := rand.Read(params.Salt); err != nil {
+		return xerrors.Errorf("generating salt: %w", err)
+	}
+	key, err := deriveKey(passphrase, &params)
+	if err != nil {
+		return err
+	}
+	check, err := seal(key, KEncryption, types.KeyInfo{})
+	if err != nil {
+		return err
+	}
+	params.Check = check.PrivateKey
+
+	b, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	if err := eks.KeyStore.Put(KEncryption, types.KeyInfo{Type: KTEncryptionParams, PrivateKey: b}); err != nil {
+		return xerrors.Errorf("saving keystore encryption params: %w", err)
+	}
+	eks.params, eks.key = &params, key
+
+	return eks.encryptAll()
+}
+
+// unlock derives the key from the passphrase, and encrypts the plaintext
+// wallet keys left in the keystore with it.
+func (eks *encryptedKeyStore) unlock(passphrase string) error {
+	eks.lk.Lock()
+	defer eks.lk.Unlock()
+
+	if eks.params == nil {
+		return ErrNotEncrypted
+	}
+
+	key, err := deriveKey(passphrase, eks.params)
+	if err != nil {
+		return err
+	}
+	if _, err := open(key, KEncryption, types.KeyInfo{Type: KTEncrypted, PrivateKey: eks.params.Check}); err != nil {
+		return ErrWrongPassphrase
+	}
+	eks.key = key
+
+	return eks.encryptAll()
+}
+
+func (eks *encryptedKeyStore) lock() {
+	eks.lk.Lock()
+	defer eks.lk.Unlock()
+
+	for i := range eks.key {
+		eks.key[i] = 0
+	}
+	eks.key = nil
+}
+
+// encryptAll replaces the plaintext wallet keys with encrypted ones. An
+// encrypted copy is saved before the plaintext key is deleted, so that the key
+// can be recovered from it, if replacing the key is interrupted.
+func (eks *encryptedKeyStore) encryptAll() error {
+	names, err := eks.KeyStore.List()
+	if err != nil {
+		return xerrors.Errorf("listing keystore: %w", err)
+	}
+
+	present := make(map[string]bool, len(names))
+	for _, name := range names {
+		present[name] = true
+	}
+
+	for _, name := range names {
+		if !strings.HasPrefix(name, kEncryptingPrefix) {
+			continue
+		}
+		orig := strings.TrimPrefix(name, kEncryptingPrefix)
+		if !present[orig] {
+			ki, err := eks.KeyStore.Get(name)
+			if err != nil {
+				return xerrors.Errorf("getting encrypted copy of '%s': %w", orig, err)
+			}
+			if err := eks.KeyStore.Put(orig, ki); err != nil {
+				return xerrors.Errorf("restoring '%s' from encrypted copy: %w", orig, err)
+			}
+			present[orig] = true
+		}
+		if err := eks.KeyStore.Delete(name); err != nil {
+			return xerrors.Errorf("deleting encrypted copy of '%s': %w", orig, err)
+		}
+	}
+
+	for name := range present {
+		if !isWalletKey(name) {
+			continue
+		}
+		ki, err := eks.KeyStore.Get(name)
+		if err != nil {
+			return xerrors.Errorf("getting key '%s': %w", name, err)
+		}
+		if ki.Type == KTEncrypted {
+			continue
+		}
+
+		sealed, err := seal(eks.key, name, ki)
+		if err != nil {
+			return err
+		}
+		if err := eks.KeyStore.Put(kEncryptingPrefix+name, sealed); err != nil {
+			return xerrors.Errorf("saving encrypted copy of '%s': %w", name, err)
+		}
+		if err := eks.KeyStore.Delete(name); err != nil {
+			return xerrors.Errorf("deleting plaintext key '%s': %w", name, err)
+		}
+		if err := eks.KeyStore.Put(name, sealed); err != nil {
+			return xerrors.Errorf("saving encrypted key '%s': %w", name, err)
+		}
+		if err := eks.KeyStore.Delete(kEncryptingPrefix + name); err != nil {
+			return xerrors.Errorf("deleting encrypted copy of '%s': %w", name, err)
+		}
+		log.Infof("encrypted wallet key %s", name)
+	}
+
+	return nil
+}
+
+func deriveKey(passphrase string, params *encryptionParams) ([]byte, error) {
+	key, err := scrypt.Key([]byte(passphrase), params.Salt, params.N, params.R, params.P, scryptKeyLen)
+	if err != nil {
+		return nil, xerrors.Errorf("deriving key from passphrase: %w", err)
+	}
+	return key, nil
+}
+
+// seal encrypts the key info, authenticating the name it's stored under, so
+// that entries can't be swapped.
+func seal(key []byte, name string, ki types.KeyInfo) (types.KeyInfo, error) {
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return types.KeyInfo{}, err
+	}
+
+	plaintext, err := json.Marshal(ki)
+	if err != nil {
+		return types.KeyInfo{}, xerrors.Errorf("encoding key '%s': %w", name, err)
+	}
+
+	sk := sealedKey{Nonce: make([]byte, aead.NonceSize())}
+	if _, err := rand.Read(sk.Nonce); err != nil {
+		return types.KeyInfo{}, xerrors.Errorf("generating nonce: %w", err)
+	}
+	sk.Ciphertext = aead.Seal(nil, sk.Nonce, plaintext, []byte(name))
+	if k, err := NewKey(ki); err == nil {
+		sk.Address = k.Address.String()
+	}
+
+	b, err := json.Marshal(sk)
+	if err != nil {
+		return types.KeyInfo{}, err
+	}
+	return types.KeyInfo{Type: KTEncrypted, PrivateKey: b}, nil
+}
+
+func open(key []byte, name string, ki types.KeyInfo) (types.KeyInfo, error) {
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return types.KeyInfo{}, err
+	}
+
+	var sk sealedKey
+	if err := json.Unmarshal(ki.PrivateKey, &sk); err != nil {
+		return types.KeyInfo{}, xerrors.Errorf("decoding encrypted key '%s': %w", name, err)
+	}
+	if len(sk.Nonce) != aead.NonceSize() {
+		return types.KeyInfo{}, xerrors.Errorf("decoding encrypted key '%s': bad nonce length %d", name, len(sk.Nonce))
+	}
+
+	plaintext, err := aead.Open(nil, sk.Nonce, sk.Ciphertext, []byte(name))
+	if err != nil {
+		return types.KeyInfo{}, xerrors.Errorf("decrypting key '%s': %w", name, err)
+	}
+
+	var out types.KeyInfo
+	if err := json.Unmarshal(plaintext, &out); err != nil {
+		return types.KeyInfo{}, xerrors.Errorf("decoding decrypted key '%s': %w", name, err)
+	}
+	return out, nil
+}
@@ -0,0 +1,155 @@
+package wallet
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+
+	"github.com/EpiK-Protocol/go-epik/api"
+	"github.com/EpiK-Protocol/go-epik/chain/types"
+)
+
+func TestEncryptedKeyStore(t *testing.T) {
+	ctx := context.Background()
+	ks := NewMemKeyStore()
+	require.NoError(t, ks.Put("libp2p-host", types.KeyInfo{Type: "libp2p-host", PrivateKey: []byte("host")}))
+
+	w, err := NewWallet(ks)
+	require.NoError(t, err)
+
+	def, err := w.WalletNew(ctx, types.KTSecp256k1)
+	require.NoError(t, err)
+	other, err := w.WalletNew(ctx, types.KTSecp256k1)
+	require.NoError(t, err)
+	require.NoError(t, w.WalletDelete(ctx, other))
+	other, err = w.WalletNew(ctx, types.KTSecp256k1)
+	require.NoError(t, err)
+
+	require.True(t, xerrors.Is(w.WalletLock(ctx), ErrNotEncrypted))
+	// unlocking doesn't set a passphrase
+	require.True(t, xerrors.Is(w.WalletUnlock(ctx, "secret", 0), ErrNotEncrypted))
+	require.NotContains(t, ks.m, KEncryption)
+
+	// setting the passphrase encrypts the existing keys
+	require.NoError(t, w.WalletSetPassphrase(ctx, "secret"))
+	require.True(t, xerrors.Is(w.WalletSetPassphrase(ctx, "other"), ErrAlreadyEncrypted))
+	for name, ki := range ks.m {
+		if name == KEncryption {
+			require.Equal(t, KTEncryptionParams, ki.Type)
+			continue
+		}
+		if isWalletKey(name) {
+			require.Equal(t, KTEncrypted, ki.Type, name)
+		} else {
+			require.Equal(t, []byte("host"), ki.PrivateKey, "%s isn't encrypted", name)
+		}
+	}
+
+	sig, err := w.WalletSign(ctx, def, []byte("data"), api.MsgMeta{})
+	require.NoError(t, err)
+	require.NotNil(t, sig)
+
+	require.NoError(t, w.WalletLock(ctx))
+
+	_, err = w.WalletSign(ctx, other, []byte("data"), api.MsgMeta{})
+	require.True(t, xerrors.Is(err, ErrWalletLocked), err)
+	_, err = w.WalletNew(ctx, types.KTSecp256k1)
+	require.True(t, xerrors.Is(err, ErrWalletLocked), err)
+
+	has, err := w.WalletHas(ctx, other)
+	require.NoError(t, err)
+	require.True(t, has)
+	list, err := w.WalletList(ctx)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	a, err := w.GetDefault(ctx)
+	require.NoError(t, err)
+	require.Equal(t, def, a)
+
+	// a restarted node starts locked
+	w, err = NewWallet(ks)
+	require.NoError(t, err)
+	_, err = w.WalletExport(ctx, def)
+	require.True(t, xerrors.Is(err, ErrWalletLocked), err)
+
+	require.True(t, xerrors.Is(w.WalletUnlock(ctx, "wrong", 0), ErrWrongPassphrase))
+	require.NoError(t, w.WalletUnlock(ctx, "secret", 0))
+	_, err = w.WalletSign(ctx, other, []byte("data"), api.MsgMeta{})
+	require.NoError(t, err)
+
+	// keys added while unlocked are encrypted
+	added, err := w.WalletNew(ctx, types.KTSecp256k1)
+	require.NoError(t, err)
+	require.Equal(t, KTEncrypted, ks.m[KNamePrefix+added.String()].Type)
+
+	// encrypted keys can't be swapped
+	ks.m[KNamePrefix+other.String()] = ks.m[KNamePrefix+added.String()]
+	w, err = NewWallet(ks)
+	require.NoError(t, err)
+	require.NoError(t, w.WalletUnlock(ctx, "secret", 0))
+	_, err = w.WalletExport(ctx, other)
+	require.Error(t, err)
+}
+
+func TestEncryptedKeyStoreRecovery(t *testing.T) {
+	ctx := context.Background()
+	ks := NewMemKeyStore()
+
+	w, err := NewWallet(ks)
+	require.NoError(t, err)
+	addr, err := w.WalletNew(ctx, types.KTSecp256k1)
+	require.NoError(t, err)
+	require.NoError(t, w.WalletSetPassphrase(ctx, "secret"))
+
+	// interrupted while replacing a plaintext key, after deleting it
+	k, err := GenerateKey(types.KTSecp256k1)
+	require.NoError(t, err)
+	name := KNamePrefix + k.Address.String()
+	sealed, err := seal(w.encrypted.key, name, k.KeyInfo)
+	require.NoError(t, err)
+	require.NoError(t, ks.Put(kEncryptingPrefix+name, sealed))
+
+	// and a plaintext key left behind
+	plain, err := GenerateKey(types.KTSecp256k1)
+	require.NoError(t, err)
+	require.NoError(t, ks.Put(KNamePrefix+plain.Address.String(), plain.KeyInfo))
+
+	w, err = NewWallet(ks)
+	require.NoError(t, err)
+	require.NoError(t, w.WalletUnlock(ctx, "secret", 0))
+
+	_, ok := ks.m[kEncryptingPrefix+name]
+	require.False(t, ok)
+	for _, a := range []*Key{k, plain} {
+		ki, err := w.WalletExport(ctx, a.Address)
+		require.NoError(t, err)
+		require.Equal(t, a.PrivateKey, ki.PrivateKey)
+		require.Equal(t, KTEncrypted, ks.m[KNamePrefix+a.Address.String()].Type)
+	}
+
+	has, err := w.WalletHas(ctx, addr)
+	require.NoError(t, err)
+	require.True(t, has)
+}
+
+func TestWalletUnlockTimeout(t *testing.T) {
+	ctx := context.Background()
+
+	w, err := NewWallet(NewMemKeyStore())
+	require.NoError(t, err)
+	addr, err := w.WalletNew(ctx, types.KTSecp256k1)
+	require.NoError(t, err)
+	require.NoError(t, w.WalletSetPassphrase(ctx, "secret"))
+	require.NoError(t, w.WalletLock(ctx))
+
+	require.NoError(t, w.WalletUnlock(ctx, "secret", 50*time.Millisecond))
+	_, err = w.WalletSign(ctx, addr, []byte("data"), api.MsgMeta{})
+	require.NoError(t, err)
+
+	require.Eventually(t, w.encrypted.locked, 5*time.Second, 10*time.Millisecond)
+	_, err = w.WalletSign(ctx, addr, []byte("data"), api.MsgMeta{})
+	require.True(t, xerrors.Is(err, ErrWalletLocked), err)
+}
@@ -5,6 +5,7 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/filecoin-project/go-address"
 	"github.com/filecoin-project/go-state-types/crypto"
@@ -27,8 +28,10 @@ const (
 )
 
 type LocalWallet struct {
-	keys     map[address.Address]*Key
-	keystore types.KeyStore
+	keys      map[address.Address]*Key
+	keystore  types.KeyStore
+	encrypted *encryptedKeyStore
+	lockTimer *time.Timer
 
 	lk sync.Mutex
 }
@@ -38,10 +41,23 @@ type Default interface {
 	SetDefault(ctx context.Context, a address.Address) error
 }
 
+// Locker unlocks and locks the wallet keys encrypted at rest.
+type Locker interface {
+	WalletSetPassphrase(ctx context.Context, passphrase string) error
+	WalletUnlock(ctx context.Context, passphrase string, timeout time.Duration) error
+	WalletLock(ctx context.Context) error
+}
+
 func NewWallet(keystore types.KeyStore) (*LocalWallet, error) {
+	eks, err := newEncryptedKeyStore(keystore)
+	if err != nil {
+		return nil, err
+	}
+
 	w := &LocalWallet{
-		keys:     make(map[address.Address]*Key),
-		keystore: keystore,
+		keys:      make(map[address.Address]*Key),
+		keystore:  eks,
+		encrypted: eks,
 	}
 
 	return w, nil
@@ -199,6 +215,13 @@ func (w *LocalWallet) GetDefault(ctx context.Context) (address.Address, error) {
 
 	ki, err := w.keystore.Get(KDefault)
 	if err != nil {
+		if xerrors.Is(err, ErrWalletLocked) {
+			a, err := w.encrypted.address(KDefault)
+			if err != nil {
+				return address.Undef, xerrors.Errorf("failed to get default key: %w", err)
+			}
+			return address.NewFromString(a)
+		}
 		if xerrors.Is(err, types.ErrKeyInfoNotFound) {
 			list, lerr := w.WalletList(ctx)
 			if lerr != nil {
@@ -207,6 +230,9 @@ func (w *LocalWallet) GetDefault(ctx context.Context) (address.Address, error) {
 			if len(list) > 0 {
 				a := list[0]
 				kii, err := w.keystore.Get(KNamePrefix + a.String())
+				if xerrors.Is(err, ErrWalletLocked) {
+					return a, nil // saved as the default once unlocked
+				}
 				if err != nil {
 					return address.Undef, err
 				}
@@ -311,11 +337,91 @@ func (w *LocalWallet) WalletNewFromSeed(typ types.KeyType, seed []byte) (address
 func (w *LocalWallet) WalletHas(ctx context.Context, addr address.Address) (bool, error) {
 	k, err := w.findKey(addr)
 	if err != nil {
+		if xerrors.Is(err, ErrWalletLocked) {
+			return true, nil
+		}
 		return false, err
 	}
 	return k != nil, nil
 }
 
+// WalletSetPassphrase encrypts the keys in the keystore with a new
+// passphrase. The wallet stays unlocked until it's locked.
+func (w *LocalWallet) WalletSetPassphrase(ctx context.Context, passphrase string) error {
+	if w.encrypted == nil {
+		return xerrors.Errorf("wallet has no keystore")
+	}
+	if passphrase == "" {
+		return xerrors.Errorf("passphrase must not be empty")
+	}
+
+	w.lk.Lock()
+	defer w.lk.Unlock()
+
+	if err := w.encrypted.setPassphrase(passphrase); err != nil {
+		return xerrors.Errorf("setting wallet passphrase: %w", err)
+	}
+	return nil
+}
+
+// WalletUnlock unlocks the keys encrypted at rest. With a non-zero timeout,
+// the wallet is locked again once it passes.
+func (w *LocalWallet) WalletUnlock(ctx context.Context, passphrase string, timeout time.Duration) error {
+	if w.encrypted == nil {
+		return xerrors.Errorf("wallet has no keystore")
+	}
+
+	w.lk.Lock()
+	defer w.lk.Unlock()
+
+	if err := w.encrypted.unlock(passphrase); err != nil {
+		return xerrors.Errorf("unlocking wallet: %w", err)
+	}
+
+	if w.lockTimer != nil {
+		w.lockTimer.Stop()
+		w.lockTimer = nil
+	}
+	if timeout > 0 {
+		var timer *time.Timer
+		timer = time.AfterFunc(timeout, func() {
+			w.lk.Lock()
+			defer w.lk.Unlock()
+
+			if w.lockTimer != timer {
+				return // unlocked again since
+			}
+			w.lock()
+			log.Info("wallet locked after the unlock timeout")
+		})
+		w.lockTimer = timer
+	}
+
+	return nil
+}
+
+// WalletLock forgets the keys, until the wallet is unlocked again.
+func (w *LocalWallet) WalletLock(ctx context.Context) error {
+	if w.encrypted == nil || !w.encrypted.encrypted() {
+		return ErrNotEncrypted
+	}
+
+	w.lk.Lock()
+	defer w.lk.Unlock()
+
+	w.lock()
+	return nil
+}
+
+func (w *LocalWallet) lock() {
+	if w.lockTimer != nil {
+		w.lockTimer.Stop()
+		w.lockTimer = nil
+	}
+	w.encrypted.lock()
+	w.keys = make(map[address.Address]*Key)
+}
+
 func (w *LocalWallet) walletDelete(ctx context.Context, addr address.Address) error {
 	k, err := w.findKey(addr)
 
@@ -410,3 +516,21 @@ func (n nilDefault) SetDefault(ctx context.Context, a address.Address) error {
 
 var NilDefault nilDefault
 var _ Default = NilDefault
+
+type nilLocker struct{}
+
+func (n nilLocker) WalletSetPassphrase(ctx context.Context, passphrase string) error {
+	return xerrors.Errorf("not supported; local wallet disabled")
+}
+
+func (n nilLocker) WalletUnlock(ctx context.Context, passphrase string, timeout time.Duration) error {
+	return xerrors.Errorf("not supported; local wallet disabled")
+}
+
+func (n nilLocker) WalletLock(ctx context.Context) error {
+	return xerrors.Errorf("not supported; local wallet disabled")
+}
+
+var NilLocker nilLocker
+var _ Locker = NilLocker
+var _ Locker = &LocalWallet{}
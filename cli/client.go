@@ -100,6 +100,7 @@ var clientCmd = &cli.Command{
 		WithCategory("retrieval", clientRetrieveMinerCmd),
 		WithCategory("retrieval", clientRetrieveInfoCmd),
 		WithCategory("retrieval", clientRetrievePledgeStateCmd),
+		WithCategory("retrieval", clientRetrieveLockedCmd),
 		WithCategory("retrieval", clientRetrieveApplyForWithdrawCmd),
 		WithCategory("retrieval", clientRetrieveWithdrawCmd),
 		WithCategory("util", clientCommPCmd),
@@ -1097,12 +1098,29 @@ var clientRetrieveCmd = &cli.Command{
 			Name:  "pieceCid",
 			Usage: "require data to be retrieved from a specific Piece CID",
 		},
+		&cli.StringFlag{
+			Name:  "path",
+			Usage: "only retrieve the node at this data model path below dataCid, e.g. Links/2/Hash for the third entry of a directory",
+		},
+		&cli.StringFlag{
+			Name:  "range",
+			Usage: "only retrieve the top-level chunks of the file holding this byte range, as offset:length, length being optional",
+		},
 	},
 	Action: func(cctx *cli.Context) error {
 		if cctx.NArg() != 2 {
 			return ShowHelp(cctx, fmt.Errorf("incorrect number of arguments"))
 		}
 
+		var rng *lapi.RetrievalRange
+		if cctx.IsSet("range") {
+			r, err := parseRetrievalRange(cctx.String("range"))
+			if err != nil {
+				return ShowHelp(cctx, err)
+			}
+			rng = &r
+		}
+
 		fapi, closer, err := GetFullNodeAPI(cctx)
 		if err != nil {
 			return err
@@ -1209,7 +1227,10 @@ var clientRetrieveCmd = &cli.Command{
 			Path:  cctx.Args().Get(1),
 			IsCAR: cctx.Bool("car"),
 		}
-		updates, err := fapi.ClientRetrieveWithEvents(ctx, offer.Order(payer), ref)
+		order := offer.Order(payer)
+		order.DatamodelPath = cctx.String("path")
+		order.Range = rng
+		updates, err := fapi.ClientRetrieveWithEvents(ctx, order, ref)
 		if err != nil {
 			return xerrors.Errorf("error setting up retrieval: %w", err)
 		}
@@ -1238,6 +1259,28 @@ var clientRetrieveCmd = &cli.Command{
 	},
 }
 
+// parseRetrievalRange parses a byte range given as offset:length, the length
+// being optional.
+func parseRetrievalRange(s string) (lapi.RetrievalRange, error) {
+	var r lapi.RetrievalRange
+	parts := strings.SplitN(s, ":", 2)
+
+	offset, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return r, xerrors.Errorf("parsing range offset %q: %w", parts[0], err)
+	}
+	r.Offset = offset
+
+	if len(parts) == 2 && parts[1] != "" {
+		length, err := strconv.ParseUint(parts[1], 10, 64)
+		if err != nil {
+			return r, xerrors.Errorf("parsing range length %q: %w", parts[1], err)
+		}
+		r.Length = length
+	}
+	return r, nil
+}
+
 var clientRetrieveDealCmd = &cli.Command{
 	Name:      "retrieve-deal",
 	Usage:     "retrieve deal info",
@@ -1628,6 +1671,59 @@ var clientRetrievePledgeStateCmd = &cli.Command{
 	},
 }
 
+var clientRetrieveLockedCmd = &cli.Command{
+	Name:      "retrieve-locked",
+	Usage:     "List the retrieval pledge funds applied for withdrawal, and when they unlock",
+	ArgsUsage: "[wallets...]",
+	Action: func(cctx *cli.Context) error {
+		api, closer, err := GetFullNodeAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+		ctx := ReqContext(cctx)
+
+		var wallets []address.Address
+		if cctx.NArg() > 0 {
+			for _, arg := range cctx.Args().Slice() {
+				addr, err := address.NewFromString(arg)
+				if err != nil {
+					return ShowHelp(cctx, fmt.Errorf("failed to parse address %s: %w", arg, err))
+				}
+				wallets = append(wallets, addr)
+			}
+		} else {
+			wallets, err = api.WalletList(ctx)
+			if err != nil {
+				return err
+			}
+		}
+
+		head, err := api.ChainHead(ctx)
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 2, 4, 2, ' ', 0)
+		fmt.Fprintf(w, "Wallet\tLocked\tApplied\tUnlocks\n")
+		for _, wallet := range wallets {
+			locked, err := api.StateRetrievalLocked(ctx, wallet, head.Key())
+			if err != nil {
+				// wallets not on chain, or which never pledged
+				if cctx.NArg() > 0 {
+					return xerrors.Errorf("getting locked funds of %s: %w", wallet, err)
+				}
+				continue
+			}
+			if locked.Amount.IsZero() {
+				continue
+			}
+			fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", wallet, types.EPK(locked.Amount), locked.ApplyEpoch, EpochTime(head.Height(), locked.UnlockEpoch))
+		}
+		return w.Flush()
+	},
+}
+
 var clientRetrieveApplyForWithdrawCmd = &cli.Command{
 	Name:      "retrieve-apply",
 	Usage:     "apply for withdraw amount for retrieval",
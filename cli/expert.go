@@ -4,13 +4,19 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"os"
+	"sort"
+	"time"
 
 	lapi "github.com/EpiK-Protocol/go-epik/api"
 	"github.com/EpiK-Protocol/go-epik/build"
 	"github.com/EpiK-Protocol/go-epik/chain/actors"
 	types "github.com/EpiK-Protocol/go-epik/chain/types"
 	"github.com/EpiK-Protocol/go-epik/lib/tablewriter"
+	"github.com/EpiK-Protocol/go-epik/node/config"
+	"github.com/EpiK-Protocol/go-epik/voterewards"
 	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/filecoin-project/go-state-types/big"
 	"github.com/filecoin-project/specs-actors/v2/actors/builtin"
 	"github.com/filecoin-project/specs-actors/v2/actors/builtin/expert"
@@ -28,6 +34,7 @@ var expertCmd = &cli.Command{
 	Subcommands: []*cli.Command{
 		expertInitCmd,
 		expertInfoCmd,
+		expertRewardsCmd,
 		expertFileCmd,
 		expertListCmd,
 		expertNominateCmd,
@@ -223,12 +230,93 @@ var expertInfoCmd = &cli.Command{
 	},
 }
 
+var expertRewardsCmd = &cli.Command{
+	Name:      "rewards",
+	Usage:     "Show the vesting schedule of the rewards of an expert",
+	ArgsUsage: "<expert>",
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "csv",
+			Usage: "output as CSV",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		if cctx.Args().Len() != 1 {
+			return ShowHelp(cctx, fmt.Errorf("must pass expert address"))
+		}
+
+		expertAddr, err := address.NewFromString(cctx.Args().First())
+		if err != nil {
+			return err
+		}
+
+		api, closer, err := GetFullNodeAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		ctx := ReqContext(cctx)
+
+		gen, err := api.ChainGetGenesis(ctx)
+		if err != nil {
+			return err
+		}
+		genesis := time.Unix(int64(gen.MinTimestamp()), 0).UTC()
+
+		rewards, err := api.StateExpertRewards(ctx, expertAddr, types.EmptyTSK)
+		if err != nil {
+			return err
+		}
+
+		if cctx.Bool("csv") {
+			fmt.Fprintln(cctx.App.Writer, "Date,Epoch,Unlocked,Claimable")
+			fmt.Fprintf(cctx.App.Writer, "%s,%d,%s,%s\n", epochDay(rewards.Height, genesis), rewards.Height,
+				types.EPK(rewards.UnlockedFunds).Unitless(), types.EPK(rewards.UnlockedFunds).Unitless())
+			for _, d := range rewards.Schedule {
+				fmt.Fprintf(cctx.App.Writer, "%s,%d,%s,%s\n", epochDay(d.Epoch, genesis), d.Epoch,
+					types.EPK(d.Unlocked).Unitless(), types.EPK(d.Claimable).Unitless())
+			}
+			return nil
+		}
+
+		fmt.Fprintf(cctx.App.Writer, "Expert: %s\n", rewards.Expert)
+		fmt.Fprintf(cctx.App.Writer, "Locked: %s\n", types.EPK(rewards.LockedFunds))
+		fmt.Fprintf(cctx.App.Writer, "Claimable: %s\n", types.EPK(rewards.UnlockedFunds))
+		if rewards.DisqualifiedAt >= 0 {
+			fmt.Fprintf(cctx.App.Writer, "Disqualified: at %s, no new rewards\n", EpochTime(rewards.Height, rewards.DisqualifiedAt))
+			fmt.Fprintf(cctx.App.Writer, "Contribution cleared: %s\n", EpochTime(rewards.Height, rewards.ContributionClearedAt))
+		}
+		if len(rewards.Schedule) == 0 {
+			return nil
+		}
+
+		fmt.Fprintln(cctx.App.Writer)
+		w := tablewriter.New(
+			tablewriter.Col("Date"),
+			tablewriter.Col("Epoch"),
+			tablewriter.Col("Unlocked"),
+			tablewriter.Col("Claimable"))
+		for _, d := range rewards.Schedule {
+			w.Write(map[string]interface{}{
+				"Date":      epochDay(d.Epoch, genesis),
+				"Epoch":     d.Epoch,
+				"Unlocked":  types.EPK(d.Unlocked),
+				"Claimable": types.EPK(d.Claimable),
+			})
+		}
+		return w.Flush(cctx.App.Writer)
+	},
+}
+
 var expertFileCmd = &cli.Command{
 	Name:  "file",
 	Usage: "Interact with epik expert",
 	Subcommands: []*cli.Command{
 		fileRegisterCmd,
 		fileListCmd,
+		fileRedundancyCmd,
+		fileImportManifestCmd,
 	},
 }
 
@@ -285,6 +373,16 @@ var fileListCmd = &cli.Command{
 	Name:      "list",
 	Usage:     "expert list file",
 	ArgsUsage: "[expert]",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "cursor",
+			Usage: "list the files after this piece CID",
+		},
+		&cli.Uint64Flag{
+			Name:  "limit",
+			Usage: "maximum number of files to list, 0 = all",
+		},
+	},
 	Action: func(cctx *cli.Context) error {
 
 		if cctx.Args().Len() != 1 {
@@ -296,6 +394,15 @@ var fileListCmd = &cli.Command{
 			return err
 		}
 
+		var cursor *cid.Cid
+		if cctx.IsSet("cursor") {
+			c, err := cid.Parse(cctx.String("cursor"))
+			if err != nil {
+				return xerrors.Errorf("parsing cursor: %w", err)
+			}
+			cursor = &c
+		}
+
 		ctx := ReqContext(cctx)
 
 		api, closer, err := GetFullNodeAPI(cctx) // TODO: consider storing full node address in config
@@ -304,29 +411,289 @@ var fileListCmd = &cli.Command{
 		}
 		defer closer()
 
-		infos, err := api.StateExpertDatas(ctx, expert, nil, false, types.EmptyTSK)
+		page, err := api.StateExpertDataList(ctx, expert, cursor, cctx.Uint64("limit"), types.EmptyTSK)
+		if err != nil {
+			return err
+		}
 
 		w := tablewriter.New(
 			tablewriter.Col("RootID"),
 			tablewriter.Col("PieceCID"),
-			tablewriter.Col("Size"))
+			tablewriter.Col("Size"),
+			tablewriter.Col("Redundancy"),
+			tablewriter.Col("Miners"),
+			tablewriter.Col("Deals"))
 
-		for _, d := range infos {
-			if d.RootID == d.PieceID {
+		for _, f := range page.Files {
+			if f.RootID == f.PieceID {
 				// ignore fake data
 				continue
 			}
 			w.Write(map[string]interface{}{
-				"RootID":   d.RootID,
-				"PieceCID": d.PieceID,
-				"Size":     types.SizeStr(types.NewInt(uint64(d.PieceSize))),
+				"RootID":     f.RootID,
+				"PieceCID":   f.PieceID,
+				"Size":       types.SizeStr(types.NewInt(uint64(f.PieceSize))),
+				"Redundancy": f.Redundancy,
+				"Miners":     len(f.Miners),
+				"Deals":      len(f.Deals),
 			})
 		}
 
+		if err := w.Flush(cctx.App.Writer); err != nil {
+			return err
+		}
+		if page.Next != nil {
+			fmt.Fprintf(cctx.App.Writer, "\nmore files: --cursor %s\n", page.Next)
+		}
+		return nil
+	},
+}
+
+var fileRedundancyCmd = &cli.Command{
+	Name:      "redundancy",
+	Usage:     "show miners and deals storing a file",
+	ArgsUsage: "[pieceCid]",
+	Action: func(cctx *cli.Context) error {
+		if cctx.Args().Len() != 1 {
+			return fmt.Errorf("usage: redundancy <pieceCid>")
+		}
+
+		piece, err := cid.Parse(cctx.Args().First())
+		if err != nil {
+			return err
+		}
+
+		ctx := ReqContext(cctx)
+
+		api, closer, err := GetFullNodeAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		fs, err := api.StateExpertFileRedundancy(ctx, piece, types.EmptyTSK)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Expert: %s\n", fs.Expert)
+		fmt.Printf("RootID: %s\n", fs.RootID)
+		fmt.Printf("Size: %s\n", types.SizeStr(types.NewInt(uint64(fs.PieceSize))))
+		fmt.Printf("Redundancy: %d\n", fs.Redundancy)
+		fmt.Printf("Stored by: %d miners\n", len(fs.Miners))
+		for _, m := range fs.Miners {
+			fmt.Printf("\t%s\n", m)
+		}
+		fmt.Println()
+
+		w := tablewriter.New(
+			tablewriter.Col("DealID"),
+			tablewriter.Col("Provider"),
+			tablewriter.Col("Client"),
+			tablewriter.Col("Sector"),
+			tablewriter.Col("Activated"),
+			tablewriter.Col("Expiration"))
+
+		for _, d := range fs.Deals {
+			row := map[string]interface{}{
+				"DealID":   d.DealID,
+				"Provider": d.Provider,
+				"Client":   d.Client,
+			}
+			if d.SectorStartEpoch >= 0 {
+				row["Activated"] = d.SectorStartEpoch
+			}
+			if d.Expiration >= 0 {
+				row["Sector"] = d.Sector
+				row["Expiration"] = d.Expiration
+			}
+			w.Write(row)
+		}
+
 		return w.Flush(cctx.App.Writer)
 	},
 }
 
+var fileImportManifestCmd = &cli.Command{
+	Name:      "import-manifest",
+	Usage:     "register files listed in a manifest in batch messages",
+	ArgsUsage: "[expert] [manifest]",
+	Description: `The manifest is either CSV rows of root,piece,size,path (an optional header
+   may order the columns) or a JSON array of {"root", "piece", "size", "path"}.
+   Piece CID and size are computed from the CAR file at path when missing, or
+   from the local import of root otherwise.
+
+   Progress is kept in a file next to the manifest, rerunning the command after
+   an interruption resumes the import.`,
+	Flags: []cli.Flag{
+		&cli.IntFlag{
+			Name:  "batch",
+			Usage: "maximum number of files per message",
+			Value: 256,
+		},
+		&cli.StringFlag{
+			Name:  "progress",
+			Usage: "progress file (default: <manifest>.progress)",
+		},
+		&cli.BoolFlag{
+			Name:  "no-wait",
+			Usage: "don't wait for the messages to land on chain",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		if cctx.Args().Len() != 2 {
+			return ShowHelp(cctx, fmt.Errorf("'import-manifest' expects two arguments, expert and manifest"))
+		}
+
+		expertAddr, err := address.NewFromString(cctx.Args().First())
+		if err != nil {
+			return err
+		}
+
+		manifestPath := cctx.Args().Get(1)
+		mf, err := os.Open(manifestPath)
+		if err != nil {
+			return err
+		}
+		entries, err := parseManifest(mf)
+		mf.Close() //nolint:errcheck
+		if err != nil {
+			return err
+		}
+
+		progressPath := cctx.String("progress")
+		if progressPath == "" {
+			progressPath = manifestPath + ".progress"
+		}
+		progress, err := loadManifestProgress(progressPath)
+		if err != nil {
+			return err
+		}
+
+		api, closer, err := GetFullNodeAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		ctx := ReqContext(cctx)
+
+		// messages of an interrupted run must land before checking what is registered
+		if len(progress.Pending) > 0 {
+			fmt.Printf("waiting for %d messages of a previous run\n", len(progress.Pending))
+			if err := waitRegisterMessages(ctx, api, progress.Pending); err != nil {
+				return err
+			}
+			progress.Pending = nil
+			if err := progress.save(); err != nil {
+				return err
+			}
+		}
+
+		files := make([]lapi.ExpertFileImport, 0, len(entries))
+		for _, e := range entries {
+			if !e.complete() {
+				if f, ok := progress.Pieces[e.Root.String()]; ok {
+					files = append(files, f)
+					continue
+				}
+
+				if e.Path != "" {
+					commP, err := api.ClientCalcCommP(ctx, e.Path)
+					if err != nil {
+						return xerrors.Errorf("computing commP of %s: %w", e.Path, err)
+					}
+					e.Piece, e.Size = commP.Root, commP.Size.Padded()
+				} else {
+					ds, err := api.ClientDealPieceCID(ctx, e.Root)
+					if err != nil {
+						return xerrors.Errorf("failed to get data cid/size for root %s: %w", e.Root, err)
+					}
+					e.Piece, e.Size = ds.PieceCID, ds.PieceSize
+				}
+			}
+
+			f := lapi.ExpertFileImport{
+				RootID:    e.Root,
+				PieceID:   e.Piece,
+				PieceSize: e.Size,
+			}
+			files = append(files, f)
+			if _, ok := progress.Pieces[e.Root.String()]; !ok {
+				progress.Pieces[e.Root.String()] = f
+				if err := progress.save(); err != nil {
+					return err
+				}
+			}
+		}
+
+		batchSize := cctx.Int("batch")
+		if batchSize <= 0 {
+			return xerrors.Errorf("batch size must be positive")
+		}
+
+		var registered int
+		for len(files) > 0 {
+			n := batchSize
+			if n > len(files) {
+				n = len(files)
+			}
+
+			batches, err := api.ClientExpertRegisterFiles(ctx, &lapi.ExpertRegisterFilesParams{
+				Expert:       expertAddr,
+				Files:        files[:n],
+				MaxBatchSize: batchSize,
+			})
+			if err != nil {
+				return xerrors.Errorf("registering files: %w", err)
+			}
+			var berr string
+			for _, b := range batches {
+				if b.Error != "" {
+					berr = b.Error
+					continue
+				}
+				fmt.Printf("register %d files: %s\n", len(b.Files), b.Message)
+				progress.Pending = append(progress.Pending, b.Message)
+				registered += len(b.Files)
+			}
+			if err := progress.save(); err != nil {
+				return err
+			}
+			if berr != "" {
+				return xerrors.Errorf("registering files: %s", berr)
+			}
+			files = files[n:]
+		}
+
+		fmt.Printf("%d of %d files sent for registration, others are registered already\n", registered, len(entries))
+		if cctx.Bool("no-wait") || len(progress.Pending) == 0 {
+			return nil
+		}
+
+		if err := waitRegisterMessages(ctx, api, progress.Pending); err != nil {
+			return err
+		}
+		progress.Pending = nil
+		return progress.save()
+	},
+}
+
+func waitRegisterMessages(ctx context.Context, api lapi.FullNode, msgs []cid.Cid) error {
+	for _, mcid := range msgs {
+		wait, err := api.StateWaitMsg(ctx, mcid, build.MessageConfidence)
+		if err != nil {
+			return xerrors.Errorf("waiting for %s: %w", mcid, err)
+		}
+		if wait.Receipt.ExitCode != 0 {
+			fmt.Printf("message %s failed with exit code %d, its files will be retried\n", mcid, wait.Receipt.ExitCode)
+			continue
+		}
+		fmt.Printf("message %s landed at height %d\n", mcid, wait.Height)
+	}
+	return nil
+}
+
 var expertListCmd = &cli.Command{
 	Name:  "list",
 	Usage: "expert list",
@@ -532,6 +899,8 @@ var expertVote = &cli.Command{
 		expertVoteRescind,
 		expertVoteWithdraw,
 		expertVoteInject,
+		expertVotePlanRewards,
+		expertVoteHistory,
 	},
 }
 
@@ -752,3 +1121,170 @@ var expertVoteInject = &cli.Command{
 		return nil
 	},
 }
+
+var expertVotePlanRewards = &cli.Command{
+	Name:  "plan-rewards",
+	Usage: "Show the messages withdrawing and compounding voting rewards, without pushing them",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:    "from",
+			Usage:   "optionally specify the voter account, otherwise it will use the default wallet address",
+			Aliases: []string{"f"},
+		},
+		&cli.StringFlag{
+			Name:  "min-withdraw",
+			Usage: "least amount of rewards worth a withdrawal (EPK)",
+		},
+		&cli.BoolFlag{
+			Name:  "compound",
+			Usage: "vote the withdrawn rewards again",
+		},
+		&cli.StringSliceFlag{
+			Name:  "candidate",
+			Usage: "candidates to split the compounded rewards evenly, in proportion to the current votes when unset",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		api, closer, err := GetFullNodeAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		ctx := ReqContext(cctx)
+
+		fromAddr, err := parseFrom(cctx, ctx, api, true)
+		if err != nil {
+			return err
+		}
+
+		plan, err := voterewards.PlanVoter(ctx, api, config.VoterRewards{
+			Voter:       fromAddr.String(),
+			MinWithdraw: cctx.String("min-withdraw"),
+			Compound:    cctx.Bool("compound"),
+			Candidates:  cctx.StringSlice("candidate"),
+		})
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Withdrawable rewards: %s\n", types.EPK(plan.Rewards))
+		fmt.Printf("Unlocked votes: %s\n", types.EPK(plan.Unlocked))
+		if plan.Empty() {
+			fmt.Println("Nothing to withdraw")
+			return nil
+		}
+
+		fmt.Printf("\n%s\n", voterewards.Describe(plan.Withdraw))
+		for _, msg := range plan.Votes {
+			fmt.Println(voterewards.Describe(msg))
+		}
+		return nil
+	},
+}
+
+var expertVoteHistory = &cli.Command{
+	Name:  "history",
+	Usage: "Show how the votes of candidates evolved",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "candidate",
+			Usage: "only show the votes of this candidate",
+		},
+		&cli.Int64Flag{
+			Name:  "from",
+			Usage: "first epoch (default: a week before --to)",
+		},
+		&cli.Int64Flag{
+			Name:  "to",
+			Usage: "last epoch (default: chain head)",
+		},
+		&cli.Int64Flag{
+			Name:  "step",
+			Usage: "epochs between samples",
+			Value: int64(builtin.EpochsInDay),
+		},
+		&cli.BoolFlag{
+			Name:  "csv",
+			Usage: "output as CSV",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		api, closer, err := GetFullNodeAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		ctx := ReqContext(cctx)
+
+		candidate := address.Undef
+		if s := cctx.String("candidate"); s != "" {
+			candidate, err = address.NewFromString(s)
+			if err != nil {
+				return ShowHelp(cctx, fmt.Errorf("failed to parse candidate address: %w", err))
+			}
+		}
+
+		head, err := api.ChainHead(ctx)
+		if err != nil {
+			return err
+		}
+
+		to := head.Height()
+		if cctx.IsSet("to") {
+			to = abi.ChainEpoch(cctx.Int64("to"))
+		}
+		from := to - 7*builtin.EpochsInDay
+		if cctx.IsSet("from") {
+			from = abi.ChainEpoch(cctx.Int64("from"))
+		}
+		if from < 0 {
+			from = 0
+		}
+
+		hist, err := api.StateVoteTallyHistory(ctx, candidate, from, to, abi.ChainEpoch(cctx.Int64("step")), head.Key())
+		if err != nil {
+			return err
+		}
+
+		candidates := make([]string, 0, len(hist.Candidates))
+		for c := range hist.Candidates {
+			candidates = append(candidates, c)
+		}
+		sort.Strings(candidates)
+
+		if cctx.Bool("csv") {
+			fmt.Fprintln(cctx.App.Writer, "Epoch,Candidate,Votes,Blocked")
+			for _, c := range candidates {
+				for _, p := range hist.Candidates[c] {
+					fmt.Fprintf(cctx.App.Writer, "%d,%s,%s,%t\n", p.Epoch, c, types.EPK(p.Votes).Unitless(), p.Blocked)
+				}
+			}
+			return nil
+		}
+
+		w := tablewriter.New(
+			tablewriter.Col("Candidate"),
+			tablewriter.Col("Epoch"),
+			tablewriter.Col("Votes"),
+			tablewriter.Col("Change"),
+			tablewriter.Col("Blocked"))
+
+		for _, c := range candidates {
+			prev := big.Zero()
+			for _, p := range hist.Candidates[c] {
+				w.Write(map[string]interface{}{
+					"Candidate": c,
+					"Epoch":     p.Epoch,
+					"Votes":     types.EPK(p.Votes),
+					"Change":    types.EPK(big.Sub(p.Votes, prev)),
+					"Blocked":   p.Blocked,
+				})
+				prev = p.Votes
+			}
+		}
+
+		return w.Flush(cctx.App.Writer)
+	},
+}
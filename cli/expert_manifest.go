@@ -0,0 +1,188 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	cid "github.com/ipfs/go-cid"
+	"golang.org/x/xerrors"
+
+	lapi "github.com/EpiK-Protocol/go-epik/api"
+)
+
+// manifestEntry is a file listed in an expert import manifest. Piece and size
+// may be left out, they are then computed from Path or from the local import
+// of Root.
+type manifestEntry struct {
+	Root  cid.Cid
+	Piece cid.Cid
+	Size  abi.PaddedPieceSize
+	Path  string
+}
+
+type jsonManifestEntry struct {
+	Root  string
+	Piece string
+	Size  uint64
+	Path  string
+}
+
+// parseManifest reads a JSON array of entries, or CSV rows of
+// root,piece,size,path with an optional header.
+func parseManifest(r io.Reader) ([]manifestEntry, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	b = bytes.TrimSpace(b)
+	if len(b) > 0 && b[0] == '[' {
+		return parseJSONManifest(b)
+	}
+	return parseCSVManifest(b)
+}
+
+func parseJSONManifest(b []byte) ([]manifestEntry, error) {
+	var raw []jsonManifestEntry
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return nil, xerrors.Errorf("parsing json manifest: %w", err)
+	}
+
+	out := make([]manifestEntry, 0, len(raw))
+	for i, r := range raw {
+		e, err := newManifestEntry(r.Root, r.Piece, strconv.FormatUint(r.Size, 10), r.Path)
+		if err != nil {
+			return nil, xerrors.Errorf("entry %d: %w", i, err)
+		}
+		out = append(out, e)
+	}
+	return out, nil
+}
+
+func parseCSVManifest(b []byte) ([]manifestEntry, error) {
+	cr := csv.NewReader(bytes.NewReader(b))
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	rows, err := cr.ReadAll()
+	if err != nil {
+		return nil, xerrors.Errorf("parsing csv manifest: %w", err)
+	}
+
+	cols := map[string]int{"root": 0, "piece": 1, "size": 2, "path": 3}
+	if len(rows) > 0 && len(rows[0]) > 0 {
+		if _, err := cid.Parse(rows[0][0]); err != nil {
+			cols = map[string]int{}
+			for i, name := range rows[0] {
+				cols[strings.ToLower(strings.TrimSpace(name))] = i
+			}
+			if _, ok := cols["root"]; !ok {
+				return nil, xerrors.Errorf("csv manifest header has no root column")
+			}
+			rows = rows[1:]
+		}
+	}
+
+	field := func(row []string, name string) string {
+		i, ok := cols[name]
+		if !ok || i >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[i])
+	}
+
+	out := make([]manifestEntry, 0, len(rows))
+	for i, row := range rows {
+		e, err := newManifestEntry(field(row, "root"), field(row, "piece"), field(row, "size"), field(row, "path"))
+		if err != nil {
+			return nil, xerrors.Errorf("row %d: %w", i+1, err)
+		}
+		out = append(out, e)
+	}
+	return out, nil
+}
+
+func newManifestEntry(root, piece, size, path string) (manifestEntry, error) {
+	var e manifestEntry
+	var err error
+
+	if root == "" {
+		return e, xerrors.New("missing root cid")
+	}
+	if e.Root, err = cid.Parse(root); err != nil {
+		return e, xerrors.Errorf("parsing root cid: %w", err)
+	}
+	if piece != "" {
+		if e.Piece, err = cid.Parse(piece); err != nil {
+			return e, xerrors.Errorf("parsing piece cid: %w", err)
+		}
+	}
+	if size != "" && size != "0" {
+		sz, err := strconv.ParseUint(size, 10, 64)
+		if err != nil {
+			return e, xerrors.Errorf("parsing piece size: %w", err)
+		}
+		e.Size = abi.PaddedPieceSize(sz)
+		if err := e.Size.Validate(); err != nil {
+			return e, xerrors.Errorf("invalid piece size: %w", err)
+		}
+	}
+	e.Path = path
+	return e, nil
+}
+
+func (e *manifestEntry) complete() bool {
+	return e.Piece.Defined() && e.Size != 0
+}
+
+// manifestProgress is saved next to a manifest, so that an interrupted import
+// neither recomputes pieces nor pushes messages twice.
+type manifestProgress struct {
+	// Pieces computed for manifest entries, keyed by root cid
+	Pieces map[string]lapi.ExpertFileImport
+	// Pending are pushed import messages not yet known to be on chain
+	Pending []cid.Cid
+
+	path string
+}
+
+func loadManifestProgress(path string) (*manifestProgress, error) {
+	p := &manifestProgress{
+		Pieces: map[string]lapi.ExpertFileImport{},
+		path:   path,
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return p, nil
+	}
+	if err != nil {
+		return nil, xerrors.Errorf("reading progress file: %w", err)
+	}
+	if err := json.Unmarshal(b, p); err != nil {
+		return nil, xerrors.Errorf("parsing progress file %s: %w", path, err)
+	}
+	if p.Pieces == nil {
+		p.Pieces = map[string]lapi.ExpertFileImport{}
+	}
+	return p, nil
+}
+
+func (p *manifestProgress) save() error {
+	b, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := fmt.Sprintf("%s.tmp", p.path)
+	if err := ioutil.WriteFile(tmp, b, 0644); err != nil {
+		return xerrors.Errorf("writing progress file: %w", err)
+	}
+	return os.Rename(tmp, p.path)
+}
@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	tutils "github.com/filecoin-project/specs-actors/v2/support/testing"
+	"github.com/stretchr/testify/require"
+
+	lapi "github.com/EpiK-Protocol/go-epik/api"
+)
+
+func TestParseManifest(t *testing.T) {
+	root := tutils.MakeCID("root", nil)
+	piece := tutils.MakeCID("piece", nil)
+
+	csvManifest := "path,root,size,piece\n" +
+		"/data/a.car," + root.String() + ",,\n" +
+		"," + root.String() + ",2048," + piece.String() + "\n"
+	entries, err := parseManifest(strings.NewReader(csvManifest))
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, "/data/a.car", entries[0].Path)
+	require.False(t, entries[0].complete())
+	require.Equal(t, piece, entries[1].Piece)
+	require.Equal(t, abi.PaddedPieceSize(2048), entries[1].Size)
+	require.True(t, entries[1].complete())
+
+	// headerless rows are root,piece,size,path
+	entries, err = parseManifest(strings.NewReader(root.String() + "," + piece.String() + ",2048\n"))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.True(t, entries[0].complete())
+
+	jsonManifest := `[{"root": "` + root.String() + `", "piece": "` + piece.String() + `", "size": 2048}]`
+	entries, err = parseManifest(strings.NewReader(jsonManifest))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, root, entries[0].Root)
+	require.True(t, entries[0].complete())
+
+	_, err = parseManifest(strings.NewReader(root.String() + "," + piece.String() + ",1000\n"))
+	require.Error(t, err)
+}
+
+func TestManifestProgress(t *testing.T) {
+	dir, err := ioutil.TempDir("", "epik-manifest-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir) //nolint:errcheck
+	path := filepath.Join(dir, "manifest.progress")
+
+	p, err := loadManifestProgress(path)
+	require.NoError(t, err)
+	require.Empty(t, p.Pending)
+
+	root := tutils.MakeCID("root", nil)
+	p.Pieces[root.String()] = lapi.ExpertFileImport{
+		RootID:    root,
+		PieceID:   tutils.MakeCID("piece", nil),
+		PieceSize: 2048,
+	}
+	p.Pending = append(p.Pending, tutils.MakeCID("msg", nil))
+	require.NoError(t, p.save())
+
+	p2, err := loadManifestProgress(path)
+	require.NoError(t, err)
+	require.Equal(t, p.Pieces, p2.Pieces)
+	require.Equal(t, p.Pending, p2.Pending)
+}
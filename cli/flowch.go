@@ -3,8 +3,11 @@ package cli
 import (
 	"bytes"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
+	"os"
 	"sort"
 
 	"github.com/EpiK-Protocol/go-epik/api"
@@ -17,6 +20,7 @@ import (
 
 	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/flowch"
 	"github.com/EpiK-Protocol/go-epik/chain/types"
+	"github.com/EpiK-Protocol/go-epik/lib/tablewriter"
 )
 
 var flowchCmd = &cli.Command{
@@ -30,6 +34,9 @@ var flowchCmd = &cli.Command{
 		flowchStatusCmd,
 		flowchStatusByFromToCmd,
 		flowchCloseCmd,
+		flowchAutoCmd,
+		flowchExportCmd,
+		flowchImportCmd,
 	},
 }
 
@@ -306,6 +313,186 @@ var flowchCloseCmd = &cli.Command{
 	},
 }
 
+var flowchAutoCmd = &cli.Command{
+	Name:  "auto",
+	Usage: "Show the automatic settlement and collection of the inbound payment channels",
+	Action: func(cctx *cli.Context) error {
+		api, closer, err := GetFullNodeAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		ctx := ReqContext(cctx)
+
+		chs, err := api.FlowchAutoStatus(ctx)
+		if err != nil {
+			return err
+		}
+
+		head, err := api.ChainHead(ctx)
+		if err != nil {
+			return err
+		}
+
+		sort.Slice(chs, func(i, j int) bool {
+			return chs[i].LastActivity > chs[j].LastActivity
+		})
+
+		w := tablewriter.New(tablewriter.Col("Channel"),
+			tablewriter.Col("Redeemable"),
+			tablewriter.Col("LastActivity"),
+			tablewriter.Col("State"),
+			tablewriter.Col("SettlingAt"),
+			tablewriter.NewLineCol("Error"))
+
+		for _, ch := range chs {
+			row := map[string]interface{}{
+				"Channel":      ch.Channel,
+				"Redeemable":   types.EPK(ch.Value),
+				"LastActivity": EpochTime(head.Height(), ch.LastActivity),
+				"State":        flowchAutoState(ch),
+			}
+			if ch.SettlingAt > 0 {
+				row["SettlingAt"] = EpochTime(head.Height(), ch.SettlingAt)
+			}
+			if ch.Error != "" {
+				row["Error"] = ch.Error
+			}
+			w.Write(row)
+		}
+
+		return w.Flush(cctx.App.Writer)
+	},
+}
+
+func flowchAutoState(ch *api.FlowchAutoChannel) string {
+	switch {
+	case ch.Collected:
+		return "collected"
+	case ch.CollectMsg != nil:
+		return "collecting"
+	case ch.SettlingAt > 0:
+		return "settling"
+	case ch.SettleMsg != nil:
+		return "settle pending"
+	default:
+		return "open"
+	}
+}
+
+var flowchExportCmd = &cli.Command{
+	Name:      "export",
+	Usage:     "Export payment channels with their lanes and vouchers into a signed bundle",
+	ArgsUsage: "[channelAddress...]",
+	Description: `Export the given payment channels, or all tracked channels, into a JSON bundle
+   signed by a wallet of this node. The bundle can be imported into another node
+   with 'flowch import', or used to reconcile the vouchers with an accounting system.`,
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "signer",
+			Usage: "wallet signing the bundle, defaults to the default wallet",
+		},
+		&cli.StringFlag{
+			Name:    "output",
+			Aliases: []string{"o"},
+			Usage:   "file to write the bundle to, defaults to stdout",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		var chs []address.Address
+		for _, arg := range cctx.Args().Slice() {
+			ch, err := address.NewFromString(arg)
+			if err != nil {
+				return ShowHelp(cctx, fmt.Errorf("failed to parse payment channel address %s: %w", arg, err))
+			}
+			chs = append(chs, ch)
+		}
+
+		api, closer, err := GetFullNodeAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		ctx := ReqContext(cctx)
+
+		var signer address.Address
+		if s := cctx.String("signer"); s != "" {
+			signer, err = address.NewFromString(s)
+			if err != nil {
+				return ShowHelp(cctx, fmt.Errorf("failed to parse signer address: %w", err))
+			}
+		} else {
+			signer, err = api.WalletDefaultAddress(ctx)
+			if err != nil {
+				return err
+			}
+		}
+
+		bundle, err := api.FlowchExport(ctx, signer, chs)
+		if err != nil {
+			return err
+		}
+
+		b, err := json.MarshalIndent(bundle, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		if out := cctx.String("output"); out != "" {
+			if err := ioutil.WriteFile(out, b, 0600); err != nil {
+				return err
+			}
+			fmt.Fprintf(cctx.App.Writer, "Exported %d channels to %s\n", len(bundle.Channels), out)
+			return nil
+		}
+		fmt.Fprintln(cctx.App.Writer, string(b))
+		return nil
+	},
+}
+
+var flowchImportCmd = &cli.Command{
+	Name:      "import",
+	Usage:     "Import payment channels from a bundle written by 'flowch export'",
+	ArgsUsage: "[bundleFile]",
+	Action: func(cctx *cli.Context) error {
+		if cctx.Args().Len() != 1 {
+			return ShowHelp(cctx, fmt.Errorf("must pass bundle file"))
+		}
+
+		var b []byte
+		var err error
+		if path := cctx.Args().First(); path == "-" {
+			b, err = ioutil.ReadAll(os.Stdin)
+		} else {
+			b, err = ioutil.ReadFile(path)
+		}
+		if err != nil {
+			return err
+		}
+
+		var bundle api.FlowchBundle
+		if err := json.Unmarshal(b, &bundle); err != nil {
+			return fmt.Errorf("failed to decode bundle: %w", err)
+		}
+
+		api, closer, err := GetFullNodeAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		ctx := ReqContext(cctx)
+
+		chs, err := api.FlowchImport(ctx, &bundle)
+		for _, ch := range chs {
+			fmt.Fprintf(cctx.App.Writer, "Imported channel %s\n", ch)
+		}
+		return err
+	},
+}
+
 var flowchVoucherCmd = &cli.Command{
 	Name:  "voucher",
 	Usage: "Interact with payment channel vouchers",
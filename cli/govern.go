@@ -13,20 +13,16 @@ import (
 	"strconv"
 	"strings"
 	"text/tabwriter"
+	"time"
 
 	"github.com/EpiK-Protocol/go-epik/api"
-	"github.com/EpiK-Protocol/go-epik/blockstore"
 	"github.com/EpiK-Protocol/go-epik/build"
 	"github.com/EpiK-Protocol/go-epik/chain/actors"
-	"github.com/EpiK-Protocol/go-epik/chain/actors/adt"
-	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/multisig"
-	"github.com/EpiK-Protocol/go-epik/chain/stmgr"
 	types "github.com/EpiK-Protocol/go-epik/chain/types"
 	"github.com/filecoin-project/go-address"
 	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/filecoin-project/go-state-types/big"
 	"github.com/ipfs/go-cid"
-	cbor "github.com/ipfs/go-ipld-cbor"
 	"github.com/urfave/cli/v2"
 	cbg "github.com/whyrusleeping/cbor-gen"
 	"golang.org/x/xerrors"
@@ -70,6 +66,8 @@ var govCmd = &cli.Command{
 		govExpertfund,
 		govMiners,
 		govListParamsCmd,
+		govHistory,
+		govProposals,
 		govApprove,
 	}}
 
@@ -245,6 +243,7 @@ var govKnowledge = &cli.Command{
 	Usage: "Manipulate knowledge fund params",
 	Subcommands: []*cli.Command{
 		govKnowledgeSetPayee,
+		govKnowledgeReport,
 	},
 }
 
@@ -291,6 +290,177 @@ var govKnowledgeSetPayee = &cli.Command{
 	},
 }
 
+var govKnowledgeReport = &cli.Command{
+	Name:  "report",
+	Usage: "Report the daily payouts of the knowledge fund to its payees",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "from",
+			Usage: "first epoch, or UTC date as YYYY-MM-DD (default: a week before --to)",
+		},
+		&cli.StringFlag{
+			Name:  "to",
+			Usage: "last epoch, or UTC date as YYYY-MM-DD (default: chain head)",
+		},
+		&cli.BoolFlag{
+			Name:  "csv",
+			Usage: "output as CSV",
+		},
+		&cli.BoolFlag{
+			Name:  "json",
+			Usage: "output as JSON",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		api, closer, err := GetFullNodeAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		ctx := ReqContext(cctx)
+
+		head, err := api.ChainHead(ctx)
+		if err != nil {
+			return err
+		}
+		gen, err := api.ChainGetGenesis(ctx)
+		if err != nil {
+			return err
+		}
+		genesis := time.Unix(int64(gen.MinTimestamp()), 0).UTC()
+
+		to := head.Height()
+		if s := cctx.String("to"); s != "" {
+			// a date includes its whole day
+			to, err = parseEpochOrDate(s, genesis, 1)
+			if err != nil {
+				return ShowHelp(cctx, fmt.Errorf("failed to parse --to: %w", err))
+			}
+		}
+		from := to - 7*builtin.EpochsInDay
+		if s := cctx.String("from"); s != "" {
+			from, err = parseEpochOrDate(s, genesis, 0)
+			if err != nil {
+				return ShowHelp(cctx, fmt.Errorf("failed to parse --from: %w", err))
+			}
+		}
+		if from < 0 {
+			from = 0
+		}
+
+		payouts, err := api.StateKnowledgePayouts(ctx, from, to, head.Key())
+		if err != nil {
+			return err
+		}
+		ledger := knowledgeLedger(payouts.Payouts, genesis)
+
+		if cctx.Bool("json") {
+			out, err := json.MarshalIndent(map[string]interface{}{
+				"Ledger":       ledger,
+				"PayeeChanges": payouts.PayeeChanges,
+			}, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(cctx.App.Writer, string(out))
+			return nil
+		}
+
+		if cctx.Bool("csv") {
+			fmt.Fprintln(cctx.App.Writer, "Day,Payee,Amount,Payouts,FirstEpoch,LastEpoch")
+			for _, e := range ledger {
+				fmt.Fprintf(cctx.App.Writer, "%s,%s,%s,%d,%d,%d\n", e.Day, e.Payee, types.EPK(e.Amount).Unitless(), e.Payouts, e.FirstEpoch, e.LastEpoch)
+			}
+			return nil
+		}
+
+		w := tabwriter.NewWriter(cctx.App.Writer, 2, 4, 2, ' ', 0)
+		fmt.Fprintf(w, "Day\tPayee\tAmount\tPayouts\tEpochs\n")
+		total := big.Zero()
+		for _, e := range ledger {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d-%d\n", e.Day, e.Payee, types.EPK(e.Amount), e.Payouts, e.FirstEpoch, e.LastEpoch)
+			total = big.Add(total, e.Amount)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		fmt.Fprintf(cctx.App.Writer, "\nTotal paid in epochs %d-%d: %s\n", from, to, types.EPK(total))
+
+		if len(payouts.PayeeChanges) > 0 {
+			fmt.Fprintln(cctx.App.Writer, "\nPayee changes:")
+			for _, c := range payouts.PayeeChanges {
+				msg := "-"
+				if c.Message.Defined() {
+					msg = c.Message.String()
+				}
+				fmt.Fprintf(cctx.App.Writer, "\t%s (epoch %d): %s -> %s, message %s\n", epochDay(c.Epoch, genesis), c.Epoch, c.From, c.To, msg)
+			}
+		}
+		return nil
+	},
+}
+
+// knowledgeLedgerEntry sums the payouts of the knowledge fund to a payee in a day.
+type knowledgeLedgerEntry struct {
+	Day        string
+	Payee      address.Address
+	Amount     abi.TokenAmount
+	Payouts    int
+	FirstEpoch abi.ChainEpoch
+	LastEpoch  abi.ChainEpoch
+}
+
+// knowledgeLedger groups payouts, sorted by epoch, per UTC day and payee.
+func knowledgeLedger(payouts []api.KnowledgePayout, genesis time.Time) []*knowledgeLedgerEntry {
+	type key struct {
+		day   string
+		payee address.Address
+	}
+	entries := map[key]*knowledgeLedgerEntry{}
+	var out []*knowledgeLedgerEntry
+	for _, p := range payouts {
+		k := key{day: epochDay(p.Epoch, genesis), payee: p.Payee}
+		e, ok := entries[k]
+		if !ok {
+			e = &knowledgeLedgerEntry{
+				Day:        k.day,
+				Payee:      p.Payee,
+				Amount:     big.Zero(),
+				FirstEpoch: p.Epoch,
+			}
+			entries[k] = e
+			out = append(out, e)
+		}
+		e.Amount = big.Add(e.Amount, p.Amount)
+		e.Payouts++
+		e.LastEpoch = p.Epoch
+	}
+	return out
+}
+
+func epochDay(epoch abi.ChainEpoch, genesis time.Time) string {
+	return genesis.Add(time.Duration(epoch) * time.Duration(build.BlockDelaySecs) * time.Second).Format("2006-01-02")
+}
+
+// parseEpochOrDate parses an epoch, or a UTC date converted to the first epoch
+// of the day, offset by the given number of days.
+func parseEpochOrDate(s string, genesis time.Time, days int) (abi.ChainEpoch, error) {
+	if e, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return abi.ChainEpoch(e), nil
+	}
+	day, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return 0, xerrors.Errorf("expected an epoch or a YYYY-MM-DD date: %w", err)
+	}
+	secs := day.AddDate(0, 0, days).Sub(genesis) / time.Second
+	epoch := abi.ChainEpoch(int64(secs) / int64(build.BlockDelaySecs))
+	if days > 0 {
+		epoch--
+	}
+	return epoch, nil
+}
+
 //////////////////////////
 //     gov market
 //////////////////////////
@@ -699,13 +869,103 @@ var govListParamsCmd = &cli.Command{
 	},
 }
 
+var govHistory = &cli.Command{
+	Name:  "history",
+	Usage: "List the changes of consensus params and governor authorities",
+	Flags: []cli.Flag{
+		&cli.Int64Flag{
+			Name:  "from",
+			Usage: "first epoch",
+		},
+		&cli.Int64Flag{
+			Name:  "to",
+			Usage: "last epoch (default: chain head)",
+		},
+		&cli.StringFlag{
+			Name:  "param",
+			Usage: "only list the changes of this param, e.g. MinersPoStRatio or Authority",
+		},
+		&cli.BoolFlag{
+			Name:  "csv",
+			Usage: "output as CSV",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		api, acloser, err := GetFullNodeAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer acloser()
+		ctx := ReqContext(cctx)
+
+		to := abi.ChainEpoch(cctx.Int64("to"))
+		if !cctx.IsSet("to") {
+			head, err := api.ChainHead(ctx)
+			if err != nil {
+				return err
+			}
+			to = head.Height()
+		}
+
+		changes, err := api.StateGovernParamsHistory(ctx, abi.ChainEpoch(cctx.Int64("from")), to)
+		if err != nil {
+			return err
+		}
+
+		undef := func(a address.Address) string {
+			if a == address.Undef {
+				return ""
+			}
+			return a.String()
+		}
+
+		param := cctx.String("param")
+		if cctx.Bool("csv") {
+			fmt.Fprintln(cctx.App.Writer, "Epoch,Param,Old,New,Grantee,Governor,Message")
+			for _, c := range changes {
+				if param != "" && c.Param != param {
+					continue
+				}
+				msg := ""
+				if c.Message.Defined() {
+					msg = c.Message.String()
+				}
+				fmt.Fprintf(cctx.App.Writer, "%d,%s,%q,%q,%s,%s,%s\n", c.Epoch, c.Param, c.Old, c.New, undef(c.Grantee), undef(c.Governor), msg)
+			}
+			return nil
+		}
+
+		w := tabwriter.NewWriter(cctx.App.Writer, 2, 4, 2, ' ', 0)
+		fmt.Fprintf(w, "Epoch\tParam\tOld\tNew\tGovernor\tMessage\n")
+		for _, c := range changes {
+			if param != "" && c.Param != param {
+				continue
+			}
+			name := c.Param
+			if c.Grantee != address.Undef {
+				name = fmt.Sprintf("%s(%s)", c.Param, c.Grantee)
+			}
+			msg := "-"
+			if c.Message.Defined() {
+				msg = c.Message.String()
+			}
+			governor := undef(c.Governor)
+			if governor == "" {
+				governor = "-"
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n", c.Epoch, name, c.Old, c.New, governor, msg)
+		}
+		return w.Flush()
+	},
+}
+
 ////////////////////
 //     approve
 ////////////////////
 
 var govApprove = &cli.Command{
 	Name:      "approve",
-	Usage:     "Approve a multisig message",
+	Usage:     "Approve a multisig message (see also 'proposals approve')",
 	ArgsUsage: "<messageId>",
 	Action: func(cctx *cli.Context) error {
 		if cctx.Args().Len() != 1 {
@@ -719,19 +979,7 @@ var govApprove = &cli.Command{
 		defer closer()
 		ctx := ReqContext(cctx)
 
-		// msig
-		var msig address.Address
-		if fmsig := cctx.String("msig"); fmsig == "" {
-			return fmt.Errorf("flag 'msig-governor' is required on command 'gov'")
-		} else {
-			msig, err = address.NewFromString(fmsig)
-			if err != nil {
-				return err
-			}
-		}
-
-		// txid
-		txid, err := strconv.ParseUint(cctx.Args().Get(0), 10, 64)
+		msig, err := govMsigAddr(cctx)
 		if err != nil {
 			return err
 		}
@@ -743,64 +991,17 @@ var govApprove = &cli.Command{
 		}
 
 		// confirm tx
-		{
-			head, err := api.ChainHead(ctx)
-			if err != nil {
-				return err
-			}
-
-			act, err := api.StateGetActor(ctx, msig, head.Key())
-			if err != nil {
-				return err
-			}
-
-			store := adt.WrapStore(ctx, cbor.NewCborStore(blockstore.NewAPIBlockstore(api)))
-			mstate, err := multisig.Load(store, act)
-			if err != nil {
-				return err
-			}
-
-			tx, err := mstate.PendingTxn(int64(txid))
-			if err != nil {
-				return err
-			}
-			targAct, err := api.StateGetActor(ctx, tx.To, types.EmptyTSK)
-			if err != nil {
-				return fmt.Errorf("failed to get actor %s (transaction.To): %w", tx.To, err)
-			}
-			// decode actor,method,param
-			w := tabwriter.NewWriter(cctx.App.Writer, 8, 4, 2, ' ', 0)
-			actorName := builtin2.ActorNameByCode(targAct.Code)
-			method := stmgr.MethodsMap[targAct.Code][tx.Method]
-			if tx.Method != 0 {
-				fmt.Fprintf(w, "ID\tState\tApprovals\tTo\tValue\tMethod\tParams\n")
-				ptyp := reflect.New(method.Params.Elem()).Interface().(cbg.CBORUnmarshaler)
-				if err := ptyp.UnmarshalCBOR(bytes.NewReader(tx.Params)); err != nil {
-					return xerrors.Errorf("failed to decode parameters of transaction %d: %w", txid, err)
-				}
-
-				b, err := json.Marshal(ptyp)
-				if err != nil {
-					return xerrors.Errorf("could not json marshal parameter type: %w", err)
-				}
-
-				paramStr := string(b)
-				fmt.Fprintf(w, "%d\t%s\t%d\t%s(%s)\t%s\t%s(%d)\t%s\n", txid, "pending", len(tx.Approved), tx.To.String(), actorName,
-					types.EPK(tx.Value), method.Name, tx.Method, paramStr)
-			} else {
-				return xerrors.Errorf("decode 'Send' transaction not implemented")
-			}
-
-			if err := w.Flush(); err != nil {
-				return xerrors.Errorf("flushing output: %+v", err)
-			}
+		p, threshold, err := loadGovProposal(ctx, api, msig, cctx.Args().Get(0))
+		if err != nil {
+			return err
+		}
+		p.print(cctx, threshold)
 
-			if !PromptConfirm("approve the proposal") {
-				return nil
-			}
+		if !PromptConfirm("approve the proposal") {
+			return nil
 		}
 
-		return sendApprove(cctx, ctx, api, msig, txid, fromAddr)
+		return sendApprove(cctx, ctx, api, msig, uint64(p.ID), fromAddr)
 	},
 }
 
@@ -0,0 +1,400 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/filecoin-project/go-address"
+	cbor "github.com/ipfs/go-ipld-cbor"
+	"github.com/urfave/cli/v2"
+	cbg "github.com/whyrusleeping/cbor-gen"
+	"golang.org/x/xerrors"
+
+	builtin2 "github.com/filecoin-project/specs-actors/v2/actors/builtin"
+
+	"github.com/EpiK-Protocol/go-epik/api"
+	"github.com/EpiK-Protocol/go-epik/blockstore"
+	"github.com/EpiK-Protocol/go-epik/chain/actors/adt"
+	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/multisig"
+	"github.com/EpiK-Protocol/go-epik/chain/stmgr"
+	"github.com/EpiK-Protocol/go-epik/chain/types"
+)
+
+//////////////////////////
+//     gov proposals
+//////////////////////////
+
+var govProposals = &cli.Command{
+	Name:  "proposals",
+	Usage: "Manage the pending proposals of the multisig governor",
+	Subcommands: []*cli.Command{
+		govProposalsList,
+		govProposalsInspect,
+		govProposalsApprove,
+		govProposalsCancel,
+	},
+}
+
+var govProposalsList = &cli.Command{
+	Name:  "list",
+	Usage: "List the pending proposals of the multisig governor",
+	Action: func(cctx *cli.Context) error {
+		api, closer, err := GetFullNodeAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+		ctx := ReqContext(cctx)
+
+		msig, err := govMsigAddr(cctx)
+		if err != nil {
+			return err
+		}
+
+		proposals, threshold, err := loadGovProposals(ctx, api, msig)
+		if err != nil {
+			return err
+		}
+		if len(proposals) == 0 {
+			fmt.Println("No pending proposals")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(cctx.App.Writer, 8, 4, 2, ' ', 0)
+		fmt.Fprintf(w, "ID\tApprovals\tTo\tValue\tMethod\tParams\n")
+		for _, p := range proposals {
+			fmt.Fprintf(w, "%d\t%d/%d\t%s(%s)\t%s\t%s(%d)\t%s\n", p.ID, len(p.Approved), threshold, p.To, p.Actor,
+				types.EPK(p.Value), p.MethodName, p.Method, p.paramsJSON(false))
+		}
+		return w.Flush()
+	},
+}
+
+var govProposalsInspect = &cli.Command{
+	Name:      "inspect",
+	Usage:     "Show the details of a pending proposal of the multisig governor",
+	ArgsUsage: "<proposalId>",
+	Action: func(cctx *cli.Context) error {
+		if cctx.Args().Len() != 1 {
+			return ShowHelp(cctx, fmt.Errorf("'inspect' expects one argument, proposal ID"))
+		}
+
+		api, closer, err := GetFullNodeAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+		ctx := ReqContext(cctx)
+
+		msig, err := govMsigAddr(cctx)
+		if err != nil {
+			return err
+		}
+
+		p, threshold, err := loadGovProposal(ctx, api, msig, cctx.Args().First())
+		if err != nil {
+			return err
+		}
+
+		p.print(cctx, threshold)
+		return nil
+	},
+}
+
+var govProposalsApprove = &cli.Command{
+	Name:      "approve",
+	Usage:     "Approve a pending proposal of the multisig governor",
+	ArgsUsage: "<proposalId>",
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "yes",
+			Usage: "approve without asking for confirmation",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		if cctx.Args().Len() != 1 {
+			return ShowHelp(cctx, fmt.Errorf("'approve' expects one argument, proposal ID"))
+		}
+
+		api, closer, err := GetFullNodeAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+		ctx := ReqContext(cctx)
+
+		msig, err := govMsigAddr(cctx)
+		if err != nil {
+			return err
+		}
+
+		fromAddr, err := parseFrom(cctx, ctx, api, true)
+		if err != nil {
+			return err
+		}
+
+		p, threshold, err := loadGovProposal(ctx, api, msig, cctx.Args().First())
+		if err != nil {
+			return err
+		}
+
+		fromID, err := api.StateLookupID(ctx, fromAddr, types.EmptyTSK)
+		if err != nil {
+			return xerrors.Errorf("failed to lookup id address for %s: %w", fromAddr, err)
+		}
+		for _, a := range p.Approved {
+			if a == fromID {
+				return xerrors.Errorf("%s already approved proposal %d", fromAddr, p.ID)
+			}
+		}
+
+		p.print(cctx, threshold)
+		if !cctx.Bool("yes") && !PromptConfirm("approve the proposal") {
+			return nil
+		}
+
+		return sendApprove(cctx, ctx, api, msig, uint64(p.ID), fromAddr)
+	},
+}
+
+var govProposalsCancel = &cli.Command{
+	Name:      "cancel",
+	Usage:     "Cancel a pending proposal of the multisig governor, only allowed to its proposer",
+	ArgsUsage: "<proposalId>",
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "yes",
+			Usage: "cancel without asking for confirmation",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		if cctx.Args().Len() != 1 {
+			return ShowHelp(cctx, fmt.Errorf("'cancel' expects one argument, proposal ID"))
+		}
+
+		api, closer, err := GetFullNodeAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+		ctx := ReqContext(cctx)
+
+		msig, err := govMsigAddr(cctx)
+		if err != nil {
+			return err
+		}
+
+		fromAddr, err := parseFrom(cctx, ctx, api, true)
+		if err != nil {
+			return err
+		}
+
+		p, threshold, err := loadGovProposal(ctx, api, msig, cctx.Args().First())
+		if err != nil {
+			return err
+		}
+
+		fromID, err := api.StateLookupID(ctx, fromAddr, types.EmptyTSK)
+		if err != nil {
+			return xerrors.Errorf("failed to lookup id address for %s: %w", fromAddr, err)
+		}
+		if len(p.Approved) == 0 || p.Approved[0] != fromID {
+			return xerrors.Errorf("%s is not the proposer of proposal %d", fromAddr, p.ID)
+		}
+
+		p.print(cctx, threshold)
+		if !cctx.Bool("yes") && !PromptConfirm("cancel the proposal") {
+			return nil
+		}
+
+		msgCid, err := api.MsigCancel(ctx, msig, uint64(p.ID), p.To, p.Value, fromAddr, uint64(p.Method), p.Params)
+		if err != nil {
+			return err
+		}
+
+		fmt.Println("sent cancel in message: ", msgCid)
+
+		wait, err := api.StateWaitMsg(ctx, msgCid, uint64(cctx.Int("confidence")))
+		if err != nil {
+			return err
+		}
+
+		if wait.Receipt.ExitCode != 0 {
+			return fmt.Errorf("cancel returned exit %d", wait.Receipt.ExitCode)
+		}
+
+		fmt.Println("cancel returned exit Ok")
+		return nil
+	},
+}
+
+// govProposal is a pending transaction of the multisig governor, decoded
+// against the actor it calls.
+type govProposal struct {
+	multisig.Transaction
+
+	ID         int64
+	Actor      string
+	MethodName string
+	// DecodedParams is nil if the method takes no params or they can't be decoded
+	DecodedParams interface{}
+}
+
+func (p *govProposal) paramsJSON(indent bool) string {
+	if p.DecodedParams == nil {
+		if len(p.Params) == 0 {
+			return ""
+		}
+		return fmt.Sprintf("0x%x", p.Params)
+	}
+
+	var b []byte
+	var err error
+	if indent {
+		b, err = json.MarshalIndent(p.DecodedParams, "", "  ")
+	} else {
+		b, err = json.Marshal(p.DecodedParams)
+	}
+	if err != nil {
+		return fmt.Sprintf("0x%x", p.Params)
+	}
+	return string(b)
+}
+
+func (p *govProposal) print(cctx *cli.Context, threshold uint64) {
+	out := cctx.App.Writer
+	fmt.Fprintf(out, "Proposal ID: %d\n", p.ID)
+	fmt.Fprintf(out, "To:          %s (%s)\n", p.To, p.Actor)
+	fmt.Fprintf(out, "Value:       %s\n", types.EPK(p.Value))
+	fmt.Fprintf(out, "Method:      %s (%d)\n", p.MethodName, p.Method)
+	if params := p.paramsJSON(true); params != "" {
+		fmt.Fprintf(out, "Params:      %s\n", params)
+	}
+	fmt.Fprintf(out, "Approvals:   %d/%d\n", len(p.Approved), threshold)
+	for i, a := range p.Approved {
+		if i == 0 {
+			fmt.Fprintf(out, "\t%s (proposer)\n", a)
+		} else {
+			fmt.Fprintf(out, "\t%s\n", a)
+		}
+	}
+}
+
+func govMsigAddr(cctx *cli.Context) (address.Address, error) {
+	fmsig := cctx.String("msig")
+	if fmsig == "" {
+		return address.Undef, fmt.Errorf("flag 'msig' is required on command 'gov'")
+	}
+	return address.NewFromString(fmsig)
+}
+
+// loadGovProposals returns the pending proposals of the multisig governor
+// sorted by ID, and its approval threshold.
+func loadGovProposals(ctx context.Context, api api.FullNode, msig address.Address) ([]*govProposal, uint64, error) {
+	mstate, err := loadGovMsig(ctx, api, msig)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	threshold, err := mstate.Threshold()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var out []*govProposal
+	err = mstate.ForEachPendingTxn(func(id int64, txn multisig.Transaction) error {
+		p, err := decodeGovProposal(ctx, api, id, txn)
+		if err != nil {
+			return err
+		}
+		out = append(out, p)
+		return nil
+	})
+	if err != nil {
+		return nil, 0, xerrors.Errorf("loading pending transactions of %s: %w", msig, err)
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].ID < out[j].ID
+	})
+	return out, threshold, nil
+}
+
+func loadGovProposal(ctx context.Context, api api.FullNode, msig address.Address, idStr string) (*govProposal, uint64, error) {
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		return nil, 0, xerrors.Errorf("parsing proposal ID: %w", err)
+	}
+
+	mstate, err := loadGovMsig(ctx, api, msig)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	threshold, err := mstate.Threshold()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	txn, err := mstate.PendingTxn(id)
+	if err != nil {
+		return nil, 0, xerrors.Errorf("loading proposal %d: %w", id, err)
+	}
+
+	p, err := decodeGovProposal(ctx, api, id, txn)
+	if err != nil {
+		return nil, 0, err
+	}
+	return p, threshold, nil
+}
+
+func loadGovMsig(ctx context.Context, api api.FullNode, msig address.Address) (multisig.State, error) {
+	act, err := api.StateGetActor(ctx, msig, types.EmptyTSK)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to get multisig %s: %w", msig, err)
+	}
+
+	store := adt.WrapStore(ctx, cbor.NewCborStore(blockstore.NewAPIBlockstore(api)))
+	return multisig.Load(store, act)
+}
+
+func decodeGovProposal(ctx context.Context, api api.FullNode, id int64, txn multisig.Transaction) (*govProposal, error) {
+	p := &govProposal{
+		Transaction: txn,
+		ID:          id,
+		Actor:       unknownActor,
+		MethodName:  "Send",
+	}
+
+	targAct, err := api.StateGetActor(ctx, txn.To, types.EmptyTSK)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to get actor %s (transaction.To): %w", txn.To, err)
+	}
+	p.Actor = builtin2.ActorNameByCode(targAct.Code)
+
+	if txn.Method == 0 {
+		return p, nil
+	}
+
+	method, ok := stmgr.MethodsMap[targAct.Code][txn.Method]
+	if !ok {
+		p.MethodName = invalidMethodName
+		return p, nil
+	}
+	p.MethodName = method.Name
+
+	if len(txn.Params) == 0 {
+		return p, nil
+	}
+	params := reflect.New(method.Params.Elem()).Interface().(cbg.CBORUnmarshaler)
+	if err := params.UnmarshalCBOR(bytes.NewReader(txn.Params)); err == nil {
+		p.DecodedParams = params
+	}
+	return p, nil
+}
@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	"github.com/urfave/cli/v2"
+	"golang.org/x/crypto/ssh/terminal"
 	"golang.org/x/xerrors"
 
 	"github.com/filecoin-project/go-address"
@@ -39,6 +40,9 @@ var walletCmd = &cli.Command{
 		walletSign,
 		walletVerify,
 		walletDelete,
+		walletSetPassphrase,
+		walletUnlock,
+		walletLock,
 		// walletMarket,
 		walletCoinbase,
 	},
@@ -509,6 +513,109 @@ var walletDelete = &cli.Command{
 	},
 }
 
+var walletSetPassphrase = &cli.Command{
+	Name:  "set-passphrase",
+	Usage: "Encrypt the wallet keys at rest with a new passphrase",
+	Description: `Reads the passphrase twice from the terminal, or from stdin, and encrypts the
+   keys in the node keystore with it. The wallet stays unlocked until it's locked.`,
+	Action: func(cctx *cli.Context) error {
+		api, closer, err := GetFullNodeAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+		ctx := ReqContext(cctx)
+
+		in := bufio.NewReader(os.Stdin)
+		passphrase, err := readPassphrase(in, "Enter new passphrase: ")
+		if err != nil {
+			return err
+		}
+		confirm, err := readPassphrase(in, "Repeat passphrase: ")
+		if err != nil {
+			return err
+		}
+		if confirm != passphrase {
+			return xerrors.Errorf("passphrases don't match")
+		}
+
+		if err := api.WalletSetPassphrase(ctx, passphrase); err != nil {
+			return err
+		}
+
+		fmt.Println("wallet keys encrypted")
+		return nil
+	},
+}
+
+var walletUnlock = &cli.Command{
+	Name:        "unlock",
+	Usage:       "Unlock the wallet keys encrypted at rest",
+	Description: `Reads the passphrase from the terminal, or from stdin.`,
+	Flags: []cli.Flag{
+		&cli.DurationFlag{
+			Name:  "timeout",
+			Usage: "lock the wallet again after this long, 0 keeps it unlocked",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		api, closer, err := GetFullNodeAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+		ctx := ReqContext(cctx)
+
+		passphrase, err := readPassphrase(bufio.NewReader(os.Stdin), "Enter passphrase: ")
+		if err != nil {
+			return err
+		}
+
+		if err := api.WalletUnlock(ctx, passphrase, cctx.Duration("timeout")); err != nil {
+			return err
+		}
+
+		fmt.Println("wallet unlocked")
+		return nil
+	},
+}
+
+var walletLock = &cli.Command{
+	Name:  "lock",
+	Usage: "Lock the wallet keys encrypted at rest",
+	Action: func(cctx *cli.Context) error {
+		api, closer, err := GetFullNodeAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+		ctx := ReqContext(cctx)
+
+		return api.WalletLock(ctx)
+	},
+}
+
+// readPassphrase reads a passphrase without echoing it, or a line from in, when
+// stdin isn't a terminal.
+func readPassphrase(in *bufio.Reader, prompt string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !terminal.IsTerminal(fd) {
+		line, err := in.ReadString('\n')
+		if err != nil && line == "" {
+			return "", xerrors.Errorf("reading passphrase: %w", err)
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+
+	fmt.Print(prompt)
+	b, err := terminal.ReadPassword(fd)
+	fmt.Println()
+	if err != nil {
+		return "", xerrors.Errorf("reading passphrase: %w", err)
+	}
+	return string(b), nil
+}
+
 // var walletMarket = &cli.Command{
 // 	Name:  "market",
 // 	Usage: "Interact with market balances",
@@ -1,7 +1,6 @@
 package main
 
 import (
-	"database/sql"
 	"fmt"
 	"hash/crc32"
 	"strconv"
@@ -10,6 +9,8 @@ import (
 	logging "github.com/ipfs/go-log/v2"
 	"github.com/urfave/cli/v2"
 	"golang.org/x/xerrors"
+
+	"github.com/EpiK-Protocol/go-epik/cmd/epik-chainwatch/storage"
 )
 
 var dotCmd = &cli.Command{
@@ -22,7 +23,7 @@ var dotCmd = &cli.Command{
 			return err
 		}
 
-		db, err := sql.Open("postgres", cctx.String("db"))
+		db, err := storage.Open(storage.Dialect(cctx.String("db-driver")), cctx.String("db"))
 		if err != nil {
 			return err
 		}
@@ -32,10 +33,6 @@ var dotCmd = &cli.Command{
 			}
 		}()
 
-		if err := db.Ping(); err != nil {
-			return xerrors.Errorf("Database failed to respond to ping (is it online?): %w", err)
-		}
-
 		minH, err := strconv.ParseInt(cctx.Args().Get(0), 10, 32)
 		if err != nil {
 			return err
@@ -106,7 +103,7 @@ where b.height > $1 and b.height < $2`, minH, maxH)
 	},
 }
 
-func syncedBlocks(db *sql.DB) (map[cid.Cid]struct{}, error) {
+func syncedBlocks(db *storage.DB) (map[cid.Cid]struct{}, error) {
 	// timestamp is used to return a configurable amount of rows based on when they were last added.
 	rws, err := db.Query(`select cid FROM blocks_synced`)
 	if err != nil {
@@ -35,6 +35,13 @@ func main() {
 				Name:    "db",
 				EnvVars: []string{"EPIK_DB"},
 				Value:   "",
+				Usage:   "postgres connection string, or path of the sqlite database file",
+			},
+			&cli.StringFlag{
+				Name:    "db-driver",
+				EnvVars: []string{"EPIK_DB_DRIVER"},
+				Value:   "postgres",
+				Usage:   "database backend: postgres or sqlite",
 			},
 			&cli.StringFlag{
 				Name:    "log-level",
@@ -17,16 +17,11 @@ import (
 	_init "github.com/EpiK-Protocol/go-epik/chain/actors/builtin/init"
 	"github.com/EpiK-Protocol/go-epik/chain/events/state"
 	"github.com/EpiK-Protocol/go-epik/chain/types"
+	"github.com/EpiK-Protocol/go-epik/cmd/epik-chainwatch/storage"
 	cw_util "github.com/EpiK-Protocol/go-epik/cmd/epik-chainwatch/util"
 )
 
-func (p *Processor) setupCommonActors() error {
-	tx, err := p.db.Begin()
-	if err != nil {
-		return err
-	}
-
-	if _, err := tx.Exec(`
+const commonActorsSchema = `
 create table if not exists id_address_map
 (
 	id text not null,
@@ -62,22 +57,6 @@ create index if not exists id_address_map_address_index
 create index if not exists id_address_map_id_index
 	on id_address_map (id);
 
-create or replace function actor_tips(epoch bigint)
-    returns table (id text,
-                    code text,
-                    head text,
-                    nonce int,
-                    balance text,
-                    stateroot text,
-                    height bigint,
-                    parentstateroot text) as
-$body$
-    select distinct on (id) * from actors
-        inner join state_heights sh on sh.parentstateroot = stateroot
-        where height < $1
-		order by id, height desc;
-$body$ language sql;
-
 create table if not exists actor_states
 (
 	head text not null,
@@ -94,11 +73,36 @@ create index if not exists actor_states_head_index
 create index if not exists actor_states_code_head_index
 	on actor_states (head, code);
 
-`); err != nil {
-		return err
-	}
+`
 
-	return tx.Commit()
+// actorTipsFunction returns the latest state of each actor before an epoch.
+// SQLite has no stored functions, so it only exists on Postgres.
+const actorTipsFunction = `
+create or replace function actor_tips(epoch bigint)
+    returns table (id text,
+                    code text,
+                    head text,
+                    nonce int,
+                    balance text,
+                    stateroot text,
+                    height bigint,
+                    parentstateroot text) as
+$body$
+    select distinct on (id) * from actors
+        inner join state_heights sh on sh.parentstateroot = stateroot
+        where height < $1
+		order by id, height desc;
+$body$ language sql;
+`
+
+var commonActorsMigrations = []storage.Migration{{
+	Version:  1,
+	Postgres: commonActorsSchema + actorTipsFunction,
+	SQLite:   commonActorsSchema,
+}}
+
+func (p *Processor) setupCommonActors() error {
+	return p.db.Migrate("common_actors", commonActorsMigrations)
 }
 
 func (p *Processor) HandleCommonActorsChanges(ctx context.Context, actors map[cid.Cid]ActorTips) error {
@@ -175,34 +179,25 @@ func (p Processor) storeActorAddresses(ctx context.Context, actors map[cid.Cid]A
 		return err
 	}
 
-	if _, err := tx.Exec(`
-create temp table iam (like id_address_map excluding constraints) on commit drop;
-`); err != nil {
-		return xerrors.Errorf("prep temp: %w", err)
-	}
-
-	stmt, err := tx.Prepare(`copy iam (id, address) from STDIN `)
+	// HACK until chain watch can handle reorgs we need to update this table when ID -> PubKey mappings change
+	ins, err := p.db.NewInserter(tx, "id_address_map", "id", "address")
 	if err != nil {
 		return err
 	}
+	ins.OnConflict("(id) do update set address = excluded.address")
 
 	for a, i := range addressToID {
 		if i == address.Undef {
 			continue
 		}
-		if _, err := stmt.Exec(
+		if err := ins.Add(
 			i.String(),
 			a.String(),
 		); err != nil {
 			return err
 		}
 	}
-	if err := stmt.Close(); err != nil {
-		return err
-	}
-
-	// HACK until chain watch can handle reorgs we need to update this table when ID -> PubKey mappings change
-	if _, err := tx.Exec(`insert into id_address_map select * from iam on conflict (id) do update set address = EXCLUDED.address`); err != nil {
+	if err := ins.Flush(); err != nil {
 		log.Warnw("Failed to update id_address_map table, this is a known issue")
 		return nil
 	}
@@ -220,13 +215,8 @@ func (p *Processor) storeActorHeads(actors map[cid.Cid]ActorTips) error {
 	if err != nil {
 		return err
 	}
-	if _, err := tx.Exec(`
-		create temp table a_tmp (like actors excluding constraints) on commit drop;
-	`); err != nil {
-		return xerrors.Errorf("prep temp: %w", err)
-	}
 
-	stmt, err := tx.Prepare(`copy a_tmp (id, code, head, nonce, balance, stateroot) from stdin `)
+	ins, err := p.db.NewInserter(tx, "actors", "id", "code", "head", "nonce", "balance", "stateroot")
 	if err != nil {
 		return err
 	}
@@ -238,18 +228,14 @@ func (p *Processor) storeActorHeads(actors map[cid.Cid]ActorTips) error {
 		}
 		for _, actorInfo := range actTips {
 			for _, a := range actorInfo {
-				if _, err := stmt.Exec(a.addr.String(), actorName, a.act.Head.String(), a.act.Nonce, a.act.Balance.String(), a.stateroot.String()); err != nil {
+				if err := ins.Add(a.addr.String(), actorName, a.act.Head.String(), a.act.Nonce, a.act.Balance.String(), a.stateroot.String()); err != nil {
 					return err
 				}
 			}
 		}
 	}
 
-	if err := stmt.Close(); err != nil {
-		return err
-	}
-
-	if _, err := tx.Exec(`insert into actors select * from a_tmp on conflict do nothing `); err != nil {
+	if err := ins.Flush(); err != nil {
 		return xerrors.Errorf("actor put: %w", err)
 	}
 
@@ -266,13 +252,8 @@ func (p *Processor) storeActorStates(actors map[cid.Cid]ActorTips) error {
 	if err != nil {
 		return err
 	}
-	if _, err := tx.Exec(`
-		create temp table as_tmp (like actor_states excluding constraints) on commit drop;
-	`); err != nil {
-		return xerrors.Errorf("prep temp: %w", err)
-	}
 
-	stmt, err := tx.Prepare(`copy as_tmp (head, code, state) from stdin `)
+	ins, err := p.db.NewInserter(tx, "actor_states", "head", "code", "state")
 	if err != nil {
 		return err
 	}
@@ -284,18 +265,14 @@ func (p *Processor) storeActorStates(actors map[cid.Cid]ActorTips) error {
 		}
 		for _, actorInfo := range actTips {
 			for _, a := range actorInfo {
-				if _, err := stmt.Exec(a.act.Head.String(), actorName, a.state); err != nil {
+				if err := ins.Add(a.act.Head.String(), actorName, a.state); err != nil {
 					return err
 				}
 			}
 		}
 	}
 
-	if err := stmt.Close(); err != nil {
-		return err
-	}
-
-	if _, err := tx.Exec(`insert into actor_states select * from as_tmp on conflict do nothing `); err != nil {
+	if err := ins.Flush(); err != nil {
 		return xerrors.Errorf("actor put: %w", err)
 	}
 
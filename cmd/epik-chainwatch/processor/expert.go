@@ -0,0 +1,329 @@
+package processor
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/xerrors"
+
+	builtin2 "github.com/filecoin-project/specs-actors/v2/actors/builtin"
+	expert2 "github.com/filecoin-project/specs-actors/v2/actors/builtin/expert"
+
+	"github.com/EpiK-Protocol/go-epik/blockstore"
+	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/expert"
+	"github.com/EpiK-Protocol/go-epik/chain/store"
+	"github.com/EpiK-Protocol/go-epik/chain/types"
+	"github.com/EpiK-Protocol/go-epik/cmd/epik-chainwatch/storage"
+)
+
+const expertsSchema = `
+create table if not exists experts
+(
+	expert_id text not null,
+	owner_id text not null,
+	proposer_id text not null,
+	expert_type text not null,
+	application_hash text not null,
+	state_root text not null,
+
+	constraint experts_pk
+		primary key (expert_id)
+);
+
+create table if not exists expert_status_changes
+(
+	expert_id text not null,
+	state_root text not null,
+
+	status text not null,
+	implicated_times bigint not null,
+	data_count bigint not null,
+	current_votes text not null,
+	required_votes text not null,
+
+	constraint expert_status_changes_pk
+		primary key (expert_id, state_root)
+);
+
+create table if not exists expert_datas
+(
+	piece_cid text not null,
+	root_cid text not null,
+	piece_size bigint not null,
+	expert_id text not null,
+	state_root text not null,
+
+	constraint expert_datas_pk
+		primary key (piece_cid)
+);
+
+create index if not exists expert_datas_expert_id_index
+	on expert_datas (expert_id);
+
+`
+
+var expertsMigrations = []storage.Migration{{
+	Version:  1,
+	Postgres: expertsSchema,
+	SQLite:   expertsSchema,
+}}
+
+func (p *Processor) setupExperts() error {
+	return p.db.Migrate("experts", expertsMigrations)
+}
+
+type expertActorInfo struct {
+	common actorInfo
+
+	// prev is nil if the expert registered in this tipset
+	prev, cur expert.State
+}
+
+func (p *Processor) HandleExpertChanges(ctx context.Context, expertTips ActorTips) error {
+	expertChanges, err := p.processExperts(ctx, expertTips)
+	if err != nil {
+		return xerrors.Errorf("Failed to process expert actors: %w", err)
+	}
+
+	if err := p.persistExperts(ctx, expertChanges); err != nil {
+		return xerrors.Errorf("Failed to persist expert actors: %w", err)
+	}
+
+	return nil
+}
+
+func (p *Processor) processExperts(ctx context.Context, expertTips ActorTips) ([]expertActorInfo, error) {
+	start := time.Now()
+	defer func() {
+		log.Debugw("Processed Experts", "duration", time.Since(start).String())
+	}()
+
+	stor := store.ActorStore(ctx, blockstore.NewAPIBlockstore(p.node))
+
+	var out []expertActorInfo
+	for _, experts := range expertTips {
+		for _, act := range experts {
+			var ei expertActorInfo
+			ei.common = act
+
+			cur, err := expert.Load(stor, &act.act)
+			if err != nil {
+				log.Warnw("failed to find expert actor state", "address", act.addr, "error", err)
+				continue
+			}
+			ei.cur = cur
+
+			prevActor, err := p.node.StateGetActor(ctx, act.addr, act.parentTsKey)
+			if err == nil {
+				if ei.prev, err = expert.Load(stor, prevActor); err != nil {
+					return nil, xerrors.Errorf("load expert %s state (@ %s): %w", act.addr, act.parentTsKey, err)
+				}
+			} else if !strings.Contains(err.Error(), types.ErrActorNotFound.Error()) {
+				return nil, xerrors.Errorf("get expert %s (@ %s): %w", act.addr, act.parentTsKey, err)
+			}
+
+			out = append(out, ei)
+		}
+	}
+	return out, nil
+}
+
+func (p *Processor) persistExperts(ctx context.Context, experts []expertActorInfo) error {
+	start := time.Now()
+	defer func() {
+		log.Debugw("Persisted Experts", "duration", time.Since(start).String())
+	}()
+
+	grp, _ := errgroup.WithContext(ctx)
+
+	grp.Go(func() error {
+		if err := p.storeExperts(experts); err != nil {
+			return xerrors.Errorf("Failed to store experts: %w", err)
+		}
+		return nil
+	})
+
+	grp.Go(func() error {
+		if err := p.storeExpertStatusChanges(experts); err != nil {
+			return xerrors.Errorf("Failed to store expert status changes: %w", err)
+		}
+		return nil
+	})
+
+	grp.Go(func() error {
+		if err := p.storeExpertDatas(experts); err != nil {
+			return xerrors.Errorf("Failed to store expert datas: %w", err)
+		}
+		return nil
+	})
+
+	return grp.Wait()
+}
+
+func (p *Processor) storeExperts(experts []expertActorInfo) error {
+	tx, err := p.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	ins, err := p.db.NewInserter(tx, "experts", "expert_id", "owner_id", "proposer_id", "expert_type", "application_hash", "state_root")
+	if err != nil {
+		return err
+	}
+
+	for _, e := range experts {
+		if e.prev != nil {
+			continue
+		}
+		info, err := e.cur.Info()
+		if err != nil {
+			return xerrors.Errorf("get expert %s info: %w", e.common.addr, err)
+		}
+
+		expertType := "foundation"
+		if info.Type == builtin2.ExpertNormal {
+			expertType = "normal"
+		}
+		if err := ins.Add(
+			e.common.addr.String(),
+			info.Owner.String(),
+			info.Proposer.String(),
+			expertType,
+			info.ApplicationHash,
+			e.common.stateroot.String(),
+		); err != nil {
+			return err
+		}
+	}
+
+	if err := ins.Flush(); err != nil {
+		return xerrors.Errorf("insert experts: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+func (p *Processor) storeExpertStatusChanges(experts []expertActorInfo) error {
+	tx, err := p.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	ins, err := p.db.NewInserter(tx, "expert_status_changes", "expert_id", "state_root", "status", "implicated_times", "data_count", "current_votes", "required_votes")
+	if err != nil {
+		return err
+	}
+
+	for _, e := range experts {
+		to, err := e.cur.Info()
+		if err != nil {
+			return xerrors.Errorf("get expert %s info: %w", e.common.addr, err)
+		}
+		if e.prev != nil {
+			from, err := e.prev.Info()
+			if err != nil {
+				return xerrors.Errorf("get expert %s info: %w", e.common.addr, err)
+			}
+			// same as the state predicate, data count changes are recorded by expert_datas
+			if from.Status == to.Status &&
+				from.ImplicatedTimes == to.ImplicatedTimes &&
+				from.CurrentVotes.Equals(to.CurrentVotes) &&
+				from.Owner == to.Owner {
+				continue
+			}
+		}
+
+		if err := ins.Add(
+			e.common.addr.String(),
+			e.common.stateroot.String(),
+			expertStatus(to.Status),
+			to.ImplicatedTimes,
+			to.DataCount,
+			to.CurrentVotes.String(),
+			to.RequiredVotes.String(),
+		); err != nil {
+			return err
+		}
+	}
+
+	if err := ins.Flush(); err != nil {
+		return xerrors.Errorf("insert expert_status_changes: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+func (p *Processor) storeExpertDatas(experts []expertActorInfo) error {
+	tx, err := p.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	ins, err := p.db.NewInserter(tx, "expert_datas", "piece_cid", "root_cid", "piece_size", "expert_id", "state_root")
+	if err != nil {
+		return err
+	}
+
+	for _, e := range experts {
+		added, err := expertDatasAdded(e)
+		if err != nil {
+			return xerrors.Errorf("diff expert %s datas: %w", e.common.addr, err)
+		}
+
+		for _, d := range added {
+			if err := ins.Add(
+				d.PieceID,
+				d.RootID,
+				d.PieceSize,
+				e.common.addr.String(),
+				e.common.stateroot.String(),
+			); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := ins.Flush(); err != nil {
+		return xerrors.Errorf("insert expert_datas: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// expertDatasAdded returns the datas the expert imported in the tipset.
+func expertDatasAdded(e expertActorInfo) ([]expert.DataOnChainInfo, error) {
+	if e.prev != nil {
+		changes, err := expert.DiffDatas(e.prev, e.cur)
+		if err != nil {
+			return nil, err
+		}
+		return changes.Added, nil
+	}
+
+	datas, err := e.cur.Datas()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]expert.DataOnChainInfo, 0, len(datas))
+	for _, d := range datas {
+		out = append(out, *d)
+	}
+	return out, nil
+}
+
+func expertStatus(status expert2.ExpertState) string {
+	switch status {
+	case expert2.ExpertStateRegistered:
+		return "registered"
+	case expert2.ExpertStateUnqualified:
+		return "unqualified"
+	case expert2.ExpertStateQualified:
+		return "qualified"
+	case expert2.ExpertStateBlocked:
+		return "blocked"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,185 @@
+package processor
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-state-types/abi"
+
+	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin"
+	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/govern"
+	"github.com/EpiK-Protocol/go-epik/chain/events/state"
+	"github.com/EpiK-Protocol/go-epik/cmd/epik-chainwatch/storage"
+)
+
+var governMigrations = []storage.Migration{{
+	Version: 1,
+	Postgres: `
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'govern_authority_event_type') THEN
+        CREATE TYPE govern_authority_event_type AS ENUM
+        (
+            'GRANTED', 'CHANGED', 'REVOKED'
+        );
+    END IF;
+END$$;
+
+create table if not exists govern_authority_events
+(
+	governor_id text not null,
+	state_root text not null,
+	event govern_authority_event_type not null,
+
+	/* the authorities held after the event, empty when revoked */
+	authorities json not null,
+
+	constraint govern_authority_events_pk
+		primary key (governor_id, state_root)
+);
+
+`,
+	SQLite: `
+create table if not exists govern_authority_events
+(
+	governor_id text not null,
+	state_root text not null,
+	event text not null check (event in ('GRANTED', 'CHANGED', 'REVOKED')),
+
+	/* the authorities held after the event, empty when revoked */
+	authorities json not null,
+
+	constraint govern_authority_events_pk
+		primary key (governor_id, state_root)
+);
+
+`,
+}}
+
+func (p *Processor) setupGovern() error {
+	return p.db.Migrate("govern", governMigrations)
+}
+
+type GovernAuthorityEvent string
+
+const (
+	AuthorityGranted = "GRANTED"
+	AuthorityChanged = "CHANGED"
+	AuthorityRevoked = "REVOKED"
+)
+
+type governActorInfo struct {
+	common actorInfo
+
+	changes *govern.GovernorChanges
+}
+
+// governorAuthority is an authority as recorded in the database, with the
+// name of the actor rather than its code.
+type governorAuthority struct {
+	Actor   string
+	Methods []abi.MethodNum
+}
+
+func (p *Processor) HandleGovernChanges(ctx context.Context, governTips ActorTips) error {
+	governChanges, err := p.processGovern(ctx, governTips)
+	if err != nil {
+		return xerrors.Errorf("Failed to process govern actor: %w", err)
+	}
+
+	if err := p.persistGovern(ctx, governChanges); err != nil {
+		return xerrors.Errorf("Failed to persist govern actor: %w", err)
+	}
+
+	return nil
+}
+
+func (p *Processor) processGovern(ctx context.Context, governTips ActorTips) ([]governActorInfo, error) {
+	start := time.Now()
+	defer func() {
+		log.Debugw("Processed Govern Actor", "duration", time.Since(start).String())
+	}()
+
+	pred := state.NewStatePredicates(p.node)
+
+	var out []governActorInfo
+	for _, governs := range governTips {
+		for _, act := range governs {
+			changed, val, err := pred.OnGovernActorChanged(pred.OnGovernorAuthorityChanged())(ctx, act.parentTsKey, act.tsKey)
+			if err != nil {
+				return nil, xerrors.Errorf("diff governors (@ %s): %w", act.stateroot, err)
+			}
+			if !changed {
+				continue
+			}
+			changes, ok := val.(*govern.GovernorChanges)
+			if !ok {
+				return nil, xerrors.Errorf("Unknown type returned by governor predicate: %T", val)
+			}
+			out = append(out, governActorInfo{common: act, changes: changes})
+		}
+	}
+	return out, nil
+}
+
+func (p *Processor) persistGovern(ctx context.Context, governs []governActorInfo) error {
+	start := time.Now()
+	defer func() {
+		log.Debugw("Persisted Govern Actor", "duration", time.Since(start).String())
+	}()
+
+	tx, err := p.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	ins, err := p.db.NewInserter(tx, "govern_authority_events", "governor_id", "state_root", "event", "authorities")
+	if err != nil {
+		return err
+	}
+
+	record := func(g governActorInfo, gi govern.GovernorInfo, event GovernAuthorityEvent) error {
+		authorities := make([]governorAuthority, 0, len(gi.Authorities))
+		if event != AuthorityRevoked {
+			for _, a := range gi.Authorities {
+				actorName := a.ActorCodeID.String()
+				if builtin.IsBuiltinActor(a.ActorCodeID) {
+					actorName = builtin.ActorNameByCode(a.ActorCodeID)
+				}
+				authorities = append(authorities, governorAuthority{Actor: actorName, Methods: a.Methods})
+			}
+		}
+		js, err := json.Marshal(authorities)
+		if err != nil {
+			return err
+		}
+		return ins.Add(gi.Address.String(), g.common.stateroot.String(), event, string(js))
+	}
+
+	for _, g := range governs {
+		for _, added := range g.changes.Added {
+			if err := record(g, added, AuthorityGranted); err != nil {
+				return err
+			}
+		}
+		for _, modified := range g.changes.Modified {
+			if err := record(g, modified.To, AuthorityChanged); err != nil {
+				return err
+			}
+		}
+		for _, removed := range g.changes.Removed {
+			if err := record(g, removed, AuthorityRevoked); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := ins.Flush(); err != nil {
+		return xerrors.Errorf("insert govern_authority_events: %w", err)
+	}
+
+	return tx.Commit()
+}
@@ -10,15 +10,10 @@ import (
 
 	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/market"
 	"github.com/EpiK-Protocol/go-epik/chain/events/state"
+	"github.com/EpiK-Protocol/go-epik/cmd/epik-chainwatch/storage"
 )
 
-func (p *Processor) setupMarket() error {
-	tx, err := p.db.Begin()
-	if err != nil {
-		return err
-	}
-
-	if _, err := tx.Exec(`
+const marketSchema = `
 create table if not exists market_deal_proposals
 (
     deal_id bigint not null,
@@ -76,11 +71,16 @@ create table if not exists minerid_dealid_sectorid
         primary key (miner_id, sector_id, deal_id)
 );
 
-`); err != nil {
-		return err
-	}
+`
 
-	return tx.Commit()
+var marketMigrations = []storage.Migration{{
+	Version:  1,
+	Postgres: marketSchema,
+	SQLite:   marketSchema,
+}}
+
+func (p *Processor) setupMarket() error {
+	return p.db.Migrate("market", marketMigrations)
 }
 
 type marketActorInfo struct {
@@ -161,10 +161,7 @@ func (p *Processor) storeMarketActorDealStates(marketTips []marketActorInfo) err
 	if err != nil {
 		return err
 	}
-	if _, err := tx.Exec(`create temp table mds (like market_deal_states excluding constraints) on commit drop;`); err != nil {
-		return err
-	}
-	stmt, err := tx.Prepare(`copy mds (deal_id, sector_start_epoch, last_update_epoch, slash_epoch, state_root) from STDIN`)
+	ins, err := p.db.NewInserter(tx, "market_deal_states", "deal_id", "sector_start_epoch", "last_update_epoch", "slash_epoch", "state_root")
 	if err != nil {
 		return err
 	}
@@ -180,7 +177,7 @@ func (p *Processor) storeMarketActorDealStates(marketTips []marketActorInfo) err
 				return err
 			}
 
-			if _, err := stmt.Exec(
+			if err := ins.Add(
 				id,
 				ds.State.SectorStartEpoch,
 				ds.State.LastUpdatedEpoch,
@@ -192,11 +189,7 @@ func (p *Processor) storeMarketActorDealStates(marketTips []marketActorInfo) err
 
 		}
 	}
-	if err := stmt.Close(); err != nil {
-		return err
-	}
-
-	if _, err := tx.Exec(`insert into market_deal_states select * from mds on conflict do nothing`); err != nil {
+	if err := ins.Flush(); err != nil {
 		return err
 	}
 
@@ -213,11 +206,7 @@ func (p *Processor) storeMarketActorDealProposals(ctx context.Context, marketTip
 		return err
 	}
 
-	if _, err := tx.Exec(`create temp table mdp (like market_deal_proposals excluding constraints) on commit drop;`); err != nil {
-		return xerrors.Errorf("prep temp: %w", err)
-	}
-
-	stmt, err := tx.Prepare(`copy mdp (deal_id, state_root, piece_cid, padded_piece_size, unpadded_piece_size, is_verified, client_id, provider_id, start_epoch, end_epoch, slashed_epoch, storage_price_per_epoch, provider_collateral, client_collateral) from STDIN`)
+	ins, err := p.db.NewInserter(tx, "market_deal_proposals", "deal_id", "state_root", "piece_cid", "padded_piece_size", "unpadded_piece_size", "is_verified", "client_id", "provider_id", "start_epoch", "end_epoch", "slashed_epoch", "storage_price_per_epoch", "provider_collateral", "client_collateral")
 	if err != nil {
 		return err
 	}
@@ -235,7 +224,7 @@ func (p *Processor) storeMarketActorDealProposals(ctx context.Context, marketTip
 				return err
 			}
 
-			if _, err := stmt.Exec(
+			if err := ins.Add(
 				id,
 				mt.common.stateroot.String(),
 				ds.Proposal.PieceCID.String(),
@@ -256,10 +245,7 @@ func (p *Processor) storeMarketActorDealProposals(ctx context.Context, marketTip
 
 		}
 	}
-	if err := stmt.Close(); err != nil {
-		return err
-	}
-	if _, err := tx.Exec(`insert into market_deal_proposals select * from mdp on conflict do nothing`); err != nil {
+	if err := ins.Flush(); err != nil {
 		return err
 	}
 
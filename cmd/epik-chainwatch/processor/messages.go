@@ -10,16 +10,11 @@ import (
 	"github.com/ipfs/go-cid"
 
 	"github.com/EpiK-Protocol/go-epik/chain/types"
+	"github.com/EpiK-Protocol/go-epik/cmd/epik-chainwatch/storage"
 	"github.com/EpiK-Protocol/go-epik/lib/parmap"
 )
 
-func (p *Processor) setupMessages() error {
-	tx, err := p.db.Begin()
-	if err != nil {
-		return err
-	}
-
-	if _, err := tx.Exec(`
+const messagesSchema = `
 create table if not exists messages
 (
 	cid text not null
@@ -83,11 +78,16 @@ create table if not exists receipts
 
 create index if not exists receipts_msg_state_index
 	on receipts (msg, state);
-`); err != nil {
-		return err
-	}
+`
 
-	return tx.Commit()
+var messagesMigrations = []storage.Migration{{
+	Version:  1,
+	Postgres: messagesSchema,
+	SQLite:   messagesSchema,
+}}
+
+func (p *Processor) setupMessages() error {
+	return p.db.Migrate("messages", messagesMigrations)
 }
 
 func (p *Processor) HandleMessageChanges(ctx context.Context, blocks map[cid.Cid]*types.BlockHeader) error {
@@ -124,19 +124,13 @@ func (p *Processor) storeReceipts(recs map[mrec]*types.MessageReceipt) error {
 		return err
 	}
 
-	if _, err := tx.Exec(`
-create temp table recs (like receipts excluding constraints) on commit drop;
-`); err != nil {
-		return xerrors.Errorf("prep temp: %w", err)
-	}
-
-	stmt, err := tx.Prepare(`copy recs (msg, state, idx, exit, gas_used, return) from stdin `)
+	ins, err := p.db.NewInserter(tx, "receipts", "msg", "state", "idx", "exit", "gas_used", "return")
 	if err != nil {
 		return err
 	}
 
 	for c, m := range recs {
-		if _, err := stmt.Exec(
+		if err := ins.Add(
 			c.msg.String(),
 			c.state.String(),
 			c.idx,
@@ -147,11 +141,7 @@ create temp table recs (like receipts excluding constraints) on commit drop;
 			return err
 		}
 	}
-	if err := stmt.Close(); err != nil {
-		return err
-	}
-
-	if _, err := tx.Exec(`insert into receipts select * from recs on conflict do nothing `); err != nil {
+	if err := ins.Flush(); err != nil {
 		return xerrors.Errorf("actor put: %w", err)
 	}
 
@@ -164,20 +154,14 @@ func (p *Processor) storeMsgInclusions(incls map[cid.Cid][]cid.Cid) error {
 		return err
 	}
 
-	if _, err := tx.Exec(`
-create temp table mi (like block_messages excluding constraints) on commit drop;
-`); err != nil {
-		return xerrors.Errorf("prep temp: %w", err)
-	}
-
-	stmt, err := tx.Prepare(`copy mi (block, message) from STDIN `)
+	ins, err := p.db.NewInserter(tx, "block_messages", "block", "message")
 	if err != nil {
 		return err
 	}
 
 	for b, msgs := range incls {
 		for _, msg := range msgs {
-			if _, err := stmt.Exec(
+			if err := ins.Add(
 				b.String(),
 				msg.String(),
 			); err != nil {
@@ -185,11 +169,7 @@ create temp table mi (like block_messages excluding constraints) on commit drop;
 			}
 		}
 	}
-	if err := stmt.Close(); err != nil {
-		return err
-	}
-
-	if _, err := tx.Exec(`insert into block_messages select * from mi on conflict do nothing `); err != nil {
+	if err := ins.Flush(); err != nil {
 		return xerrors.Errorf("actor put: %w", err)
 	}
 
@@ -202,13 +182,7 @@ func (p *Processor) storeMessages(msgs map[cid.Cid]*types.Message) error {
 		return err
 	}
 
-	if _, err := tx.Exec(`
-create temp table msgs (like messages excluding constraints) on commit drop;
-`); err != nil {
-		return xerrors.Errorf("prep temp: %w", err)
-	}
-
-	stmt, err := tx.Prepare(`copy msgs (cid, "from", "to", size_bytes, nonce, "value", gas_premium, gas_fee_cap, gas_limit, method, params) from stdin `)
+	ins, err := p.db.NewInserter(tx, "messages", "cid", "from", "to", "size_bytes", "nonce", "value", "gas_premium", "gas_fee_cap", "gas_limit", "method", "params")
 	if err != nil {
 		return err
 	}
@@ -219,7 +193,7 @@ create temp table msgs (like messages excluding constraints) on commit drop;
 			msgBytes = len(b)
 		}
 
-		if _, err := stmt.Exec(
+		if err := ins.Add(
 			c.String(),
 			m.From.String(),
 			m.To.String(),
@@ -235,11 +209,7 @@ create temp table msgs (like messages excluding constraints) on commit drop;
 			return err
 		}
 	}
-	if err := stmt.Close(); err != nil {
-		return err
-	}
-
-	if _, err := tx.Exec(`insert into messages select * from msgs on conflict do nothing `); err != nil {
+	if err := ins.Flush(); err != nil {
 		return xerrors.Errorf("actor put: %w", err)
 	}
 
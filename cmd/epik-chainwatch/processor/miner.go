@@ -21,17 +21,12 @@ import (
 	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/power"
 	"github.com/EpiK-Protocol/go-epik/chain/store"
 	"github.com/EpiK-Protocol/go-epik/chain/types"
+	"github.com/EpiK-Protocol/go-epik/cmd/epik-chainwatch/storage"
 	cw_util "github.com/EpiK-Protocol/go-epik/cmd/epik-chainwatch/util"
 )
 
-func (p *Processor) setupMiners() error {
-	tx, err := p.db.Begin()
-	if err != nil {
-		return err
-	}
-	//TODO: modify table structure
-	if _, err := tx.Exec(`
-
+// TODO: modify table structure
+const minersSchema = `
 create table if not exists miner_info
 (
 	miner_id text not null,
@@ -105,7 +100,11 @@ create table if not exists miner_power
 	constraint miner_power_pk
 		primary key (miner_id, state_root)
 );
+`
 
+var minersMigrations = []storage.Migration{{
+	Version: 1,
+	Postgres: minersSchema + `
 DO $$
 BEGIN
     IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'miner_sector_event_type') THEN
@@ -128,11 +127,25 @@ create table if not exists miner_sector_events
 		primary key (sector_id, event, miner_id, state_root)
 );
 
-`); err != nil {
-		return err
-	}
+`,
+	SQLite: minersSchema + `
 
-	return tx.Commit()
+create table if not exists miner_sector_events
+(
+    miner_id text not null,
+    sector_id bigint not null,
+    state_root text not null,
+    event text not null check (event in ('PRECOMMIT_ADDED', 'PRECOMMIT_EXPIRED', 'COMMIT_CAPACITY_ADDED', 'SECTOR_ADDED', 'SECTOR_EXTENDED', 'SECTOR_EXPIRED', 'SECTOR_FAULTED', 'SECTOR_RECOVERING', 'SECTOR_RECOVERED', 'SECTOR_TERMINATED')),
+    
+	constraint miner_sector_events_pk
+		primary key (sector_id, event, miner_id, state_root)
+);
+
+`,
+}}
+
+func (p *Processor) setupMiners() error {
+	return p.db.Migrate("miners", minersMigrations)
 }
 
 type SectorLifecycleEvent string
@@ -305,12 +318,8 @@ func (p *Processor) storeMinerPreCommitInfo(ctx context.Context, miners []minerA
 		return err
 	}
 
-	if _, err := tx.Exec(`create temp table spi (like sector_precommit_info excluding constraints) on commit drop;`); err != nil {
-		return xerrors.Errorf("Failed to create temp table for sector_precommit_info: %w", err)
-	}
-
-	// stmt, err := tx.Prepare(`copy spi (miner_id, sector_id, sealed_cid, state_root, seal_rand_epoch, expiration_epoch, precommit_deposit, precommit_epoch, deal_weight, verified_deal_weight, is_replace_capacity, replace_sector_deadline, replace_sector_partition, replace_sector_number) from STDIN`)
-	stmt, err := tx.Prepare(`copy spi (miner_id, sector_id, sealed_cid, state_root, seal_rand_epoch, precommit_epoch) from STDIN`)
+	// ins, err := p.db.NewInserter(tx, "sector_precommit_info", "miner_id", "sector_id", "sealed_cid", "state_root", "seal_rand_epoch", "expiration_epoch", "precommit_deposit", "precommit_epoch", "deal_weight", "verified_deal_weight", "is_replace_capacity", "replace_sector_deadline", "replace_sector_partition", "replace_sector_number")
+	ins, err := p.db.NewInserter(tx, "sector_precommit_info", "miner_id", "sector_id", "sealed_cid", "state_root", "seal_rand_epoch", "precommit_epoch")
 
 	if err != nil {
 		return xerrors.Errorf("Failed to prepare miner precommit info statement: %w", err)
@@ -341,7 +350,7 @@ func (p *Processor) storeMinerPreCommitInfo(ctx context.Context, miners []minerA
 					}
 				}
 				/* if added.Info.ReplaceCapacity {
-					if _, err := stmt.Exec(
+					if err := ins.Add(
 						m.common.addr.String(),
 						added.Info.SectorNumber,
 						added.Info.SealedCID.String(),
@@ -360,7 +369,7 @@ func (p *Processor) storeMinerPreCommitInfo(ctx context.Context, miners []minerA
 						return err
 					}
 				} else { */
-				if _, err := stmt.Exec(
+				if err := ins.Add(
 					m.common.addr.String(),
 					added.Info.SectorNumber,
 					added.Info.SealedCID.String(),
@@ -416,11 +425,7 @@ func (p *Processor) storeMinerPreCommitInfo(ctx context.Context, miners []minerA
 		return err
 	}
 
-	if err := stmt.Close(); err != nil {
-		return xerrors.Errorf("Failed to close sector precommit info statement: %w", err)
-	}
-
-	if _, err := tx.Exec(`insert into sector_precommit_info select * from spi on conflict do nothing`); err != nil {
+	if err := ins.Flush(); err != nil {
 		return xerrors.Errorf("Failed to insert into sector precommit info table: %w", err)
 	}
 
@@ -436,12 +441,8 @@ func (p *Processor) storeMinerSectorInfo(ctx context.Context, miners []minerActo
 		return err
 	}
 
-	if _, err := tx.Exec(`create temp table si (like sector_info excluding constraints) on commit drop;`); err != nil {
-		return xerrors.Errorf("Failed to create temp table for sector_: %w", err)
-	}
-
-	// stmt, err := tx.Prepare(`copy si (miner_id, sector_id, sealed_cid, state_root, activation_epoch, expiration_epoch, deal_weight, verified_deal_weight, initial_pledge, expected_day_reward, expected_storage_pledge) from STDIN`)
-	stmt, err := tx.Prepare(`copy si (miner_id, sector_id, sealed_cid, state_root, activation_epoch, ) from STDIN`)
+	// ins, err := p.db.NewInserter(tx, "sector_info", "miner_id", "sector_id", "sealed_cid", "state_root", "activation_epoch", "expiration_epoch", "deal_weight", "verified_deal_weight", "initial_pledge", "expected_day_reward", "expected_storage_pledge")
+	ins, err := p.db.NewInserter(tx, "sector_info", "miner_id", "sector_id", "sealed_cid", "state_root", "activation_epoch")
 	if err != nil {
 		return xerrors.Errorf("Failed to prepare miner sector info statement: %w", err)
 	}
@@ -465,7 +466,7 @@ func (p *Processor) storeMinerSectorInfo(ctx context.Context, miners []minerActo
 			/* var extended []uint64 */
 			for _, added := range changes.Added {
 				// add the sector to the table
-				if _, err := stmt.Exec(
+				if err := ins.Add(
 					m.common.addr.String(),
 					added.SectorNumber,
 					added.SealedCID.String(),
@@ -517,11 +518,7 @@ func (p *Processor) storeMinerSectorInfo(ctx context.Context, miners []minerActo
 		return err
 	}
 
-	if err := stmt.Close(); err != nil {
-		return xerrors.Errorf("Failed to close sector info statement: %w", err)
-	}
-
-	if _, err := tx.Exec(`insert into sector_info select * from si on conflict do nothing`); err != nil {
+	if err := ins.Flush(); err != nil {
 		return xerrors.Errorf("Failed to insert into sector info table: %w", err)
 	}
 
@@ -555,11 +552,7 @@ func (p *Processor) storeMinerSectorEvents(ctx context.Context, sectorEvents, pr
 		return err
 	}
 
-	if _, err := tx.Exec(`create temp table mse (like miner_sector_events excluding constraints) on commit drop;`); err != nil {
-		return xerrors.Errorf("Failed to create temp table for sector_: %w", err)
-	}
-
-	stmt, err := tx.Prepare(`copy mse (miner_id, sector_id, event, state_root) from STDIN`)
+	ins, err := p.db.NewInserter(tx, "miner_sector_events", "miner_id", "sector_id", "event", "state_root")
 	if err != nil {
 		return xerrors.Errorf("Failed to prepare miner sector info statement: %w", err)
 	}
@@ -571,7 +564,7 @@ func (p *Processor) storeMinerSectorEvents(ctx context.Context, sectorEvents, pr
 			mse := mse
 			innerGrp.Go(func() error {
 				for _, sid := range mse.SectorIDs {
-					if _, err := stmt.Exec(
+					if err := ins.Add(
 						mse.MinerID.String(),
 						sid,
 						mse.Event,
@@ -592,7 +585,7 @@ func (p *Processor) storeMinerSectorEvents(ctx context.Context, sectorEvents, pr
 			mse := mse
 			innerGrp.Go(func() error {
 				for _, sid := range mse.SectorIDs {
-					if _, err := stmt.Exec(
+					if err := ins.Add(
 						mse.MinerID.String(),
 						sid,
 						mse.Event,
@@ -613,7 +606,7 @@ func (p *Processor) storeMinerSectorEvents(ctx context.Context, sectorEvents, pr
 			mse := mse
 			grp.Go(func() error {
 				for _, sid := range mse.SectorIDs {
-					if _, err := stmt.Exec(
+					if err := ins.Add(
 						mse.MinerID.String(),
 						sid,
 						mse.Event,
@@ -632,11 +625,7 @@ func (p *Processor) storeMinerSectorEvents(ctx context.Context, sectorEvents, pr
 		return err
 	}
 
-	if err := stmt.Close(); err != nil {
-		return xerrors.Errorf("Failed to close sector event statement: %w", err)
-	}
-
-	if _, err := tx.Exec(`insert into miner_sector_events select * from mse on conflict do nothing`); err != nil {
+	if err := ins.Flush(); err != nil {
 		return xerrors.Errorf("Failed to insert into sector event table: %w", err)
 	}
 
@@ -894,11 +883,7 @@ func (p *Processor) storeMinersActorInfoState(ctx context.Context, miners []mine
 		return err
 	}
 
-	if _, err := tx.Exec(`create temp table mi (like miner_info excluding constraints) on commit drop;`); err != nil {
-		return xerrors.Errorf("prep temp: %w", err)
-	}
-
-	stmt, err := tx.Prepare(`copy mi (miner_id, owner_addr, worker_addr, peer_id, sector_size) from STDIN`)
+	ins, err := p.db.NewInserter(tx, "miner_info", "miner_id", "owner_addr", "worker_addr", "peer_id", "sector_size")
 	if err != nil {
 		return err
 	}
@@ -915,7 +900,7 @@ func (p *Processor) storeMinersActorInfoState(ctx context.Context, miners []mine
 		if mi.PeerId != nil {
 			pid = mi.PeerId.String()
 		}
-		if _, err := stmt.Exec(
+		if err := ins.Add(
 			m.common.addr.String(),
 			mi.Owner.String(),
 			mi.Worker.String(),
@@ -927,11 +912,7 @@ func (p *Processor) storeMinersActorInfoState(ctx context.Context, miners []mine
 		}
 
 	}
-	if err := stmt.Close(); err != nil {
-		return err
-	}
-
-	if _, err := tx.Exec(`insert into miner_info select * from mi on conflict do nothing `); err != nil {
+	if err := ins.Flush(); err != nil {
 		return xerrors.Errorf("actor put: %w", err)
 	}
 
@@ -944,18 +925,14 @@ func (p *Processor) storePreCommitDealInfo(dealEvents <-chan *SectorDealEvent) e
 		return err
 	}
 
-	if _, err := tx.Exec(`create temp table mds (like minerid_dealid_sectorid excluding constraints) on commit  drop;`); err != nil {
-		return xerrors.Errorf("Failed to create temp table for minerid_dealid_sectorid: %w", err)
-	}
-
-	stmt, err := tx.Prepare(`copy mds (deal_id, miner_id, sector_id) from STDIN`)
+	ins, err := p.db.NewInserter(tx, "minerid_dealid_sectorid", "deal_id", "miner_id", "sector_id")
 	if err != nil {
 		return xerrors.Errorf("Failed to prepare minerid_dealid_sectorid statement: %w", err)
 	}
 
 	for sde := range dealEvents {
 		for _, did := range sde.DealIDs {
-			if _, err := stmt.Exec(
+			if err := ins.Add(
 				uint64(did),
 				sde.MinerID.String(),
 				sde.SectorID,
@@ -965,11 +942,7 @@ func (p *Processor) storePreCommitDealInfo(dealEvents <-chan *SectorDealEvent) e
 		}
 	}
 
-	if err := stmt.Close(); err != nil {
-		return xerrors.Errorf("Failed to close miner sector deals statement: %w", err)
-	}
-
-	if _, err := tx.Exec(`insert into minerid_dealid_sectorid select * from mds on conflict do nothing`); err != nil {
+	if err := ins.Flush(); err != nil {
 		return xerrors.Errorf("Failed to insert into miner deal sector table: %w", err)
 	}
 
@@ -991,17 +964,13 @@ func (p *Processor) storeMinersPower(miners []minerActorInfo) error {
 		return xerrors.Errorf("begin miner_power tx: %w", err)
 	}
 
-	if _, err := tx.Exec(`create temp table mp (like miner_power excluding constraints) on commit drop`); err != nil {
-		return xerrors.Errorf("prep miner_power temp: %w", err)
-	}
-
-	stmt, err := tx.Prepare(`copy mp (miner_id, state_root, raw_bytes_power, quality_adjusted_power) from STDIN`)
+	ins, err := p.db.NewInserter(tx, "miner_power", "miner_id", "state_root", "raw_bytes_power", "quality_adjusted_power")
 	if err != nil {
 		return xerrors.Errorf("prepare tmp miner_power: %w", err)
 	}
 
 	for _, m := range miners {
-		if _, err := stmt.Exec(
+		if err := ins.Add(
 			m.common.addr.String(),
 			m.common.stateroot.String(),
 			m.rawPower.String(),
@@ -1011,11 +980,7 @@ func (p *Processor) storeMinersPower(miners []minerActorInfo) error {
 		}
 	}
 
-	if err := stmt.Close(); err != nil {
-		return xerrors.Errorf("close prepared miner_power: %w", err)
-	}
-
-	if _, err := tx.Exec(`insert into miner_power select * from mp on conflict do nothing`); err != nil {
+	if err := ins.Flush(); err != nil {
 		return xerrors.Errorf("insert miner_power from tmp: %w", err)
 	}
 
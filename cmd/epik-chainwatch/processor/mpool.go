@@ -64,13 +64,7 @@ func (p *Processor) storeMpoolInclusions(msgs []api.MpoolUpdate) error {
 		return err
 	}
 
-	if _, err := tx.Exec(`
-		create temp table mi (like mpool_messages excluding constraints) on commit drop;
-	`); err != nil {
-		return xerrors.Errorf("prep temp: %w", err)
-	}
-
-	stmt, err := tx.Prepare(`copy mi (msg, add_ts) from stdin `)
+	ins, err := p.db.NewInserter(tx, "mpool_messages", "msg", "add_ts")
 	if err != nil {
 		return err
 	}
@@ -80,7 +74,7 @@ func (p *Processor) storeMpoolInclusions(msgs []api.MpoolUpdate) error {
 			continue
 		}
 
-		if _, err := stmt.Exec(
+		if err := ins.Add(
 			msg.Message.Message.Cid().String(),
 			time.Now().Unix(),
 		); err != nil {
@@ -88,11 +82,7 @@ func (p *Processor) storeMpoolInclusions(msgs []api.MpoolUpdate) error {
 		}
 	}
 
-	if err := stmt.Close(); err != nil {
-		return err
-	}
-
-	if _, err := tx.Exec(`insert into mpool_messages select * from mi on conflict do nothing `); err != nil {
+	if err := ins.Flush(); err != nil {
 		return xerrors.Errorf("actor put: %w", err)
 	}
 
@@ -7,6 +7,8 @@ import (
 	"golang.org/x/xerrors"
 
 	"github.com/filecoin-project/go-state-types/big"
+
+	"github.com/EpiK-Protocol/go-epik/cmd/epik-chainwatch/storage"
 )
 
 type powerActorInfo struct {
@@ -24,13 +26,7 @@ type powerActorInfo struct {
 	minerCountAboveMinimumPower int64
 }
 
-func (p *Processor) setupPower() error {
-	tx, err := p.db.Begin()
-	if err != nil {
-		return err
-	}
-
-	if _, err := tx.Exec(`
+const powerSchema = `
 create table if not exists chain_power
 (
 	state_root text not null
@@ -49,11 +45,16 @@ create table if not exists chain_power
 	miner_count int not null,
 	minimum_consensus_miner_count int not null
 );
-`); err != nil {
-		return err
-	}
+`
+
+var powerMigrations = []storage.Migration{{
+	Version:  1,
+	Postgres: powerSchema,
+	SQLite:   powerSchema,
+}}
 
-	return tx.Commit()
+func (p *Processor) setupPower() error {
+	return p.db.Migrate("power", powerMigrations)
 }
 
 func (p *Processor) HandlePowerChanges(ctx context.Context, powerTips ActorTips) error {
@@ -142,17 +143,13 @@ func (p *Processor) storePowerSmoothingEstimates(powerStates []powerActorInfo) e
 		return xerrors.Errorf("begin chain_power tx: %w", err)
 	}
 
-	if _, err := tx.Exec(`create temp table cp (like chain_power) on commit drop`); err != nil {
-		return xerrors.Errorf("prep chain_power: %w", err)
-	}
-
-	stmt, err := tx.Prepare(`copy cp (state_root, total_raw_bytes_power, total_raw_bytes_committed, total_qa_bytes_power, total_qa_bytes_committed, total_pledge_collateral, qa_smoothed_position_estimate, qa_smoothed_velocity_estimate, miner_count, minimum_consensus_miner_count) from stdin;`)
+	ins, err := p.db.NewInserter(tx, "chain_power", "state_root", "total_raw_bytes_power", "total_raw_bytes_committed", "total_qa_bytes_power", "total_qa_bytes_committed", "total_pledge_collateral", "qa_smoothed_position_estimate", "qa_smoothed_velocity_estimate", "miner_count", "minimum_consensus_miner_count")
 	if err != nil {
 		return xerrors.Errorf("prepare tmp chain_power: %w", err)
 	}
 
 	for _, ps := range powerStates {
-		if _, err := stmt.Exec(
+		if err := ins.Add(
 			ps.common.stateroot.String(),
 
 			ps.totalRawBytes.String(),
@@ -171,11 +168,7 @@ func (p *Processor) storePowerSmoothingEstimates(powerStates []powerActorInfo) e
 		}
 	}
 
-	if err := stmt.Close(); err != nil {
-		return xerrors.Errorf("close prepared chain_power: %w", err)
-	}
-
-	if _, err := tx.Exec(`insert into chain_power select * from cp on conflict do nothing`); err != nil {
+	if err := ins.Flush(); err != nil {
 		return xerrors.Errorf("insert chain_power from tmp: %w", err)
 	}
 
@@ -2,7 +2,6 @@ package processor
 
 import (
 	"context"
-	"database/sql"
 	"encoding/json"
 	"math"
 	"sync"
@@ -19,6 +18,8 @@ import (
 
 	"github.com/EpiK-Protocol/go-epik/api"
 	"github.com/EpiK-Protocol/go-epik/chain/types"
+	"github.com/EpiK-Protocol/go-epik/cmd/epik-chainwatch/storage"
+	"github.com/EpiK-Protocol/go-epik/cmd/epik-chainwatch/syncer"
 	cw_util "github.com/EpiK-Protocol/go-epik/cmd/epik-chainwatch/util"
 	"github.com/EpiK-Protocol/go-epik/lib/parmap"
 )
@@ -26,7 +27,7 @@ import (
 var log = logging.Logger("processor")
 
 type Processor struct {
-	db *sql.DB
+	db *storage.DB
 
 	node     api.FullNode
 	ctxStore *cw_util.APIIpldStore
@@ -52,7 +53,7 @@ type actorInfo struct {
 	state string
 }
 
-func NewProcessor(ctx context.Context, db *sql.DB, node api.FullNode, batch int) *Processor {
+func NewProcessor(ctx context.Context, db *storage.DB, node api.FullNode, batch int) *Processor {
 	ctxStore := cw_util.NewAPIIpldStore(ctx, node)
 	return &Processor{
 		db:       db,
@@ -88,6 +89,22 @@ func (p *Processor) setupSchemas() error {
 		return err
 	}
 
+	if err := p.setupExperts(); err != nil {
+		return err
+	}
+
+	if err := p.setupVotes(); err != nil {
+		return err
+	}
+
+	if err := p.setupRetrieval(); err != nil {
+		return err
+	}
+
+	if err := p.setupGovern(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -138,6 +155,7 @@ func (p *Processor) Start(ctx context.Context) {
 					"MinerChanges", len(actorChanges[builtin2.StorageMinerActorCodeID]),
 					"RewardChanges", len(actorChanges[builtin2.RewardActorCodeID]),
 					"AccountChanges", len(actorChanges[builtin2.AccountActorCodeID]),
+					"ExpertChanges", len(actorChanges[builtin2.ExpertActorCodeID]),
 					"nullRounds", len(nullRounds))
 
 				grp := sync.WaitGroup{}
@@ -178,6 +196,42 @@ func (p *Processor) Start(ctx context.Context) {
 					}
 				}()
 
+				grp.Add(1)
+				go func() {
+					defer grp.Done()
+					if err := p.HandleExpertChanges(ctx, actorChanges[builtin2.ExpertActorCodeID]); err != nil {
+						log.Errorf("Failed to handle expert changes: %v", err)
+						return
+					}
+				}()
+
+				grp.Add(1)
+				go func() {
+					defer grp.Done()
+					if err := p.HandleVoteChanges(ctx, actorChanges[builtin2.VoteFundActorCodeID]); err != nil {
+						log.Errorf("Failed to handle vote actor changes: %v", err)
+						return
+					}
+				}()
+
+				grp.Add(1)
+				go func() {
+					defer grp.Done()
+					if err := p.HandleRetrievalChanges(ctx, actorChanges[builtin2.RetrievalFundActorCodeID]); err != nil {
+						log.Errorf("Failed to handle retrieval actor changes: %v", err)
+						return
+					}
+				}()
+
+				grp.Add(1)
+				go func() {
+					defer grp.Done()
+					if err := p.HandleGovernChanges(ctx, actorChanges[builtin2.GovernActorCodeID]); err != nil {
+						log.Errorf("Failed to handle govern actor changes: %v", err)
+						return
+					}
+				}()
+
 				grp.Add(1)
 				go func() {
 					defer grp.Done()
@@ -213,7 +267,7 @@ func (p *Processor) Start(ctx context.Context) {
 }
 
 func (p *Processor) refreshViews() error {
-	if _, err := p.db.Exec(`refresh materialized view state_heights`); err != nil {
+	if err := p.db.RefreshView(syncer.StateHeights); err != nil {
 		return err
 	}
 
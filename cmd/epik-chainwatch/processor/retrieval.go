@@ -0,0 +1,301 @@
+package processor
+
+import (
+	"context"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+
+	"github.com/EpiK-Protocol/go-epik/blockstore"
+	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/retrieval"
+	"github.com/EpiK-Protocol/go-epik/chain/store"
+	"github.com/EpiK-Protocol/go-epik/cmd/epik-chainwatch/storage"
+)
+
+const retrievalSchema = `
+create table if not exists retrieval_pledges
+(
+	pledger_id text not null,
+	target_id text not null,
+	state_root text not null,
+
+	amount text not null,
+
+	constraint retrieval_pledges_pk
+		primary key (pledger_id, target_id, state_root)
+);
+
+create index if not exists retrieval_pledges_target_id_index
+	on retrieval_pledges (target_id);
+`
+
+var retrievalMigrations = []storage.Migration{{
+	Version: 1,
+	Postgres: retrievalSchema + `
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'retrieval_bind_event_type') THEN
+        CREATE TYPE retrieval_bind_event_type AS ENUM
+        (
+            'BIND', 'UNBIND'
+        );
+    END IF;
+END$$;
+
+create table if not exists retrieval_bind_events
+(
+	address_id text not null,
+	miner_id text not null,
+	state_root text not null,
+	event retrieval_bind_event_type not null,
+
+	constraint retrieval_bind_events_pk
+		primary key (address_id, miner_id, state_root)
+);
+
+create index if not exists retrieval_bind_events_miner_id_index
+	on retrieval_bind_events (miner_id);
+
+`,
+	SQLite: retrievalSchema + `
+
+create table if not exists retrieval_bind_events
+(
+	address_id text not null,
+	miner_id text not null,
+	state_root text not null,
+	event text not null check (event in ('BIND', 'UNBIND')),
+
+	constraint retrieval_bind_events_pk
+		primary key (address_id, miner_id, state_root)
+);
+
+create index if not exists retrieval_bind_events_miner_id_index
+	on retrieval_bind_events (miner_id);
+
+`,
+}}
+
+func (p *Processor) setupRetrieval() error {
+	return p.db.Migrate("retrieval", retrievalMigrations)
+}
+
+type RetrievalBindEvent string
+
+const (
+	MinerBound   = "BIND"
+	MinerUnbound = "UNBIND"
+)
+
+type retrievalActorInfo struct {
+	common actorInfo
+
+	prev, cur retrieval.State
+}
+
+func (p *Processor) HandleRetrievalChanges(ctx context.Context, retrievalTips ActorTips) error {
+	retrievalChanges, err := p.processRetrieval(ctx, retrievalTips)
+	if err != nil {
+		return xerrors.Errorf("Failed to process retrieval actor: %w", err)
+	}
+
+	if err := p.persistRetrieval(ctx, retrievalChanges); err != nil {
+		return xerrors.Errorf("Failed to persist retrieval actor: %w", err)
+	}
+
+	return nil
+}
+
+func (p *Processor) processRetrieval(ctx context.Context, retrievalTips ActorTips) ([]retrievalActorInfo, error) {
+	start := time.Now()
+	defer func() {
+		log.Debugw("Processed Retrieval Actor", "duration", time.Since(start).String())
+	}()
+
+	stor := store.ActorStore(ctx, blockstore.NewAPIBlockstore(p.node))
+
+	var out []retrievalActorInfo
+	for _, retrievals := range retrievalTips {
+		for _, act := range retrievals {
+			var ri retrievalActorInfo
+			ri.common = act
+
+			cur, err := retrieval.Load(stor, &act.act)
+			if err != nil {
+				return nil, xerrors.Errorf("load retrieval state (@ %s): %w", act.stateroot, err)
+			}
+			ri.cur = cur
+
+			prevActor, err := p.node.StateGetActor(ctx, retrieval.Address, act.parentTsKey)
+			if err != nil {
+				return nil, xerrors.Errorf("get retrieval actor (@ %s): %w", act.parentTsKey, err)
+			}
+			if ri.prev, err = retrieval.Load(stor, prevActor); err != nil {
+				return nil, xerrors.Errorf("load retrieval state (@ %s): %w", act.parentTsKey, err)
+			}
+
+			out = append(out, ri)
+		}
+	}
+	return out, nil
+}
+
+func (p *Processor) persistRetrieval(ctx context.Context, retrievals []retrievalActorInfo) error {
+	start := time.Now()
+	defer func() {
+		log.Debugw("Persisted Retrieval Actor", "duration", time.Since(start).String())
+	}()
+
+	grp, _ := errgroup.WithContext(ctx)
+
+	grp.Go(func() error {
+		if err := p.storeRetrievalPledges(retrievals); err != nil {
+			return xerrors.Errorf("Failed to store retrieval pledges: %w", err)
+		}
+		return nil
+	})
+
+	grp.Go(func() error {
+		if err := p.storeRetrievalBindEvents(retrievals); err != nil {
+			return xerrors.Errorf("Failed to store retrieval bind events: %w", err)
+		}
+		return nil
+	})
+
+	return grp.Wait()
+}
+
+func (p *Processor) storeRetrievalPledges(retrievals []retrievalActorInfo) error {
+	tx, err := p.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	ins, err := p.db.NewInserter(tx, "retrieval_pledges", "pledger_id", "target_id", "state_root", "amount")
+	if err != nil {
+		return err
+	}
+
+	for _, r := range retrievals {
+		changes, err := retrieval.DiffPledges(r.prev, r.cur)
+		if err != nil {
+			return xerrors.Errorf("diff retrieval pledges (@ %s): %w", r.common.stateroot, err)
+		}
+
+		// record the new amount pledged to each target whose amount changed,
+		// zero when the pledge was withdrawn.
+		record := func(pledger address.Address, from, to map[address.Address]abi.TokenAmount) error {
+			for target, amount := range to {
+				if prev, ok := from[target]; ok && prev.Equals(amount) {
+					continue
+				}
+				if err := ins.Add(pledger.String(), target.String(), r.common.stateroot.String(), amount.String()); err != nil {
+					return err
+				}
+			}
+			for target := range from {
+				if _, ok := to[target]; ok {
+					continue
+				}
+				if err := ins.Add(pledger.String(), target.String(), r.common.stateroot.String(), big.Zero().String()); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+		for _, added := range changes.Added {
+			if err := record(added.Pledger, nil, added.Targets); err != nil {
+				return err
+			}
+		}
+		for _, modified := range changes.Modified {
+			if err := record(modified.Pledger, modified.From, modified.To); err != nil {
+				return err
+			}
+		}
+		for _, removed := range changes.Removed {
+			if err := record(removed.Pledger, removed.Targets, nil); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := ins.Flush(); err != nil {
+		return xerrors.Errorf("insert retrieval_pledges: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+func (p *Processor) storeRetrievalBindEvents(retrievals []retrievalActorInfo) error {
+	tx, err := p.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	ins, err := p.db.NewInserter(tx, "retrieval_bind_events", "address_id", "miner_id", "state_root", "event")
+	if err != nil {
+		return err
+	}
+
+	for _, r := range retrievals {
+		prev, err := retrievalBinds(r.prev)
+		if err != nil {
+			return xerrors.Errorf("list retrieval binds (@ %s): %w", r.common.parentTsKey, err)
+		}
+		cur, err := retrievalBinds(r.cur)
+		if err != nil {
+			return xerrors.Errorf("list retrieval binds (@ %s): %w", r.common.stateroot, err)
+		}
+
+		for addr, miners := range cur {
+			for m := range miners {
+				if _, ok := prev[addr][m]; ok {
+					continue
+				}
+				if err := ins.Add(addr.String(), m.String(), r.common.stateroot.String(), MinerBound); err != nil {
+					return err
+				}
+			}
+		}
+		for addr, miners := range prev {
+			for m := range miners {
+				if _, ok := cur[addr][m]; ok {
+					continue
+				}
+				if err := ins.Add(addr.String(), m.String(), r.common.stateroot.String(), MinerUnbound); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if err := ins.Flush(); err != nil {
+		return xerrors.Errorf("insert retrieval_bind_events: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// retrievalBinds returns the miners bound to each address.
+func retrievalBinds(st retrieval.State) (map[address.Address]map[address.Address]struct{}, error) {
+	out := map[address.Address]map[address.Address]struct{}{}
+	err := st.ForEachState(func(addr address.Address, state *retrieval.RetrievalState) error {
+		if len(state.BindMiners) == 0 {
+			return nil
+		}
+		miners := make(map[address.Address]struct{}, len(state.BindMiners))
+		for _, m := range state.BindMiners {
+			miners[m] = struct{}{}
+		}
+		out[addr] = miners
+		return nil
+	})
+	return out, err
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/reward"
 	"github.com/EpiK-Protocol/go-epik/chain/types"
 
+	"github.com/EpiK-Protocol/go-epik/cmd/epik-chainwatch/storage"
 	cw_util "github.com/EpiK-Protocol/go-epik/cmd/epik-chainwatch/util"
 )
 
@@ -75,13 +76,7 @@ func (rw *rewardActorInfo) set(s reward.State) (err error) {
 	return nil
 }
 
-func (p *Processor) setupRewards() error {
-	tx, err := p.db.Begin()
-	if err != nil {
-		return err
-	}
-
-	if _, err := tx.Exec(`
+const rewardsSchema = `
 /* captures chain-specific power state for any given stateroot */
 create table if not exists chain_reward
 (
@@ -100,11 +95,16 @@ create table if not exists chain_reward
 
 	total_mined_reward text not null
 );
-`); err != nil {
-		return err
-	}
+`
+
+var rewardsMigrations = []storage.Migration{{
+	Version:  1,
+	Postgres: rewardsSchema,
+	SQLite:   rewardsSchema,
+}}
 
-	return tx.Commit()
+func (p *Processor) setupRewards() error {
+	return p.db.Migrate("rewards", rewardsMigrations)
 }
 
 func (p *Processor) HandleRewardChanges(ctx context.Context, rewardTips ActorTips, nullRounds []types.TipSetKey) error {
@@ -190,17 +190,13 @@ func (p *Processor) persistRewardActors(ctx context.Context, rewards []rewardAct
 		return xerrors.Errorf("begin chain_reward tx: %w", err)
 	}
 
-	if _, err := tx.Exec(`create temp table cr (like chain_reward excluding constraints) on commit drop`); err != nil {
-		return xerrors.Errorf("prep chain_reward temp: %w", err)
-	}
-
-	stmt, err := tx.Prepare(`copy cr ( state_root, cum_sum_baseline, cum_sum_realized, effective_network_time, effective_baseline_power, new_baseline_power, new_reward, new_reward_smoothed_position_estimate, new_reward_smoothed_velocity_estimate, total_mined_reward) from STDIN`)
+	ins, err := p.db.NewInserter(tx, "chain_reward", "state_root", "cum_sum_baseline", "cum_sum_realized", "effective_network_time", "effective_baseline_power", "new_baseline_power", "new_reward", "new_reward_smoothed_position_estimate", "new_reward_smoothed_velocity_estimate", "total_mined_reward")
 	if err != nil {
 		return xerrors.Errorf("prepare tmp chain_reward: %w", err)
 	}
 
 	for _, rewardState := range rewards {
-		if _, err := stmt.Exec(
+		if err := ins.Add(
 			rewardState.common.stateroot.String(),
 			/* rewardState.cumSumBaselinePower.String(),
 			rewardState.cumSumRealizedPower.String(),
@@ -216,11 +212,7 @@ func (p *Processor) persistRewardActors(ctx context.Context, rewards []rewardAct
 		}
 	}
 
-	if err := stmt.Close(); err != nil {
-		return xerrors.Errorf("close prepared chain_reward: %w", err)
-	}
-
-	if _, err := tx.Exec(`insert into chain_reward select * from cr on conflict do nothing`); err != nil {
+	if err := ins.Flush(); err != nil {
 		return xerrors.Errorf("insert chain_reward from tmp: %w", err)
 	}
 
@@ -0,0 +1,315 @@
+package processor
+
+import (
+	"context"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/big"
+
+	"github.com/EpiK-Protocol/go-epik/blockstore"
+	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/vote"
+	"github.com/EpiK-Protocol/go-epik/chain/store"
+	"github.com/EpiK-Protocol/go-epik/cmd/epik-chainwatch/storage"
+)
+
+const votesSchema = `
+create table if not exists vote_candidates
+(
+	candidate_id text not null,
+	state_root text not null,
+
+	votes text not null,
+	blocked bool not null,
+
+	constraint vote_candidates_pk
+		primary key (candidate_id, state_root)
+);
+`
+
+var votesMigrations = []storage.Migration{{
+	Version: 1,
+	Postgres: votesSchema + `
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'vote_event_type') THEN
+        CREATE TYPE vote_event_type AS ENUM
+        (
+            'VOTE', 'RESCIND'
+        );
+    END IF;
+END$$;
+
+create table if not exists vote_events
+(
+	voter_id text not null,
+	candidate_id text not null,
+	state_root text not null,
+	event vote_event_type not null,
+	amount text not null,
+
+	constraint vote_events_pk
+		primary key (voter_id, candidate_id, state_root)
+);
+
+create index if not exists vote_events_candidate_id_index
+	on vote_events (candidate_id);
+
+`,
+	SQLite: votesSchema + `
+
+create table if not exists vote_events
+(
+	voter_id text not null,
+	candidate_id text not null,
+	state_root text not null,
+	event text not null check (event in ('VOTE', 'RESCIND')),
+	amount text not null,
+
+	constraint vote_events_pk
+		primary key (voter_id, candidate_id, state_root)
+);
+
+create index if not exists vote_events_candidate_id_index
+	on vote_events (candidate_id);
+
+`,
+}}
+
+func (p *Processor) setupVotes() error {
+	return p.db.Migrate("votes", votesMigrations)
+}
+
+type VoteEvent string
+
+const (
+	VoteAdded     = "VOTE"
+	VoteRescinded = "RESCIND"
+)
+
+type voteActorInfo struct {
+	common actorInfo
+
+	prev, cur vote.State
+}
+
+type voterEvent struct {
+	voter     address.Address
+	candidate string
+	stateroot string
+	event     VoteEvent
+	amount    big.Int
+}
+
+func (p *Processor) HandleVoteChanges(ctx context.Context, voteTips ActorTips) error {
+	voteChanges, err := p.processVotes(ctx, voteTips)
+	if err != nil {
+		return xerrors.Errorf("Failed to process vote actor: %w", err)
+	}
+
+	if err := p.persistVotes(ctx, voteChanges); err != nil {
+		return xerrors.Errorf("Failed to persist vote actor: %w", err)
+	}
+
+	return nil
+}
+
+func (p *Processor) processVotes(ctx context.Context, voteTips ActorTips) ([]voteActorInfo, error) {
+	start := time.Now()
+	defer func() {
+		log.Debugw("Processed Vote Actor", "duration", time.Since(start).String())
+	}()
+
+	stor := store.ActorStore(ctx, blockstore.NewAPIBlockstore(p.node))
+
+	var out []voteActorInfo
+	for _, votes := range voteTips {
+		for _, act := range votes {
+			var vi voteActorInfo
+			vi.common = act
+
+			cur, err := vote.Load(stor, &act.act)
+			if err != nil {
+				return nil, xerrors.Errorf("load vote state (@ %s): %w", act.stateroot, err)
+			}
+			vi.cur = cur
+
+			prevActor, err := p.node.StateGetActor(ctx, vote.Address, act.parentTsKey)
+			if err != nil {
+				return nil, xerrors.Errorf("get vote actor (@ %s): %w", act.parentTsKey, err)
+			}
+			if vi.prev, err = vote.Load(stor, prevActor); err != nil {
+				return nil, xerrors.Errorf("load vote state (@ %s): %w", act.parentTsKey, err)
+			}
+
+			out = append(out, vi)
+		}
+	}
+	return out, nil
+}
+
+func (p *Processor) persistVotes(ctx context.Context, votes []voteActorInfo) error {
+	start := time.Now()
+	defer func() {
+		log.Debugw("Persisted Vote Actor", "duration", time.Since(start).String())
+	}()
+
+	grp, _ := errgroup.WithContext(ctx)
+
+	grp.Go(func() error {
+		if err := p.storeVoteCandidates(votes); err != nil {
+			return xerrors.Errorf("Failed to store vote candidates: %w", err)
+		}
+		return nil
+	})
+
+	grp.Go(func() error {
+		if err := p.storeVoteEvents(votes); err != nil {
+			return xerrors.Errorf("Failed to store vote events: %w", err)
+		}
+		return nil
+	})
+
+	return grp.Wait()
+}
+
+func (p *Processor) storeVoteCandidates(votes []voteActorInfo) error {
+	tx, err := p.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	ins, err := p.db.NewInserter(tx, "vote_candidates", "candidate_id", "state_root", "votes", "blocked")
+	if err != nil {
+		return err
+	}
+
+	for _, v := range votes {
+		changes, err := vote.DiffCandidates(v.prev, v.cur)
+		if err != nil {
+			return xerrors.Errorf("diff vote candidates (@ %s): %w", v.common.stateroot, err)
+		}
+
+		for _, added := range changes.Added {
+			if err := ins.Add(added.Candidate.String(), v.common.stateroot.String(), added.Info.Votes.String(), added.Info.Blocked); err != nil {
+				return err
+			}
+		}
+		for _, modified := range changes.Modified {
+			if err := ins.Add(modified.Candidate.String(), v.common.stateroot.String(), modified.To.Votes.String(), modified.To.Blocked); err != nil {
+				return err
+			}
+		}
+		for _, removed := range changes.Removed {
+			if err := ins.Add(removed.Candidate.String(), v.common.stateroot.String(), big.Zero().String(), removed.Info.Blocked); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := ins.Flush(); err != nil {
+		return xerrors.Errorf("insert vote_candidates: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+func (p *Processor) storeVoteEvents(votes []voteActorInfo) error {
+	tx, err := p.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	ins, err := p.db.NewInserter(tx, "vote_events", "voter_id", "candidate_id", "state_root", "event", "amount")
+	if err != nil {
+		return err
+	}
+
+	for _, v := range votes {
+		events, err := p.diffVoters(v)
+		if err != nil {
+			return xerrors.Errorf("diff voters (@ %s): %w", v.common.stateroot, err)
+		}
+
+		for _, e := range events {
+			if err := ins.Add(e.voter.String(), e.candidate, e.stateroot, e.event, e.amount.String()); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := ins.Flush(); err != nil {
+		return xerrors.Errorf("insert vote_events: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// diffVoters turns the changes of the votes each voter gives to each candidate
+// into vote and rescind events.
+func (p *Processor) diffVoters(v voteActorInfo) ([]voterEvent, error) {
+	changes, err := vote.DiffVoters(v.prev, v.cur)
+	if err != nil {
+		return nil, err
+	}
+
+	// the epoch and balance only affect the voter rewards, which are not recorded
+	candidates := func(st vote.State, voter address.Address) (map[string]big.Int, error) {
+		info, err := st.VoterInfo(voter, v.common.height, v.common.act.Balance)
+		if err != nil {
+			return nil, xerrors.Errorf("get voter %s info: %w", voter, err)
+		}
+		return info.Candidates, nil
+	}
+
+	var out []voterEvent
+	record := func(voter address.Address, from, to map[string]big.Int) {
+		for candidate, votes := range to {
+			prev, ok := from[candidate]
+			if !ok {
+				prev = big.Zero()
+			}
+			if delta := big.Sub(votes, prev); delta.GreaterThan(big.Zero()) {
+				out = append(out, voterEvent{voter, candidate, v.common.stateroot.String(), VoteAdded, delta})
+			} else if delta.LessThan(big.Zero()) {
+				out = append(out, voterEvent{voter, candidate, v.common.stateroot.String(), VoteRescinded, delta.Neg()})
+			}
+		}
+		for candidate, votes := range from {
+			if _, ok := to[candidate]; !ok && !votes.IsZero() {
+				out = append(out, voterEvent{voter, candidate, v.common.stateroot.String(), VoteRescinded, votes})
+			}
+		}
+	}
+
+	for _, voter := range changes.Added {
+		to, err := candidates(v.cur, voter)
+		if err != nil {
+			return nil, err
+		}
+		record(voter, nil, to)
+	}
+	for _, voter := range changes.Modified {
+		from, err := candidates(v.prev, voter)
+		if err != nil {
+			return nil, err
+		}
+		to, err := candidates(v.cur, voter)
+		if err != nil {
+			return nil, err
+		}
+		record(voter, from, to)
+	}
+	for _, voter := range changes.Removed {
+		from, err := candidates(v.prev, voter)
+		if err != nil {
+			return nil, err
+		}
+		record(voter, from, nil)
+	}
+
+	return out, nil
+}
@@ -1,24 +1,21 @@
 package main
 
 import (
-	"database/sql"
 	"fmt"
 	"net/http"
 	_ "net/http/pprof"
 	"os"
 	"strings"
 
-	_ "github.com/lib/pq"
-
 	"github.com/filecoin-project/go-jsonrpc"
 	logging "github.com/ipfs/go-log/v2"
 	"github.com/urfave/cli/v2"
-	"golang.org/x/xerrors"
 
 	"github.com/EpiK-Protocol/go-epik/api"
 	lcli "github.com/EpiK-Protocol/go-epik/cli"
 	"github.com/EpiK-Protocol/go-epik/cmd/epik-chainwatch/processor"
 	"github.com/EpiK-Protocol/go-epik/cmd/epik-chainwatch/scheduler"
+	"github.com/EpiK-Protocol/go-epik/cmd/epik-chainwatch/storage"
 	"github.com/EpiK-Protocol/go-epik/cmd/epik-chainwatch/syncer"
 	"github.com/EpiK-Protocol/go-epik/cmd/epik-chainwatch/util"
 )
@@ -75,7 +72,7 @@ var runCmd = &cli.Command{
 
 		maxBatch := cctx.Int("max-batch")
 
-		db, err := sql.Open("postgres", cctx.String("db"))
+		db, err := storage.Open(storage.Dialect(cctx.String("db-driver")), cctx.String("db"))
 		if err != nil {
 			return err
 		}
@@ -85,10 +82,9 @@ var runCmd = &cli.Command{
 			}
 		}()
 
-		if err := db.Ping(); err != nil {
-			return xerrors.Errorf("Database failed to respond to ping (is it online?): %w", err)
+		if db.Dialect == storage.Postgres {
+			db.SetMaxOpenConns(1350)
 		}
-		db.SetMaxOpenConns(1350)
 
 		sync := syncer.NewSyncer(db, api, 1400)
 		sync.Start(ctx)
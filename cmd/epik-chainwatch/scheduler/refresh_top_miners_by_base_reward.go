@@ -2,24 +2,15 @@ package scheduler
 
 import (
 	"context"
-	"database/sql"
 
 	"golang.org/x/xerrors"
-)
 
-func setupTopMinerByBaseRewardSchema(ctx context.Context, db *sql.DB) error {
-	select {
-	case <-ctx.Done():
-		return nil
-	default:
-	}
+	"github.com/EpiK-Protocol/go-epik/cmd/epik-chainwatch/storage"
+)
 
-	tx, err := db.Begin()
-	if err != nil {
-		return err
-	}
-	if _, err := tx.Exec(`
-		create materialized view if not exists top_miners_by_base_reward as
+var topMinersByBaseReward = storage.View{
+	Name: "top_miners_by_base_reward",
+	Query: `
 			with total_rewards_by_miner as (
 				select
 					b.miner,
@@ -28,49 +19,63 @@ func setupTopMinerByBaseRewardSchema(ctx context.Context, db *sql.DB) error {
 				inner join chain_reward cr on b.parentstateroot = cr.state_root
 				group by 1
 			) select
-				rank() over (order by total_reward desc),
+				rank() over (order by total_reward desc) as rank,
 				miner,
 				total_reward
 			from total_rewards_by_miner
-			group by 2, 3;
-
-		create index if not exists top_miners_by_base_reward_miner_index
-			on top_miners_by_base_reward (miner);
+			group by 2, 3`,
+}
 
-		create materialized view if not exists top_miners_by_base_reward_max_height as
+var topMinersByBaseRewardMaxHeight = storage.View{
+	Name: "top_miners_by_base_reward_max_height",
+	Query: `
 			select
-				b."timestamp"as current_timestamp,
+				b."timestamp" as "current_timestamp",
 				max(b.height) as current_height
 			from blocks b
 			join chain_reward cr on b.parentstateroot = cr.state_root
 			where cr.new_reward is not null
 			group by 1
 			order by 1 desc
-			limit 1;
-	`); err != nil {
-		return xerrors.Errorf("create top_miners_by_base_reward views: %w", err)
+			limit 1`,
+}
+
+const topMinersByBaseRewardIndexes = `
+		create index if not exists top_miners_by_base_reward_miner_index
+			on top_miners_by_base_reward (miner);
+`
+
+var topMinersByBaseRewardMigrations = []storage.Migration{{
+	Version:  1,
+	Postgres: topMinersByBaseReward.Create(storage.Postgres) + topMinersByBaseRewardIndexes + topMinersByBaseRewardMaxHeight.Create(storage.Postgres),
+	SQLite:   topMinersByBaseReward.Create(storage.SQLite) + topMinersByBaseRewardIndexes + topMinersByBaseRewardMaxHeight.Create(storage.SQLite),
+}}
+
+func setupTopMinerByBaseRewardSchema(ctx context.Context, db *storage.DB) error {
+	select {
+	case <-ctx.Done():
+		return nil
+	default:
 	}
 
-	if err := tx.Commit(); err != nil {
-		return xerrors.Errorf("committing top_miners_by_base_reward views; %w", err)
+	if err := db.Migrate("top_miners_by_base_reward", topMinersByBaseRewardMigrations); err != nil {
+		return xerrors.Errorf("create top_miners_by_base_reward views: %w", err)
 	}
 	return nil
 }
 
-func refreshTopMinerByBaseReward(ctx context.Context, db *sql.DB) error {
+func refreshTopMinerByBaseReward(ctx context.Context, db *storage.DB) error {
 	select {
 	case <-ctx.Done():
 		return nil
 	default:
 	}
 
-	_, err := db.Exec("refresh materialized view top_miners_by_base_reward;")
-	if err != nil {
+	if err := db.RefreshView(topMinersByBaseReward); err != nil {
 		return xerrors.Errorf("refresh top_miners_by_base_reward: %w", err)
 	}
 
-	_, err = db.Exec("refresh materialized view top_miners_by_base_reward_max_height;")
-	if err != nil {
+	if err := db.RefreshView(topMinersByBaseRewardMaxHeight); err != nil {
 		return xerrors.Errorf("refresh top_miners_by_base_reward_max_height: %w", err)
 	}
 
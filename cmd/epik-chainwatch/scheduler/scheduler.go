@@ -2,12 +2,13 @@ package scheduler
 
 import (
 	"context"
-	"database/sql"
 	"time"
 
 	logging "github.com/ipfs/go-log/v2"
 
 	"golang.org/x/xerrors"
+
+	"github.com/EpiK-Protocol/go-epik/cmd/epik-chainwatch/storage"
 )
 
 var log = logging.Logger("scheduler")
@@ -15,11 +16,11 @@ var log = logging.Logger("scheduler")
 // Scheduler manages the execution of jobs triggered
 // by tickers. Not externally configurable at runtime.
 type Scheduler struct {
-	db *sql.DB
+	db *storage.DB
 }
 
 // PrepareScheduler returns a ready-to-run Scheduler
-func PrepareScheduler(db *sql.DB) *Scheduler {
+func PrepareScheduler(db *storage.DB) *Scheduler {
 	return &Scheduler{db}
 }
 
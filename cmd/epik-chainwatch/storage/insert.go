@@ -0,0 +1,125 @@
+package storage
+
+import (
+	"database/sql"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/lib/pq"
+	"golang.org/x/xerrors"
+)
+
+// tmpTables numbers the temporary tables rows are copied to on Postgres, so
+// that a transaction can insert into a table several times.
+var tmpTables int64
+
+// Inserter inserts rows into a table in a transaction. By default, rows
+// conflicting with existing ones are dropped.
+//
+// On Postgres the rows are copied to a temporary table as they are added, on
+// SQLite they are kept in memory. Either way they are only inserted into the
+// table on Flush.
+type Inserter struct {
+	dialect    Dialect
+	tx         *sql.Tx
+	table      string
+	columns    []string
+	onConflict string
+
+	// Postgres
+	tmp  string
+	copy *sql.Stmt
+
+	// SQLite
+	rows [][]interface{}
+}
+
+// NewInserter returns an inserter of the given columns of table. Column names
+// are quoted, so they must be written in lower case.
+func (db *DB) NewInserter(tx *sql.Tx, table string, columns ...string) (*Inserter, error) {
+	ins := &Inserter{
+		dialect:    db.Dialect,
+		tx:         tx,
+		table:      table,
+		columns:    columns,
+		onConflict: "do nothing",
+	}
+
+	if db.Dialect == Postgres {
+		ins.tmp = table + "_tmp_" + strconv.FormatInt(atomic.AddInt64(&tmpTables, 1), 10)
+		if _, err := tx.Exec(`create temp table ` + ins.tmp + ` (like ` + table + ` excluding constraints) on commit drop`); err != nil {
+			return nil, xerrors.Errorf("prep temp %s: %w", table, err)
+		}
+
+		var err error
+		if ins.copy, err = tx.Prepare(pq.CopyIn(ins.tmp, columns...)); err != nil {
+			return nil, xerrors.Errorf("prepare copy %s: %w", table, err)
+		}
+	}
+
+	return ins, nil
+}
+
+// OnConflict replaces the default "do nothing" action taken on rows
+// conflicting with existing ones, e.g. "(id) do update set address = excluded.address".
+func (ins *Inserter) OnConflict(action string) *Inserter {
+	ins.onConflict = action
+	return ins
+}
+
+// Add adds a row, with a value for each column.
+func (ins *Inserter) Add(values ...interface{}) error {
+	if len(values) != len(ins.columns) {
+		return xerrors.Errorf("%s row has %d values for %d columns", ins.table, len(values), len(ins.columns))
+	}
+
+	if ins.dialect == Postgres {
+		_, err := ins.copy.Exec(values...)
+		return err
+	}
+
+	ins.rows = append(ins.rows, values)
+	return nil
+}
+
+// Flush inserts the rows added into the table.
+func (ins *Inserter) Flush() error {
+	columns := make([]string, len(ins.columns))
+	for i, c := range ins.columns {
+		columns[i] = `"` + c + `"`
+	}
+	cols := strings.Join(columns, ", ")
+
+	if ins.dialect == Postgres {
+		if err := ins.copy.Close(); err != nil {
+			return xerrors.Errorf("close copy %s: %w", ins.table, err)
+		}
+		if _, err := ins.tx.Exec(`insert into ` + ins.table + ` (` + cols + `) select ` + cols + ` from ` + ins.tmp + ` on conflict ` + ins.onConflict); err != nil {
+			return xerrors.Errorf("insert %s: %w", ins.table, err)
+		}
+		return nil
+	}
+
+	if len(ins.rows) == 0 {
+		return nil
+	}
+
+	params := make([]string, len(ins.columns))
+	for i := range params {
+		params[i] = "?"
+	}
+	stmt, err := ins.tx.Prepare(`insert into ` + ins.table + ` (` + cols + `) values (` + strings.Join(params, ", ") + `) on conflict ` + ins.onConflict)
+	if err != nil {
+		return xerrors.Errorf("prepare insert %s: %w", ins.table, err)
+	}
+	for _, row := range ins.rows {
+		if _, err := stmt.Exec(row...); err != nil {
+			_ = stmt.Close()
+			return xerrors.Errorf("insert %s: %w", ins.table, err)
+		}
+	}
+	ins.rows = nil
+
+	return stmt.Close()
+}
@@ -0,0 +1,75 @@
+package storage
+
+import (
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// Migration is a change of the schema of a chainwatch component, written in
+// each dialect.
+type Migration struct {
+	// Version orders the migrations of a component, starting at 1.
+	Version int
+
+	Postgres string
+	SQLite   string
+}
+
+func (m Migration) statements(d Dialect) string {
+	if d == SQLite {
+		return m.SQLite
+	}
+	return m.Postgres
+}
+
+// Migrate applies the migrations of the component the database hasn't
+// applied yet, in order, each in its own transaction.
+func (db *DB) Migrate(component string, migrations []Migration) error {
+	if _, err := db.Exec(`
+create table if not exists schema_migrations
+(
+	component text not null,
+	version int not null,
+	applied_at bigint not null,
+
+	constraint schema_migrations_pk
+		primary key (component, version)
+);
+`); err != nil {
+		return xerrors.Errorf("create schema_migrations: %w", err)
+	}
+
+	var current int
+	if err := db.QueryRow(`select coalesce(max(version), 0) from schema_migrations where component = $1`, component).Scan(&current); err != nil {
+		return xerrors.Errorf("get %s schema version: %w", component, err)
+	}
+
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			return xerrors.Errorf("%s migration %d has version %d", component, i+1, m.Version)
+		}
+		if m.Version <= current {
+			continue
+		}
+
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(m.statements(db.Dialect)); err != nil {
+			_ = tx.Rollback()
+			return xerrors.Errorf("apply %s migration %d: %w", component, m.Version, err)
+		}
+		if _, err := tx.Exec(`insert into schema_migrations (component, version, applied_at) values ($1, $2, $3)`, component, m.Version, time.Now().Unix()); err != nil {
+			_ = tx.Rollback()
+			return xerrors.Errorf("record %s migration %d: %w", component, m.Version, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return xerrors.Errorf("commit %s migration %d: %w", component, m.Version, err)
+		}
+		log.Infow("Applied schema migration", "component", component, "version", m.Version)
+	}
+
+	return nil
+}
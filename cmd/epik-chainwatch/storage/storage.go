@@ -0,0 +1,103 @@
+// Package storage is the database layer of chainwatch. It hides the
+// differences between the supported backends, Postgres and an embedded SQLite
+// database, from the syncer, processor and scheduler.
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"strconv"
+	"time"
+
+	logging "github.com/ipfs/go-log/v2"
+	_ "github.com/lib/pq"
+	"golang.org/x/xerrors"
+	"modernc.org/sqlite"
+)
+
+var log = logging.Logger("storage")
+
+// Dialect is the SQL dialect of a database backend.
+type Dialect string
+
+const (
+	Postgres Dialect = "postgres"
+	SQLite   Dialect = "sqlite"
+)
+
+// sqliteBusyTimeout is how long a SQLite connection waits for the database
+// lock held by another connection, before failing with SQLITE_BUSY.
+const sqliteBusyTimeout = 5 * time.Minute
+
+// DB is a chainwatch database.
+type DB struct {
+	*sql.DB
+
+	Dialect Dialect
+}
+
+// Open opens the database. dsn is a connection string for Postgres, and the
+// path of the database file for SQLite.
+func Open(dialect Dialect, dsn string) (*DB, error) {
+	var db *sql.DB
+	switch dialect {
+	case Postgres:
+		var err error
+		if db, err = sql.Open("postgres", dsn); err != nil {
+			return nil, err
+		}
+	case SQLite:
+		if dsn == "" || dsn == ":memory:" {
+			// every connection would get its own database
+			return nil, xerrors.Errorf("sqlite requires the path of the database file")
+		}
+		db = sql.OpenDB(&sqliteConnector{dsn: dsn})
+	default:
+		return nil, xerrors.Errorf("unknown database dialect %q", dialect)
+	}
+
+	if err := db.Ping(); err != nil {
+		_ = db.Close()
+		return nil, xerrors.Errorf("Database failed to respond to ping (is it online?): %w", err)
+	}
+
+	if dialect == SQLite {
+		// readers don't block the writer, and the setting is kept by the file
+		if _, err := db.Exec(`pragma journal_mode = wal`); err != nil {
+			_ = db.Close()
+			return nil, xerrors.Errorf("enable write-ahead log: %w", err)
+		}
+	}
+
+	return &DB{DB: db, Dialect: dialect}, nil
+}
+
+// sqliteConnector opens SQLite connections which wait for the database lock.
+// The busy timeout has to be set on every connection, which the driver doesn't
+// support in its data source name.
+type sqliteConnector struct {
+	dsn string
+}
+
+func (c *sqliteConnector) Connect(context.Context) (driver.Conn, error) {
+	conn, err := c.Driver().Open(c.dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	execer, ok := conn.(driver.Execer) //nolint:staticcheck
+	if !ok {
+		_ = conn.Close()
+		return nil, xerrors.Errorf("sqlite connection doesn't support exec")
+	}
+	if _, err := execer.Exec("pragma busy_timeout = "+strconv.FormatInt(sqliteBusyTimeout.Milliseconds(), 10), nil); err != nil {
+		_ = conn.Close()
+		return nil, xerrors.Errorf("set busy timeout: %w", err)
+	}
+	return conn, nil
+}
+
+func (c *sqliteConnector) Driver() driver.Driver {
+	return &sqlite.Driver{}
+}
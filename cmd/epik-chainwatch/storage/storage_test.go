@@ -0,0 +1,137 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func openSQLite(t *testing.T) *DB {
+	db, err := Open(SQLite, filepath.Join(t.TempDir(), "chainwatch.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, db.Close())
+	})
+	return db
+}
+
+func TestMigrate(t *testing.T) {
+	db := openSQLite(t)
+
+	migrations := []Migration{{
+		Version:  1,
+		Postgres: `create table things (id text not null constraint things_pk primary key);`,
+		SQLite:   `create table things (id text not null constraint things_pk primary key);`,
+	}}
+	require.NoError(t, db.Migrate("things", migrations))
+	// applied migrations are skipped, the table would already exist otherwise
+	require.NoError(t, db.Migrate("things", migrations))
+
+	migrations = append(migrations, Migration{
+		Version:  2,
+		Postgres: `alter table things add column size bigint;`,
+		SQLite:   `alter table things add column size bigint;`,
+	})
+	require.NoError(t, db.Migrate("things", migrations))
+
+	var version int
+	require.NoError(t, db.QueryRow(`select max(version) from schema_migrations where component = 'things'`).Scan(&version))
+	require.Equal(t, 2, version)
+
+	// a failed migration is rolled back and not recorded
+	require.Error(t, db.Migrate("things", append(migrations, Migration{Version: 3, SQLite: `create table more (id text); bad sql;`})))
+	require.NoError(t, db.QueryRow(`select max(version) from schema_migrations where component = 'things'`).Scan(&version))
+	require.Equal(t, 2, version)
+	_, err := db.Exec(`select * from more`)
+	require.Error(t, err)
+
+	require.Error(t, db.Migrate("gaps", []Migration{{Version: 2}}))
+}
+
+func TestInserter(t *testing.T) {
+	db := openSQLite(t)
+
+	_, err := db.Exec(`create table id_address_map (id text not null primary key, address text not null, "from" text)`)
+	require.NoError(t, err)
+
+	insert := func(onConflict string, rows ...[2]string) {
+		tx, err := db.Begin()
+		require.NoError(t, err)
+		ins, err := db.NewInserter(tx, "id_address_map", "id", "address")
+		require.NoError(t, err)
+		if onConflict != "" {
+			ins.OnConflict(onConflict)
+		}
+		for _, r := range rows {
+			require.NoError(t, ins.Add(r[0], r[1]))
+		}
+		require.NoError(t, ins.Flush())
+		require.NoError(t, tx.Commit())
+	}
+	addresses := func() map[string]string {
+		rows, err := db.Query(`select id, address from id_address_map`)
+		require.NoError(t, err)
+		defer rows.Close() //nolint:errcheck
+		out := map[string]string{}
+		for rows.Next() {
+			var id, addr string
+			require.NoError(t, rows.Scan(&id, &addr))
+			out[id] = addr
+		}
+		require.NoError(t, rows.Err())
+		return out
+	}
+
+	insert("", [2]string{"f01", "a"}, [2]string{"f02", "b"})
+	insert("", [2]string{"f01", "c"}, [2]string{"f03", "d"})
+	require.Equal(t, map[string]string{"f01": "a", "f02": "b", "f03": "d"}, addresses())
+
+	insert("(id) do update set address = excluded.address", [2]string{"f01", "c"})
+	require.Equal(t, map[string]string{"f01": "c", "f02": "b", "f03": "d"}, addresses())
+
+	tx, err := db.Begin()
+	require.NoError(t, err)
+	ins, err := db.NewInserter(tx, "id_address_map", "id", "address", "from")
+	require.NoError(t, err)
+	require.Error(t, ins.Add("f04"))
+	require.NoError(t, ins.Add("f04", "e", "f01"))
+	require.NoError(t, ins.Flush())
+	require.NoError(t, tx.Commit())
+
+	var from string
+	require.NoError(t, db.QueryRow(`select "from" from id_address_map where id = $1`, "f04").Scan(&from))
+	require.Equal(t, "f01", from)
+}
+
+func TestRefreshView(t *testing.T) {
+	db := openSQLite(t)
+
+	heights := View{
+		Name:  "state_heights",
+		Query: `select min(b.height) height, b.parentstateroot from blocks b group by b.parentstateroot`,
+	}
+	require.NoError(t, db.Migrate("blocks", []Migration{{
+		Version: 1,
+		SQLite:  `create table blocks (cid text not null primary key, parentstateroot text not null, height bigint not null);` + heights.Create(SQLite),
+	}}))
+
+	_, err := db.Exec(`insert into blocks values ('b1', 's1', 1), ('b2', 's1', 2), ('b3', 's2', 3)`)
+	require.NoError(t, err)
+
+	count := func() (n int) {
+		require.NoError(t, db.QueryRow(`select count(*) from state_heights`).Scan(&n))
+		return n
+	}
+	require.Equal(t, 0, count())
+
+	require.NoError(t, db.RefreshView(heights))
+	require.Equal(t, 2, count())
+
+	var height int64
+	require.NoError(t, db.QueryRow(`select height from state_heights where parentstateroot = 's1'`).Scan(&height))
+	require.Equal(t, int64(1), height)
+
+	require.NoError(t, db.RefreshView(heights))
+	require.Equal(t, 2, count())
+}
@@ -0,0 +1,46 @@
+package storage
+
+import (
+	"golang.org/x/xerrors"
+)
+
+// View is a materialized view: the rows Query returned when it was last
+// refreshed. SQLite has no materialized views, so there it is a table which
+// is emptied and filled again on refresh.
+type View struct {
+	Name  string
+	Query string
+}
+
+// Create returns the statement creating the view in the dialect, to be used in
+// migrations.
+func (v View) Create(d Dialect) string {
+	if d == SQLite {
+		return `create table if not exists ` + v.Name + ` as ` + v.Query + `;`
+	}
+	return `create materialized view if not exists ` + v.Name + ` as ` + v.Query + `;`
+}
+
+// RefreshView recomputes the rows of the view.
+func (db *DB) RefreshView(v View) error {
+	if db.Dialect == Postgres {
+		if _, err := db.Exec(`refresh materialized view ` + v.Name); err != nil {
+			return xerrors.Errorf("refresh %s: %w", v.Name, err)
+		}
+		return nil
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`delete from ` + v.Name); err != nil {
+		_ = tx.Rollback()
+		return xerrors.Errorf("clear %s: %w", v.Name, err)
+	}
+	if _, err := tx.Exec(`insert into ` + v.Name + ` ` + v.Query); err != nil {
+		_ = tx.Rollback()
+		return xerrors.Errorf("refresh %s: %w", v.Name, err)
+	}
+	return tx.Commit()
+}
@@ -4,7 +4,6 @@ import (
 	"container/list"
 	"context"
 	"database/sql"
-	"fmt"
 	"sync"
 	"time"
 
@@ -16,12 +15,13 @@ import (
 	"github.com/EpiK-Protocol/go-epik/api"
 	"github.com/EpiK-Protocol/go-epik/chain/store"
 	"github.com/EpiK-Protocol/go-epik/chain/types"
+	"github.com/EpiK-Protocol/go-epik/cmd/epik-chainwatch/storage"
 )
 
 var log = logging.Logger("syncer")
 
 type Syncer struct {
-	db *sql.DB
+	db *storage.DB
 
 	lookbackLimit uint64
 
@@ -29,7 +29,7 @@ type Syncer struct {
 	node     api.FullNode
 }
 
-func NewSyncer(db *sql.DB, node api.FullNode, lookbackLimit uint64) *Syncer {
+func NewSyncer(db *storage.DB, node api.FullNode, lookbackLimit uint64) *Syncer {
 	return &Syncer{
 		db:            db,
 		node:          node,
@@ -37,13 +37,14 @@ func NewSyncer(db *sql.DB, node api.FullNode, lookbackLimit uint64) *Syncer {
 	}
 }
 
-func (s *Syncer) setupSchemas() error {
-	tx, err := s.db.Begin()
-	if err != nil {
-		return err
-	}
+// StateHeights is the height each state root was first reached at.
+var StateHeights = storage.View{
+	Name: "state_heights",
+	Query: `select min(b.height) height, b.parentstateroot
+	from blocks b group by b.parentstateroot`,
+}
 
-	if _, err := tx.Exec(`
+const syncerSchema = `
 /* tracks circulating fil available on the network at each tipset */
 create table if not exists chain_economics
 (
@@ -134,21 +135,24 @@ create table if not exists blocks
 
 create unique index if not exists block_cid_uindex
 	on blocks (cid,height);
+`
 
-create materialized view if not exists state_heights
-    as select min(b.height) height, b.parentstateroot
-	from blocks b group by b.parentstateroot;
-
+const stateHeightsIndexes = `
 create index if not exists state_heights_height_index
 	on state_heights (height);
 
 create index if not exists state_heights_parentstateroot_index
 	on state_heights (parentstateroot);
-`); err != nil {
-		return err
-	}
+`
 
-	return tx.Commit()
+var syncerMigrations = []storage.Migration{{
+	Version:  1,
+	Postgres: syncerSchema + StateHeights.Create(storage.Postgres) + stateHeightsIndexes,
+	SQLite:   syncerSchema + StateHeights.Create(storage.SQLite) + stateHeightsIndexes,
+}}
+
+func (s *Syncer) setupSchemas() error {
+	return s.db.Migrate("syncer", syncerMigrations)
 }
 
 func (s *Syncer) Start(ctx context.Context) {
@@ -321,19 +325,16 @@ func (s *Syncer) storeCirculatingSupply(ctx context.Context, tipset *types.TipSe
 		return err
 	}
 
-	ceInsert := `insert into chain_economics (parent_state_root, circulating_fil, vested_fil, mined_fil, burnt_fil, locked_fil) ` +
-		`values ('%s', '%s', '%s', '%s', '%s', '%s') on conflict on constraint chain_economics_pk do ` +
-		`update set (circulating_fil, vested_fil, mined_fil, burnt_fil, locked_fil) = ('%[2]s', '%[3]s', '%[4]s', '%[5]s', '%[6]s') ` +
-		`where chain_economics.parent_state_root = '%[1]s';`
-
-	if _, err := s.db.Exec(fmt.Sprintf(ceInsert,
+	if _, err := s.db.Exec(`insert into chain_economics (parent_state_root, circulating_fil, vested_fil, mined_fil, burnt_fil, locked_fil) `+
+		`values ($1, $2, $3, $4, $5, $6) on conflict (parent_state_root) do `+
+		`update set circulating_fil = excluded.circulating_fil, vested_fil = excluded.vested_fil, mined_fil = excluded.mined_fil, burnt_fil = excluded.burnt_fil, locked_fil = excluded.locked_fil`,
 		tipset.ParentState().String(),
 		supply.EpkCirculating.String(),
 		supply.EpkVested.String(),
 		supply.EpkMined.String(),
 		supply.EpkBurnt.String(),
 		supply.EpkLocked.String(),
-	)); err != nil {
+	); err != nil {
 		return xerrors.Errorf("insert circulating supply for tipset (%s): %w", tipset.Key().String(), err)
 	}
 
@@ -353,133 +354,99 @@ func (s *Syncer) storeHeaders(bhs map[cid.Cid]*types.BlockHeader, sync bool, tim
 		return xerrors.Errorf("begin: %w", err)
 	}
 
-	if _, err := tx.Exec(`
-
-create temp table bc (like block_cids excluding constraints) on commit drop;
-create temp table de (like drand_entries excluding constraints) on commit drop;
-create temp table bde (like block_drand_entries excluding constraints) on commit drop;
-create temp table tbp (like block_parents excluding constraints) on commit drop;
-create temp table bs (like blocks_synced excluding constraints) on commit drop;
-create temp table b (like blocks excluding constraints) on commit drop;
-
-
-`); err != nil {
-		return xerrors.Errorf("prep temp: %w", err)
-	}
-
 	{
-		stmt, err := tx.Prepare(`copy bc (cid) from STDIN`)
+		ins, err := s.db.NewInserter(tx, "block_cids", "cid")
 		if err != nil {
 			return err
 		}
 
 		for _, bh := range bhs {
-			if _, err := stmt.Exec(bh.Cid().String()); err != nil {
+			if err := ins.Add(bh.Cid().String()); err != nil {
 				log.Error(err)
 			}
 		}
 
-		if err := stmt.Close(); err != nil {
-			return err
-		}
-
-		if _, err := tx.Exec(`insert into block_cids select * from bc on conflict do nothing `); err != nil {
+		if err := ins.Flush(); err != nil {
 			return xerrors.Errorf("drand entries put: %w", err)
 		}
 	}
 
 	{
-		stmt, err := tx.Prepare(`copy de (round, data) from STDIN`)
+		ins, err := s.db.NewInserter(tx, "drand_entries", "round", "data")
 		if err != nil {
 			return err
 		}
 
 		for _, bh := range bhs {
 			for _, ent := range bh.BeaconEntries {
-				if _, err := stmt.Exec(ent.Round, ent.Data); err != nil {
+				if err := ins.Add(ent.Round, ent.Data); err != nil {
 					log.Error(err)
 				}
 			}
 		}
 
-		if err := stmt.Close(); err != nil {
-			return err
-		}
-
-		if _, err := tx.Exec(`insert into drand_entries select * from de on conflict do nothing `); err != nil {
+		if err := ins.Flush(); err != nil {
 			return xerrors.Errorf("drand entries put: %w", err)
 		}
 	}
 
 	{
-		stmt, err := tx.Prepare(`copy bde (round, block) from STDIN`)
+		ins, err := s.db.NewInserter(tx, "block_drand_entries", "round", "block")
 		if err != nil {
 			return err
 		}
 
 		for _, bh := range bhs {
 			for _, ent := range bh.BeaconEntries {
-				if _, err := stmt.Exec(ent.Round, bh.Cid().String()); err != nil {
+				if err := ins.Add(ent.Round, bh.Cid().String()); err != nil {
 					log.Error(err)
 				}
 			}
 		}
 
-		if err := stmt.Close(); err != nil {
-			return err
-		}
-
-		if _, err := tx.Exec(`insert into block_drand_entries select * from bde on conflict do nothing `); err != nil {
+		if err := ins.Flush(); err != nil {
 			return xerrors.Errorf("block drand entries put: %w", err)
 		}
 	}
 
 	{
-		stmt, err := tx.Prepare(`copy tbp (block, parent) from STDIN`)
+		ins, err := s.db.NewInserter(tx, "block_parents", "block", "parent")
 		if err != nil {
 			return err
 		}
 
 		for _, bh := range bhs {
 			for _, parent := range bh.Parents {
-				if _, err := stmt.Exec(bh.Cid().String(), parent.String()); err != nil {
+				if err := ins.Add(bh.Cid().String(), parent.String()); err != nil {
 					log.Error(err)
 				}
 			}
 		}
 
-		if err := stmt.Close(); err != nil {
-			return err
-		}
-
-		if _, err := tx.Exec(`insert into block_parents select * from tbp on conflict do nothing `); err != nil {
+		if err := ins.Flush(); err != nil {
 			return xerrors.Errorf("parent put: %w", err)
 		}
 	}
 
 	if sync {
 
-		stmt, err := tx.Prepare(`copy bs (cid, synced_at) from stdin `)
+		ins, err := s.db.NewInserter(tx, "blocks_synced", "cid", "synced_at")
 		if err != nil {
 			return err
 		}
 
 		for _, bh := range bhs {
-			if _, err := stmt.Exec(bh.Cid().String(), timestamp.Unix()); err != nil {
+			if err := ins.Add(bh.Cid().String(), timestamp.Unix()); err != nil {
 				log.Error(err)
 			}
 		}
 
-		if err := stmt.Close(); err != nil {
-			return err
-		}
-
-		if _, err := tx.Exec(`insert into blocks_synced select * from bs on conflict do nothing `); err != nil {
+		if err := ins.Flush(); err != nil {
 			return xerrors.Errorf("syncd put: %w", err)
 		}
 	}
 
-	stmt2, err := tx.Prepare(`copy b (cid, parentWeight, parentStateRoot, height, miner, "timestamp", ticket, election_proof, win_count, parent_base_fee, forksig) from stdin`)
+	ins2, err := s.db.NewInserter(tx, "blocks", "cid", "parentweight", "parentstateroot", "height", "miner", "timestamp", "ticket", "election_proof", "win_count", "parent_base_fee", "forksig")
 	if err != nil {
 		return err
 	}
@@ -499,7 +466,7 @@ create temp table b (like blocks excluding constraints) on commit drop;
 			}
 		}
 
-		if _, err := stmt2.Exec(
+		if err := ins2.Add(
 			bh.Cid().String(),
 			bh.ParentWeight.String(),
 			bh.ParentStateRoot.String(),
@@ -515,11 +482,7 @@ create temp table b (like blocks excluding constraints) on commit drop;
 		}
 	}
 
-	if err := stmt2.Close(); err != nil {
-		return xerrors.Errorf("s2 close: %w", err)
-	}
-
-	if _, err := tx.Exec(`insert into blocks select * from b on conflict do nothing `); err != nil {
+	if err := ins2.Flush(); err != nil {
 		return xerrors.Errorf("blk put: %w", err)
 	}
 
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/EpiK-Protocol/go-epik/api"
+	lcli "github.com/EpiK-Protocol/go-epik/cli"
+)
+
+var dataCmd = &cli.Command{
+	Name:  "data",
+	Usage: "Manage automatic chain-data replication",
+	Subcommands: []*cli.Command{
+		dataStatusCmd,
+	},
+}
+
+var dataStatusCmd = &cli.Command{
+	Name:  "status",
+	Usage: "Show the replication progress of chain data",
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "pieces",
+			Usage: "list replication state of every piece",
+		},
+		&cli.StringFlag{
+			Name:  "state",
+			Usage: "only list pieces in the given state (discovered, retrieving, retrieved, dealing, stored, failed)",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		nodeApi, closer, err := lcli.GetStorageMinerAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		ctx := lcli.ReqContext(cctx)
+
+		listPieces := cctx.Bool("pieces") || cctx.IsSet("state")
+		st, err := nodeApi.MinerDataStatus(ctx, listPieces)
+		if err != nil {
+			return err
+		}
+
+		total := 0
+		for _, n := range st.States {
+			total += n
+		}
+
+		fmt.Printf("Scanned Height: %d\n", st.CheckHeight)
+		fmt.Printf("Total Pieces:   %d\n", total)
+		for _, state := range []api.MinerDataState{
+			api.MinerDataDiscovered,
+			api.MinerDataRetrieving,
+			api.MinerDataRetrieved,
+			api.MinerDataDealing,
+			api.MinerDataStored,
+			api.MinerDataFailed,
+		} {
+			fmt.Printf("\t%s: %d\n", state, st.States[state])
+		}
+
+		if !listPieces {
+			return nil
+		}
+
+		pieces := st.Pieces
+		if cctx.IsSet("state") {
+			filter := api.MinerDataState(cctx.String("state"))
+			pieces = pieces[:0]
+			for _, p := range st.Pieces {
+				if p.State == filter {
+					pieces = append(pieces, p)
+				}
+			}
+		}
+		sort.Slice(pieces, func(i, j int) bool {
+			return pieces[i].PieceCID.String() < pieces[j].PieceCID.String()
+		})
+
+		fmt.Println()
+		w := tabwriter.NewWriter(os.Stdout, 2, 4, 2, ' ', 0)
+		_, _ = fmt.Fprintf(w, "Piece\tRoot\tState\tTries\tSources\tReason\n")
+		for _, p := range pieces {
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%s\n",
+				p.PieceCID,
+				p.RootCID,
+				p.State,
+				p.TryCount,
+				len(p.Sources),
+				p.Reason,
+			)
+		}
+		return w.Flush()
+	},
+}
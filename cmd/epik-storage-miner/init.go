@@ -473,7 +473,7 @@ func storageMinerInit(ctx context.Context, cctx *cli.Context, api lapi.FullNode,
 				return fmt.Errorf("failed to open filesystem journal: %w", err)
 			}
 
-			m := storageminer.NewMiner(api, epp, a, slashfilter.New(mds), j)
+			m := storageminer.NewMiner(api, epp, a, slashfilter.New(mds), j, mds, nil)
 			{
 				if err := m.Start(ctx); err != nil {
 					return xerrors.Errorf("failed to start up genesis miner: %w", err)
@@ -46,6 +46,7 @@ func main() {
 		lcli.WithCategory("storage", storageCmd),
 		lcli.WithCategory("storage", sealingCmd),
 		lcli.WithCategory("retrieval", piecesCmd),
+		lcli.WithCategory("retrieval", dataCmd),
 	}
 	jaeger := tracing.SetupJaegerTracing("epik")
 	defer func() {
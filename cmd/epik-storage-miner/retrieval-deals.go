@@ -2,8 +2,11 @@ package main
 
 import (
 	"fmt"
+	"math"
 	"os"
+	"sort"
 	"text/tabwriter"
+	"time"
 
 	"github.com/docker/go-units"
 	"github.com/filecoin-project/go-fil-markets/retrievalmarket"
@@ -21,6 +24,7 @@ var retrievalDealsCmd = &cli.Command{
 		retrievalDealsListCmd,
 		retrievalSetAskCmd,
 		retrievalGetAskCmd,
+		retrievalSourcesCmd,
 	},
 }
 
@@ -243,3 +247,64 @@ var retrievalGetAskCmd = &cli.Command{
 
 	},
 }
+
+var retrievalSourcesCmd = &cli.Command{
+	Name:  "sources",
+	Usage: "List the miners chain data was retrieved from, ranked by reputation",
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "errors",
+			Usage: "show failures by error class",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		api, closer, err := lcli.GetStorageMinerAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		sources, err := api.MinerDataSources(lcli.DaemonContext(cctx))
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 2, 4, 2, ' ', 0)
+
+		_, _ = fmt.Fprintf(w, "Miner\tScore\tSuccess\tFailed\tRate\tSpeed\tTTFB\tLast Success\tLast Failure\n")
+
+		for _, src := range sources {
+			_, _ = fmt.Fprintf(w,
+				"%s\t%.1f\t%d\t%d\t%.1f%%\t%s/s\t%s\t%s\t%s\n",
+				src.Miner,
+				src.Score,
+				src.Successes,
+				src.Failures,
+				100*float64(src.Successes)/math.Max(1, float64(src.Successes+src.Failures)),
+				units.BytesSize(src.BytesPerSecond()),
+				src.AvgTTFB().Round(time.Millisecond),
+				formatSourceTime(src.LastSuccess),
+				formatSourceTime(src.LastFailure),
+			)
+			if cctx.Bool("errors") {
+				classes := make([]string, 0, len(src.Errors))
+				for class := range src.Errors {
+					classes = append(classes, class)
+				}
+				sort.Strings(classes)
+				for _, class := range classes {
+					_, _ = fmt.Fprintf(w, "\t\t\t%d\t%s\n", src.Errors[class], class)
+				}
+			}
+		}
+
+		return w.Flush()
+	},
+}
+
+func formatSourceTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Format(time.Stamp)
+}
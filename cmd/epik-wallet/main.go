@@ -11,6 +11,7 @@ import (
 	"github.com/urfave/cli/v2"
 	"go.opencensus.io/stats/view"
 	"go.opencensus.io/tag"
+	"golang.org/x/xerrors"
 
 	"github.com/filecoin-project/go-jsonrpc"
 
@@ -33,6 +34,7 @@ func main() {
 
 	local := []*cli.Command{
 		runCmd,
+		policyCmd,
 	}
 
 	app := &cli.App{
@@ -82,7 +84,11 @@ var runCmd = &cli.Command{
 		},
 		&cli.BoolFlag{
 			Name:  "offline",
-			Usage: "don't query chain state in interactive mode",
+			Usage: "don't query chain state in interactive mode, nor for the policy",
+		},
+		&cli.StringFlag{
+			Name:  "policy",
+			Usage: "path of a JSON policy file restricting what the wallet signs",
 		},
 	},
 	Action: func(cctx *cli.Context) error {
@@ -165,6 +171,23 @@ var runCmd = &cli.Command{
 			w = &LoggedWallet{under: w}
 		}
 
+		if pf := cctx.String("policy"); pf != "" {
+			policy, err := LoadPolicy(pf)
+			if err != nil {
+				return xerrors.Errorf("loading policy: %w", err)
+			}
+			if !cctx.Bool("offline") {
+				policy.actorCode = nodeActorCode(func() (api.FullNode, jsonrpc.ClientCloser, error) {
+					return lcli.GetFullNodeAPI(cctx)
+				})
+			}
+
+			w = &PolicyWallet{
+				under:  w,
+				policy: policy,
+			}
+		}
+
 		rpcServer := jsonrpc.NewServer()
 		rpcServer.Register("EpiK", metrics.MetricedWalletAPI(w))
 
@@ -0,0 +1,548 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-jsonrpc"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/crypto"
+	builtin2 "github.com/filecoin-project/specs-actors/v2/actors/builtin"
+
+	"github.com/EpiK-Protocol/go-epik/api"
+	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin"
+	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/cron"
+	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/expertfund"
+	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/govern"
+	init_ "github.com/EpiK-Protocol/go-epik/chain/actors/builtin/init"
+	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/knowledge"
+	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/market"
+	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/power"
+	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/retrieval"
+	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/reward"
+	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/vesting"
+	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/vote"
+	"github.com/EpiK-Protocol/go-epik/chain/stmgr"
+	"github.com/EpiK-Protocol/go-epik/chain/types"
+)
+
+// PolicyConfig is the policy file of the wallet, in JSON. An address signs
+// according to its own rules, or the default rules if it has none. Without
+// default rules, addresses not listed can't sign anything.
+//
+// Example:
+//
+//	{
+//	  "Default": {"MaxValue": "0"},
+//	  "Addresses": {
+//	    "f3...": {
+//	      "AllowedRecipients": ["f01234"],
+//	      "AllowedMethods": ["0", "votefund.Vote", "votefund.Rescind"],
+//	      "MaxValue": "100 EPK",
+//	      "MaxDailyValue": "1000 EPK",
+//	      "MaxGasFeeCap": "0.000001 EPK",
+//	      "AllowedSignTypes": ["block"]
+//	    }
+//	  }
+//	}
+type PolicyConfig struct {
+	Default   *PolicyRules
+	Addresses map[string]PolicyRules
+}
+
+// PolicyRules restrict what an address signs. Empty fields don't restrict
+// anything, except AllowedSignTypes.
+type PolicyRules struct {
+	// AllowedRecipients are the addresses messages may be sent to, as they
+	// appear in the messages.
+	AllowedRecipients []string
+
+	// AllowedMethods are the methods messages may call, either by number
+	// ("0" for plain sends), or by actor and method name ("votefund.Vote",
+	// "storageminer.ChangeWorkerAddress"). A method given by name only matches
+	// messages sent to an actor of that type. The type of actors other than
+	// singletons, like votefund or govern, is looked up on chain, and such
+	// methods never match when the wallet is offline.
+	AllowedMethods []string
+
+	// MaxValue is the most a single message may transfer.
+	MaxValue string
+	// MaxDailyValue is the most the messages signed in a day (UTC) may
+	// transfer together. Only the messages signed since the wallet started
+	// are counted.
+	MaxDailyValue string
+
+	// MaxGasFeeCap is the highest gas fee cap of a message.
+	MaxGasFeeCap string
+	// MaxFee is the most a message may pay for gas, its gas fee cap times its
+	// gas limit.
+	MaxFee string
+
+	// AllowedSignTypes are what may be signed besides messages, such as
+	// "block" or "dealproposal".
+	AllowedSignTypes []api.MsgType
+}
+
+// Policy decides which messages and other data the wallet signs.
+type Policy struct {
+	def   *rules
+	addrs map[address.Address]*rules
+
+	// actorCode looks up the code of the actors messages are sent to, for the
+	// methods given by name. Unset when offline.
+	actorCode func(context.Context, address.Address) (cid.Cid, error)
+
+	lk    sync.Mutex
+	spent map[address.Address]*dailySpend
+}
+
+type rules struct {
+	recipients map[address.Address]struct{}
+	methods    []methodRule
+
+	maxValue      *abi.TokenAmount
+	maxDailyValue *abi.TokenAmount
+	maxGasFeeCap  *abi.TokenAmount
+	maxFee        *abi.TokenAmount
+
+	signTypes map[api.MsgType]struct{}
+}
+
+type methodRule struct {
+	num abi.MethodNum
+
+	// byName is set for methods given by actor and method name
+	byName bool
+	// code is the code of the actor the method belongs to, if given by name
+	code cid.Cid
+}
+
+type dailySpend struct {
+	day   string
+	value abi.TokenAmount
+}
+
+// singletons are the actors with a single instance, at a fixed address.
+var singletons = map[cid.Cid]address.Address{
+	builtin2.InitActorCodeID:          init_.Address,
+	builtin2.RewardActorCodeID:        reward.Address,
+	builtin2.CronActorCodeID:          cron.Address,
+	builtin2.StoragePowerActorCodeID:  power.Address,
+	builtin2.StorageMarketActorCodeID: market.Address,
+	builtin2.GovernActorCodeID:        govern.Address,
+	builtin2.ExpertFundActorCodeID:    expertfund.Address,
+	builtin2.RetrievalFundActorCodeID: retrieval.Address,
+	builtin2.VoteFundActorCodeID:      vote.Address,
+	builtin2.KnowledgeFundActorCodeID: knowledge.Address,
+	builtin2.VestingActorCodeID:       vesting.Address,
+}
+
+func LoadPolicy(file string) (*Policy, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, xerrors.Errorf("reading policy file: %w", err)
+	}
+
+	var cfg PolicyConfig
+	if err := json.Unmarshal(b, &cfg); err != nil {
+		return nil, xerrors.Errorf("parsing policy file: %w", err)
+	}
+
+	return NewPolicy(cfg)
+}
+
+func NewPolicy(cfg PolicyConfig) (*Policy, error) {
+	p := &Policy{
+		addrs: map[address.Address]*rules{},
+		spent: map[address.Address]*dailySpend{},
+	}
+
+	if cfg.Default != nil {
+		r, err := parseRules(*cfg.Default)
+		if err != nil {
+			return nil, xerrors.Errorf("default rules: %w", err)
+		}
+		p.def = r
+	}
+
+	for s, rc := range cfg.Addresses {
+		a, err := address.NewFromString(s)
+		if err != nil {
+			return nil, xerrors.Errorf("parsing address %q: %w", s, err)
+		}
+		r, err := parseRules(rc)
+		if err != nil {
+			return nil, xerrors.Errorf("rules of %s: %w", s, err)
+		}
+		p.addrs[a] = r
+	}
+
+	return p, nil
+}
+
+func parseRules(rc PolicyRules) (*rules, error) {
+	r := &rules{
+		signTypes: map[api.MsgType]struct{}{},
+	}
+
+	if len(rc.AllowedRecipients) > 0 {
+		r.recipients = map[address.Address]struct{}{}
+		for _, s := range rc.AllowedRecipients {
+			a, err := address.NewFromString(s)
+			if err != nil {
+				return nil, xerrors.Errorf("parsing recipient %q: %w", s, err)
+			}
+			r.recipients[a] = struct{}{}
+		}
+	}
+
+	for _, s := range rc.AllowedMethods {
+		mr, err := parseMethodRule(s)
+		if err != nil {
+			return nil, err
+		}
+		r.methods = append(r.methods, mr...)
+	}
+
+	for _, l := range []struct {
+		name string
+		val  string
+		out  **abi.TokenAmount
+	}{
+		{"MaxValue", rc.MaxValue, &r.maxValue},
+		{"MaxDailyValue", rc.MaxDailyValue, &r.maxDailyValue},
+		{"MaxGasFeeCap", rc.MaxGasFeeCap, &r.maxGasFeeCap},
+		{"MaxFee", rc.MaxFee, &r.maxFee},
+	} {
+		if l.val == "" {
+			continue
+		}
+		v, err := types.ParseEPK(l.val)
+		if err != nil {
+			return nil, xerrors.Errorf("parsing %s: %w", l.name, err)
+		}
+		amt := abi.TokenAmount(v)
+		*l.out = &amt
+	}
+
+	for _, t := range rc.AllowedSignTypes {
+		if t == api.MTChainMsg {
+			return nil, xerrors.Errorf("AllowedSignTypes can't list messages, the other rules decide which are signed")
+		}
+		r.signTypes[t] = struct{}{}
+	}
+
+	return r, nil
+}
+
+// parseMethodRule parses a method number, or an actor and method name. Actor
+// names are the last part of the builtin actor names, e.g. votefund for
+// epk/1/votefund. A method name may match methods of several actors.
+func parseMethodRule(s string) ([]methodRule, error) {
+	if n, err := strconv.ParseUint(s, 10, 64); err == nil {
+		return []methodRule{{num: abi.MethodNum(n)}}, nil
+	}
+
+	i := strings.IndexByte(s, '.')
+	if i < 0 {
+		return nil, xerrors.Errorf("method %q is neither a number nor actor.Method", s)
+	}
+	actorName, methodName := s[:i], s[i+1:]
+
+	var out []methodRule
+	for code, methods := range stmgr.MethodsMap {
+		if path.Base(builtin.ActorNameByCode(code)) != actorName {
+			continue
+		}
+		for num, m := range methods {
+			if m.Name == methodName {
+				out = append(out, methodRule{num: num, byName: true, code: code})
+			}
+		}
+	}
+	if len(out) == 0 {
+		return nil, xerrors.Errorf("unknown method %q", s)
+	}
+	return out, nil
+}
+
+func (p *Policy) rulesFor(signer address.Address) (*rules, error) {
+	if r, ok := p.addrs[signer]; ok {
+		return r, nil
+	}
+	if p.def != nil {
+		return p.def, nil
+	}
+	return nil, xerrors.Errorf("no rules for %s", signer)
+}
+
+// CheckSign checks that signer may sign the data described by meta, without
+// counting message values towards the daily limit.
+func (p *Policy) CheckSign(ctx context.Context, signer address.Address, toSign []byte, meta api.MsgMeta) error {
+	r, err := p.rulesFor(signer)
+	if err != nil {
+		return err
+	}
+
+	if meta.Type != api.MTChainMsg {
+		if _, ok := r.signTypes[meta.Type]; !ok {
+			return xerrors.Errorf("signing %s is not allowed", meta.Type)
+		}
+		return nil
+	}
+
+	msg, err := decodeSignedMessage(toSign, meta)
+	if err != nil {
+		return err
+	}
+
+	code, err := p.recipientCode(ctx, r, msg)
+	if err != nil {
+		return err
+	}
+
+	p.lk.Lock()
+	defer p.lk.Unlock()
+
+	return p.checkMessage(r, signer, msg, code, time.Now())
+}
+
+// reserve checks a message the signer is about to sign, like CheckSign, and
+// counts its value towards the daily limit. The returned func undoes this,
+// when signing fails.
+func (p *Policy) reserve(ctx context.Context, signer address.Address, toSign []byte, meta api.MsgMeta) (func(), error) {
+	if meta.Type != api.MTChainMsg {
+		return func() {}, p.CheckSign(ctx, signer, toSign, meta)
+	}
+
+	r, err := p.rulesFor(signer)
+	if err != nil {
+		return nil, err
+	}
+
+	msg, err := decodeSignedMessage(toSign, meta)
+	if err != nil {
+		return nil, err
+	}
+
+	code, err := p.recipientCode(ctx, r, msg)
+	if err != nil {
+		return nil, err
+	}
+
+	p.lk.Lock()
+	defer p.lk.Unlock()
+
+	now := time.Now()
+	if err := p.checkMessage(r, signer, msg, code, now); err != nil {
+		return nil, err
+	}
+
+	s := p.spentOn(signer, now)
+	s.value = big.Add(s.value, msg.Value)
+
+	return func() {
+		p.lk.Lock()
+		defer p.lk.Unlock()
+
+		if s.day == day(time.Now()) {
+			s.value = big.Sub(s.value, msg.Value)
+		}
+	}, nil
+}
+
+// checkMessage checks msg against the rules, code being the code of its
+// recipient, as returned by recipientCode. Must be called with the lock held.
+func (p *Policy) checkMessage(r *rules, signer address.Address, msg *types.Message, code cid.Cid, now time.Time) error {
+	if msg.From != signer {
+		return xerrors.Errorf("message is from %s, not the signer %s", msg.From, signer)
+	}
+
+	if r.recipients != nil {
+		if _, ok := r.recipients[msg.To]; !ok {
+			return xerrors.Errorf("recipient %s is not allowed", msg.To)
+		}
+	}
+
+	if r.methods != nil && !r.allowsMethod(code, msg.Method) {
+		return xerrors.Errorf("method %d of %s is not allowed", msg.Method, msg.To)
+	}
+
+	if r.maxValue != nil && msg.Value.GreaterThan(*r.maxValue) {
+		return xerrors.Errorf("value %s exceeds the limit of %s", types.EPK(msg.Value), types.EPK(*r.maxValue))
+	}
+
+	if r.maxDailyValue != nil {
+		total := big.Add(p.spentOn(signer, now).value, msg.Value)
+		if total.GreaterThan(*r.maxDailyValue) {
+			return xerrors.Errorf("value %s would bring the value sent today to %s, over the limit of %s", types.EPK(msg.Value), types.EPK(total), types.EPK(*r.maxDailyValue))
+		}
+	}
+
+	if r.maxGasFeeCap != nil && msg.GasFeeCap.GreaterThan(*r.maxGasFeeCap) {
+		return xerrors.Errorf("gas fee cap %s exceeds the limit of %s", types.EPK(msg.GasFeeCap), types.EPK(*r.maxGasFeeCap))
+	}
+
+	if r.maxFee != nil && msg.RequiredFunds().GreaterThan(*r.maxFee) {
+		return xerrors.Errorf("max fee %s exceeds the limit of %s", types.EPK(msg.RequiredFunds()), types.EPK(*r.maxFee))
+	}
+
+	return nil
+}
+
+// recipientCode returns the code of the actor msg is sent to, when a method
+// given by name may allow the message, and cid.Undef when it's not needed or
+// can't be looked up.
+func (p *Policy) recipientCode(ctx context.Context, r *rules, msg *types.Message) (cid.Cid, error) {
+	if !r.namesMethod(msg.Method) {
+		return cid.Undef, nil
+	}
+
+	for code, a := range singletons {
+		if a == msg.To {
+			return code, nil
+		}
+	}
+
+	if p.actorCode == nil {
+		return cid.Undef, nil
+	}
+	code, err := p.actorCode(ctx, msg.To)
+	if err != nil {
+		return cid.Undef, xerrors.Errorf("looking up the actor of recipient %s: %w", msg.To, err)
+	}
+	return code, nil
+}
+
+func (r *rules) namesMethod(num abi.MethodNum) bool {
+	for _, m := range r.methods {
+		if m.byName && m.num == num {
+			return true
+		}
+	}
+	return false
+}
+
+// allowsMethod checks the method called on an actor of the given code, which
+// is undefined if unknown.
+func (r *rules) allowsMethod(code cid.Cid, num abi.MethodNum) bool {
+	for _, m := range r.methods {
+		if m.num != num {
+			continue
+		}
+		if !m.byName || (code.Defined() && m.code == code) {
+			return true
+		}
+	}
+	return false
+}
+
+// nodeActorCode looks up the code of actors with the node API.
+func nodeActorCode(apiGetter func() (api.FullNode, jsonrpc.ClientCloser, error)) func(context.Context, address.Address) (cid.Cid, error) {
+	return func(ctx context.Context, a address.Address) (cid.Cid, error) {
+		napi, closer, err := apiGetter()
+		if err != nil {
+			return cid.Undef, xerrors.Errorf("getting node api: %w", err)
+		}
+		defer closer()
+
+		act, err := napi.StateGetActor(ctx, a, types.EmptyTSK)
+		if err != nil {
+			return cid.Undef, err
+		}
+		return act.Code, nil
+	}
+}
+
+// spentOn returns what the signer has sent on the day of t. Must be called
+// with the lock held.
+func (p *Policy) spentOn(signer address.Address, t time.Time) *dailySpend {
+	d := day(t)
+	s, ok := p.spent[signer]
+	if !ok || s.day != d {
+		s = &dailySpend{day: d, value: big.Zero()}
+		p.spent[signer] = s
+	}
+	return s
+}
+
+func day(t time.Time) string {
+	return t.UTC().Format("2006-01-02")
+}
+
+// decodeSignedMessage decodes the message in meta, and checks that it is what
+// is being signed.
+func decodeSignedMessage(toSign []byte, meta api.MsgMeta) (*types.Message, error) {
+	var cmsg types.Message
+	if err := cmsg.UnmarshalCBOR(bytes.NewReader(meta.Extra)); err != nil {
+		return nil, xerrors.Errorf("unmarshalling message: %w", err)
+	}
+
+	_, bc, err := cid.CidFromBytes(toSign)
+	if err != nil {
+		return nil, xerrors.Errorf("getting cid from signing bytes: %w", err)
+	}
+
+	if !cmsg.Cid().Equals(bc) {
+		return nil, xerrors.Errorf("cid(meta.Extra).bytes() != msg")
+	}
+
+	return &cmsg, nil
+}
+
+// PolicyWallet only signs what its policy allows.
+type PolicyWallet struct {
+	under  api.WalletAPI
+	policy *Policy
+}
+
+func (c *PolicyWallet) WalletNew(ctx context.Context, typ types.KeyType) (address.Address, error) {
+	return c.under.WalletNew(ctx, typ)
+}
+
+func (c *PolicyWallet) WalletHas(ctx context.Context, addr address.Address) (bool, error) {
+	return c.under.WalletHas(ctx, addr)
+}
+
+func (c *PolicyWallet) WalletList(ctx context.Context) ([]address.Address, error) {
+	return c.under.WalletList(ctx)
+}
+
+func (c *PolicyWallet) WalletSign(ctx context.Context, k address.Address, msg []byte, meta api.MsgMeta) (*crypto.Signature, error) {
+	undo, err := c.policy.reserve(ctx, k, msg, meta)
+	if err != nil {
+		log.Warnw("WalletSign denied by policy", "address", k, "type", meta.Type, "reason", err)
+		return nil, xerrors.Errorf("denied by policy: %w", err)
+	}
+
+	sig, err := c.under.WalletSign(ctx, k, msg, meta)
+	if err != nil {
+		undo()
+		return nil, err
+	}
+	return sig, nil
+}
+
+func (c *PolicyWallet) WalletExport(ctx context.Context, a address.Address) (*types.KeyInfo, error) {
+	return c.under.WalletExport(ctx, a)
+}
+
+func (c *PolicyWallet) WalletImport(ctx context.Context, ki *types.KeyInfo) (address.Address, error) {
+	return c.under.WalletImport(ctx, ki)
+}
+
+func (c *PolicyWallet) WalletDelete(ctx context.Context, addr address.Address) error {
+	return c.under.WalletDelete(ctx, addr)
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/filecoin-project/go-jsonrpc"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+
+	"github.com/EpiK-Protocol/go-epik/api"
+	"github.com/EpiK-Protocol/go-epik/chain/types"
+	lcli "github.com/EpiK-Protocol/go-epik/cli"
+)
+
+var policyCmd = &cli.Command{
+	Name:  "policy",
+	Usage: "Manage the signing policy",
+	Subcommands: []*cli.Command{
+		policyCheckCmd,
+	},
+}
+
+var policyCheckCmd = &cli.Command{
+	Name:      "check",
+	Usage:     "Check whether the policy allows signing a message",
+	ArgsUsage: "[message json]",
+	Description: `Checks a message, given as JSON, against the policy, as if it was signed
+   by its sender right after the wallet started. Pass --type to check signing
+   something else, like a block, by the --signer address.`,
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:     "policy",
+			Usage:    "path of the JSON policy file",
+			Required: true,
+		},
+		&cli.StringFlag{
+			Name:  "type",
+			Usage: "what is signed: message, block, dealproposal or unknown",
+			Value: api.MTChainMsg,
+		},
+		&cli.StringFlag{
+			Name:  "signer",
+			Usage: "address signing, when not signing a message",
+		},
+		&cli.BoolFlag{
+			Name:  "offline",
+			Usage: "don't look up the recipient on chain, for the methods allowed by name",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		policy, err := LoadPolicy(cctx.String("policy"))
+		if err != nil {
+			return err
+		}
+		if !cctx.Bool("offline") {
+			policy.actorCode = nodeActorCode(func() (api.FullNode, jsonrpc.ClientCloser, error) {
+				return lcli.GetFullNodeAPI(cctx)
+			})
+		}
+
+		meta := api.MsgMeta{Type: api.MsgType(cctx.String("type"))}
+
+		var signer address.Address
+		var toSign []byte
+		if meta.Type == api.MTChainMsg {
+			if !cctx.Args().Present() {
+				return xerrors.Errorf("must specify the message")
+			}
+
+			var msg types.Message
+			if err := json.Unmarshal([]byte(cctx.Args().First()), &msg); err != nil {
+				return xerrors.Errorf("parsing message: %w", err)
+			}
+
+			if meta.Extra, err = msg.Serialize(); err != nil {
+				return xerrors.Errorf("serializing message: %w", err)
+			}
+			toSign = msg.Cid().Bytes()
+			signer = msg.From
+		} else {
+			if signer, err = address.NewFromString(cctx.String("signer")); err != nil {
+				return xerrors.Errorf("parsing signer: %w", err)
+			}
+		}
+
+		if err := policy.CheckSign(lcli.ReqContext(cctx), signer, toSign, meta); err != nil {
+			return xerrors.Errorf("denied: %w", err)
+		}
+
+		fmt.Println("allowed")
+		return nil
+	},
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	builtin2 "github.com/filecoin-project/specs-actors/v2/actors/builtin"
+	"github.com/ipfs/go-cid"
+
+	"github.com/EpiK-Protocol/go-epik/api"
+	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/miner"
+	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/vote"
+	"github.com/EpiK-Protocol/go-epik/chain/types"
+)
+
+func mustAddr(t *testing.T, s string) address.Address {
+	a, err := address.NewFromString(s)
+	require.NoError(t, err)
+	return a
+}
+
+func signArgs(t *testing.T, msg *types.Message) ([]byte, api.MsgMeta) {
+	b, err := msg.Serialize()
+	require.NoError(t, err)
+	return msg.Cid().Bytes(), api.MsgMeta{Type: api.MTChainMsg, Extra: b}
+}
+
+func TestPolicy(t *testing.T) {
+	ctx := context.Background()
+	signer := mustAddr(t, "f1ys5qqiciehcml3sp764ymbbytfn3qoar5fo3iwy")
+	other := mustAddr(t, "f1cyg66djxytxhzdq7ynoqfxk7xinp6xsejbeufli")
+	minerAddr := mustAddr(t, "f01000")
+	msigAddr := mustAddr(t, "f01001")
+
+	rules := PolicyRules{
+		AllowedRecipients: []string{other.String(), minerAddr.String(), msigAddr.String(), vote.Address.String()},
+		AllowedMethods:    []string{"0", "votefund.Vote", "storageminer.ChangePeerID"},
+		MaxValue:          "10 EPK",
+		MaxDailyValue:     "15 EPK",
+		MaxGasFeeCap:      "1000 attoEPK",
+		AllowedSignTypes:  []api.MsgType{api.MTBlock},
+	}
+	policy, err := NewPolicy(PolicyConfig{
+		Addresses: map[string]PolicyRules{signer.String(): rules},
+	})
+	require.NoError(t, err)
+	policy.actorCode = func(_ context.Context, a address.Address) (cid.Cid, error) {
+		switch a {
+		case minerAddr:
+			return builtin2.StorageMinerActorCodeID, nil
+		case msigAddr:
+			return builtin2.MultisigActorCodeID, nil
+		}
+		return builtin2.AccountActorCodeID, nil
+	}
+
+	msg := func(to address.Address, method abi.MethodNum, value string) *types.Message {
+		return &types.Message{
+			From:       signer,
+			To:         to,
+			Method:     method,
+			Value:      abi.TokenAmount(types.MustParseEPK(value)),
+			GasLimit:   1000,
+			GasFeeCap:  abi.NewTokenAmount(1000),
+			GasPremium: abi.NewTokenAmount(10),
+		}
+	}
+	check := func(m *types.Message) error {
+		toSign, meta := signArgs(t, m)
+		return policy.CheckSign(ctx, signer, toSign, meta)
+	}
+
+	require.NoError(t, check(msg(other, 0, "1")))
+	require.Error(t, check(msg(signer, 0, "1")), "recipient")
+	require.Error(t, check(msg(other, 0, "11")), "value")
+
+	require.NoError(t, check(msg(vote.Address, vote.Methods.Vote, "1")))
+	require.Error(t, check(msg(vote.Address, vote.Methods.Rescind, "0")))
+	require.NoError(t, check(msg(minerAddr, miner.Methods.ChangePeerID, "0")))
+	// the methods of other actors numbered like ChangePeerID aren't allowed
+	require.Equal(t, vote.Methods.Rescind, miner.Methods.ChangePeerID)
+	require.Error(t, check(msg(vote.Address, miner.Methods.ChangePeerID, "0")))
+	require.Error(t, check(msg(msigAddr, miner.Methods.ChangePeerID, "0")))
+
+	// nor anything but singletons when the recipient can't be looked up
+	policy.actorCode = nil
+	require.Error(t, check(msg(minerAddr, miner.Methods.ChangePeerID, "0")))
+	require.NoError(t, check(msg(vote.Address, vote.Methods.Vote, "1")))
+
+	feeCap := msg(other, 0, "1")
+	feeCap.GasFeeCap = abi.NewTokenAmount(1001)
+	require.Error(t, check(feeCap))
+
+	fromOther := msg(other, 0, "1")
+	fromOther.From = other
+	require.Error(t, check(fromOther))
+
+	// the message in meta must be the one signed
+	toSign, _ := signArgs(t, msg(other, 0, "1"))
+	_, tampered := signArgs(t, msg(other, 0, "2"))
+	require.Error(t, policy.CheckSign(ctx, signer, toSign, tampered))
+
+	require.NoError(t, policy.CheckSign(ctx, signer, nil, api.MsgMeta{Type: api.MTBlock}))
+	require.Error(t, policy.CheckSign(ctx, signer, nil, api.MsgMeta{Type: api.MTDealProposal}))
+
+	// without default rules, other addresses can't sign
+	require.Error(t, policy.CheckSign(ctx, other, nil, api.MsgMeta{Type: api.MTBlock}))
+}
+
+func TestPolicyDailyValue(t *testing.T) {
+	ctx := context.Background()
+	signer := mustAddr(t, "f1ys5qqiciehcml3sp764ymbbytfn3qoar5fo3iwy")
+	to := mustAddr(t, "f1cyg66djxytxhzdq7ynoqfxk7xinp6xsejbeufli")
+
+	policy, err := NewPolicy(PolicyConfig{
+		Default: &PolicyRules{MaxDailyValue: "15 EPK"},
+	})
+	require.NoError(t, err)
+
+	send := func(nonce uint64, value string) ([]byte, api.MsgMeta) {
+		return signArgs(t, &types.Message{
+			From:       signer,
+			To:         to,
+			Nonce:      nonce,
+			Value:      abi.TokenAmount(types.MustParseEPK(value)),
+			GasFeeCap:  abi.NewTokenAmount(0),
+			GasPremium: abi.NewTokenAmount(0),
+		})
+	}
+
+	toSign, meta := send(0, "10")
+	_, err = policy.reserve(ctx, signer, toSign, meta)
+	require.NoError(t, err)
+
+	toSign, meta = send(1, "10")
+	require.Error(t, policy.CheckSign(ctx, signer, toSign, meta))
+	_, err = policy.reserve(ctx, signer, toSign, meta)
+	require.Error(t, err)
+
+	toSign, meta = send(1, "5")
+	undo, err := policy.reserve(ctx, signer, toSign, meta)
+	require.NoError(t, err)
+
+	// a failed signature doesn't count
+	undo()
+	_, err = policy.reserve(ctx, signer, toSign, meta)
+	require.NoError(t, err)
+
+	toSign, meta = send(2, "0.1")
+	require.Error(t, policy.CheckSign(ctx, signer, toSign, meta))
+}
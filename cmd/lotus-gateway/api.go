@@ -7,8 +7,11 @@ import (
 
 	"github.com/EpiK-Protocol/go-epik/api"
 	"github.com/EpiK-Protocol/go-epik/build"
+	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/govern"
+	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/knowledge"
 	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/miner"
 	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/vesting"
+	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/vote"
 	"github.com/EpiK-Protocol/go-epik/chain/types"
 	"github.com/EpiK-Protocol/go-epik/lib/sigs"
 	_ "github.com/EpiK-Protocol/go-epik/lib/sigs/bls"
@@ -75,6 +78,13 @@ type gatewayDepsAPI interface {
 	StateSectorGetInfo(ctx context.Context, maddr address.Address, n abi.SectorNumber, tsk types.TipSetKey) (*miner.SectorOnChainInfo, error)
 	// StateVerifiedClientStatus(ctx context.Context, addr address.Address, tsk types.TipSetKey) (*abi.StoragePower, error)
 	StateVMCirculatingSupplyInternal(context.Context, types.TipSetKey) (api.CirculatingSupply, error)
+	StateExpertInfo(context.Context, address.Address, types.TipSetKey) (*api.ExpertInfo, error)
+	StateGovernParams(context.Context, types.TipSetKey) (*govern.GovParams, error)
+	StateKnowledgeInfo(context.Context, types.TipSetKey) (*knowledge.Info, error)
+	StateListExperts(context.Context, types.TipSetKey) ([]address.Address, error)
+	StateRetrievalPledge(context.Context, address.Address, types.TipSetKey) (*api.RetrievalState, error)
+	StateVoteTally(context.Context, types.TipSetKey) (*vote.Tally, error)
+	StateVoterInfo(context.Context, address.Address, types.TipSetKey) (*vote.VoterInfo, error)
 }
 
 type GatewayAPI struct {
@@ -418,6 +428,55 @@ func (a *GatewayAPI) StateVMCirculatingSupplyInternal(ctx context.Context, tsk t
 	return a.api.StateVMCirculatingSupplyInternal(ctx, tsk)
 }
 
+func (a *GatewayAPI) StateExpertInfo(ctx context.Context, addr address.Address, tsk types.TipSetKey) (*api.ExpertInfo, error) {
+	if err := a.checkTipsetKey(ctx, tsk); err != nil {
+		return nil, err
+	}
+	return a.api.StateExpertInfo(ctx, addr, tsk)
+}
+
+func (a *GatewayAPI) StateGovernParams(ctx context.Context, tsk types.TipSetKey) (*govern.GovParams, error) {
+	if err := a.checkTipsetKey(ctx, tsk); err != nil {
+		return nil, err
+	}
+	return a.api.StateGovernParams(ctx, tsk)
+}
+
+func (a *GatewayAPI) StateKnowledgeInfo(ctx context.Context, tsk types.TipSetKey) (*knowledge.Info, error) {
+	if err := a.checkTipsetKey(ctx, tsk); err != nil {
+		return nil, err
+	}
+	return a.api.StateKnowledgeInfo(ctx, tsk)
+}
+
+func (a *GatewayAPI) StateListExperts(ctx context.Context, tsk types.TipSetKey) ([]address.Address, error) {
+	if err := a.checkTipsetKey(ctx, tsk); err != nil {
+		return nil, err
+	}
+	return a.api.StateListExperts(ctx, tsk)
+}
+
+func (a *GatewayAPI) StateRetrievalPledge(ctx context.Context, addr address.Address, tsk types.TipSetKey) (*api.RetrievalState, error) {
+	if err := a.checkTipsetKey(ctx, tsk); err != nil {
+		return nil, err
+	}
+	return a.api.StateRetrievalPledge(ctx, addr, tsk)
+}
+
+func (a *GatewayAPI) StateVoteTally(ctx context.Context, tsk types.TipSetKey) (*vote.Tally, error) {
+	if err := a.checkTipsetKey(ctx, tsk); err != nil {
+		return nil, err
+	}
+	return a.api.StateVoteTally(ctx, tsk)
+}
+
+func (a *GatewayAPI) StateVoterInfo(ctx context.Context, addr address.Address, tsk types.TipSetKey) (*vote.VoterInfo, error) {
+	if err := a.checkTipsetKey(ctx, tsk); err != nil {
+		return nil, err
+	}
+	return a.api.StateVoterInfo(ctx, addr, tsk)
+}
+
 func (a *GatewayAPI) WalletVerify(ctx context.Context, k address.Address, msg []byte, sig *crypto.Signature) (bool, error) {
 	return sigs.Verify(sig, k, msg) == nil, nil
 }
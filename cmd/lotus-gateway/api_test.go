@@ -12,6 +12,7 @@ import (
 	"github.com/EpiK-Protocol/go-epik/build"
 
 	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
 
 	"github.com/EpiK-Protocol/go-epik/chain/types/mock"
 
@@ -110,6 +111,7 @@ func TestGatewayAPIChainGetTipSetByHeight(t *testing.T) {
 type mockGatewayDepsAPI struct {
 	lk      sync.RWMutex
 	tipsets []*types.TipSet
+	experts []address.Address
 
 	gatewayDepsAPI // satisfies all interface requirements but will panic if
 	// methods are called. easier than filling out with panic stubs IMO
@@ -235,3 +237,63 @@ func (m *mockGatewayDepsAPI) StateWaitMsgLimited(ctx context.Context, msg cid.Ci
 func (m *mockGatewayDepsAPI) StateReadState(ctx context.Context, act address.Address, ts types.TipSetKey) (*api.ActorState, error) {
 	panic("implement me")
 }
+
+func (m *mockGatewayDepsAPI) StateListExperts(ctx context.Context, tsk types.TipSetKey) ([]address.Address, error) {
+	return m.experts, nil
+}
+
+// TestGatewayEpikLookback tests that the EpiK actor queries enforce the
+// lookback cap
+func TestGatewayEpikLookback(t *testing.T) {
+	ctx := context.Background()
+
+	lookbackTimestamp := uint64(time.Now().Unix()) - uint64(LookbackCap.Seconds())
+	expert, err := address.NewIDAddress(1000)
+	require.NoError(t, err)
+
+	mock := &mockGatewayDepsAPI{experts: []address.Address{expert}}
+	a := NewGatewayAPI(mock)
+
+	// Tipset height is 5, genesis is at LookbackCap - 10 epochs
+	old := mock.createTipSets(5, lookbackTimestamp-build.BlockDelaySecs*10).Key()
+
+	calls := map[string]func(types.TipSetKey) error{
+		"StateExpertInfo": func(tsk types.TipSetKey) error {
+			_, err := a.StateExpertInfo(ctx, expert, tsk)
+			return err
+		},
+		"StateGovernParams": func(tsk types.TipSetKey) error {
+			_, err := a.StateGovernParams(ctx, tsk)
+			return err
+		},
+		"StateKnowledgeInfo": func(tsk types.TipSetKey) error {
+			_, err := a.StateKnowledgeInfo(ctx, tsk)
+			return err
+		},
+		"StateListExperts": func(tsk types.TipSetKey) error {
+			_, err := a.StateListExperts(ctx, tsk)
+			return err
+		},
+		"StateRetrievalPledge": func(tsk types.TipSetKey) error {
+			_, err := a.StateRetrievalPledge(ctx, expert, tsk)
+			return err
+		},
+		"StateVoteTally": func(tsk types.TipSetKey) error {
+			_, err := a.StateVoteTally(ctx, tsk)
+			return err
+		},
+		"StateVoterInfo": func(tsk types.TipSetKey) error {
+			_, err := a.StateVoterInfo(ctx, expert, tsk)
+			return err
+		},
+	}
+	for name, call := range calls {
+		require.True(t, xerrors.Is(call(old), ErrLookbackTooLong), name)
+	}
+
+	// Tipset within the lookback cap
+	recent := mock.createTipSets(5, 0).Key()
+	experts, err := a.StateListExperts(ctx, recent)
+	require.NoError(t, err)
+	require.Equal(t, []address.Address{expert}, experts)
+}
@@ -190,11 +190,78 @@ func TestCLIDealFlow(t *testing.T) {
 	clitest.RunClientTest(t, cli.Commands, nodes.lite)
 }
 
+// TestEpikStateQueries tests that the EpiK actor queries made through the
+// gateway return the state of the full node
+func TestEpikStateQueries(t *testing.T) {
+	_ = os.Setenv("BELLMAN_NO_GPU", "1")
+	clitest.QuietMiningLogs()
+
+	blocktime := 5 * time.Millisecond
+	ctx := context.Background()
+	nodes := startNodes(ctx, t, blocktime, maxLookbackCap, maxStateWaitLookbackLimit)
+	defer nodes.closer()
+
+	gw := nodes.gateway
+	full := nodes.full
+
+	head, err := full.ChainHead(ctx)
+	require.NoError(t, err)
+	tsk := head.Key()
+
+	experts, err := gw.StateListExperts(ctx, tsk)
+	require.NoError(t, err)
+	expected, err := full.StateListExperts(ctx, tsk)
+	require.NoError(t, err)
+	require.Equal(t, expected, experts)
+
+	for _, e := range experts {
+		info, err := gw.StateExpertInfo(ctx, e, tsk)
+		require.NoError(t, err)
+		expected, err := full.StateExpertInfo(ctx, e, tsk)
+		require.NoError(t, err)
+		require.Equal(t, expected.Owner, info.Owner)
+		require.Equal(t, expected.Status, info.Status)
+	}
+
+	tally, err := gw.StateVoteTally(ctx, tsk)
+	require.NoError(t, err)
+	expectedTally, err := full.StateVoteTally(ctx, tsk)
+	require.NoError(t, err)
+	require.Equal(t, expectedTally, tally)
+
+	knowledge, err := gw.StateKnowledgeInfo(ctx, tsk)
+	require.NoError(t, err)
+	expectedKnowledge, err := full.StateKnowledgeInfo(ctx, tsk)
+	require.NoError(t, err)
+	require.Equal(t, expectedKnowledge, knowledge)
+
+	params, err := gw.StateGovernParams(ctx, tsk)
+	require.NoError(t, err)
+	expectedParams, err := full.StateGovernParams(ctx, tsk)
+	require.NoError(t, err)
+	require.Equal(t, expectedParams, params)
+
+	// The full node starts with a wallet
+	wallet, err := full.WalletDefaultAddress(ctx)
+	require.NoError(t, err)
+
+	voter, err := gw.StateVoterInfo(ctx, wallet, tsk)
+	expectedVoter, expectedErr := full.StateVoterInfo(ctx, wallet, tsk)
+	require.Equal(t, expectedErr == nil, err == nil)
+	require.Equal(t, expectedVoter, voter)
+
+	pledge, err := gw.StateRetrievalPledge(ctx, wallet, tsk)
+	expectedPledge, expectedErr := full.StateRetrievalPledge(ctx, wallet, tsk)
+	require.Equal(t, expectedErr == nil, err == nil)
+	require.Equal(t, expectedPledge, pledge)
+}
+
 type testNodes struct {
-	lite   test.TestNode
-	full   test.TestNode
-	miner  test.TestStorageNode
-	closer jsonrpc.ClientCloser
+	lite    test.TestNode
+	full    test.TestNode
+	miner   test.TestStorageNode
+	gateway api.GatewayAPI
+	closer  jsonrpc.ClientCloser
 }
 
 func startNodesWithFunds(
@@ -229,6 +296,7 @@ func startNodes(
 	stateWaitLookbackLimit abi.ChainEpoch,
 ) *testNodes {
 	var closer jsonrpc.ClientCloser
+	var gapi api.GatewayAPI
 
 	// Create one miner and two full nodes.
 	// - Put a gateway server in front of full node 1
@@ -249,7 +317,6 @@ func startNodes(
 				require.NoError(t, err)
 
 				// Create a gateway client API that connects to the gateway server
-				var gapi api.GatewayAPI
 				gapi, closer, err = client.NewGatewayRPC(ctx, addr, nil)
 				require.NoError(t, err)
 
@@ -284,7 +351,7 @@ func startNodes(
 	bm.MineBlocks()
 	t.Cleanup(bm.Stop)
 
-	return &testNodes{lite: lite, full: full, miner: miner, closer: closer}
+	return &testNodes{lite: lite, full: full, miner: miner, gateway: gapi, closer: closer}
 }
 
 func sendFunds(ctx context.Context, fromNode test.TestNode, fromAddr address.Address, toAddr address.Address, amt types.BigInt) error {
@@ -0,0 +1,52 @@
+package expertwatch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+const webhookTimeout = 30 * time.Second
+
+// notifyHandler posts the events received on ch to the webhook, until ctx is done.
+func notifyHandler(ctx context.Context, url string, ch chan Event) {
+	client := &http.Client{Timeout: webhookTimeout}
+	for {
+		select {
+		case evt := <-ch:
+			if err := postEvent(ctx, client, url, evt); err != nil {
+				log.Warnf("notifying %s event of %s: %s", evt.Type, evt.Expert, err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func postEvent(ctx context.Context, client *http.Client, url string, evt Event) error {
+	b, err := json.Marshal(evt)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	if resp.StatusCode/100 != 2 {
+		return xerrors.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
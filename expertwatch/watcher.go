@@ -0,0 +1,256 @@
+package expertwatch
+
+import (
+	"context"
+	"strings"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	logging "github.com/ipfs/go-log/v2"
+	"go.uber.org/fx"
+	"golang.org/x/xerrors"
+
+	"github.com/EpiK-Protocol/go-epik/api"
+	"github.com/EpiK-Protocol/go-epik/build"
+	"github.com/EpiK-Protocol/go-epik/chain/events"
+	"github.com/EpiK-Protocol/go-epik/chain/types"
+	"github.com/EpiK-Protocol/go-epik/journal"
+	"github.com/EpiK-Protocol/go-epik/node/config"
+	"github.com/EpiK-Protocol/go-epik/node/impl/full"
+	"github.com/EpiK-Protocol/go-epik/node/modules/helpers"
+)
+
+var log = logging.Logger("expertwatch")
+
+// Event types emitted by the watcher
+const (
+	EventStatusChanged = "status_changed"
+	EventImplicated    = "implicated"
+	EventVoteWarning   = "vote_warning"
+	EventVoteShortfall = "vote_shortfall"
+	EventVoteRecovered = "vote_recovered"
+)
+
+// Event is a change of a watched expert. Events are recorded in the journal
+// and posted to the configured webhook.
+type Event struct {
+	Type            string
+	Expert          address.Address
+	Height          abi.ChainEpoch
+	Status          string
+	PrevStatus      string
+	LostEpoch       abi.ChainEpoch
+	ImplicatedTimes uint64
+	CurrentVotes    abi.TokenAmount
+	RequiredVotes   abi.TokenAmount
+	// VotesChange is the change of the current votes since the previous tipset
+	VotesChange abi.TokenAmount
+}
+
+// API are the dependencies needed to run the expert watcher
+type API struct {
+	fx.In
+
+	full.ChainAPI
+	full.StateAPI
+}
+
+type watcherAPI interface {
+	StateLookupID(context.Context, address.Address, types.TipSetKey) (address.Address, error)
+	StateExpertInfo(context.Context, address.Address, types.TipSetKey) (*api.ExpertInfo, error)
+}
+
+type voteLevel int
+
+const (
+	voteOK voteLevel = iota
+	voteWarning
+	voteShortfall
+)
+
+type watcher struct {
+	ctx     context.Context
+	api     watcherAPI
+	experts []address.Address
+	margin  uint64
+
+	journal journal.Journal
+	evtType journal.EventType
+	notify  chan Event
+}
+
+// WatchExperts follows the configured experts on chain and reports their
+// status transitions, implications and vote shortfalls.
+func WatchExperts(cfg config.ExpertWatch) func(mctx helpers.MetricsCtx, lc fx.Lifecycle, j journal.Journal, a API) error {
+	return func(mctx helpers.MetricsCtx, lc fx.Lifecycle, j journal.Journal, a API) error {
+		ctx := helpers.LifecycleCtx(mctx, lc)
+
+		experts := make([]address.Address, 0, len(cfg.Experts))
+		for _, s := range cfg.Experts {
+			addr, err := address.NewFromString(s)
+			if err != nil {
+				return xerrors.Errorf("parsing watched expert %q: %w", s, err)
+			}
+			experts = append(experts, addr)
+		}
+
+		lc.Append(fx.Hook{
+			OnStart: func(context.Context) error {
+				w := newWatcher(ctx, &a, experts, cfg.VoteWarningMargin, j)
+				if cfg.Webhook != "" {
+					w.notify = make(chan Event, 64)
+					go notifyHandler(ctx, cfg.Webhook, w.notify)
+				}
+
+				ev := events.NewEvents(ctx, &a)
+				return ev.StateChanged(w.check, w.stateChanged, w.revert, int(build.MessageConfidence), events.NoTimeout, w.match)
+			},
+		})
+		return nil
+	}
+}
+
+func newWatcher(ctx context.Context, api watcherAPI, experts []address.Address, margin uint64, j journal.Journal) *watcher {
+	return &watcher{
+		ctx:     ctx,
+		api:     api,
+		experts: experts,
+		margin:  margin,
+		journal: j,
+		evtType: j.RegisterEventType("expertwatch", "expert_change"),
+	}
+}
+
+func (w *watcher) check(ts *types.TipSet) (done bool, more bool, err error) {
+	return false, true, nil
+}
+
+func (w *watcher) revert(ctx context.Context, ts *types.TipSet) error {
+	return nil
+}
+
+// match compares the watched experts between two tipsets.
+func (w *watcher) match(oldTs, newTs *types.TipSet) (bool, events.StateChange, error) {
+	var evts []Event
+	for _, addr := range w.experts {
+		idAddr, err := w.api.StateLookupID(w.ctx, addr, newTs.Key())
+		if err != nil {
+			// not created yet
+			continue
+		}
+
+		cur, err := w.api.StateExpertInfo(w.ctx, idAddr, newTs.Key())
+		if err != nil {
+			return false, nil, xerrors.Errorf("loading expert %s: %w", addr, err)
+		}
+		prev, err := w.api.StateExpertInfo(w.ctx, idAddr, oldTs.Key())
+		if err != nil {
+			if !strings.Contains(err.Error(), types.ErrActorNotFound.Error()) {
+				return false, nil, xerrors.Errorf("loading expert %s at %s: %w", addr, oldTs.Key(), err)
+			}
+			// created in the new tipset
+			prev = nil
+		}
+
+		evts = append(evts, diffExpert(idAddr, prev, cur, w.margin, newTs.Height())...)
+	}
+
+	if len(evts) == 0 {
+		return false, nil, nil
+	}
+	return true, evts, nil
+}
+
+func (w *watcher) stateChanged(oldTs, newTs *types.TipSet, states events.StateChange, curH abi.ChainEpoch) (more bool, err error) {
+	evts, ok := states.([]Event)
+	if !ok {
+		return true, nil
+	}
+
+	for _, evt := range evts {
+		w.emit(evt)
+	}
+	return true, nil
+}
+
+func (w *watcher) emit(evt Event) {
+	log.Warnw("expert changed", "type", evt.Type, "expert", evt.Expert, "height", evt.Height, "status", evt.Status,
+		"votes", types.EPK(evt.CurrentVotes), "required", types.EPK(evt.RequiredVotes))
+
+	w.journal.RecordEvent(w.evtType, func() interface{} {
+		return evt
+	})
+
+	if w.notify == nil {
+		return
+	}
+	select {
+	case w.notify <- evt:
+	default:
+		log.Warnf("notification queue full, dropping %s event of %s", evt.Type, evt.Expert)
+	}
+}
+
+// diffExpert returns the events between two states of an expert. prev is nil
+// for experts created in the new tipset.
+func diffExpert(addr address.Address, prev, cur *api.ExpertInfo, margin uint64, height abi.ChainEpoch) []Event {
+	newEvent := func(typ string) Event {
+		evt := Event{
+			Type:            typ,
+			Expert:          addr,
+			Height:          height,
+			Status:          cur.StatusDesc,
+			LostEpoch:       cur.LostEpoch,
+			ImplicatedTimes: cur.ImplicatedTimes,
+			CurrentVotes:    cur.CurrentVotes,
+			RequiredVotes:   cur.RequiredVotes,
+			VotesChange:     big.Zero(),
+		}
+		if prev != nil {
+			evt.PrevStatus = prev.StatusDesc
+			if prev.CurrentVotes.Int != nil && cur.CurrentVotes.Int != nil {
+				evt.VotesChange = big.Sub(cur.CurrentVotes, prev.CurrentVotes)
+			}
+		}
+		return evt
+	}
+
+	var out []Event
+	if prev == nil || prev.Status != cur.Status {
+		out = append(out, newEvent(EventStatusChanged))
+	}
+	if prev != nil && cur.ImplicatedTimes > prev.ImplicatedTimes {
+		out = append(out, newEvent(EventImplicated))
+	}
+
+	prevLevel := voteOK
+	if prev != nil {
+		prevLevel = votesLevel(prev, margin)
+	}
+	switch curLevel := votesLevel(cur, margin); {
+	case curLevel == prevLevel:
+	case curLevel == voteShortfall:
+		out = append(out, newEvent(EventVoteShortfall))
+	case curLevel == voteWarning:
+		out = append(out, newEvent(EventVoteWarning))
+	case prev != nil:
+		out = append(out, newEvent(EventVoteRecovered))
+	}
+	return out
+}
+
+func votesLevel(info *api.ExpertInfo, margin uint64) voteLevel {
+	if info.CurrentVotes.Int == nil || info.RequiredVotes.Int == nil || info.RequiredVotes.IsZero() {
+		return voteOK
+	}
+	if info.CurrentVotes.LessThan(info.RequiredVotes) {
+		return voteShortfall
+	}
+
+	warn := big.Div(big.Mul(info.RequiredVotes, big.NewIntUnsigned(100+margin)), big.NewInt(100))
+	if info.CurrentVotes.LessThan(warn) {
+		return voteWarning
+	}
+	return voteOK
+}
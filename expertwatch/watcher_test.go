@@ -0,0 +1,53 @@
+package expertwatch
+
+import (
+	"testing"
+
+	"github.com/filecoin-project/go-state-types/big"
+	expert2 "github.com/filecoin-project/specs-actors/v2/actors/builtin/expert"
+	tutils "github.com/filecoin-project/specs-actors/v2/support/testing"
+	"github.com/stretchr/testify/require"
+
+	"github.com/EpiK-Protocol/go-epik/api"
+)
+
+func expertInfo(status expert2.ExpertState, implicated uint64, votes, required int64) *api.ExpertInfo {
+	info := &api.ExpertInfo{}
+	info.Status = status
+	info.ImplicatedTimes = implicated
+	info.CurrentVotes = big.NewInt(votes)
+	info.RequiredVotes = big.NewInt(required)
+	return info
+}
+
+func eventTypes(evts []Event) []string {
+	out := make([]string, 0, len(evts))
+	for _, evt := range evts {
+		out = append(out, evt.Type)
+	}
+	return out
+}
+
+func TestDiffExpert(t *testing.T) {
+	addr := tutils.NewIDAddr(t, 100)
+
+	// unchanged
+	evts := diffExpert(addr, expertInfo(expert2.ExpertStateQualified, 0, 200, 100), expertInfo(expert2.ExpertStateQualified, 0, 200, 100), 10, 10)
+	require.Empty(t, evts)
+
+	// within the warning margin
+	evts = diffExpert(addr, expertInfo(expert2.ExpertStateQualified, 0, 200, 100), expertInfo(expert2.ExpertStateQualified, 0, 105, 100), 10, 10)
+	require.Equal(t, []string{EventVoteWarning}, eventTypes(evts))
+	require.Equal(t, big.NewInt(-95), evts[0].VotesChange)
+
+	// short of votes and disqualified
+	evts = diffExpert(addr, expertInfo(expert2.ExpertStateQualified, 0, 105, 100), expertInfo(expert2.ExpertStateUnqualified, 0, 90, 100), 10, 11)
+	require.Equal(t, []string{EventStatusChanged, EventVoteShortfall}, eventTypes(evts))
+
+	evts = diffExpert(addr, expertInfo(expert2.ExpertStateUnqualified, 0, 90, 100), expertInfo(expert2.ExpertStateQualified, 1, 150, 100), 10, 12)
+	require.Equal(t, []string{EventStatusChanged, EventImplicated, EventVoteRecovered}, eventTypes(evts))
+
+	// newly created
+	evts = diffExpert(addr, nil, expertInfo(expert2.ExpertStateRegistered, 0, 0, 100), 10, 13)
+	require.Equal(t, []string{EventStatusChanged, EventVoteShortfall}, eventTypes(evts))
+}
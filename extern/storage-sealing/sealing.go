@@ -236,6 +236,12 @@ func (m *Sealing) Address() address.Address {
 	return m.maddr
 }
 
+// SealingSectors returns the number of sectors in the sealing pipeline,
+// including staging and failed ones
+func (m *Sealing) SealingSectors() uint64 {
+	return m.stats.curSealing()
+}
+
 func getDealPerSectorLimit(size abi.SectorSize) (int, error) {
 	if size < 64<<30 {
 		return 256, nil
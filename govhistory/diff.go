@@ -0,0 +1,365 @@
+package govhistory
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/exitcode"
+	builtin2 "github.com/filecoin-project/specs-actors/v2/actors/builtin"
+	expertfund2 "github.com/filecoin-project/specs-actors/v2/actors/builtin/expertfund"
+	govern2 "github.com/filecoin-project/specs-actors/v2/actors/builtin/govern"
+	power2 "github.com/filecoin-project/specs-actors/v2/actors/builtin/power"
+	"github.com/ipfs/go-cid"
+	cbg "github.com/whyrusleeping/cbor-gen"
+	"golang.org/x/xerrors"
+
+	"github.com/EpiK-Protocol/go-epik/api"
+	"github.com/EpiK-Protocol/go-epik/chain/actors/adt"
+	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/expertfund"
+	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/govern"
+	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/knowledge"
+	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/market"
+	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/power"
+	"github.com/EpiK-Protocol/go-epik/chain/state"
+	"github.com/EpiK-Protocol/go-epik/chain/stmgr"
+	"github.com/EpiK-Protocol/go-epik/chain/store"
+	"github.com/EpiK-Protocol/go-epik/chain/types"
+)
+
+// Names of the governance params, as in govern.GovParams
+const (
+	ParamMinersPoStRatio      = "MinersPoStRatio"
+	ParamMinersPledgePeriod   = "MinersPledgePeriod"
+	ParamMarketInitialQuota   = "MarketInitialQuota"
+	ParamExpertDataThreshold  = "ExpertDataThreshold"
+	ParamExpertDailyThreshold = "ExpertDailyThreshold"
+	ParamKnowledgePayee       = "KnowledgePayee"
+	// ParamAuthority is the param of the grants and revocations of authorities
+	ParamAuthority = "Authority"
+)
+
+// governedMethods are the methods changing the params stored by each actor.
+var governedMethods = map[address.Address][]abi.MethodNum{
+	power.Address:      {builtin2.MethodsPower.ChangeWdPoStRatio, builtin2.MethodsPower.ChangePledgeReleasePeriod},
+	market.Address:     {builtin2.MethodsMarket.SetInitialQuota},
+	expertfund.Address: {builtin2.MethodsExpertFunds.ChangeThreshold},
+	knowledge.Address:  {builtin2.MethodsKnowledge.ChangePayee},
+	govern.Address:     {builtin2.MethodsGovern.Grant, builtin2.MethodsGovern.Revoke},
+}
+
+// paramMethods are the methods changing each param.
+var paramMethods = map[string][]abi.MethodNum{
+	ParamMinersPoStRatio:      {builtin2.MethodsPower.ChangeWdPoStRatio},
+	ParamMinersPledgePeriod:   {builtin2.MethodsPower.ChangePledgeReleasePeriod},
+	ParamMarketInitialQuota:   {builtin2.MethodsMarket.SetInitialQuota},
+	ParamExpertDataThreshold:  {builtin2.MethodsExpertFunds.ChangeThreshold},
+	ParamExpertDailyThreshold: {builtin2.MethodsExpertFunds.ChangeThreshold},
+	ParamKnowledgePayee:       {builtin2.MethodsKnowledge.ChangePayee},
+	ParamAuthority:            {builtin2.MethodsGovern.Grant, builtin2.MethodsGovern.Revoke},
+}
+
+// paramLoaders format the params stored by each actor.
+var paramLoaders = map[address.Address]func(adt.Store, *types.Actor) (map[string]string, error){
+	power.Address: func(s adt.Store, act *types.Actor) (map[string]string, error) {
+		st, err := power.Load(s, act)
+		if err != nil {
+			return nil, err
+		}
+		ratio, err := st.PoStRatio()
+		if err != nil {
+			return nil, err
+		}
+		period, err := st.PledgeReleasePeriod()
+		if err != nil {
+			return nil, err
+		}
+		return map[string]string{
+			ParamMinersPoStRatio:    fmt.Sprintf("%d (effective at epoch %d)", ratio.Ratio, ratio.EffectiveEpoch),
+			ParamMinersPledgePeriod: fmt.Sprint(period),
+		}, nil
+	},
+	market.Address: func(s adt.Store, act *types.Actor) (map[string]string, error) {
+		st, err := market.Load(s, act)
+		if err != nil {
+			return nil, err
+		}
+		quotas, err := st.Quotas()
+		if err != nil {
+			return nil, err
+		}
+		return map[string]string{
+			ParamMarketInitialQuota: fmt.Sprint(quotas.InitialQuota()),
+		}, nil
+	},
+	expertfund.Address: func(s adt.Store, act *types.Actor) (map[string]string, error) {
+		st, err := expertfund.Load(s, act)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]string{
+			ParamExpertDataThreshold:  fmt.Sprint(st.DataThreshold()),
+			ParamExpertDailyThreshold: fmt.Sprint(st.DailyThreshold()),
+		}, nil
+	},
+	knowledge.Address: func(s adt.Store, act *types.Actor) (map[string]string, error) {
+		st, err := knowledge.Load(s, act)
+		if err != nil {
+			return nil, err
+		}
+		info, err := st.Info()
+		if err != nil {
+			return nil, err
+		}
+		return map[string]string{
+			ParamKnowledgePayee: info.Payee.String(),
+		}, nil
+	},
+}
+
+// diffTipSets returns the governance changes made by the execution of
+// parent, which led to the parent state of ts.
+func diffTipSets(ctx context.Context, cs *store.ChainStore, sm *stmgr.StateManager, parent, ts *types.TipSet) ([]*api.GovernChange, error) {
+	pre, err := sm.ParentState(parent)
+	if err != nil {
+		return nil, xerrors.Errorf("loading state of tipset %d: %w", parent.Height(), err)
+	}
+	cur, err := sm.ParentState(ts)
+	if err != nil {
+		return nil, xerrors.Errorf("loading state of tipset %d: %w", ts.Height(), err)
+	}
+
+	s := cs.ActorStore(ctx)
+	var changes []*api.GovernChange
+	for addr := range governedMethods {
+		preAct, curAct, err := loadActors(pre, cur, addr)
+		if err != nil {
+			return nil, err
+		}
+		if preAct.Head == curAct.Head {
+			continue
+		}
+
+		var actorChanges []*api.GovernChange
+		if addr == govern.Address {
+			actorChanges, err = diffGovernors(s, preAct, curAct)
+		} else {
+			actorChanges, err = diffParams(s, paramLoaders[addr], preAct, curAct)
+		}
+		if err != nil {
+			return nil, xerrors.Errorf("diffing params of %s: %w", addr, err)
+		}
+		for _, c := range actorChanges {
+			c.Epoch = parent.Height()
+			c.Actor = addr
+		}
+		changes = append(changes, actorChanges...)
+	}
+	if len(changes) == 0 {
+		return nil, nil
+	}
+
+	// find the messages responsible for the changes
+	_, trace, err := sm.ExecutionTrace(ctx, parent)
+	if err != nil {
+		return nil, xerrors.Errorf("tracing execution of tipset %d: %w", parent.Height(), err)
+	}
+	for _, c := range changes {
+		if msg, governor, ok := findGovernedCall(trace, c, cur.LookupID); ok {
+			c.Message = msg
+			c.Governor = governor
+		}
+	}
+
+	sortChanges(changes)
+	return changes, nil
+}
+
+func loadActors(pre, cur *state.StateTree, addr address.Address) (*types.Actor, *types.Actor, error) {
+	preAct, err := pre.GetActor(addr)
+	if err != nil {
+		return nil, nil, xerrors.Errorf("loading actor %s: %w", addr, err)
+	}
+	curAct, err := cur.GetActor(addr)
+	if err != nil {
+		return nil, nil, xerrors.Errorf("loading actor %s: %w", addr, err)
+	}
+	return preAct, curAct, nil
+}
+
+func diffParams(s adt.Store, load func(adt.Store, *types.Actor) (map[string]string, error), preAct, curAct *types.Actor) ([]*api.GovernChange, error) {
+	preParams, err := load(s, preAct)
+	if err != nil {
+		return nil, err
+	}
+	curParams, err := load(s, curAct)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []*api.GovernChange
+	for param, v := range curParams {
+		if preParams[param] != v {
+			out = append(out, &api.GovernChange{
+				Param: param,
+				Old:   preParams[param],
+				New:   v,
+			})
+		}
+	}
+	return out, nil
+}
+
+func diffGovernors(s adt.Store, preAct, curAct *types.Actor) ([]*api.GovernChange, error) {
+	pre, err := govern.Load(s, preAct)
+	if err != nil {
+		return nil, err
+	}
+	cur, err := govern.Load(s, curAct)
+	if err != nil {
+		return nil, err
+	}
+
+	diff, err := govern.DiffGovernors(pre, cur)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []*api.GovernChange
+	for _, g := range diff.Added {
+		out = append(out, &api.GovernChange{Param: ParamAuthority, Grantee: g.Address, New: describeAuthorities(g.Authorities)})
+	}
+	for _, g := range diff.Modified {
+		out = append(out, &api.GovernChange{Param: ParamAuthority, Grantee: g.To.Address, Old: describeAuthorities(g.From.Authorities), New: describeAuthorities(g.To.Authorities)})
+	}
+	for _, g := range diff.Removed {
+		out = append(out, &api.GovernChange{Param: ParamAuthority, Grantee: g.Address, Old: describeAuthorities(g.Authorities)})
+	}
+	return out, nil
+}
+
+func describeAuthorities(auths []govern.Authority) string {
+	descs := make([]string, 0, len(auths))
+	for _, a := range auths {
+		descs = append(descs, fmt.Sprintf("%s%v", builtin2.ActorNameByCode(a.ActorCodeID), a.Methods))
+	}
+	sort.Strings(descs)
+	return strings.Join(descs, " ")
+}
+
+type governedCall struct {
+	msg  cid.Cid
+	from address.Address
+}
+
+// findGovernedCall finds the call responsible for a change in the execution
+// trace, and returns the CID of the message which made it and the address of
+// its caller, a governor or a multisig governor.
+//
+// Several governed calls may be executed in a tipset, so it's the last
+// successful call of a method changing the param, whose params match the
+// change. If the params of none of them match, the last of them is returned.
+func findGovernedCall(trace []*api.InvocResult, c *api.GovernChange, lookupID func(address.Address) (address.Address, error)) (cid.Cid, address.Address, bool) {
+	var matched, last *governedCall
+	for _, ir := range trace {
+		walkTrace(&ir.ExecutionTrace, func(et *types.ExecutionTrace) {
+			if et.Msg == nil || et.Msg.To != c.Actor || et.MsgRct == nil || et.MsgRct.ExitCode != exitcode.Ok || !changesParam(c.Param, et.Msg.Method) {
+				return
+			}
+			last = &governedCall{msg: ir.MsgCid, from: et.Msg.From}
+			if paramsMatch(c, et.Msg.Params, lookupID) {
+				matched = last
+			}
+		})
+	}
+
+	if matched == nil {
+		matched = last
+	}
+	if matched == nil {
+		return cid.Undef, address.Undef, false
+	}
+	return matched.msg, matched.from, true
+}
+
+// walkTrace calls cb on the calls of the trace, in the order of execution.
+func walkTrace(et *types.ExecutionTrace, cb func(*types.ExecutionTrace)) {
+	cb(et)
+	for i := range et.Subcalls {
+		walkTrace(&et.Subcalls[i], cb)
+	}
+}
+
+func changesParam(param string, method abi.MethodNum) bool {
+	for _, m := range paramMethods[param] {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+// paramsMatch tells whether the params of a call set the new value of the
+// change, or for authorities, whether they are those of the grantee.
+func paramsMatch(c *api.GovernChange, params []byte, lookupID func(address.Address) (address.Address, error)) bool {
+	r := bytes.NewReader(params)
+	switch c.Param {
+	case ParamMinersPoStRatio:
+		var p power2.ChangeWdPoStRatioParams
+		if err := p.UnmarshalCBOR(r); err != nil {
+			return false
+		}
+		return strings.HasPrefix(c.New, fmt.Sprintf("%d ", p.Ratio))
+	case ParamMinersPledgePeriod:
+		var p power2.ChangePledgeParams
+		if err := p.UnmarshalCBOR(r); err != nil {
+			return false
+		}
+		return fmt.Sprint(p.Period) == c.New
+	case ParamMarketInitialQuota:
+		var q cbg.CborInt
+		if err := q.UnmarshalCBOR(r); err != nil {
+			return false
+		}
+		return fmt.Sprint(int64(q)) == c.New
+	case ParamExpertDataThreshold, ParamExpertDailyThreshold:
+		var p expertfund2.ChangeThresholdParams
+		if err := p.UnmarshalCBOR(r); err != nil {
+			return false
+		}
+		if c.Param == ParamExpertDataThreshold {
+			return fmt.Sprint(p.DataStoreThreshold) == c.New
+		}
+		return fmt.Sprint(p.DailyImportThreshold) == c.New
+	case ParamKnowledgePayee:
+		var payee address.Address
+		if err := payee.UnmarshalCBOR(r); err != nil {
+			return false
+		}
+		return payee.String() == c.New
+	case ParamAuthority:
+		var p govern2.GrantOrRevokeParams
+		if err := p.UnmarshalCBOR(r); err != nil {
+			return false
+		}
+		if p.Governor == c.Grantee {
+			return true
+		}
+		id, err := lookupID(p.Governor)
+		return err == nil && id == c.Grantee
+	}
+	return false
+}
+
+func sortChanges(changes []*api.GovernChange) {
+	sort.SliceStable(changes, func(i, j int) bool {
+		if changes[i].Epoch != changes[j].Epoch {
+			return changes[i].Epoch < changes[j].Epoch
+		}
+		return changes[i].Param < changes[j].Param
+	})
+}
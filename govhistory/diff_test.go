@@ -0,0 +1,133 @@
+package govhistory
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/cbor"
+	"github.com/filecoin-project/go-state-types/exitcode"
+	builtin2 "github.com/filecoin-project/specs-actors/v2/actors/builtin"
+	govern2 "github.com/filecoin-project/specs-actors/v2/actors/builtin/govern"
+	power2 "github.com/filecoin-project/specs-actors/v2/actors/builtin/power"
+	tutils "github.com/filecoin-project/specs-actors/v2/support/testing"
+	"github.com/stretchr/testify/require"
+
+	"github.com/EpiK-Protocol/go-epik/api"
+	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/govern"
+	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/knowledge"
+	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/power"
+	"github.com/EpiK-Protocol/go-epik/chain/types"
+)
+
+func TestFindGovernedCall(t *testing.T) {
+	governor := tutils.NewIDAddr(t, 100)
+	msig := tutils.NewIDAddr(t, 101)
+	signer := tutils.NewIDAddr(t, 102)
+
+	direct := &api.InvocResult{
+		MsgCid: tutils.MakeCID("direct", nil),
+		ExecutionTrace: types.ExecutionTrace{
+			Msg:    &types.Message{From: governor, To: knowledge.Address, Method: builtin2.MethodsKnowledge.ChangePayee},
+			MsgRct: &types.MessageReceipt{ExitCode: exitcode.Ok},
+		},
+	}
+	failed := &api.InvocResult{
+		MsgCid: tutils.MakeCID("failed", nil),
+		ExecutionTrace: types.ExecutionTrace{
+			Msg:    &types.Message{From: governor, To: power.Address, Method: builtin2.MethodsPower.ChangeWdPoStRatio},
+			MsgRct: &types.MessageReceipt{ExitCode: exitcode.ErrForbidden},
+		},
+	}
+	// a multisig governor approving a proposal calling the power actor
+	approved := &api.InvocResult{
+		MsgCid: tutils.MakeCID("approved", nil),
+		ExecutionTrace: types.ExecutionTrace{
+			Msg:    &types.Message{From: signer, To: msig, Method: builtin2.MethodsMultisig.Approve},
+			MsgRct: &types.MessageReceipt{ExitCode: exitcode.Ok},
+			Subcalls: []types.ExecutionTrace{{
+				Msg:    &types.Message{From: msig, To: power.Address, Method: builtin2.MethodsPower.ChangePledgeReleasePeriod},
+				MsgRct: &types.MessageReceipt{ExitCode: exitcode.Ok},
+			}},
+		},
+	}
+	trace := []*api.InvocResult{direct, failed, approved}
+
+	payee := &api.GovernChange{Actor: knowledge.Address, Param: ParamKnowledgePayee}
+	period := &api.GovernChange{Actor: power.Address, Param: ParamMinersPledgePeriod}
+	ratio := &api.GovernChange{Actor: power.Address, Param: ParamMinersPoStRatio}
+
+	msg, from, ok := findGovernedCall(trace, payee, noLookup)
+	require.True(t, ok)
+	require.Equal(t, direct.MsgCid, msg)
+	require.Equal(t, governor, from)
+
+	msg, from, ok = findGovernedCall(trace, period, noLookup)
+	require.True(t, ok)
+	require.Equal(t, approved.MsgCid, msg)
+	require.Equal(t, msig, from)
+
+	// the ratio change failed
+	_, _, ok = findGovernedCall(trace, ratio, noLookup)
+	require.False(t, ok)
+
+	_, _, ok = findGovernedCall(trace[:2], period, noLookup)
+	require.False(t, ok)
+}
+
+func TestFindGovernedCallsInTipSet(t *testing.T) {
+	supervisor := tutils.NewIDAddr(t, 100)
+	governor := tutils.NewIDAddr(t, 101)
+	alice := tutils.NewIDAddr(t, 102)
+	bob := tutils.NewIDAddr(t, 103)
+	bobKey := tutils.NewBLSAddr(t, 103)
+	payee := tutils.NewIDAddr(t, 104)
+
+	call := func(name string, from, to address.Address, method abi.MethodNum, params cbor.Marshaler) *api.InvocResult {
+		buf := new(bytes.Buffer)
+		require.NoError(t, params.MarshalCBOR(buf))
+		return &api.InvocResult{
+			MsgCid: tutils.MakeCID(name, nil),
+			ExecutionTrace: types.ExecutionTrace{
+				Msg:    &types.Message{From: from, To: to, Method: method, Params: buf.Bytes()},
+				MsgRct: &types.MessageReceipt{ExitCode: exitcode.Ok},
+			},
+		}
+	}
+
+	// two grants and two governed calls to the same actors in a tipset
+	grantAlice := call("grant alice", supervisor, govern.Address, builtin2.MethodsGovern.Grant, &govern2.GrantOrRevokeParams{Governor: alice, All: true})
+	grantBob := call("grant bob", supervisor, govern.Address, builtin2.MethodsGovern.Grant, &govern2.GrantOrRevokeParams{Governor: bobKey, All: true})
+	changePayee := call("payee", governor, knowledge.Address, builtin2.MethodsKnowledge.ChangePayee, &payee)
+	changeRatio := call("ratio", alice, power.Address, builtin2.MethodsPower.ChangeWdPoStRatio, &power2.ChangeWdPoStRatioParams{Ratio: 5})
+	changePeriod := call("period", governor, power.Address, builtin2.MethodsPower.ChangePledgeReleasePeriod, &power2.ChangePledgeParams{Period: 100})
+	trace := []*api.InvocResult{grantAlice, grantBob, changePayee, changeRatio, changePeriod}
+
+	lookupID := func(a address.Address) (address.Address, error) {
+		if a == bobKey {
+			return bob, nil
+		}
+		return a, nil
+	}
+
+	for _, tc := range []struct {
+		change *api.GovernChange
+		msg    *api.InvocResult
+	}{
+		{&api.GovernChange{Actor: govern.Address, Param: ParamAuthority, Grantee: alice}, grantAlice},
+		{&api.GovernChange{Actor: govern.Address, Param: ParamAuthority, Grantee: bob}, grantBob},
+		{&api.GovernChange{Actor: knowledge.Address, Param: ParamKnowledgePayee, New: payee.String()}, changePayee},
+		{&api.GovernChange{Actor: power.Address, Param: ParamMinersPoStRatio, New: "5 (effective at epoch 10)"}, changeRatio},
+		{&api.GovernChange{Actor: power.Address, Param: ParamMinersPledgePeriod, New: "100"}, changePeriod},
+	} {
+		msg, from, ok := findGovernedCall(trace, tc.change, lookupID)
+		require.True(t, ok, tc.change.Param)
+		require.Equal(t, tc.msg.MsgCid, msg, tc.change.Param)
+		require.Equal(t, tc.msg.ExecutionTrace.Msg.From, from, tc.change.Param)
+	}
+}
+
+func noLookup(a address.Address) (address.Address, error) {
+	return a, nil
+}
@@ -0,0 +1,308 @@
+package govhistory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/namespace"
+	"github.com/ipfs/go-datastore/query"
+	logging "github.com/ipfs/go-log/v2"
+	"go.uber.org/fx"
+	"golang.org/x/xerrors"
+
+	"github.com/EpiK-Protocol/go-epik/api"
+	"github.com/EpiK-Protocol/go-epik/chain/stmgr"
+	"github.com/EpiK-Protocol/go-epik/chain/store"
+	"github.com/EpiK-Protocol/go-epik/chain/types"
+	"github.com/EpiK-Protocol/go-epik/node/modules/dtypes"
+	"github.com/EpiK-Protocol/go-epik/node/modules/helpers"
+)
+
+var log = logging.Logger("govhistory")
+
+var (
+	heightKey   = datastore.NewKey("/height")
+	headKey     = datastore.NewKey("/head")
+	epochPrefix = datastore.NewKey("/epoch")
+)
+
+// Index records the changes of the governance params and of the governor
+// authorities into the metadata datastore, as tipsets are applied and
+// reverted. On start it catches up from the last indexed tipset, or from
+// genesis on first run.
+//
+// Head changes are coalesced: the chain store only hands over the latest
+// head, and the index walks from its last indexed tipset to it, so that a
+// slow catch-up never holds up the chain store.
+type Index struct {
+	ds datastore.Batching
+	cs *store.ChainStore
+	sm *stmgr.StateManager
+
+	ctx context.Context
+
+	lk     sync.Mutex
+	head   *types.TipSet // latest head not yet indexed
+	notify chan struct{}
+}
+
+func NewIndex(mctx helpers.MetricsCtx, lc fx.Lifecycle, ds dtypes.MetadataDS, cs *store.ChainStore, sm *stmgr.StateManager) *Index {
+	ctx, cancel := context.WithCancel(helpers.LifecycleCtx(mctx, lc))
+
+	idx := &Index{
+		ds:     namespace.Wrap(ds, datastore.NewKey("/govhistory")),
+		cs:     cs,
+		sm:     sm,
+		ctx:    ctx,
+		notify: make(chan struct{}, 1),
+	}
+
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			idx.setTarget(cs.GetHeaviestTipSet())
+			cs.SubscribeHeadChanges(idx.onHeadChange)
+			go idx.run()
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			cancel()
+			return nil
+		},
+	})
+	return idx
+}
+
+// Changes returns the indexed changes of the epochs in [from, to].
+func (idx *Index) Changes(from, to abi.ChainEpoch) ([]*api.GovernChange, error) {
+	res, err := idx.ds.Query(query.Query{Prefix: epochPrefix.String()})
+	if err != nil {
+		return nil, xerrors.Errorf("querying governance index: %w", err)
+	}
+	defer res.Close() //nolint:errcheck
+
+	var out []*api.GovernChange
+	for r := range res.Next() {
+		if r.Error != nil {
+			return nil, xerrors.Errorf("reading governance index: %w", r.Error)
+		}
+
+		epoch, err := strconv.ParseInt(datastore.NewKey(r.Key).BaseNamespace(), 10, 64)
+		if err != nil {
+			return nil, xerrors.Errorf("parsing governance index key %s: %w", r.Key, err)
+		}
+		if abi.ChainEpoch(epoch) < from || abi.ChainEpoch(epoch) > to {
+			continue
+		}
+
+		var changes []*api.GovernChange
+		if err := json.Unmarshal(r.Value, &changes); err != nil {
+			return nil, xerrors.Errorf("decoding governance changes of epoch %d: %w", epoch, err)
+		}
+		out = append(out, changes...)
+	}
+
+	sortChanges(out)
+	return out, nil
+}
+
+// Height returns the height of the last indexed tipset.
+func (idx *Index) Height() (abi.ChainEpoch, error) {
+	b, err := idx.ds.Get(heightKey)
+	if err == datastore.ErrNotFound {
+		return -1, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	h, err := strconv.ParseInt(string(b), 10, 64)
+	return abi.ChainEpoch(h), err
+}
+
+func (idx *Index) onHeadChange(rev, app []*types.TipSet) error {
+	var head *types.TipSet
+	switch {
+	case len(app) > 0:
+		head = app[len(app)-1]
+	case len(rev) > 0:
+		parent, err := idx.cs.LoadTipSet(rev[len(rev)-1].Parents())
+		if err != nil {
+			return xerrors.Errorf("loading new head: %w", err)
+		}
+		head = parent
+	default:
+		return nil
+	}
+
+	idx.setTarget(head)
+	return nil
+}
+
+// setTarget replaces the head to index, and wakes up run, without waiting.
+func (idx *Index) setTarget(head *types.TipSet) {
+	idx.lk.Lock()
+	idx.head = head
+	idx.lk.Unlock()
+
+	select {
+	case idx.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (idx *Index) run() {
+	for {
+		select {
+		case <-idx.notify:
+			idx.lk.Lock()
+			head := idx.head
+			idx.head = nil
+			idx.lk.Unlock()
+
+			if head == nil {
+				continue
+			}
+			if err := idx.sync(head); err != nil {
+				log.Errorf("indexing governance changes up to tipset %d: %s", head.Height(), err)
+			}
+		case <-idx.ctx.Done():
+			return
+		}
+	}
+}
+
+// sync reverts the indexed tipsets which aren't in the chain of head, then
+// indexes the tipsets between the last indexed one and head.
+func (idx *Index) sync(head *types.TipSet) error {
+	indexed, err := idx.indexedHead(head)
+	if err != nil {
+		return xerrors.Errorf("loading indexed tipset: %w", err)
+	}
+
+	for indexed != nil && indexed.Height() > 0 {
+		if err := idx.ctx.Err(); err != nil {
+			return err
+		}
+
+		if indexed.Height() <= head.Height() {
+			ts, err := idx.cs.GetTipsetByHeight(idx.ctx, indexed.Height(), head, true)
+			if err != nil {
+				return xerrors.Errorf("loading tipset at %d: %w", indexed.Height(), err)
+			}
+			if ts.Equals(indexed) {
+				break
+			}
+		}
+
+		parent, err := idx.revert(indexed)
+		if err != nil {
+			return xerrors.Errorf("reverting tipset at %d: %w", indexed.Height(), err)
+		}
+		indexed = parent
+	}
+
+	next := abi.ChainEpoch(1)
+	if indexed != nil {
+		next = indexed.Height() + 1
+	}
+	if next < head.Height() {
+		log.Infof("indexing governance changes from %d to %d", next, head.Height())
+	}
+
+	for next <= head.Height() {
+		if err := idx.ctx.Err(); err != nil {
+			return err
+		}
+
+		ts, err := idx.cs.GetTipsetByHeight(idx.ctx, next, head, false)
+		if err != nil {
+			return xerrors.Errorf("loading tipset at %d: %w", next, err)
+		}
+		if err := idx.apply(ts); err != nil {
+			return xerrors.Errorf("indexing tipset at %d: %w", ts.Height(), err)
+		}
+		next = ts.Height() + 1
+	}
+	return nil
+}
+
+// indexedHead loads the last indexed tipset, nil if nothing is indexed yet.
+// Indexes written before the tipset key was recorded only have a height, which
+// is resolved in the chain of head.
+func (idx *Index) indexedHead(head *types.TipSet) (*types.TipSet, error) {
+	b, err := idx.ds.Get(headKey)
+	switch err {
+	case nil:
+		tsk, err := types.TipSetKeyFromBytes(b)
+		if err != nil {
+			return nil, err
+		}
+		return idx.cs.LoadTipSet(tsk)
+	case datastore.ErrNotFound:
+	default:
+		return nil, err
+	}
+
+	h, err := idx.Height()
+	if err != nil || h < 0 {
+		return nil, err
+	}
+	if h > head.Height() {
+		h = head.Height()
+	}
+	return idx.cs.GetTipsetByHeight(idx.ctx, h, head, true)
+}
+
+// apply indexes the changes made by the execution of the parent of ts.
+func (idx *Index) apply(ts *types.TipSet) error {
+	parent, err := idx.cs.LoadTipSet(ts.Parents())
+	if err != nil {
+		return xerrors.Errorf("loading parent tipset: %w", err)
+	}
+
+	changes, err := diffTipSets(idx.ctx, idx.cs, idx.sm, parent, ts)
+	if err != nil {
+		return err
+	}
+
+	if len(changes) > 0 {
+		b, err := json.Marshal(changes)
+		if err != nil {
+			return err
+		}
+		if err := idx.ds.Put(epochKey(parent.Height()), b); err != nil {
+			return err
+		}
+	}
+	return idx.setHead(ts)
+}
+
+// revert removes the changes indexed by apply, and returns the parent of ts,
+// which becomes the last indexed tipset.
+func (idx *Index) revert(ts *types.TipSet) (*types.TipSet, error) {
+	parent, err := idx.cs.LoadTipSet(ts.Parents())
+	if err != nil {
+		return nil, xerrors.Errorf("loading parent tipset: %w", err)
+	}
+
+	if err := idx.ds.Delete(epochKey(parent.Height())); err != nil {
+		return nil, err
+	}
+	return parent, idx.setHead(parent)
+}
+
+// setHead records ts as the last indexed tipset.
+func (idx *Index) setHead(ts *types.TipSet) error {
+	if err := idx.ds.Put(headKey, ts.Key().Bytes()); err != nil {
+		return err
+	}
+	return idx.ds.Put(heightKey, []byte(strconv.FormatInt(int64(ts.Height()), 10)))
+}
+
+func epochKey(epoch abi.ChainEpoch) datastore.Key {
+	return epochPrefix.ChildString(fmt.Sprintf("%d", epoch))
+}
@@ -0,0 +1,27 @@
+package govhistory
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/EpiK-Protocol/go-epik/chain/types"
+	"github.com/EpiK-Protocol/go-epik/chain/types/mock"
+)
+
+func TestOnHeadChangeCoalesces(t *testing.T) {
+	idx := &Index{notify: make(chan struct{}, 1)}
+
+	var heads []*types.TipSet
+	for i := 1; i <= 3; i++ {
+		heads = append(heads, mock.TipSet(mock.MkBlock(nil, uint64(i), uint64(i))))
+	}
+
+	// nothing drains the notifications, and the notifee must not block
+	for i := range heads {
+		require.NoError(t, idx.onHeadChange(nil, heads[:i+1]))
+	}
+
+	require.Len(t, idx.notify, 1)
+	require.Equal(t, heads[2], idx.head)
+}
@@ -0,0 +1,88 @@
+package miner
+
+import (
+	"encoding/binary"
+	"encoding/json"
+
+	"github.com/ipfs/go-cid"
+	"github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/namespace"
+	"github.com/ipfs/go-datastore/query"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-state-types/abi"
+
+	"github.com/EpiK-Protocol/go-epik/api"
+)
+
+var (
+	dataLedgerPrefix    = datastore.NewKey("/minerdata")
+	dataLedgerPieces    = datastore.NewKey("/pieces")
+	dataLedgerHeightKey = datastore.NewKey("/height")
+)
+
+// dataLedger persists the replication progress of MinerData, so that a
+// restarted miner neither re-scans the data index nor forgets failed pieces.
+type dataLedger struct {
+	ds datastore.Batching
+}
+
+func newDataLedger(ds datastore.Batching) *dataLedger {
+	return &dataLedger{
+		ds: namespace.Wrap(ds, dataLedgerPrefix),
+	}
+}
+
+func pieceKey(pieceCID cid.Cid) datastore.Key {
+	return dataLedgerPieces.ChildString(pieceCID.String())
+}
+
+func (l *dataLedger) height() (abi.ChainEpoch, bool, error) {
+	b, err := l.ds.Get(dataLedgerHeightKey)
+	if err == datastore.ErrNotFound {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, xerrors.Errorf("getting check height: %w", err)
+	}
+	if len(b) != 8 {
+		return 0, false, xerrors.Errorf("invalid check height length: %d", len(b))
+	}
+	return abi.ChainEpoch(binary.BigEndian.Uint64(b)), true, nil
+}
+
+func (l *dataLedger) setHeight(h abi.ChainEpoch) error {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, uint64(h))
+	return l.ds.Put(dataLedgerHeightKey, b)
+}
+
+func (l *dataLedger) put(piece *api.MinerDataPiece) error {
+	b, err := json.Marshal(piece)
+	if err != nil {
+		return xerrors.Errorf("marshaling piece record: %w", err)
+	}
+	return l.ds.Put(pieceKey(piece.PieceCID), b)
+}
+
+func (l *dataLedger) list() ([]api.MinerDataPiece, error) {
+	res, err := l.ds.Query(query.Query{Prefix: dataLedgerPieces.String()})
+	if err != nil {
+		return nil, xerrors.Errorf("querying piece records: %w", err)
+	}
+	defer res.Close() //nolint:errcheck
+
+	var out []api.MinerDataPiece
+	for r := range res.Next() {
+		if r.Error != nil {
+			return nil, r.Error
+		}
+
+		var piece api.MinerDataPiece
+		if err := json.Unmarshal(r.Value, &piece); err != nil {
+			return nil, xerrors.Errorf("unmarshaling piece record %s: %w", r.Key, err)
+		}
+		out = append(out, piece)
+	}
+	return out, nil
+}
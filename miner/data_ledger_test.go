@@ -0,0 +1,63 @@
+package miner
+
+import (
+	"context"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	tutils "github.com/filecoin-project/specs-actors/v2/support/testing"
+	ds "github.com/ipfs/go-datastore"
+	ds_sync "github.com/ipfs/go-datastore/sync"
+	"github.com/stretchr/testify/require"
+
+	"github.com/EpiK-Protocol/go-epik/api"
+)
+
+func TestDataLedgerRestore(t *testing.T) {
+	mds := ds_sync.MutexWrap(ds.NewMapDatastore())
+	maddr := tutils.NewIDAddr(t, 100)
+	source := tutils.NewIDAddr(t, 101)
+
+	m := newMinerData(nil, maddr, mds, nil)
+	require.NoError(t, m.loadLedger())
+	require.EqualValues(t, 10, m.checkHeight)
+
+	retrieved := &DataRef{
+		pieceID: tutils.MakeCID("piece1", nil),
+		rootCID: tutils.MakeCID("root1", nil),
+		miners:  map[address.Address]int{source: MinerDefaultScore},
+	}
+	m.setState(retrieved, api.MinerDataRetrieved, "")
+
+	failed := &DataRef{
+		pieceID:  tutils.MakeCID("piece2", nil),
+		rootCID:  tutils.MakeCID("root2", nil),
+		miners:   map[address.Address]int{source: 1},
+		tryCount: 4,
+	}
+	m.setState(failed, api.MinerDataFailed, "retrieval errored")
+	require.NoError(t, m.ledger.setHeight(1234))
+
+	// simulate a restart
+	m2 := newMinerData(nil, maddr, mds, nil)
+	require.NoError(t, m2.loadLedger())
+	require.EqualValues(t, 1234, m2.checkHeight)
+	require.EqualValues(t, 2, m2.totalDataCount)
+	require.EqualValues(t, 1, m2.totalRetrieveCount)
+
+	obj, ok := m2.dataRefs.Get(failed.pieceID.String())
+	require.True(t, ok)
+	data := obj.(*DataRef)
+	require.Equal(t, api.MinerDataFailed, data.state)
+	require.Equal(t, "retrieval errored", data.reason)
+	require.Equal(t, 4, data.tryCount)
+	require.Equal(t, 1, data.miners[source])
+	require.False(t, data.isRetrieved)
+
+	st, err := m2.Status(context.Background(), true)
+	require.NoError(t, err)
+	require.EqualValues(t, 1234, st.CheckHeight)
+	require.Equal(t, 1, st.States[api.MinerDataRetrieved])
+	require.Equal(t, 1, st.States[api.MinerDataFailed])
+	require.Len(t, st.Pieces, 2)
+}
@@ -0,0 +1,252 @@
+package miner
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/ipfs/go-cid"
+	"golang.org/x/xerrors"
+
+	"github.com/EpiK-Protocol/go-epik/node/config"
+)
+
+// maxDataParallelNum bounds the configured parallelism, the retrieval and deal
+// caches are sized after it.
+const maxDataParallelNum = 256
+
+// DataCandidate is a piece found in the market data index.
+type DataCandidate struct {
+	PieceCID   cid.Cid
+	RootCID    cid.Cid
+	Expert     address.Address
+	PieceSize  abi.PaddedPieceSize
+	Redundancy uint64
+}
+
+// DataLimits are the parallelism and retry limits of the replication loop,
+// and the filter of the pieces it replicates.
+type DataLimits struct {
+	RetrieveParallelNum int
+	DealParallelNum     int
+	// 0 = no limit
+	RetrieveTryCountMax int
+	MinerDefaultScore   int
+
+	Filter DataFilter
+	// NeedFileInfo is set when the policy uses the expert file info of the
+	// pieces, which is only loaded then.
+	NeedFileInfo bool
+}
+
+// DataFilter selects the pieces to replicate. The zero value accepts every
+// piece.
+type DataFilter struct {
+	// nil = all experts
+	Experts map[address.Address]struct{}
+	// 0 = no limit
+	MinPieceSize  uint64
+	MaxPieceSize  uint64
+	MinRedundancy uint64
+	MaxRedundancy uint64
+}
+
+// Active reports whether the filter rejects any piece.
+func (f DataFilter) Active() bool {
+	return f.Experts != nil ||
+		f.MinPieceSize > 0 || f.MaxPieceSize > 0 ||
+		f.MinRedundancy > 0 || f.MaxRedundancy > 0
+}
+
+// Accept reports whether the piece should be replicated at all, with the
+// reason when it should not.
+func (f DataFilter) Accept(piece *DataCandidate) (bool, string) {
+	if f.Experts != nil {
+		if _, ok := f.Experts[piece.Expert]; !ok {
+			return false, fmt.Sprintf("expert %s not allowed", piece.Expert)
+		}
+	}
+
+	if f.MinPieceSize > 0 && uint64(piece.PieceSize) < f.MinPieceSize {
+		return false, fmt.Sprintf("piece size %d below %d", piece.PieceSize, f.MinPieceSize)
+	}
+	if f.MaxPieceSize > 0 && uint64(piece.PieceSize) > f.MaxPieceSize {
+		return false, fmt.Sprintf("piece size %d above %d", piece.PieceSize, f.MaxPieceSize)
+	}
+
+	if f.MinRedundancy > 0 && piece.Redundancy < f.MinRedundancy {
+		return false, fmt.Sprintf("redundancy %d below %d", piece.Redundancy, f.MinRedundancy)
+	}
+	if f.MaxRedundancy > 0 && piece.Redundancy >= f.MaxRedundancy {
+		return false, fmt.Sprintf("redundancy %d reached %d", piece.Redundancy, f.MaxRedundancy)
+	}
+
+	return true, ""
+}
+
+// DataPolicy decides which chain data the miner replicates. It is consulted
+// on every replication round, so implementations may change their decisions
+// at runtime.
+type DataPolicy interface {
+	// Limits returns the current limits and filter of the replication loop.
+	// It's called once at the start of every round.
+	Limits() (DataLimits, error)
+	// ReadyToDeal reports whether a storage deal for the retrieved piece may
+	// be started now, with the reason when it may not.
+	ReadyToDeal(ctx context.Context, piece *DataCandidate) (bool, string, error)
+	// DealStarted records that a storage deal for the piece was started.
+	DealStarted(piece *DataCandidate)
+}
+
+// SealingSectorsFunc returns the number of sectors in the sealing pipeline.
+type SealingSectorsFunc func() (uint64, error)
+
+type defaultDataPolicy struct{}
+
+// DefaultDataPolicy replicates every piece using the package level limits.
+func DefaultDataPolicy() DataPolicy {
+	return defaultDataPolicy{}
+}
+
+func (defaultDataPolicy) Limits() (DataLimits, error) {
+	return DataLimits{
+		RetrieveParallelNum: RetrieveParallelNum,
+		DealParallelNum:     DealParallelNum,
+		RetrieveTryCountMax: RetrieveTryCountMax,
+		MinerDefaultScore:   MinerDefaultScore,
+	}, nil
+}
+
+func (defaultDataPolicy) ReadyToDeal(context.Context, *DataCandidate) (bool, string, error) {
+	return true, "", nil
+}
+
+func (defaultDataPolicy) DealStarted(*DataCandidate) {}
+
+type configDataPolicy struct {
+	getCfg  func() (config.DataReplicationConfig, error)
+	sealing SealingSectorsFunc
+
+	lk sync.Mutex
+	// read by the last Limits call
+	cfg       config.DataReplicationConfig
+	budgetDay string
+	dealt     uint64
+}
+
+// NewConfigDataPolicy returns a policy driven by the DataReplication section of
+// the miner config. getCfg is called by every Limits call, so edits of the
+// config file apply from the next replication round without a restart. The
+// daily byte budget is tracked in memory.
+func NewConfigDataPolicy(getCfg func() (config.DataReplicationConfig, error), sealing SealingSectorsFunc) DataPolicy {
+	return &configDataPolicy{
+		getCfg:  getCfg,
+		sealing: sealing,
+	}
+}
+
+func (p *configDataPolicy) Limits() (DataLimits, error) {
+	cfg, err := p.getCfg()
+	if err != nil {
+		return DataLimits{}, err
+	}
+
+	limits := DataLimits{
+		RetrieveParallelNum: cfg.RetrieveParallelNum,
+		DealParallelNum:     cfg.DealParallelNum,
+		RetrieveTryCountMax: cfg.RetrieveTryCountMax,
+		MinerDefaultScore:   cfg.MinerDefaultScore,
+		Filter: DataFilter{
+			MinPieceSize:  cfg.MinPieceSize,
+			MaxPieceSize:  cfg.MaxPieceSize,
+			MinRedundancy: cfg.MinRedundancy,
+			MaxRedundancy: cfg.MaxRedundancy,
+		},
+	}
+	if len(cfg.Experts) > 0 {
+		limits.Filter.Experts = make(map[address.Address]struct{}, len(cfg.Experts))
+		for _, s := range cfg.Experts {
+			expert, err := address.NewFromString(s)
+			if err != nil {
+				return DataLimits{}, xerrors.Errorf("parsing expert address %q: %w", s, err)
+			}
+			limits.Filter.Experts[expert] = struct{}{}
+		}
+	}
+	// the budget is counted in piece sizes
+	limits.NeedFileInfo = limits.Filter.Active() || cfg.DailyByteBudget > 0
+	// at least one of each must run, or replication stalls
+	if limits.RetrieveParallelNum < 1 {
+		limits.RetrieveParallelNum = 1
+	}
+	if limits.RetrieveParallelNum > maxDataParallelNum {
+		limits.RetrieveParallelNum = maxDataParallelNum
+	}
+	if limits.DealParallelNum < 1 {
+		limits.DealParallelNum = 1
+	}
+	if limits.DealParallelNum > maxDataParallelNum {
+		limits.DealParallelNum = maxDataParallelNum
+	}
+	if limits.MinerDefaultScore < 1 {
+		limits.MinerDefaultScore = 1
+	}
+	if limits.MinerDefaultScore > MinerMaxScore {
+		limits.MinerDefaultScore = MinerMaxScore
+	}
+
+	p.lk.Lock()
+	p.cfg = cfg
+	p.lk.Unlock()
+
+	return limits, nil
+}
+
+func (p *configDataPolicy) ReadyToDeal(ctx context.Context, piece *DataCandidate) (bool, string, error) {
+	p.lk.Lock()
+	cfg := p.cfg
+	p.lk.Unlock()
+
+	if cfg.MaxSealingSectors > 0 && p.sealing != nil {
+		sealing, err := p.sealing()
+		if err != nil {
+			return false, "", xerrors.Errorf("getting sealing sectors: %w", err)
+		}
+		if sealing >= cfg.MaxSealingSectors {
+			return false, fmt.Sprintf("%d sectors sealing", sealing), nil
+		}
+	}
+
+	if cfg.DailyByteBudget > 0 {
+		p.lk.Lock()
+		dealt := p.dealtToday()
+		p.lk.Unlock()
+
+		if dealt+uint64(piece.PieceSize) > cfg.DailyByteBudget {
+			return false, fmt.Sprintf("daily budget exhausted, %d of %d bytes used", dealt, cfg.DailyByteBudget), nil
+		}
+	}
+
+	return true, "", nil
+}
+
+func (p *configDataPolicy) DealStarted(piece *DataCandidate) {
+	p.lk.Lock()
+	defer p.lk.Unlock()
+
+	p.dealtToday()
+	p.dealt += uint64(piece.PieceSize)
+}
+
+// must be called with lk held
+func (p *configDataPolicy) dealtToday() uint64 {
+	today := time.Now().UTC().Format("2006-01-02")
+	if p.budgetDay != today {
+		p.budgetDay = today
+		p.dealt = 0
+	}
+	return p.dealt
+}
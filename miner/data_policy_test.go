@@ -0,0 +1,98 @@
+package miner
+
+import (
+	"context"
+	"testing"
+
+	tutils "github.com/filecoin-project/specs-actors/v2/support/testing"
+	"github.com/stretchr/testify/require"
+
+	"github.com/EpiK-Protocol/go-epik/node/config"
+)
+
+func TestConfigDataPolicy(t *testing.T) {
+	ctx := context.Background()
+	expert := tutils.NewIDAddr(t, 100)
+	other := tutils.NewIDAddr(t, 101)
+
+	cfg := config.DefaultStorageMiner().DataReplication
+	sealing := uint64(0)
+	p := NewConfigDataPolicy(func() (config.DataReplicationConfig, error) {
+		return cfg, nil
+	}, func() (uint64, error) {
+		return sealing, nil
+	})
+
+	piece := &DataCandidate{
+		PieceCID:   tutils.MakeCID("piece", nil),
+		Expert:     expert,
+		PieceSize:  1 << 20,
+		Redundancy: 3,
+	}
+
+	accept := func() bool {
+		limits, err := p.Limits()
+		require.NoError(t, err)
+		ok, _ := limits.Filter.Accept(piece)
+		return ok
+	}
+
+	require.True(t, accept())
+
+	// changes of the config apply on the next round
+	cfg.Experts = []string{other.String()}
+	require.False(t, accept())
+
+	cfg.Experts = []string{expert.String()}
+	cfg.MaxRedundancy = 3
+	require.False(t, accept())
+
+	cfg.MaxRedundancy = 0
+	cfg.MaxPieceSize = 1 << 10
+	require.False(t, accept())
+	cfg.MaxPieceSize = 0
+
+	cfg.Experts = []string{"invalid"}
+	_, err := p.Limits()
+	require.Error(t, err)
+	cfg.Experts = nil
+
+	limits, err := p.Limits()
+	require.NoError(t, err)
+	require.False(t, limits.Filter.Active())
+	require.False(t, limits.NeedFileInfo)
+
+	cfg.MaxSealingSectors = 2
+	sealing = 2
+	_, err = p.Limits()
+	require.NoError(t, err)
+	ok, _, err := p.ReadyToDeal(ctx, piece)
+	require.NoError(t, err)
+	require.False(t, ok)
+	sealing = 1
+
+	cfg.DailyByteBudget = 3 << 20
+	limits, err = p.Limits()
+	require.NoError(t, err)
+	require.True(t, limits.NeedFileInfo)
+	for i := 0; i < 3; i++ {
+		ok, _, err = p.ReadyToDeal(ctx, piece)
+		require.NoError(t, err)
+		require.True(t, ok)
+		p.DealStarted(piece)
+	}
+	ok, _, err = p.ReadyToDeal(ctx, piece)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	limits, err = p.Limits()
+	require.NoError(t, err)
+	require.Equal(t, RetrieveParallelNum, limits.RetrieveParallelNum)
+	require.Equal(t, MinerDefaultScore, limits.MinerDefaultScore)
+
+	cfg.RetrieveParallelNum, cfg.DealParallelNum = 0, -1
+	limits, err = p.Limits()
+	require.NoError(t, err)
+	require.Equal(t, 1, limits.RetrieveParallelNum)
+	require.Equal(t, 1, limits.DealParallelNum)
+}
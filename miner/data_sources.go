@@ -0,0 +1,210 @@
+package miner
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-fil-markets/retrievalmarket"
+	"github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/query"
+	"golang.org/x/xerrors"
+
+	"github.com/EpiK-Protocol/go-epik/api"
+)
+
+var dataLedgerSources = datastore.NewKey("/sources")
+
+// error class of retrievals failing before a deal was made
+const sourceErrQuery = "query"
+
+// retrievalTrack follows a running retrieval to measure its timings.
+type retrievalTrack struct {
+	miner     address.Address
+	start     time.Time
+	firstByte time.Time
+}
+
+func (l *dataLedger) putSource(st *api.RetrievalSourceStats) error {
+	b, err := json.Marshal(st)
+	if err != nil {
+		return xerrors.Errorf("marshaling source stats: %w", err)
+	}
+	return l.ds.Put(dataLedgerSources.ChildString(st.Miner.String()), b)
+}
+
+func (l *dataLedger) listSources() ([]api.RetrievalSourceStats, error) {
+	res, err := l.ds.Query(query.Query{Prefix: dataLedgerSources.String()})
+	if err != nil {
+		return nil, xerrors.Errorf("querying source stats: %w", err)
+	}
+	defer res.Close() //nolint:errcheck
+
+	var out []api.RetrievalSourceStats
+	for r := range res.Next() {
+		if r.Error != nil {
+			return nil, r.Error
+		}
+
+		var st api.RetrievalSourceStats
+		if err := json.Unmarshal(r.Value, &st); err != nil {
+			return nil, xerrors.Errorf("unmarshaling source stats %s: %w", r.Key, err)
+		}
+		out = append(out, st)
+	}
+	return out, nil
+}
+
+// sourceWeight rates a source miner in (0, 1]: its smoothed success rate,
+// discounted by up to half when it is slower than the fastest known source.
+func sourceWeight(st *api.RetrievalSourceStats, maxBps float64) float64 {
+	w := st.SuccessRate()
+	if maxBps > 0 {
+		w *= 0.5 + 0.5*st.BytesPerSecond()/maxBps
+	}
+	return w
+}
+
+func (m *MinerData) maxSourceBps() float64 {
+	var max float64
+	for _, st := range m.sources {
+		if bps := st.BytesPerSecond(); bps > max {
+			max = bps
+		}
+	}
+	return max
+}
+
+func (m *MinerData) sourceStats(miner address.Address) *api.RetrievalSourceStats {
+	st, ok := m.sources[miner]
+	if !ok {
+		st = &api.RetrievalSourceStats{
+			Miner:  miner,
+			Errors: map[string]uint64{},
+		}
+		m.sources[miner] = st
+	}
+	return st
+}
+
+func (m *MinerData) persistSource(st *api.RetrievalSourceStats) {
+	if m.ledger == nil {
+		return
+	}
+	if err := m.ledger.putSource(st); err != nil {
+		log.Warnf("failed to persist source stats %s: %s", st.Miner, err)
+	}
+}
+
+// pickSource chooses the miner to retrieve data from. Miners are picked at
+// random, weighted by their per-data score and their recorded reputation.
+func (m *MinerData) pickSource(data *DataRef) (address.Address, bool) {
+	maxBps := m.maxSourceBps()
+
+	addrs := make([]address.Address, 0, len(data.miners))
+	weights := make([]float64, 0, len(data.miners))
+	var total float64
+	for addr, score := range data.miners {
+		w := float64(score) * sourceWeight(m.sourceStats(addr), maxBps)
+		addrs = append(addrs, addr)
+		weights = append(weights, w)
+		total += w
+	}
+	if len(addrs) == 0 {
+		return address.Undef, false
+	}
+
+	r := rand.Float64() * total
+	for i, w := range weights {
+		if r < w {
+			return addrs[i], true
+		}
+		r -= w
+	}
+	return addrs[len(addrs)-1], true
+}
+
+func (m *MinerData) trackRetrieval(rk string, miner address.Address) {
+	m.tracks[rk] = &retrievalTrack{
+		miner: miner,
+		start: time.Now(),
+	}
+}
+
+// recordProgress notes the first byte received by a running retrieval.
+func (m *MinerData) recordProgress(rk string, deal *api.RetrievalDeal) {
+	track, ok := m.tracks[rk]
+	if ok && track.firstByte.IsZero() && deal.TotalReceived > 0 {
+		track.firstByte = time.Now()
+	}
+}
+
+// recordRetrieval records the outcome of a finished retrieval from miner.
+func (m *MinerData) recordRetrieval(rk string, miner address.Address, deal *api.RetrievalDeal) {
+	track, tracked := m.tracks[rk]
+	delete(m.tracks, rk)
+	if miner == address.Undef && tracked {
+		miner = track.miner
+	}
+	if miner == address.Undef {
+		return
+	}
+
+	st := m.sourceStats(miner)
+	now := time.Now()
+	if retrievalmarket.IsTerminalSuccess(deal.Status) {
+		st.Successes++
+		st.LastSuccess = now
+		if tracked {
+			st.TotalBytes += deal.TotalReceived
+			st.TotalTime += now.Sub(track.start)
+			if !track.firstByte.IsZero() {
+				st.TotalTTFB += track.firstByte.Sub(track.start)
+				st.TTFBSamples++
+			}
+		}
+	} else {
+		st.Failures++
+		st.LastFailure = now
+		st.Errors[retrievalmarket.DealStatuses[deal.Status]]++
+	}
+	m.persistSource(st)
+}
+
+// recordQueryFailure records a retrieval from miner failing before a deal was made.
+func (m *MinerData) recordQueryFailure(miner address.Address) {
+	st := m.sourceStats(miner)
+	st.Failures++
+	st.LastFailure = time.Now()
+	st.Errors[sourceErrQuery]++
+	m.persistSource(st)
+}
+
+// Sources returns the recorded source miners ranked by score.
+func (m *MinerData) Sources(ctx context.Context) ([]api.RetrievalSourceStats, error) {
+	if m.ledger == nil {
+		return nil, nil
+	}
+
+	sources, err := m.ledger.listSources()
+	if err != nil {
+		return nil, err
+	}
+
+	var maxBps float64
+	for i := range sources {
+		if bps := sources[i].BytesPerSecond(); bps > maxBps {
+			maxBps = bps
+		}
+	}
+	for i := range sources {
+		sources[i].Score = 100 * sourceWeight(&sources[i], maxBps)
+	}
+	sort.SliceStable(sources, func(i, j int) bool {
+		return sources[i].Score > sources[j].Score
+	})
+	return sources, nil
+}
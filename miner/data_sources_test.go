@@ -0,0 +1,54 @@
+package miner
+
+import (
+	"context"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-fil-markets/retrievalmarket"
+	tutils "github.com/filecoin-project/specs-actors/v2/support/testing"
+	ds "github.com/ipfs/go-datastore"
+	ds_sync "github.com/ipfs/go-datastore/sync"
+	"github.com/stretchr/testify/require"
+
+	"github.com/EpiK-Protocol/go-epik/api"
+)
+
+func TestDataSourcesReputation(t *testing.T) {
+	mds := ds_sync.MutexWrap(ds.NewMapDatastore())
+	maddr := tutils.NewIDAddr(t, 100)
+	good := tutils.NewIDAddr(t, 101)
+	bad := tutils.NewIDAddr(t, 102)
+
+	m := newMinerData(nil, maddr, mds, nil)
+
+	for i := 0; i < 3; i++ {
+		m.trackRetrieval("piece", good)
+		m.recordProgress("piece", &api.RetrievalDeal{TotalReceived: 1})
+		m.recordRetrieval("piece", good, &api.RetrievalDeal{
+			Status:        retrievalmarket.DealStatusCompleted,
+			TotalReceived: 1 << 20,
+		})
+	}
+	m.recordQueryFailure(bad)
+	m.trackRetrieval("piece", bad)
+	m.recordRetrieval("piece", address.Undef, &api.RetrievalDeal{Status: retrievalmarket.DealStatusErrored})
+
+	require.Empty(t, m.tracks)
+
+	// restored from the datastore
+	m2 := newMinerData(nil, maddr, mds, nil)
+	require.NoError(t, m2.loadLedger())
+	require.EqualValues(t, 3, m2.sources[good].Successes)
+	require.EqualValues(t, 3<<20, m2.sources[good].TotalBytes)
+	require.EqualValues(t, 3, m2.sources[good].TTFBSamples)
+	require.EqualValues(t, 2, m2.sources[bad].Failures)
+	require.EqualValues(t, 1, m2.sources[bad].Errors[sourceErrQuery])
+	require.EqualValues(t, 1, m2.sources[bad].Errors[retrievalmarket.DealStatuses[retrievalmarket.DealStatusErrored]])
+
+	sources, err := m2.Sources(context.Background())
+	require.NoError(t, err)
+	require.Len(t, sources, 2)
+	require.Equal(t, good, sources[0].Miner)
+	require.Greater(t, sources[0].Score, sources[1].Score)
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/filecoin-project/go-state-types/crypto"
 	lru "github.com/hashicorp/golang-lru"
+	"github.com/ipfs/go-datastore"
 
 	"github.com/EpiK-Protocol/go-epik/api"
 	"github.com/EpiK-Protocol/go-epik/build"
@@ -48,7 +49,7 @@ func randTimeOffset(width time.Duration) time.Duration {
 	return val - (width / 2)
 }
 
-func NewMiner(api api.FullNode, epp gen.WinningPoStProver, addr address.Address, sf *slashfilter.SlashFilter, j journal.Journal) *Miner {
+func NewMiner(api api.FullNode, epp gen.WinningPoStProver, addr address.Address, sf *slashfilter.SlashFilter, j journal.Journal, ds datastore.Batching, policy DataPolicy) *Miner {
 	arc, err := lru.NewARC(10000)
 	if err != nil {
 		panic(err)
@@ -76,7 +77,7 @@ func NewMiner(api api.FullNode, epp gen.WinningPoStProver, addr address.Address,
 			evtTypeBlockMined: j.RegisterEventType("miner", "block_mined"),
 		},
 		journal:          j,
-		minerData:        newMinerData(api, addr),
+		minerData:        newMinerData(api, addr, ds, policy),
 		isMineOneRunning: false,
 	}
 }
@@ -112,6 +113,16 @@ func (m *Miner) Address() address.Address {
 	return m.address
 }
 
+// DataStatus returns the replication progress of the chain-data loop.
+func (m *Miner) DataStatus(ctx context.Context, pieces bool) (api.MinerDataStatus, error) {
+	return m.minerData.Status(ctx, pieces)
+}
+
+// DataSources returns the retrieval history of the chain-data source miners.
+func (m *Miner) DataSources(ctx context.Context) ([]api.RetrievalSourceStats, error) {
+	return m.minerData.Sources(ctx)
+}
+
 func (m *Miner) Start(ctx context.Context) error {
 	m.lk.Lock()
 	defer m.lk.Unlock()
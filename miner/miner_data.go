@@ -3,7 +3,6 @@ package miner
 import (
 	"context"
 	"fmt"
-	"math/rand"
 	"sync"
 	"time"
 
@@ -17,7 +16,9 @@ import (
 	"github.com/filecoin-project/go-state-types/abi"
 	lru "github.com/hashicorp/golang-lru"
 	cid "github.com/ipfs/go-cid"
+	"github.com/ipfs/go-datastore"
 	"go.opencensus.io/trace"
+	"golang.org/x/xerrors"
 )
 
 var (
@@ -40,6 +41,18 @@ var (
 	MinerPunishmentScore = 2
 )
 
+// dataInfoTTL is how long the expert file info of a piece is used before it's
+// reloaded, as its redundancy grows while other miners store it.
+const dataInfoTTL = 30 * time.Minute
+
+// dataGiveUpPeriod is how long a piece rests once its retrievals failed
+// RetrieveTryCountMax times, before it's tried again from scratch.
+const dataGiveUpPeriod = 24 * time.Hour
+
+// dataIndexPageSize is the number of data indexes fetched per request while
+// catching up with the chain.
+const dataIndexPageSize = 1000
+
 type DataRef struct {
 	pieceID     cid.Cid
 	rootCID     cid.Cid
@@ -48,6 +61,32 @@ type DataRef struct {
 	retryTime   time.Time
 	isRetrieved bool
 	isDealed    bool
+
+	state  api.MinerDataState
+	reason string
+
+	// loaded from the expertfund actor on first use, and reloaded once
+	// older than dataInfoTTL
+	infoLoadedAt time.Time
+	expert       address.Address
+	pieceSize    abi.PaddedPieceSize
+	redundancy   uint64
+}
+
+func (d *DataRef) record() *api.MinerDataPiece {
+	sources := make(map[string]int, len(d.miners))
+	for addr, score := range d.miners {
+		sources[addr.String()] = score
+	}
+	return &api.MinerDataPiece{
+		PieceCID:  d.pieceID,
+		RootCID:   d.rootCID,
+		State:     d.state,
+		Reason:    d.reason,
+		TryCount:  d.tryCount,
+		RetryTime: d.retryTime,
+		Sources:   sources,
+	}
 }
 
 type MinerData struct {
@@ -61,28 +100,47 @@ type MinerData struct {
 	stopping chan struct{}
 
 	checkHeight abi.ChainEpoch
+	ledger      *dataLedger
+	policy      DataPolicy
+	limits      DataLimits
 
 	dataRefs   *lru.ARCCache
 	retrievals *lru.ARCCache
 	deals      *lru.ARCCache
 
+	sources map[address.Address]*api.RetrievalSourceStats
+	tracks  map[string]*retrievalTrack
+
 	totalDataCount     uint64
 	totalRetrieveCount uint64
 	totalDealCount     uint64
 }
 
-func newMinerData(api api.FullNode, addr address.Address) *MinerData {
+func newMinerData(napi api.FullNode, addr address.Address, ds datastore.Batching, policy DataPolicy) *MinerData {
 	data, err := lru.NewARC(1000000)
 	if err != nil {
 		panic(err)
 	}
+	if policy == nil {
+		policy = DefaultDataPolicy()
+	}
+	limits, err := policy.Limits()
+	if err != nil {
+		log.Errorf("failed to get data policy limits: %s", err)
+		limits, _ = DefaultDataPolicy().Limits()
+	}
 	return &MinerData{
-		api:                api,
+		api:                napi,
 		miner:              addr,
 		dataRefs:           data,
 		retrievals:         nil,
 		deals:              nil,
 		checkHeight:        10,
+		ledger:             newDataLedger(ds),
+		policy:             policy,
+		limits:             limits,
+		sources:            make(map[address.Address]*api.RetrievalSourceStats),
+		tracks:             make(map[string]*retrievalTrack),
 		totalDataCount:     0,
 		totalRetrieveCount: 0,
 		totalDealCount:     0,
@@ -95,11 +153,124 @@ func (m *MinerData) Start(ctx context.Context) error {
 	if m.stop != nil {
 		return fmt.Errorf("miner data already started")
 	}
+	if err := m.loadLedger(); err != nil {
+		log.Errorf("failed to load data ledger, rescanning data index: %s", err)
+	}
 	m.stop = make(chan struct{})
 	go m.syncData(context.TODO())
 	return nil
 }
 
+// loadLedger restores the check height and the piece records persisted by
+// previous runs.
+func (m *MinerData) loadLedger() error {
+	if m.ledger == nil {
+		return nil
+	}
+
+	height, found, err := m.ledger.height()
+	if err != nil {
+		return err
+	}
+	if found {
+		m.checkHeight = height
+	}
+
+	pieces, err := m.ledger.list()
+	if err != nil {
+		return err
+	}
+	for _, piece := range pieces {
+		data := &DataRef{
+			pieceID:   piece.PieceCID,
+			rootCID:   piece.RootCID,
+			miners:    make(map[address.Address]int, len(piece.Sources)),
+			tryCount:  piece.TryCount,
+			retryTime: piece.RetryTime,
+			state:     piece.State,
+			reason:    piece.Reason,
+		}
+		for s, score := range piece.Sources {
+			addr, err := address.NewFromString(s)
+			if err != nil {
+				log.Warnf("invalid source miner %s of data %s: %s", s, piece.PieceCID, err)
+				continue
+			}
+			data.miners[addr] = score
+		}
+		switch piece.State {
+		case api.MinerDataStored:
+			data.isDealed = true
+			data.isRetrieved = true
+			m.totalDealCount++
+			m.totalRetrieveCount++
+		case api.MinerDataRetrieved, api.MinerDataDealing:
+			data.isRetrieved = true
+			m.totalRetrieveCount++
+		}
+		m.totalDataCount++
+		m.dataRefs.Add(piece.PieceCID.String(), data)
+	}
+
+	sources, err := m.ledger.listSources()
+	if err != nil {
+		return err
+	}
+	for i := range sources {
+		st := sources[i]
+		if st.Errors == nil {
+			st.Errors = map[string]uint64{}
+		}
+		m.sources[st.Miner] = &st
+	}
+	log.Infof("loaded data ledger, height:%d, data:%d, retrieved:%d, storaged:%d", m.checkHeight, m.totalDataCount, m.totalRetrieveCount, m.totalDealCount)
+	return nil
+}
+
+// setState updates the replication state of data and persists it.
+func (m *MinerData) setState(data *DataRef, state api.MinerDataState, reason string) {
+	data.state = state
+	data.reason = reason
+	m.persist(data)
+}
+
+func (m *MinerData) persist(data *DataRef) {
+	if m.ledger == nil {
+		return
+	}
+	if err := m.ledger.put(data.record()); err != nil {
+		log.Warnf("failed to persist data %s: %s", data.pieceID, err)
+	}
+}
+
+// Status returns the replication progress recorded in the ledger.
+func (m *MinerData) Status(ctx context.Context, pieces bool) (api.MinerDataStatus, error) {
+	out := api.MinerDataStatus{
+		States: map[api.MinerDataState]int{},
+	}
+	if m.ledger == nil {
+		return out, nil
+	}
+
+	height, _, err := m.ledger.height()
+	if err != nil {
+		return api.MinerDataStatus{}, err
+	}
+	out.CheckHeight = height
+
+	records, err := m.ledger.list()
+	if err != nil {
+		return api.MinerDataStatus{}, err
+	}
+	for _, r := range records {
+		out.States[r.State]++
+	}
+	if pieces {
+		out.Pieces = records
+	}
+	return out, nil
+}
+
 func (m *MinerData) Stop(ctx context.Context) error {
 	m.lk.Lock()
 	defer m.lk.Unlock()
@@ -132,6 +303,12 @@ func (m *MinerData) syncData(ctx context.Context) {
 		default:
 		}
 
+		if limits, err := m.policy.Limits(); err != nil {
+			log.Errorf("failed to get data policy limits: %s", err)
+		} else {
+			m.limits = limits
+		}
+
 		if err := m.checkChainData(ctx); err != nil {
 			log.Errorf("failed to check chain data: %s", err)
 		}
@@ -165,25 +342,17 @@ func (m *MinerData) checkChainData(ctx context.Context) error {
 		return err
 	}
 
+	offset := uint64(0)
 	for m.checkHeight < head.Height() {
 		if m.stopping != nil {
 			break
 		}
 
-		datas, err := m.api.StateDataIndex(ctx, m.checkHeight, types.EmptyTSK)
+		page, err := m.api.StateDataIndexRange(ctx, m.checkHeight, head.Height()-1, offset, dataIndexPageSize, head.Key())
 		if err != nil {
 			return err
 		}
-		for _, data := range datas {
-			// info, err := m.api.StateExpertFileInfo(ctx, data.PieceCID, types.EmptyTSK)
-			// if err != nil {
-			// 	log.Error("failed to load expert data:", err)
-			// 	return err
-			// }
-			// if info.Redundancy <= 10 {
-			// 	log.Infof("ignore data not reach the threshold:%v", data.PieceCID)
-			// 	continue
-			// }
+		for _, data := range page.Indexes {
 			var dataRef *DataRef
 			ref, ok := m.dataRefs.Get(data.PieceCID.String())
 			if !ok {
@@ -194,16 +363,27 @@ func (m *MinerData) checkChainData(ctx context.Context) error {
 					miners:      make(map[address.Address]int),
 					isRetrieved: false,
 					isDealed:    false,
+					state:       api.MinerDataDiscovered,
 				}
 				m.totalDataCount++
 			} else {
 				dataRef = ref.(*DataRef)
 			}
-			dataRef.miners[data.Miner] = MinerDefaultScore
+			dataRef.miners[data.Miner] = m.limits.MinerDefaultScore
 			m.dataRefs.Add(data.PieceCID.String(), dataRef)
+			m.persist(dataRef)
 		}
 
-		m.checkHeight++
+		// a partially read epoch is checked again after a restart
+		m.checkHeight, offset = page.NextEpoch, page.NextOffset
+		if m.ledger != nil {
+			if err := m.ledger.setHeight(m.checkHeight); err != nil {
+				return err
+			}
+		}
+		if page.Done {
+			break
+		}
 	}
 	return nil
 }
@@ -214,7 +394,7 @@ func (m *MinerData) loadRetrievalData(ctx context.Context) (*lru.ARCCache, error
 		return nil, err
 	}
 	m.minerInfo = info
-	retrievals, err := lru.NewARC(RetrieveParallelNum * 2)
+	retrievals, err := lru.NewARC(maxDataParallelNum * 2)
 	if err != nil {
 		return nil, err
 	}
@@ -228,8 +408,13 @@ func (m *MinerData) loadRetrievalData(ctx context.Context) (*lru.ARCCache, error
 			dataObj, ok := m.dataRefs.Get(d.PieceCID.String())
 			if ok {
 				data := dataObj.(*DataRef)
-				data.isRetrieved = true
-				m.totalRetrieveCount++
+				if !data.isRetrieved {
+					data.isRetrieved = true
+					m.totalRetrieveCount++
+					if !data.isDealed {
+						m.setState(data, api.MinerDataRetrieved, "")
+					}
+				}
 			}
 		}
 		if !(d.Status == retrievalmarket.DealStatusErrored ||
@@ -251,7 +436,8 @@ func (m *MinerData) retrieveChainData(ctx context.Context) error {
 	}
 	// check retrieve deals state
 	retrieveKeys := m.retrievals.Keys()
-	for _, rk := range retrieveKeys {
+	for _, key := range retrieveKeys {
+		rk := key.(string)
 		dealObj, _ := m.retrievals.Get(rk)
 		deal := dealObj.(*api.RetrievalDeal)
 
@@ -259,17 +445,20 @@ func (m *MinerData) retrieveChainData(ctx context.Context) error {
 		if err != nil {
 			return err
 		}
+		m.recordProgress(rk, nDeal)
 
 		dataObj, _ := m.dataRefs.Get(rk)
 		data := dataObj.(*DataRef)
 		if !data.isRetrieved && retrievalmarket.IsTerminalSuccess(nDeal.Status) {
 			data.isRetrieved = true
 			m.totalRetrieveCount++
+			m.setState(data, api.MinerDataRetrieved, "")
 		}
 		if nDeal.Status == retrievalmarket.DealStatusErrored ||
 			nDeal.Status == retrievalmarket.DealStatusCancelled ||
 			retrievalmarket.IsTerminalStatus(nDeal.Status) {
 			m.retrievals.Remove(rk)
+			m.recordRetrieval(rk, deal.Miner, nDeal)
 			if !retrievalmarket.IsTerminalSuccess(nDeal.Status) {
 				data.tryCount++
 				if _, ok := data.miners[deal.Miner]; ok {
@@ -278,16 +467,18 @@ func (m *MinerData) retrieveChainData(ctx context.Context) error {
 						data.miners[deal.Miner] = 1
 					}
 				}
+				m.setState(data, api.MinerDataFailed, fmt.Sprintf("retrieval from %s %s: %s", deal.Miner, retrievalmarket.DealStatuses[nDeal.Status], nDeal.Message))
 			}
 		}
 	}
-	if m.retrievals.Len() >= RetrieveParallelNum {
+	if m.retrievals.Len() >= m.limits.RetrieveParallelNum {
 		log.Infof("wait for retrieval:%d", m.retrievals.Len())
 		return nil
 	}
 
 	keys := m.dataRefs.Keys()
-	for _, rk := range keys {
+	for _, key := range keys {
+		rk := key.(string)
 		dataObj, _ := m.dataRefs.Get(rk)
 		data := dataObj.(*DataRef)
 
@@ -305,6 +496,7 @@ func (m *MinerData) retrieveChainData(ctx context.Context) error {
 				if !data.isRetrieved {
 					data.isRetrieved = true
 					m.totalRetrieveCount++
+					m.setState(data, api.MinerDataRetrieved, "")
 				}
 				continue
 			}
@@ -320,6 +512,7 @@ func (m *MinerData) retrieveChainData(ctx context.Context) error {
 				if retrievalmarket.IsTerminalSuccess(d.Status) {
 					data.isRetrieved = true
 					m.totalRetrieveCount++
+					m.setState(data, api.MinerDataRetrieved, "")
 					hasRetrieving = true
 				}
 				if !(d.Status == retrievalmarket.DealStatusErrored ||
@@ -344,10 +537,15 @@ func (m *MinerData) retrieveChainData(ctx context.Context) error {
 				data.isRetrieved = true
 				m.totalRetrieveCount++
 			}
+			if !data.isDealed {
+				data.isDealed = true
+				m.totalDealCount++
+			}
+			m.setState(data, api.MinerDataStored, "")
 			continue
 		}
 
-		if m.retrievals.Len() >= RetrieveParallelNum {
+		if m.retrievals.Len() >= m.limits.RetrieveParallelNum {
 			log.Infof("wait for retrieval:%d", m.retrievals.Len())
 			break
 		}
@@ -358,14 +556,33 @@ func (m *MinerData) retrieveChainData(ctx context.Context) error {
 			}
 		}
 
-		var addrs []address.Address
-		for k, v := range data.miners {
-			for i := 0; i < v; i++ {
-				addrs = append(addrs, k)
+		if m.limits.RetrieveTryCountMax > 0 && data.tryCount >= m.limits.RetrieveTryCountMax {
+			until := data.retryTime.Add(dataGiveUpPeriod)
+			if time.Now().Before(until) {
+				reason := fmt.Sprintf("gave up after %d retrievals until %s", data.tryCount, until.Format(time.RFC3339))
+				if data.reason != reason {
+					m.setState(data, api.MinerDataFailed, reason)
+				}
+				continue
 			}
+			data.tryCount = 0
+			m.persist(data)
+		}
+
+		piece, err := m.candidate(ctx, data)
+		if err != nil {
+			log.Warnf("failed to load data info %s: %s", data.pieceID, err)
+			continue
+		}
+		if ok, reason := m.limits.Filter.Accept(piece); !ok {
+			log.Debugf("skip retrieving data %s: %s", data.pieceID, reason)
+			continue
 		}
 
-		miner := addrs[rand.Intn(len(addrs))]
+		miner, ok := m.pickSource(data)
+		if !ok {
+			continue
+		}
 		deal, err := m.api.ClientRetrieveQuery(ctx, m.minerInfo.Owner, data.rootCID, &data.pieceID, miner)
 		if err != nil {
 			if _, ok := data.miners[miner]; ok {
@@ -374,6 +591,8 @@ func (m *MinerData) retrieveChainData(ctx context.Context) error {
 					data.miners[miner] = 1
 				}
 			}
+			m.recordQueryFailure(miner)
+			m.setState(data, api.MinerDataFailed, fmt.Sprintf("retrieval query to %s: %s", miner, err))
 			log.Warnf("failed to retrieve miner:%s, data:%s, try:%d, err:%s", miner, data.rootCID, data.tryCount, err)
 			// if data.tryCount > RetrieveTryCountMax {
 			// 	for index, m := range data.miners {
@@ -388,6 +607,8 @@ func (m *MinerData) retrieveChainData(ctx context.Context) error {
 		}
 		data.tryCount++
 		data.retryTime = time.Now()
+		m.setState(data, api.MinerDataRetrieving, "")
+		m.trackRetrieval(rk, miner)
 		log.Warnf("client retrieve miner:%s, data:%s", miner, data.rootCID)
 
 		m.retrievals.Add(rk, deal)
@@ -395,6 +616,34 @@ func (m *MinerData) retrieveChainData(ctx context.Context) error {
 	return nil
 }
 
+// candidate describes data for the replication policy. Its expert file info
+// is only loaded when the policy uses it, on first use, and reloaded once
+// stale. If the info was never loaded, it fails and the piece is tried again
+// on the next round; a failed reload keeps the last info.
+func (m *MinerData) candidate(ctx context.Context, data *DataRef) (*DataCandidate, error) {
+	if m.limits.NeedFileInfo && time.Since(data.infoLoadedAt) >= dataInfoTTL {
+		info, err := m.api.StateExpertFileInfo(ctx, data.pieceID, types.EmptyTSK)
+		switch {
+		case err == nil:
+			data.expert = info.Expert
+			data.pieceSize = info.PieceSize
+			data.redundancy = info.Redundancy
+			data.infoLoadedAt = time.Now()
+		case data.infoLoadedAt.IsZero():
+			return nil, err
+		default:
+			log.Warnf("failed to reload data info %s, using the last one: %s", data.pieceID, err)
+		}
+	}
+	return &DataCandidate{
+		PieceCID:   data.pieceID,
+		RootCID:    data.rootCID,
+		Expert:     data.expert,
+		PieceSize:  data.pieceSize,
+		Redundancy: data.redundancy,
+	}, nil
+}
+
 func checkDealStatus(deal *api.DealInfo) (bool, bool) {
 	// isDealed := (deal.State == storagemarket.StorageDealAwaitingPreCommit ||
 	// 	deal.State == storagemarket.StorageDealSealing ||
@@ -408,7 +657,7 @@ func checkDealStatus(deal *api.DealInfo) (bool, bool) {
 }
 
 func (m *MinerData) loadStorageData(ctx context.Context) (*lru.ARCCache, error) {
-	storages, err := lru.NewARC(DealParallelNum * 2)
+	storages, err := lru.NewARC(maxDataParallelNum * 2)
 	if err != nil {
 		return nil, err
 	}
@@ -424,8 +673,11 @@ func (m *MinerData) loadStorageData(ctx context.Context) (*lru.ARCCache, error)
 				isFinish, isDealed := checkDealStatus(&d)
 				if isDealed {
 					data := dataObj.(*DataRef)
-					data.isDealed = true
-					m.totalDealCount++
+					if !data.isDealed {
+						data.isDealed = true
+						m.totalDealCount++
+						m.setState(data, api.MinerDataStored, "")
+					}
 				}
 				if !isFinish {
 					storages.Add(d.PieceCID.String(), d.ProposalCid)
@@ -459,12 +711,16 @@ func (m *MinerData) storageChainData(ctx context.Context) error {
 		if !data.isDealed && isDealed {
 			data.isDealed = true
 			m.totalDealCount++
+			m.setState(data, api.MinerDataStored, "")
 		}
 		if isFinish {
 			m.deals.Remove(rk)
+			if !isDealed {
+				m.setState(data, api.MinerDataFailed, fmt.Sprintf("deal %s %s: %s", dealID, storagemarket.DealStates[deal.State], deal.Message))
+			}
 		}
 	}
-	if m.deals.Len() >= DealParallelNum {
+	if m.deals.Len() >= m.limits.DealParallelNum {
 		log.Infof("wait for deal:%d", m.deals.Len())
 		return nil
 	}
@@ -496,11 +752,12 @@ func (m *MinerData) storageChainData(ctx context.Context) error {
 			if !data.isDealed {
 				data.isDealed = true
 				m.totalDealCount++
+				m.setState(data, api.MinerDataStored, "")
 			}
 			continue
 		}
 
-		if m.deals.Len() >= DealParallelNum {
+		if m.deals.Len() >= m.limits.DealParallelNum {
 			log.Infof("wait for deal:%d", m.deals.Len())
 			break
 		}
@@ -511,6 +768,22 @@ func (m *MinerData) storageChainData(ctx context.Context) error {
 			}
 		}
 
+		piece, err := m.candidate(ctx, data)
+		if err != nil {
+			log.Warnf("failed to load data info %s: %s", data.pieceID, err)
+			continue
+		}
+		if ok, reason := m.limits.Filter.Accept(piece); !ok {
+			log.Debugf("skip storing data %s: %s", data.pieceID, reason)
+			continue
+		}
+		if ok, reason, err := m.policy.ReadyToDeal(ctx, piece); err != nil {
+			return xerrors.Errorf("checking data policy: %w", err)
+		} else if !ok {
+			log.Infof("wait for deal data %s: %s", data.pieceID, reason)
+			break
+		}
+
 		stData := &storagemarket.DataRef{
 			TransferType: storagemarket.TTGraphsync,
 			Root:         data.rootCID,
@@ -527,10 +800,13 @@ func (m *MinerData) storageChainData(ctx context.Context) error {
 		data.tryCount++
 		data.retryTime = time.Now()
 		if err != nil {
+			m.setState(data, api.MinerDataFailed, fmt.Sprintf("start deal: %s", err))
 			log.Warnf("failed to start deal: %s", err)
 			continue
 		}
 		log.Warnf("start deal with miner:%s deal: %s", m.miner, dealID.String())
+		m.setState(data, api.MinerDataDealing, "")
+		m.policy.DealStarted(piece)
 		m.deals.Add(rk, *dealID)
 	}
 	return nil
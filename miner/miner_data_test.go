@@ -0,0 +1,85 @@
+package miner
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	tutils "github.com/filecoin-project/specs-actors/v2/support/testing"
+	"github.com/ipfs/go-cid"
+	ds "github.com/ipfs/go-datastore"
+	ds_sync "github.com/ipfs/go-datastore/sync"
+	"github.com/stretchr/testify/require"
+
+	"github.com/EpiK-Protocol/go-epik/api"
+	"github.com/EpiK-Protocol/go-epik/chain/types"
+)
+
+// fileInfoAPI serves the expert file info of a single piece.
+type fileInfoAPI struct {
+	api.FullNode
+
+	info  api.ExpertFileInfo
+	err   error
+	calls int
+}
+
+func (a *fileInfoAPI) StateExpertFileInfo(context.Context, cid.Cid, types.TipSetKey) (*api.ExpertFileInfo, error) {
+	a.calls++
+	if a.err != nil {
+		return nil, a.err
+	}
+	info := a.info
+	return &info, nil
+}
+
+func TestDataCandidateInfo(t *testing.T) {
+	ctx := context.Background()
+	mds := ds_sync.MutexWrap(ds.NewMapDatastore())
+	napi := &fileInfoAPI{err: errors.New("not synced")}
+	m := newMinerData(napi, tutils.NewIDAddr(t, 100), mds, nil)
+
+	data := &DataRef{pieceID: tutils.MakeCID("piece", nil)}
+
+	// the info isn't loaded unless the policy uses it
+	piece, err := m.candidate(ctx, data)
+	require.NoError(t, err)
+	require.Equal(t, data.pieceID, piece.PieceCID)
+	require.Equal(t, 0, napi.calls)
+
+	m.limits.NeedFileInfo = true
+
+	// a failed lookup is retried
+	_, err = m.candidate(ctx, data)
+	require.Error(t, err)
+
+	napi.err = nil
+	napi.info = api.ExpertFileInfo{Expert: tutils.NewIDAddr(t, 101), PieceSize: abi.PaddedPieceSize(128), Redundancy: 1}
+	piece, err = m.candidate(ctx, data)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), piece.Redundancy)
+	require.Equal(t, 2, napi.calls)
+
+	// fresh info isn't reloaded
+	napi.info.Redundancy = 2
+	piece, err = m.candidate(ctx, data)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), piece.Redundancy)
+	require.Equal(t, 2, napi.calls)
+
+	// stale info is
+	data.infoLoadedAt = time.Now().Add(-dataInfoTTL)
+	piece, err = m.candidate(ctx, data)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), piece.Redundancy)
+
+	// and kept if reloading fails
+	data.infoLoadedAt = time.Now().Add(-dataInfoTTL)
+	napi.err = errors.New("not synced")
+	piece, err = m.candidate(ctx, data)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), piece.Redundancy)
+	require.Equal(t, 4, napi.calls)
+}
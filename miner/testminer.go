@@ -20,7 +20,7 @@ type MineReq struct {
 }
 
 func NewTestMiner(nextCh <-chan MineReq, addr address.Address) func(api.FullNode, gen.WinningPoStProver) *Miner {
-	return func(api api.FullNode, epp gen.WinningPoStProver) *Miner {
+	return func(napi api.FullNode, epp gen.WinningPoStProver) *Miner {
 		arc, err := lru.NewARC(10000)
 		if err != nil {
 			panic(err)
@@ -40,7 +40,7 @@ func NewTestMiner(nextCh <-chan MineReq, addr address.Address) func(api.FullNode
 		}
 
 		m := &Miner{
-			api:               api,
+			api:               napi,
 			waitFunc:          chanWaiter(nextCh),
 			epp:               epp,
 			minedBlockHeights: arc,
@@ -48,11 +48,15 @@ func NewTestMiner(nextCh <-chan MineReq, addr address.Address) func(api.FullNode
 			sf:                slashfilter.New(ds.NewMapDatastore()),
 			journal:           journal.NilJournal(),
 			minerData: &MinerData{
-				api:        api,
+				api:        napi,
 				miner:      addr,
+				ledger:     newDataLedger(ds.NewMapDatastore()),
+				policy:     DefaultDataPolicy(),
 				dataRefs:   data,
 				retrievals: retrievals,
 				deals:      deals,
+				sources:    make(map[address.Address]*api.RetrievalSourceStats),
+				tracks:     make(map[string]*retrievalTrack),
 			},
 		}
 
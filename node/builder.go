@@ -51,13 +51,14 @@ import (
 	"github.com/EpiK-Protocol/go-epik/chain/types"
 	ledgerwallet "github.com/EpiK-Protocol/go-epik/chain/wallet/ledger"
 	"github.com/EpiK-Protocol/go-epik/chain/wallet/remotewallet"
+	"github.com/EpiK-Protocol/go-epik/expertwatch"
 	sectorstorage "github.com/EpiK-Protocol/go-epik/extern/sector-storage"
 	"github.com/EpiK-Protocol/go-epik/extern/sector-storage/ffiwrapper"
 	"github.com/EpiK-Protocol/go-epik/extern/sector-storage/stores"
 	"github.com/EpiK-Protocol/go-epik/extern/sector-storage/storiface"
 	sealing "github.com/EpiK-Protocol/go-epik/extern/storage-sealing"
 	"github.com/EpiK-Protocol/go-epik/flowchmgr"
-	flowsettler "github.com/EpiK-Protocol/go-epik/flowchmgr/settler"
+	"github.com/EpiK-Protocol/go-epik/govhistory"
 	"github.com/EpiK-Protocol/go-epik/journal"
 	"github.com/EpiK-Protocol/go-epik/lib/peermgr"
 	_ "github.com/EpiK-Protocol/go-epik/lib/sigs/bls"
@@ -77,6 +78,7 @@ import (
 	"github.com/EpiK-Protocol/go-epik/node/repo"
 	"github.com/EpiK-Protocol/go-epik/paychmgr"
 	"github.com/EpiK-Protocol/go-epik/paychmgr/settler"
+	"github.com/EpiK-Protocol/go-epik/retrievalpledge"
 	"github.com/EpiK-Protocol/go-epik/storage"
 	"github.com/EpiK-Protocol/go-epik/storage/sectorblocks"
 )
@@ -149,6 +151,9 @@ const (
 	HeadMetricsKey
 	SettlePaymentChannelsKey
 	SettleFlowChannelsKey
+	WatchExpertsKey
+	AutoVoteRewardsKey
+	AutoRetrievalWithdrawKey
 	RunPeerTaggerKey
 	SetupFallbackBlockstoresKey
 
@@ -309,6 +314,7 @@ var ChainNode = Options(
 	Override(new(*messagesigner.MessageSigner), messagesigner.NewMessageSigner),
 	Override(new(*wallet.LocalWallet), wallet.NewWallet),
 	Override(new(wallet.Default), From(new(*wallet.LocalWallet))),
+	Override(new(wallet.Locker), From(new(*wallet.LocalWallet))),
 	Override(new(api.WalletAPI), From(new(wallet.MultiWallet))),
 
 	// Service: Payment channels
@@ -321,7 +327,7 @@ var ChainNode = Options(
 	Override(new(*flowchmgr.Store), flowchmgr.NewStore),
 	Override(new(*flowchmgr.Manager), flowchmgr.NewManager),
 	Override(HandleFlowChannelManagerKey, flowchmgr.HandleManager),
-	Override(SettleFlowChannelsKey, flowsettler.SettlePaymentChannels),
+	Override(SettleFlowChannelsKey, settler.SettleFlowChannels),
 
 	// Markets (common)
 	Override(new(*discoveryimpl.Local), modules.NewLocalDiscovery),
@@ -398,6 +404,7 @@ var MinerNode = Options(
 	Override(new(*slashfilter.SlashFilter), modules.NewSlashFilter),
 	Override(new(*storage.Miner), modules.StorageMiner(config.DefaultStorageMiner().Fees)),
 	Override(new(*miner.Miner), modules.SetupBlockProducer),
+	Override(new(miner.DataPolicy), modules.NewDataPolicy),
 	Override(new(gen.WinningPoStProver), storage.NewWinningPoStProver),
 
 	Override(new(*storage.AddressSelector), modules.AddressSelector(nil)),
@@ -550,6 +557,30 @@ func ConfigFullNode(c interface{}) Option {
 			Override(HeadMetricsKey, metrics.SendHeadNotifs(cfg.Metrics.Nickname)),
 		),
 
+		If(len(cfg.ExpertWatch.Experts) > 0,
+			Override(WatchExpertsKey, expertwatch.WatchExperts(cfg.ExpertWatch)),
+		),
+
+		If(len(cfg.VoteRewards.Voters) > 0,
+			Override(AutoVoteRewardsKey, modules.AutoVoteRewards(cfg.VoteRewards)),
+		),
+
+		If(cfg.GovernIndex.Enable,
+			Override(new(*govhistory.Index), govhistory.NewIndex),
+		),
+
+		If(len(cfg.RetrievalPledge.Wallets) > 0,
+			Override(new(*retrievalpledge.Manager), modules.RetrievalPledgeManager(cfg.RetrievalPledge)),
+		),
+
+		If(cfg.FlowchAuto.Enable,
+			Override(new(*paychmgr.AutoSettler), modules.FlowchAutoSettler(cfg.FlowchAuto)),
+		),
+
+		If(len(cfg.RetrievalWithdraw.Wallets) > 0,
+			Override(AutoRetrievalWithdrawKey, modules.AutoRetrievalWithdraw(cfg.RetrievalWithdraw)),
+		),
+
 		If(cfg.Wallet.RemoteBackend != "",
 			Override(new(*remotewallet.RemoteWallet), remotewallet.SetupRemoteWallet(cfg.Wallet.RemoteBackend)),
 		),
@@ -559,6 +590,7 @@ func ConfigFullNode(c interface{}) Option {
 		If(cfg.Wallet.DisableLocal,
 			Unset(new(*wallet.LocalWallet)),
 			Override(new(wallet.Default), wallet.NilDefault),
+			Override(new(wallet.Locker), wallet.NilLocker),
 		),
 	)
 }
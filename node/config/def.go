@@ -21,11 +21,19 @@ type Common struct {
 // FullNode is a full node config
 type FullNode struct {
 	Common
-	Client     Client
-	Metrics    Metrics
-	Wallet     Wallet
-	Fees       FeeConfig
-	Chainstore Chainstore
+	Client      Client
+	Metrics     Metrics
+	Wallet      Wallet
+	Fees        FeeConfig
+	Chainstore  Chainstore
+	ExpertWatch ExpertWatch
+	VoteRewards VoteRewards
+	GovernIndex GovernIndex
+
+	RetrievalPledge   RetrievalPledge
+	RetrievalWithdraw RetrievalWithdraw
+
+	FlowchAuto FlowchAuto
 }
 
 // // Common
@@ -43,6 +51,8 @@ type StorageMiner struct {
 	Storage    sectorstorage.SealerConfig
 	Fees       MinerFeeConfig
 	Addresses  MinerAddressConfig
+
+	DataReplication DataReplicationConfig
 }
 
 type DealmakingConfig struct {
@@ -87,6 +97,33 @@ type SealingConfig struct {
 	// todo TargetSectors - stop auto-pleding new sectors after this many sectors are sealed, default CC upgrade for deals sectors if above
 }
 
+// DataReplicationConfig controls the automatic retrieval and storage of data
+// registered on chain. It is re-read on every replication round, so changes
+// take effect without restarting the miner.
+type DataReplicationConfig struct {
+	// Number of retrievals / storage deals running in parallel, at least 1
+	RetrieveParallelNum int
+	DealParallelNum     int
+	// Rest a piece for a day after this many failed retrievals, 0 = no limit
+	RetrieveTryCountMax int
+	// Initial score of the miners a piece can be retrieved from
+	MinerDefaultScore int
+
+	// Only replicate data of these experts, empty = all experts
+	Experts []string
+	// Piece size limits in bytes, 0 = no limit
+	MinPieceSize uint64
+	MaxPieceSize uint64
+	// Only replicate data whose on-chain redundancy is within the limits, 0 = no limit
+	MinRedundancy uint64
+	MaxRedundancy uint64
+
+	// Don't start new deals while this many sectors are in the sealing pipeline, 0 = no limit
+	MaxSealingSectors uint64
+	// Maximum bytes of piece data to store per day (UTC), 0 = no limit
+	DailyByteBudget uint64
+}
+
 type MinerFeeConfig struct {
 	MaxPreCommitGasFee     types.EPK
 	MaxCommitGasFee        types.EPK
@@ -175,6 +212,85 @@ type Wallet struct {
 	DisableLocal  bool
 }
 
+type ExpertWatch struct {
+	// Experts lists the addresses of the experts to watch.
+	Experts []string
+	// Webhook receives the expert events as JSON POST requests.
+	Webhook string
+	// VoteWarningMargin is the percentage above the required votes under
+	// which a vote warning is raised, before the expert falls short.
+	VoteWarningMargin uint64
+}
+
+type RetrievalPledge struct {
+	// Wallets lists the client wallets whose retrieval pledge is managed.
+	Wallets []string
+	// Interval between checks of the pledges.
+	Interval Duration
+	// RunwayDays is the number of days of average spend the pledge is topped
+	// up to cover.
+	RunwayDays uint64
+	// MinTopUp is the least amount pledged at once, in EPK.
+	MinTopUp string
+	// MaxTopUp caps the amount pledged at once in EPK, no cap when empty.
+	MaxTopUp string
+	// AutoBind binds the miners the wallets retrieve from to their pledge.
+	AutoBind bool
+	// BytesPerEPK is the size a pledge of 1 EPK allows to retrieve in a day,
+	// used to reject retrievals the pledge doesn't cover. 0 disables the check.
+	BytesPerEPK uint64
+}
+
+type RetrievalWithdraw struct {
+	// Wallets lists the client wallets whose retrieval pledge funds applied
+	// for withdrawal are withdrawn as soon as their lock expires.
+	Wallets []string
+}
+
+type FlowchAuto struct {
+	// Enable settles the inbound flow channels according to the policy below,
+	// and collects them once their settling period is over.
+	Enable bool
+	// Interval between checks of the channels.
+	Interval Duration
+	// IdleEpochs settles the channels whose redeemable vouchers didn't change
+	// for this many epochs. 0 disables the criteria.
+	IdleEpochs uint64
+	// ValueThreshold settles the channels whose redeemable vouchers reach this
+	// amount, in EPK. Empty disables the criteria.
+	ValueThreshold string
+}
+
+type GovernIndex struct {
+	// Enable records the changes of the governance params and authorities,
+	// served by StateGovernParamsHistory. The first start indexes the chain
+	// from genesis in the background.
+	Enable bool
+}
+
+type VoteRewards struct {
+	// Interval between checks of the voters' rewards.
+	Interval Duration
+	// DryRun logs the messages which would be pushed instead of pushing them.
+	DryRun bool
+	Voters []VoterRewards
+}
+
+type VoterRewards struct {
+	// Voter is the wallet address voting for experts.
+	Voter string
+	// MinWithdraw is the least amount of rewards worth a withdrawal, in EPK.
+	MinWithdraw string
+	// Compound votes the withdrawn rewards again.
+	Compound bool
+	// Candidates split the compounded rewards evenly. When empty, the rewards
+	// are split in proportion to the current votes of the voter.
+	Candidates []string
+	// MaxFee caps the fee of each message in EPK, the default max fee applies
+	// when empty.
+	MaxFee string
+}
+
 type FeeConfig struct {
 	DefaultMaxFee types.EPK
 }
@@ -226,6 +342,21 @@ func DefaultFullNode() *FullNode {
 				CompactionMultiplier: 1,
 			},
 		},
+		ExpertWatch: ExpertWatch{
+			VoteWarningMargin: 10,
+		},
+		VoteRewards: VoteRewards{
+			Interval: Duration(time.Hour),
+		},
+		FlowchAuto: FlowchAuto{
+			Interval:   Duration(time.Minute),
+			IdleEpochs: 2880, // a day
+		},
+		RetrievalPledge: RetrievalPledge{
+			Interval:   Duration(10 * time.Minute),
+			RunwayDays: 7,
+			AutoBind:   true,
+		},
 	}
 }
 
@@ -281,6 +412,14 @@ func DefaultStorageMiner() *StorageMiner {
 			PreCommitControl: []string{},
 			CommitControl:    []string{},
 		},
+
+		DataReplication: DataReplicationConfig{
+			RetrieveParallelNum: 16,
+			DealParallelNum:     16,
+			RetrieveTryCountMax: 50,
+			MinerDefaultScore:   8,
+			Experts:             []string{},
+		},
 	}
 	cfg.Common.API.ListenAddress = "/ip4/127.0.0.1/tcp/2345/http"
 	cfg.Common.API.RemoteListenAddress = "127.0.0.1:2345"
@@ -17,6 +17,7 @@ import (
 	"github.com/filecoin-project/go-state-types/big"
 	"github.com/filecoin-project/go-state-types/dline"
 	"github.com/filecoin-project/specs-actors/v2/actors/builtin"
+	blocks "github.com/ipfs/go-block-format"
 	"github.com/ipfs/go-blockservice"
 	"github.com/ipfs/go-cid"
 	"github.com/ipfs/go-cidutil"
@@ -28,7 +29,9 @@ import (
 	unixfile "github.com/ipfs/go-unixfs/file"
 	"github.com/ipfs/go-unixfs/importer/balanced"
 	ihelper "github.com/ipfs/go-unixfs/importer/helpers"
+	uio "github.com/ipfs/go-unixfs/io"
 	"github.com/ipld/go-car"
+	ipldprime "github.com/ipld/go-ipld-prime"
 	basicnode "github.com/ipld/go-ipld-prime/node/basic"
 	"github.com/ipld/go-ipld-prime/traversal/selector"
 	"github.com/ipld/go-ipld-prime/traversal/selector/builder"
@@ -65,6 +68,8 @@ import (
 	"github.com/EpiK-Protocol/go-epik/node/impl/paych"
 	"github.com/EpiK-Protocol/go-epik/node/modules/dtypes"
 	"github.com/EpiK-Protocol/go-epik/node/repo/importmgr"
+	"github.com/EpiK-Protocol/go-epik/node/repo/retrievalstoremgr"
+	"github.com/EpiK-Protocol/go-epik/retrievalpledge"
 )
 
 var DefaultHashFunction = uint64(mh.BLAKE2B_MIN + 31)
@@ -93,6 +98,8 @@ type API struct {
 	RetrievalStoreMgr dtypes.ClientRetrievalStoreManager
 	DataTransfer      dtypes.ClientDataTransfer
 	Host              host.Host
+
+	PledgeMgr *retrievalpledge.Manager `optional:"true"`
 }
 
 func calcDealExpiration(minDuration uint64, md *dline.Info, startEpoch abi.ChainEpoch) abi.ChainEpoch {
@@ -582,26 +589,16 @@ func (a *API) ClientExpertRegisterFile(ctx context.Context, params *api.ExpertRe
 		return nil, xerrors.Errorf("failed to get expert info: %w", err)
 	}
 
-	expertParams, err := actors.SerializeParams(&expert.BatchImportDataParams{
-		Datas: []expert.ImportDataParams{
-			expert.ImportDataParams{
-				RootID:    params.RootID,
-				PieceID:   params.PieceID,
-				PieceSize: params.PieceSize,
-			},
-		},
-	})
+	msg, err := importDataMessage(params.Expert, expertInfo.Owner, []api.ExpertFileImport{{
+		RootID:    params.RootID,
+		PieceID:   params.PieceID,
+		PieceSize: params.PieceSize,
+	}})
 	if err != nil {
-		return nil, xerrors.Errorf("serializing params failed: %w", err)
+		return nil, err
 	}
 
-	sm, err := a.MpoolAPI.MpoolPushMessage(ctx, &types.Message{
-		To:     params.Expert,
-		From:   expertInfo.Owner,
-		Value:  types.NewInt(0),
-		Method: builtin.MethodsExpert.ImportData,
-		Params: expertParams,
-	}, nil)
+	sm, err := a.MpoolAPI.MpoolPushMessage(ctx, msg, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -610,6 +607,129 @@ func (a *API) ClientExpertRegisterFile(ctx context.Context, params *api.ExpertRe
 	return &mid, nil
 }
 
+// defaultRegisterBatchSize is the number of files tried per import message
+// before splitting for gas.
+const defaultRegisterBatchSize = 256
+
+func (a *API) ClientExpertRegisterFiles(ctx context.Context, params *api.ExpertRegisterFilesParams) ([]api.ExpertRegisterBatch, error) {
+	expertInfo, err := a.StateExpertInfo(ctx, params.Expert, types.EmptyTSK)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to get expert info: %w", err)
+	}
+
+	head, err := a.ChainHead(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// drop duplicates and pieces registered already
+	seen := make(map[cid.Cid]struct{}, len(params.Files))
+	pending := make([]api.ExpertFileImport, 0, len(params.Files))
+	for _, f := range params.Files {
+		if _, ok := seen[f.PieceID]; ok {
+			continue
+		}
+		seen[f.PieceID] = struct{}{}
+
+		existence, err := a.StateExpertFileInfo(ctx, f.PieceID, head.Key())
+		if err != nil && !strings.Contains(err.Error(), "piece not found") {
+			return nil, xerrors.Errorf("failed to check file registered %s: %w", f.PieceID, err)
+		}
+		if existence != nil {
+			if existence.Expert != params.Expert {
+				log.Warnf("piece %s already registered by expert %s", f.PieceID, existence.Expert)
+			}
+			continue
+		}
+		pending = append(pending, f)
+	}
+
+	batchSize := params.MaxBatchSize
+	if batchSize <= 0 {
+		batchSize = defaultRegisterBatchSize
+	}
+
+	// the messages pushed must be reported whatever happens next, so errors
+	// end the result instead of being returned
+	var out []api.ExpertRegisterBatch
+	for len(pending) > 0 {
+		n, mcid, err := a.pushRegisterBatch(ctx, params.Expert, expertInfo.Owner, pending, batchSize)
+		if err != nil {
+			return append(out, api.ExpertRegisterBatch{
+				Files: append([]api.ExpertFileImport(nil), pending...),
+				Error: err.Error(),
+			}), nil
+		}
+
+		out = append(out, api.ExpertRegisterBatch{
+			Message: mcid,
+			Files:   append([]api.ExpertFileImport(nil), pending[:n]...),
+		})
+		pending = pending[n:]
+	}
+
+	return out, nil
+}
+
+// pushRegisterBatch pushes a message registering at most batchSize of the
+// first pending files, and returns how many it registers.
+func (a *API) pushRegisterBatch(ctx context.Context, expertAddr, owner address.Address, pending []api.ExpertFileImport, batchSize int) (int, cid.Cid, error) {
+	n := batchSize
+	if n > len(pending) {
+		n = len(pending)
+	}
+
+	// halve the batch until it fits in the gas target of a block
+	var msg *types.Message
+	for {
+		var err error
+		msg, err = importDataMessage(expertAddr, owner, pending[:n])
+		if err != nil {
+			return 0, cid.Undef, err
+		}
+		gasLimit, err := a.MpoolAPI.GasEstimateGasLimit(ctx, msg, types.EmptyTSK)
+		if err != nil {
+			return 0, cid.Undef, xerrors.Errorf("estimating gas of %d files: %w", n, err)
+		}
+		if gasLimit <= build.BlockGasTarget || n == 1 {
+			break
+		}
+		n /= 2
+	}
+
+	sm, err := a.MpoolAPI.MpoolPushMessage(ctx, msg, nil)
+	if err != nil {
+		return 0, cid.Undef, xerrors.Errorf("pushing import message: %w", err)
+	}
+	return n, sm.Cid(), nil
+}
+
+func importDataMessage(expertAddr, owner address.Address, files []api.ExpertFileImport) (*types.Message, error) {
+	datas := make([]expert.ImportDataParams, 0, len(files))
+	for _, f := range files {
+		datas = append(datas, expert.ImportDataParams{
+			RootID:    f.RootID,
+			PieceID:   f.PieceID,
+			PieceSize: f.PieceSize,
+		})
+	}
+
+	expertParams, err := actors.SerializeParams(&expert.BatchImportDataParams{
+		Datas: datas,
+	})
+	if err != nil {
+		return nil, xerrors.Errorf("serializing params failed: %w", err)
+	}
+
+	return &types.Message{
+		To:     expertAddr,
+		From:   owner,
+		Value:  types.NewInt(0),
+		Method: builtin.MethodsExpert.ImportData,
+		Params: expertParams,
+	}, nil
+}
+
 func (a *API) ClientRemoveImport(ctx context.Context, importID multistore.StoreID) error {
 	return a.imgr().Remove(importID)
 }
@@ -818,6 +938,13 @@ func (a *API) clientRetrieve(ctx context.Context, order api.RetrievalOrder, ref
 		return
 	}
 
+	if a.PledgeMgr != nil {
+		if err := a.PledgeMgr.CheckRetrieval(ctx, order.Client, order.Miner, order.Size); err != nil {
+			finish(xerrors.Errorf("checking retrieval pledge: %w", err))
+			return
+		}
+	}
+
 	/*id, st, err := a.imgr().NewStore()
 	if err != nil {
 		return err
@@ -826,11 +953,9 @@ func (a *API) clientRetrieve(ctx context.Context, order api.RetrievalOrder, ref
 		return err
 	}*/
 
-	ppb := types.BigDiv(order.Total, types.NewInt(order.Size))
-
-	params, err := rm.NewParamsV1(ppb, order.Size, order.PaymentInterval, order.PaymentIntervalIncrease, shared.AllSelector(), order.Piece, order.UnsealPrice)
+	steps, err := parseDatamodelPath(order.DatamodelPath)
 	if err != nil {
-		finish(xerrors.Errorf("Error in retrieval params: %s", err))
+		finish(err)
 		return
 	}
 
@@ -844,41 +969,39 @@ func (a *API) clientRetrieve(ctx context.Context, order api.RetrievalOrder, ref
 		_ = a.RetrievalStoreMgr.ReleaseStore(store)
 	}()
 
-	// Subscribe to events before retrieving to avoid losing events.
-	subscribeEvents := make(chan retrievalSubscribeEvent, 1)
-	subscribeCtx, cancel := context.WithCancel(ctx)
-	defer cancel()
-	unsubscribe := a.Retrieval.SubscribeToEvents(func(event rm.ClientEvent, state rm.ClientDealState) {
-		// We'll check the deal IDs inside readSubscribeEvents.
-		if state.PayloadCID.Equals(order.Root) {
-			select {
-			case <-subscribeCtx.Done():
-			case subscribeEvents <- retrievalSubscribeEvent{event, state}:
-			}
-		}
-	})
-
-	dealID, err := a.Retrieval.Retrieve(
-		ctx,
-		order.Root,
-		params,
-		order.Total,
-		order.MinerPeer,
-		order.Client,
-		order.Miner,
-		store.StoreID())
+	rdag := store.DAGService()
 
-	if err != nil {
-		unsubscribe()
-		finish(xerrors.Errorf("Retrieve failed: %w", err))
-		return
+	sel := shared.AllSelector()
+	if len(steps) > 0 {
+		sel = dagSelector(steps)
 	}
+	if order.Range != nil {
+		// The blocks holding the range are only known from the file node, which
+		// is retrieved first, in a deal paying for the nodes along the path only.
+		if err := a.retrieveSelection(ctx, limitOrder(order, uint64(len(steps)+1)*maxBlockSize), nodeSelector(steps), store, events); err != nil {
+			finish(err)
+			return
+		}
 
-	err = readSubscribeEvents(ctx, dealID, subscribeEvents, events)
+		target, err := resolvePath(ctx, rdag, order.Root, steps)
+		if err != nil {
+			finish(xerrors.Errorf("ClientRetrieve: %w", err))
+			return
+		}
+		nd, err := rdag.Get(ctx, target)
+		if err != nil {
+			finish(xerrors.Errorf("ClientRetrieve: %w", err))
+			return
+		}
+		sel, err = rangeSelector(steps, nd, order.Range)
+		if err != nil {
+			finish(xerrors.Errorf("ClientRetrieve: %w", err))
+			return
+		}
+	}
 
-	unsubscribe()
-	if err != nil {
-		finish(xerrors.Errorf("Retrieve: %w", err))
+	if err := a.retrieveSelection(ctx, order, sel, store, events); err != nil {
+		finish(err)
 		return
 	}
 
@@ -888,15 +1011,18 @@ func (a *API) clientRetrieve(ctx context.Context, order api.RetrievalOrder, ref
 		return
 	}
 
-	rdag := store.DAGService()
-
 	if ref.IsCAR {
 		f, err := os.OpenFile(ref.Path, os.O_CREATE|os.O_WRONLY, 0644)
 		if err != nil {
 			finish(err)
 			return
 		}
-		err = car.WriteCar(ctx, rdag, []cid.Cid{order.Root}, f)
+		if len(steps) == 0 && order.Range == nil {
+			err = car.WriteCar(ctx, rdag, []cid.Cid{order.Root}, f)
+		} else {
+			// only the selected blocks were retrieved
+			err = car.NewSelectiveCar(ctx, dagReadStore{ctx: ctx, dag: rdag}, []car.Dag{{Root: order.Root, Selector: sel}}).Write(f)
+		}
 		if err != nil {
 			finish(err)
 			return
@@ -905,11 +1031,20 @@ func (a *API) clientRetrieve(ctx context.Context, order api.RetrievalOrder, ref
 		return
 	}
 
-	nd, err := rdag.Get(ctx, order.Root)
+	target, err := resolvePath(ctx, rdag, order.Root, steps)
+	if err != nil {
+		finish(xerrors.Errorf("ClientRetrieve: %w", err))
+		return
+	}
+	nd, err := rdag.Get(ctx, target)
 	if err != nil {
 		finish(xerrors.Errorf("ClientRetrieve: %w", err))
 		return
 	}
+	if order.Range != nil {
+		finish(exportRange(ctx, rdag, nd, order.Range, ref.Path))
+		return
+	}
 	file, err := unixfile.NewUnixfsFile(ctx, rdag, nd)
 	if err != nil {
 		finish(xerrors.Errorf("ClientRetrieve: %w", err))
@@ -919,6 +1054,100 @@ func (a *API) clientRetrieve(ctx context.Context, order api.RetrievalOrder, ref
 	return
 }
 
+// maxBlockSize bounds the size of a block of a retrieved DAG.
+const maxBlockSize = 1 << 20
+
+// limitOrder returns the order bounded to size bytes, at the same price per
+// byte.
+func limitOrder(order api.RetrievalOrder, size uint64) api.RetrievalOrder {
+	if size >= order.Size {
+		return order
+	}
+	ppb := types.BigDiv(order.Total, types.NewInt(order.Size))
+	order.Total = types.BigMul(ppb, types.NewInt(size))
+	order.Size = size
+	return order
+}
+
+// retrieveSelection retrieves the blocks of order.Root matched by sel into
+// the store, and forwards the deal events.
+func (a *API) retrieveSelection(ctx context.Context, order api.RetrievalOrder, sel ipldprime.Node, store retrievalstoremgr.RetrievalStore, events chan marketevents.RetrievalEvent) error {
+	ppb := types.BigDiv(order.Total, types.NewInt(order.Size))
+
+	params, err := rm.NewParamsV1(ppb, order.Size, order.PaymentInterval, order.PaymentIntervalIncrease, sel, order.Piece, order.UnsealPrice)
+	if err != nil {
+		return xerrors.Errorf("Error in retrieval params: %s", err)
+	}
+
+	// Subscribe to events before retrieving to avoid losing events.
+	subscribeEvents := make(chan retrievalSubscribeEvent, 1)
+	subscribeCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	unsubscribe := a.Retrieval.SubscribeToEvents(func(event rm.ClientEvent, state rm.ClientDealState) {
+		// We'll check the deal IDs inside readSubscribeEvents.
+		if state.PayloadCID.Equals(order.Root) {
+			select {
+			case <-subscribeCtx.Done():
+			case subscribeEvents <- retrievalSubscribeEvent{event, state}:
+			}
+		}
+	})
+	defer unsubscribe()
+
+	dealID, err := a.Retrieval.Retrieve(
+		ctx,
+		order.Root,
+		params,
+		order.Total,
+		order.MinerPeer,
+		order.Client,
+		order.Miner,
+		store.StoreID())
+	if err != nil {
+		return xerrors.Errorf("Retrieve failed: %w", err)
+	}
+
+	if err := readSubscribeEvents(ctx, dealID, subscribeEvents, events); err != nil {
+		return xerrors.Errorf("Retrieve: %w", err)
+	}
+	return nil
+}
+
+// dagReadStore reads the blocks written to a selective CAR from a DAG service.
+type dagReadStore struct {
+	ctx context.Context
+	dag ipld.DAGService
+}
+
+func (s dagReadStore) Get(c cid.Cid) (blocks.Block, error) {
+	return s.dag.Get(s.ctx, c)
+}
+
+// exportRange writes the bytes of r of the UnixFS file nd to path.
+func exportRange(ctx context.Context, dag ipld.DAGService, nd ipld.Node, r *api.RetrievalRange, path string) error {
+	dr, err := uio.NewDagReader(ctx, nd, dag)
+	if err != nil {
+		return xerrors.Errorf("reading unixfs file: %w", err)
+	}
+	if _, err := dr.Seek(int64(r.Offset), io.SeekStart); err != nil {
+		return xerrors.Errorf("seeking to offset %d: %w", r.Offset, err)
+	}
+	var rd io.Reader = dr
+	if r.Length > 0 {
+		rd = io.LimitReader(dr, int64(r.Length))
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, rd); err != nil {
+		_ = f.Close()
+		return xerrors.Errorf("exporting range: %w", err)
+	}
+	return f.Close()
+}
+
 func (a *API) ClientQueryAsk(ctx context.Context, p peer.ID, miner address.Address) (*storagemarket.StorageAsk, error) {
 	mi, err := a.StateMinerInfo(ctx, miner, types.EmptyTSK)
 	if err != nil {
@@ -1218,6 +1447,8 @@ func convertRetrieveState(state *rm.ClientDealState) *api.RetrievalDeal {
 		Status:       state.Status,
 		Message:      state.Message,
 		WaitMsgCID:   state.WaitMsgCID,
+
+		TotalReceived: state.TotalReceived,
 	}
 }
 
@@ -0,0 +1,157 @@
+package client
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/ipfs/go-cid"
+	ipld "github.com/ipfs/go-ipld-format"
+	"github.com/ipfs/go-unixfs"
+	ipldprime "github.com/ipld/go-ipld-prime"
+	basicnode "github.com/ipld/go-ipld-prime/node/basic"
+	"github.com/ipld/go-ipld-prime/traversal/selector"
+	"github.com/ipld/go-ipld-prime/traversal/selector/builder"
+	"golang.org/x/xerrors"
+
+	"github.com/EpiK-Protocol/go-epik/api"
+)
+
+// pathStep is a step of a data model path through dag-pb nodes, selecting the
+// target of a link: Links/<index>/Hash.
+type pathStep int
+
+// parseDatamodelPath parses the Links/<index>/Hash steps of a data model path.
+func parseDatamodelPath(path string) ([]pathStep, error) {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil, nil
+	}
+
+	segs := strings.Split(path, "/")
+	if len(segs)%3 != 0 {
+		return nil, xerrors.Errorf("invalid path %q: expected Links/<index>/Hash steps", path)
+	}
+
+	steps := make([]pathStep, 0, len(segs)/3)
+	for i := 0; i < len(segs); i += 3 {
+		if segs[i] != "Links" || segs[i+2] != "Hash" {
+			return nil, xerrors.Errorf("invalid path %q: expected Links/<index>/Hash steps, got %s", path, strings.Join(segs[i:i+3], "/"))
+		}
+		idx, err := strconv.Atoi(segs[i+1])
+		if err != nil || idx < 0 {
+			return nil, xerrors.Errorf("invalid path %q: invalid link index %q", path, segs[i+1])
+		}
+		steps = append(steps, pathStep(idx))
+	}
+	return steps, nil
+}
+
+// linkSelector selects the target of the links of a dag-pb node whose index
+// is in [start, end), then applies next to them.
+func linkSelector(ssb builder.SelectorSpecBuilder, start, end int, next builder.SelectorSpec) builder.SelectorSpec {
+	var links builder.SelectorSpec
+	hash := ssb.ExploreFields(func(efsb builder.ExploreFieldsSpecBuilder) {
+		efsb.Insert("Hash", next)
+	})
+	if end == start+1 {
+		links = ssb.ExploreIndex(start, hash)
+	} else {
+		links = ssb.ExploreRange(start, end, hash)
+	}
+
+	return ssb.ExploreFields(func(efsb builder.ExploreFieldsSpecBuilder) {
+		efsb.Insert("Links", links)
+	})
+}
+
+// pathSelector follows the steps from the root, then applies next to the node
+// they lead to.
+func pathSelector(ssb builder.SelectorSpecBuilder, steps []pathStep, next builder.SelectorSpec) builder.SelectorSpec {
+	for i := len(steps) - 1; i >= 0; i-- {
+		next = linkSelector(ssb, int(steps[i]), int(steps[i])+1, next)
+	}
+	return next
+}
+
+// dagSelector selects the whole DAG below the node the steps lead to.
+func dagSelector(steps []pathStep) ipldprime.Node {
+	ssb := builder.NewSelectorSpecBuilder(basicnode.Prototype.Any)
+	all := ssb.ExploreRecursive(selector.RecursionLimitNone(), ssb.ExploreAll(ssb.ExploreRecursiveEdge()))
+	return pathSelector(ssb, steps, all).Node()
+}
+
+// nodeSelector selects only the node the steps lead to.
+func nodeSelector(steps []pathStep) ipldprime.Node {
+	ssb := builder.NewSelectorSpecBuilder(basicnode.Prototype.Any)
+	return pathSelector(ssb, steps, ssb.Matcher()).Node()
+}
+
+// rangeSelector selects the blocks of the UnixFS file node nd, the steps lead
+// to, holding the bytes of r. Only the direct children of nd are known from
+// nd, so the subtrees of the children holding the bytes are selected whole:
+// on a multi-level DAG, a small range fetches whole top-level subtrees.
+func rangeSelector(steps []pathStep, nd ipld.Node, r *api.RetrievalRange) (ipldprime.Node, error) {
+	fsn, err := unixfs.ExtractFSNode(nd)
+	if err != nil {
+		return nil, xerrors.Errorf("decoding unixfs node: %w", err)
+	}
+
+	first, last, err := rangeLinks(fsn, r)
+	if err != nil {
+		return nil, err
+	}
+	if last < first {
+		// the range is held by the node itself
+		return nodeSelector(steps), nil
+	}
+
+	ssb := builder.NewSelectorSpecBuilder(basicnode.Prototype.Any)
+	all := ssb.ExploreRecursive(selector.RecursionLimitNone(), ssb.ExploreAll(ssb.ExploreRecursiveEdge()))
+	return pathSelector(ssb, steps, linkSelector(ssb, first, last+1, all)).Node(), nil
+}
+
+// rangeLinks returns the indexes of the first and last links of the UnixFS
+// file node holding the bytes of r. last is lower than first if the node holds
+// them itself.
+func rangeLinks(fsn *unixfs.FSNode, r *api.RetrievalRange) (first, last int, err error) {
+	size := fsn.FileSize()
+	if r.Offset >= size {
+		return 0, 0, xerrors.Errorf("range offset %d beyond the end of the %d bytes file", r.Offset, size)
+	}
+	end := size
+	if r.Length > 0 && r.Offset+r.Length < size {
+		end = r.Offset + r.Length
+	}
+
+	first, last = 0, -1
+	// the data of the node comes before the data of its children
+	pos := uint64(len(fsn.Data()))
+	for i, bs := range fsn.BlockSizes() {
+		if pos < end && pos+bs > r.Offset {
+			if last < first {
+				first = i
+			}
+			last = i
+		}
+		pos += bs
+	}
+	return first, last, nil
+}
+
+// resolvePath returns the node the steps lead to from root.
+func resolvePath(ctx context.Context, dag ipld.NodeGetter, root cid.Cid, steps []pathStep) (cid.Cid, error) {
+	c := root
+	for _, step := range steps {
+		nd, err := dag.Get(ctx, c)
+		if err != nil {
+			return cid.Undef, xerrors.Errorf("getting node %s: %w", c, err)
+		}
+		links := nd.Links()
+		if int(step) >= len(links) {
+			return cid.Undef, xerrors.Errorf("node %s has %d links, no link %d", c, len(links), step)
+		}
+		c = links[step].Cid
+	}
+	return c, nil
+}
@@ -0,0 +1,74 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/ipfs/go-unixfs"
+	"github.com/stretchr/testify/require"
+
+	"github.com/EpiK-Protocol/go-epik/api"
+	"github.com/EpiK-Protocol/go-epik/chain/types"
+)
+
+func TestParseDatamodelPath(t *testing.T) {
+	steps, err := parseDatamodelPath("")
+	require.NoError(t, err)
+	require.Empty(t, steps)
+
+	steps, err = parseDatamodelPath("/Links/2/Hash/Links/0/Hash/")
+	require.NoError(t, err)
+	require.Equal(t, []pathStep{2, 0}, steps)
+
+	for _, p := range []string{"Links/2", "Links/x/Hash", "Links/-1/Hash", "Data/0/Hash"} {
+		_, err := parseDatamodelPath(p)
+		require.Error(t, err, p)
+	}
+}
+
+func TestRangeLinks(t *testing.T) {
+	fsn := unixfs.NewFSNode(unixfs.TFile)
+	for i := 0; i < 4; i++ {
+		fsn.AddBlockSize(100)
+	}
+
+	cases := []struct {
+		r           api.RetrievalRange
+		first, last int
+	}{
+		{api.RetrievalRange{Offset: 0}, 0, 3},
+		{api.RetrievalRange{Offset: 150, Length: 100}, 1, 2},
+		{api.RetrievalRange{Offset: 199, Length: 1}, 1, 1},
+		{api.RetrievalRange{Offset: 300, Length: 1000}, 3, 3},
+	}
+	for _, c := range cases {
+		c := c
+		first, last, err := rangeLinks(fsn, &c.r)
+		require.NoError(t, err)
+		require.Equal(t, c.first, first, "%+v", c.r)
+		require.Equal(t, c.last, last, "%+v", c.r)
+	}
+
+	_, _, err := rangeLinks(fsn, &api.RetrievalRange{Offset: 400})
+	require.Error(t, err)
+
+	// the data of a single block file is held by its node
+	inline := unixfs.NewFSNode(unixfs.TFile)
+	inline.SetData([]byte("hello"))
+	first, last, err := rangeLinks(inline, &api.RetrievalRange{Offset: 1, Length: 2})
+	require.NoError(t, err)
+	require.True(t, last < first)
+}
+
+func TestLimitOrder(t *testing.T) {
+	order := api.RetrievalOrder{
+		Size:  10 << 20,
+		Total: types.NewInt(20 << 20),
+	}
+
+	limited := limitOrder(order, 2<<20)
+	require.Equal(t, uint64(2<<20), limited.Size)
+	require.Equal(t, types.NewInt(4<<20), limited.Total)
+
+	// orders smaller than the limit are kept
+	require.Equal(t, order, limitOrder(order, 20<<20))
+}
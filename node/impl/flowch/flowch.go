@@ -14,12 +14,14 @@ import (
 	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/flowch"
 	"github.com/EpiK-Protocol/go-epik/chain/types"
 	"github.com/EpiK-Protocol/go-epik/flowchmgr"
+	"github.com/EpiK-Protocol/go-epik/paychmgr"
 )
 
 type FlowchAPI struct {
 	fx.In
 
-	FlowchMgr *flowchmgr.Manager
+	FlowchMgr   *flowchmgr.Manager
+	AutoSettler *paychmgr.AutoSettler `optional:"true"`
 }
 
 func (a *FlowchAPI) FlowchGet(ctx context.Context, from, to address.Address, amt types.BigInt) (*api.ChannelInfo, error) {
@@ -117,6 +119,21 @@ func (a *FlowchAPI) FlowchCollect(ctx context.Context, addr address.Address) (ci
 	return a.FlowchMgr.Collect(ctx, addr)
 }
 
+func (a *FlowchAPI) FlowchAutoStatus(ctx context.Context) ([]*api.FlowchAutoChannel, error) {
+	if a.AutoSettler == nil {
+		return nil, xerrors.Errorf("automatic flow channel settlement is not enabled")
+	}
+	return a.AutoSettler.Status()
+}
+
+func (a *FlowchAPI) FlowchExport(ctx context.Context, signer address.Address, chs []address.Address) (*api.FlowchBundle, error) {
+	return a.FlowchMgr.Export(ctx, signer, chs)
+}
+
+func (a *FlowchAPI) FlowchImport(ctx context.Context, bundle *api.FlowchBundle) ([]address.Address, error) {
+	return a.FlowchMgr.Import(ctx, bundle)
+}
+
 func (a *FlowchAPI) FlowchVoucherCheckValid(ctx context.Context, ch address.Address, sv *flowch.SignedVoucher) error {
 	return a.FlowchMgr.CheckVoucherValid(ctx, ch, sv)
 }
@@ -141,17 +158,7 @@ func (a *FlowchAPI) FlowchVoucherCreate(ctx context.Context, pch address.Address
 }
 
 func (a *FlowchAPI) FlowchVoucherList(ctx context.Context, pch address.Address) ([]*flowch.SignedVoucher, error) {
-	vi, err := a.FlowchMgr.ListVouchers(ctx, pch)
-	if err != nil {
-		return nil, err
-	}
-
-	out := make([]*flowch.SignedVoucher, len(vi))
-	for k, v := range vi {
-		out[k] = v.Voucher
-	}
-
-	return out, nil
+	return a.FlowchMgr.ListVouchers(ctx, pch)
 }
 
 func (a *FlowchAPI) FlowchVoucherSubmit(ctx context.Context, ch address.Address, sv *flowch.SignedVoucher, secret []byte, proof []byte) (cid.Cid, error) {
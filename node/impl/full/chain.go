@@ -90,6 +90,70 @@ func (m *ChainModule) ChainNotify(ctx context.Context) (<-chan []*api.HeadChange
 	return m.Chain.SubHeadChanges(ctx), nil
 }
 
+func (a *ChainAPI) ChainDataIndexNotify(ctx context.Context) (<-chan []*api.DataIndexChange, error) {
+	hcs := a.Chain.SubHeadChanges(ctx)
+
+	out := make(chan []*api.DataIndexChange, 16)
+	go func() {
+		defer close(out)
+
+		for hc := range hcs {
+			var changes []*api.DataIndexChange
+			for _, c := range hc {
+				if c.Type == store.HCCurrent {
+					continue
+				}
+
+				datas, err := a.finalDataIndexes(ctx, c.Val)
+				if err != nil {
+					log.Errorf("loading data indexes of tipset %s: %s", c.Val.Key(), err)
+					continue
+				}
+				for _, data := range datas {
+					changes = append(changes, &api.DataIndexChange{
+						Type:  c.Type,
+						Index: data,
+					})
+				}
+			}
+			if len(changes) == 0 {
+				continue
+			}
+
+			select {
+			case out <- changes:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+// finalDataIndexes returns the data indexes added by the execution of the
+// parent of ts, i.e. those of the epochs from the parent to ts.
+func (a *ChainAPI) finalDataIndexes(ctx context.Context, ts *types.TipSet) ([]*api.DataIndex, error) {
+	parent, err := a.Chain.LoadTipSet(ts.Parents())
+	if err != nil {
+		return nil, xerrors.Errorf("loading parent tipset: %w", err)
+	}
+
+	dataIndex, err := loadDataIndexes(ctx, a.StateManager, a.Chain, ts)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []*api.DataIndex
+	for epoch := parent.Height(); epoch < ts.Height(); epoch++ {
+		datas, err := epochDataIndexes(dataIndex, epoch)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, datas...)
+	}
+	return out, nil
+}
+
 func (m *ChainModule) ChainHead(context.Context) (*types.TipSet, error) {
 	return m.Chain.GetHeaviestTipSet(), nil
 }
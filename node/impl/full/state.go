@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"sort"
 	"strconv"
 
 	cid "github.com/ipfs/go-cid"
@@ -41,8 +42,10 @@ import (
 	"github.com/EpiK-Protocol/go-epik/chain/types"
 	"github.com/EpiK-Protocol/go-epik/chain/vm"
 	"github.com/EpiK-Protocol/go-epik/chain/wallet"
+	"github.com/EpiK-Protocol/go-epik/govhistory"
 	"github.com/EpiK-Protocol/go-epik/node/modules/dtypes"
 	expert2 "github.com/filecoin-project/specs-actors/v2/actors/builtin/expert"
+	expertfund2 "github.com/filecoin-project/specs-actors/v2/actors/builtin/expertfund"
 )
 
 type StateModuleAPI interface {
@@ -93,6 +96,7 @@ type StateAPI struct {
 	StateManager  *stmgr.StateManager
 	Chain         *store.ChainStore
 	Beacon        beacon.Schedule
+	GovIndex      *govhistory.Index `optional:"true"`
 }
 
 func (a *StateAPI) StateNetworkName(ctx context.Context) (dtypes.NetworkName, error) {
@@ -1610,6 +1614,82 @@ func (a *StateAPI) StateExpertInfo(ctx context.Context, addr address.Address, ts
 	}, nil
 }
 
+func (a *StateAPI) StateExpertRewards(ctx context.Context, addr address.Address, tsk types.TipSetKey) (*api.ExpertRewards, error) {
+	ts, err := a.Chain.GetTipSetFromKey(tsk)
+	if err != nil {
+		return nil, xerrors.Errorf("loading tipset %s: %w", tsk, err)
+	}
+
+	efAct, err := a.StateGetActor(ctx, builtin.ExpertFundActorAddr, tsk)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to load expertfund actor: %w", err)
+	}
+
+	efs, err := expertfund.Load(a.Chain.ActorStore(ctx), efAct)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to load expertfund actor state: %w", err)
+	}
+
+	reward, err := efs.Reward(ts.Height(), addr)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to get expertfund reward: %w", err)
+	}
+	defInfo, err := efs.DisqualifiedExpertInfo(addr)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to get disqualification info: %w", err)
+	}
+
+	out := &api.ExpertRewards{
+		Expert:                addr,
+		Height:                ts.Height(),
+		LockedFunds:           reward.LockedFunds,
+		UnlockedFunds:         reward.UnlockedFunds,
+		DisqualifiedAt:        -1,
+		ContributionClearedAt: -1,
+		Schedule:              projectExpertVesting(reward.UnlockedFunds, reward.VestingFunds),
+	}
+	if defInfo != nil {
+		out.DisqualifiedAt = defInfo.DisqualifiedAt
+		out.ContributionClearedAt = defInfo.DisqualifiedAt + expertfund2.ClearExpertContributionDelay
+	}
+	return out, nil
+}
+
+// projectExpertVesting groups by day the vesting funds, keyed by the epoch
+// they were locked at, and accumulates the amounts claimable from unlocked.
+func projectExpertVesting(unlocked abi.TokenAmount, vesting map[abi.ChainEpoch]abi.TokenAmount) []api.ExpertRewardsDay {
+	byDay := map[abi.ChainEpoch]abi.TokenAmount{}
+	for lockedAt, amount := range vesting {
+		// the funds unlock once the vesting delay has fully elapsed
+		unlockAt := lockedAt + expertfund2.RewardVestingDelay + 1
+		day := unlockAt - unlockAt%builtin.EpochsInDay
+		if prev, ok := byDay[day]; ok {
+			amount = big.Add(prev, amount)
+		}
+		byDay[day] = amount
+	}
+
+	days := make([]abi.ChainEpoch, 0, len(byDay))
+	for day := range byDay {
+		days = append(days, day)
+	}
+	sort.Slice(days, func(i, j int) bool {
+		return days[i] < days[j]
+	})
+
+	out := make([]api.ExpertRewardsDay, 0, len(days))
+	claimable := unlocked
+	for _, day := range days {
+		claimable = big.Add(claimable, byDay[day])
+		out = append(out, api.ExpertRewardsDay{
+			Epoch:     day,
+			Unlocked:  byDay[day],
+			Claimable: claimable,
+		})
+	}
+	return out
+}
+
 func (a *StateAPI) StateExpertDatas(ctx context.Context, addr address.Address, filter *bitfield.BitField, filterOut bool, tsk types.TipSetKey) ([]*expert.DataOnChainInfo, error) {
 	act, err := a.StateGetActor(ctx, addr, tsk)
 	if err != nil {
@@ -1662,6 +1742,263 @@ func (a *StateAPI) StateExpertFileInfo(ctx context.Context, pieceCid cid.Cid, ts
 	}, nil
 }
 
+func (a *StateAPI) StateExpertFileRedundancy(ctx context.Context, pieceCid cid.Cid, tsk types.TipSetKey) (*api.ExpertFileStorage, error) {
+	ts, err := a.Chain.GetTipSetFromKey(tsk)
+	if err != nil {
+		return nil, xerrors.Errorf("loading tipset %s: %w", tsk, err)
+	}
+
+	act, err := a.StateManager.LoadActor(ctx, expertfund.Address, ts)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to load expertfund actor: %w", err)
+	}
+
+	st, err := expertfund.Load(a.Chain.ActorStore(ctx), act)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to load expertfund actor state: %w", err)
+	}
+
+	expertAddr, err := st.DataExpert(pieceCid)
+	if err != nil {
+		return nil, err
+	}
+
+	expertAct, err := a.StateManager.LoadActor(ctx, expertAddr, ts)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to load expert actor: %w", err)
+	}
+
+	expertSt, err := expert.Load(a.Chain.ActorStore(ctx), expertAct)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to load expert actor state: %w", err)
+	}
+
+	data, err := expertSt.Data(pieceCid)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := a.expertFileStorage(ctx, ts, expertAddr, []*expert.DataOnChainInfo{data})
+	if err != nil {
+		return nil, err
+	}
+	return files[0], nil
+}
+
+func (a *StateAPI) StateExpertDataList(ctx context.Context, expertAddr address.Address, cursor *cid.Cid, limit uint64, tsk types.TipSetKey) (*api.ExpertDataPage, error) {
+	ts, err := a.Chain.GetTipSetFromKey(tsk)
+	if err != nil {
+		return nil, xerrors.Errorf("loading tipset %s: %w", tsk, err)
+	}
+
+	act, err := a.StateManager.LoadActor(ctx, expertAddr, ts)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to load expert actor: %w", err)
+	}
+
+	eas, err := expert.Load(a.Chain.ActorStore(ctx), act)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to load expert actor state: %w", err)
+	}
+
+	datas, err := eas.Datas()
+	if err != nil {
+		return nil, xerrors.Errorf("failed to load expert datas: %w", err)
+	}
+
+	// pages follow the order of the piece CIDs, which registering more files
+	// doesn't change
+	type pieceData struct {
+		piece string
+		data  *expert.DataOnChainInfo
+	}
+	sorted := make([]pieceData, 0, len(datas))
+	for _, d := range datas {
+		pieceID, err := cid.Decode(d.PieceID)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to decode piece id %q: %w", d.PieceID, err)
+		}
+		sorted = append(sorted, pieceData{piece: pieceID.String(), data: d})
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].piece < sorted[j].piece
+	})
+
+	start := 0
+	if cursor != nil {
+		after := cursor.String()
+		start = sort.Search(len(sorted), func(i int) bool {
+			return sorted[i].piece > after
+		})
+	}
+	end := len(sorted)
+	if limit > 0 && uint64(end-start) > limit {
+		end = start + int(limit)
+	}
+
+	pageDatas := make([]*expert.DataOnChainInfo, 0, end-start)
+	for _, pd := range sorted[start:end] {
+		pageDatas = append(pageDatas, pd.data)
+	}
+	files, err := a.expertFileStorage(ctx, ts, expertAddr, pageDatas)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &api.ExpertDataPage{Files: files}
+	if end < len(sorted) {
+		next := files[len(files)-1].PieceID
+		page.Next = &next
+	}
+	return page, nil
+}
+
+// expertFileStorage joins expert datas with the market deals storing them,
+// the sectors holding the deals and the miners storing the pieces.
+func (a *StateAPI) expertFileStorage(ctx context.Context, ts *types.TipSet, expertAddr address.Address, datas []*expert.DataOnChainInfo) ([]*api.ExpertFileStorage, error) {
+	out := make([]*api.ExpertFileStorage, 0, len(datas))
+	byPiece := make(map[cid.Cid]*api.ExpertFileStorage, len(datas))
+	for _, d := range datas {
+		rootID, err := cid.Decode(d.RootID)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to decode root id %q: %w", d.RootID, err)
+		}
+		pieceID, err := cid.Decode(d.PieceID)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to decode piece id %q: %w", d.PieceID, err)
+		}
+		f := &api.ExpertFileStorage{
+			Expert:     expertAddr,
+			RootID:     rootID,
+			PieceID:    pieceID,
+			PieceSize:  d.PieceSize,
+			Redundancy: d.Redundancy,
+			Miners:     []address.Address{},
+			Deals:      []api.ExpertFileDeal{},
+		}
+		out = append(out, f)
+		byPiece[pieceID] = f
+	}
+
+	mstate, err := a.StateManager.GetMarketState(ctx, ts)
+	if err != nil {
+		return nil, err
+	}
+	proposals, err := mstate.Proposals()
+	if err != nil {
+		return nil, err
+	}
+	states, err := mstate.States()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := proposals.ForEach(func(id abi.DealID, dp market.DealProposal) error {
+		f, ok := byPiece[dp.PieceCID]
+		if !ok {
+			return nil
+		}
+		ds, found, err := states.Get(id)
+		if err != nil {
+			return xerrors.Errorf("failed to get state for deal %d: %w", id, err)
+		} else if !found {
+			ds = market.EmptyDealState()
+		}
+		f.Deals = append(f.Deals, api.ExpertFileDeal{
+			DealID:           id,
+			Provider:         dp.Provider,
+			Client:           dp.Client,
+			StartEpoch:       dp.StartEpoch,
+			SectorStartEpoch: ds.SectorStartEpoch,
+			SlashEpoch:       ds.SlashEpoch,
+			Expiration:       -1,
+		})
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	// states of the providers holding the deals, loaded once per provider,
+	// and their sectors, loaded once a deal is found in a sector
+	type providerState struct {
+		state  miner.State
+		byDeal map[abi.DealID]abi.SectorNumber
+	}
+	providers := map[address.Address]*providerState{}
+	loadProvider := func(maddr address.Address) (*providerState, error) {
+		if ps, ok := providers[maddr]; ok {
+			return ps, nil
+		}
+		act, err := a.StateManager.LoadActor(ctx, maddr, ts)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to load miner actor %s: %w", maddr, err)
+		}
+		mas, err := miner.Load(a.Chain.ActorStore(ctx), act)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to load miner actor state %s: %w", maddr, err)
+		}
+		ps := &providerState{state: mas}
+		providers[maddr] = ps
+		return ps, nil
+	}
+	dealSector := func(ps *providerState, maddr address.Address, id abi.DealID) (abi.SectorNumber, bool, error) {
+		if ps.byDeal == nil {
+			sectors, err := ps.state.LoadSectors(nil)
+			if err != nil {
+				return 0, false, xerrors.Errorf("failed to load sectors of %s: %w", maddr, err)
+			}
+			ps.byDeal = map[abi.DealID]abi.SectorNumber{}
+			for _, si := range sectors {
+				for _, id := range si.DealIDs {
+					ps.byDeal[id] = si.SectorNumber
+				}
+			}
+		}
+		sn, ok := ps.byDeal[id]
+		return sn, ok, nil
+	}
+
+	for _, f := range out {
+		checked := map[address.Address]struct{}{}
+		for i := range f.Deals {
+			deal := &f.Deals[i]
+			ps, err := loadProvider(deal.Provider)
+			if err != nil {
+				return nil, err
+			}
+
+			if deal.SectorStartEpoch >= 0 && deal.SlashEpoch < 0 {
+				sn, ok, err := dealSector(ps, deal.Provider, deal.DealID)
+				if err != nil {
+					return nil, err
+				}
+				if ok {
+					exp, err := ps.state.GetSectorExpiration(sn)
+					if err != nil {
+						return nil, xerrors.Errorf("failed to get expiration of sector %d: %w", sn, err)
+					}
+					deal.Sector = sn
+					deal.Expiration = exp.OnTime
+				}
+			}
+
+			if _, ok := checked[deal.Provider]; ok {
+				continue
+			}
+			checked[deal.Provider] = struct{}{}
+			has, err := ps.state.ContainsAnyPiece([]cid.Cid{f.PieceID})
+			if err != nil {
+				return nil, xerrors.Errorf("failed to check pieces of %s: %w", deal.Provider, err)
+			}
+			if has {
+				f.Miners = append(f.Miners, deal.Provider)
+			}
+		}
+	}
+
+	return out, nil
+}
+
 func (a *StateAPI) StateVoteTally(ctx context.Context, tsk types.TipSetKey) (*vote.Tally, error) {
 	act, err := a.StateGetActor(ctx, vote.Address, tsk)
 	if err != nil {
@@ -1675,6 +2012,108 @@ func (a *StateAPI) StateVoteTally(ctx context.Context, tsk types.TipSetKey) (*vo
 	return vst.Tally()
 }
 
+// maxVoteTallySamples bounds the number of tipsets a vote tally history walks.
+const maxVoteTallySamples = 10000
+
+func (a *StateAPI) StateVoteTallyHistory(ctx context.Context, candidate address.Address, from, to, step abi.ChainEpoch, tsk types.TipSetKey) (*api.VoteTallyHistory, error) {
+	if step <= 0 {
+		return nil, xerrors.Errorf("step must be positive, got %d", step)
+	}
+	if from < 0 || to < from {
+		return nil, xerrors.Errorf("invalid epoch range [%d, %d]", from, to)
+	}
+	if (to-from)/step+1 > maxVoteTallySamples {
+		return nil, xerrors.Errorf("too many samples, range [%d, %d] with step %d exceeds %d", from, to, step, maxVoteTallySamples)
+	}
+
+	head, err := a.Chain.GetTipSetFromKey(tsk)
+	if err != nil {
+		return nil, xerrors.Errorf("loading tipset %s: %w", tsk, err)
+	}
+	if to > head.Height() {
+		to = head.Height()
+	}
+
+	out := &api.VoteTallyHistory{
+		Candidates: map[string][]api.VoteTallyPoint{},
+	}
+
+	// running tally, updated by diffing the vote actor between samples
+	tally := map[address.Address]vote.CandidateInfo{}
+	track := func(addr address.Address) bool {
+		return candidate == address.Undef || addr == candidate
+	}
+
+	var prevHead cid.Cid
+	var prevState vote.State
+	for h := from; h <= to; h += step {
+		ts, err := a.Chain.GetTipsetByHeight(ctx, h, head, true)
+		if err != nil {
+			return nil, xerrors.Errorf("loading tipset at %d: %w", h, err)
+		}
+
+		act, err := a.StateManager.LoadActor(ctx, vote.Address, ts)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to load vote actor at %d: %w", h, err)
+		}
+
+		if prevState == nil || act.Head != prevHead {
+			vst, err := vote.Load(a.Chain.ActorStore(ctx), act)
+			if err != nil {
+				return nil, xerrors.Errorf("failed to load vote actor state at %d: %w", h, err)
+			}
+
+			if prevState == nil {
+				t, err := vst.Tally()
+				if err != nil {
+					return nil, err
+				}
+				for s, votes := range t.Candidates {
+					addr, err := address.NewFromString(s)
+					if err != nil {
+						return nil, err
+					}
+					if track(addr) {
+						tally[addr] = vote.CandidateInfo{Votes: votes, Blocked: t.Blocked[s]}
+					}
+				}
+			} else {
+				changes, err := vote.DiffCandidates(prevState, vst)
+				if err != nil {
+					return nil, xerrors.Errorf("diffing candidates at %d: %w", h, err)
+				}
+				for _, c := range changes.Added {
+					if track(c.Candidate) {
+						tally[c.Candidate] = c.Info
+					}
+				}
+				for _, c := range changes.Modified {
+					if track(c.Candidate) {
+						tally[c.Candidate] = c.To
+					}
+				}
+				for _, c := range changes.Removed {
+					delete(tally, c.Candidate)
+				}
+			}
+
+			prevHead, prevState = act.Head, vst
+		}
+
+		out.Epochs = append(out.Epochs, ts.Height())
+		for addr, info := range tally {
+			k := addr.String()
+			out.Candidates[k] = append(out.Candidates[k], api.VoteTallyPoint{
+				Epoch:   ts.Height(),
+				Votes:   info.Votes,
+				Blocked: info.Blocked,
+			})
+		}
+	}
+
+	return out, nil
+}
+
 func (a *StateAPI) StateVoterInfo(ctx context.Context, addr address.Address, tsk types.TipSetKey) (*vote.VoterInfo, error) {
 	ts, err := a.Chain.GetTipSetFromKey(tsk)
 	if err != nil {
@@ -1717,6 +2156,115 @@ func (a *StateAPI) StateKnowledgeInfo(ctx context.Context, tsk types.TipSetKey)
 	return knoState.Info()
 }
 
+// maxKnowledgePayoutEpochs bounds the range walked by a knowledge payout report.
+const maxKnowledgePayoutEpochs = 31 * builtin.EpochsInDay
+
+func (a *StateAPI) StateKnowledgePayouts(ctx context.Context, from, to abi.ChainEpoch, tsk types.TipSetKey) (*api.KnowledgePayouts, error) {
+	if from < 0 || to < from {
+		return nil, xerrors.Errorf("invalid epoch range [%d, %d]", from, to)
+	}
+	if to-from > maxKnowledgePayoutEpochs {
+		return nil, xerrors.Errorf("range [%d, %d] exceeds %d epochs", from, to, maxKnowledgePayoutEpochs)
+	}
+
+	head, err := a.Chain.GetTipSetFromKey(tsk)
+	if err != nil {
+		return nil, xerrors.Errorf("loading tipset %s: %w", tsk, err)
+	}
+	if to > head.Height() {
+		to = head.Height()
+	}
+
+	ts, err := a.Chain.GetTipsetByHeight(ctx, to, head, true)
+	if err != nil {
+		return nil, xerrors.Errorf("loading tipset at %d: %w", to, err)
+	}
+
+	store := a.Chain.ActorStore(ctx)
+	act, err := a.StateManager.LoadActor(ctx, knowledge.Address, ts)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to load knowledge actor at %d: %w", ts.Height(), err)
+	}
+	cur, err := knowledge.Load(store, act)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to load knowledge actor state at %d: %w", ts.Height(), err)
+	}
+
+	// walk back to from, diffing the states of the knowledge fund before and
+	// after the execution of each tipset
+	out := &api.KnowledgePayouts{}
+	for ts.Height() > from {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		parent, err := a.Chain.LoadTipSet(ts.Parents())
+		if err != nil {
+			return nil, xerrors.Errorf("loading parent of tipset at %d: %w", ts.Height(), err)
+		}
+		pact, err := a.StateManager.LoadActor(ctx, knowledge.Address, parent)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to load knowledge actor at %d: %w", parent.Height(), err)
+		}
+		if pact.Head == act.Head {
+			ts, act = parent, pact
+			continue
+		}
+
+		pre, err := knowledge.Load(store, pact)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to load knowledge actor state at %d: %w", parent.Height(), err)
+		}
+
+		changes, err := knowledge.DiffTally(pre, cur)
+		if err != nil {
+			return nil, xerrors.Errorf("diffing knowledge tally at %d: %w", ts.Height(), err)
+		}
+		for _, p := range changes.Added {
+			out.Payouts = append(out.Payouts, api.KnowledgePayout{Epoch: ts.Height(), Payee: p.Payee, Amount: p.Amount})
+		}
+		for _, p := range changes.Modified {
+			if amt := big.Sub(p.To, p.From); amt.GreaterThan(big.Zero()) {
+				out.Payouts = append(out.Payouts, api.KnowledgePayout{Epoch: ts.Height(), Payee: p.Payee, Amount: amt})
+			}
+		}
+
+		preInfo, err := pre.Info()
+		if err != nil {
+			return nil, err
+		}
+		curInfo, err := cur.Info()
+		if err != nil {
+			return nil, err
+		}
+		if preInfo.Payee != curInfo.Payee {
+			change := api.KnowledgePayeeChange{Epoch: ts.Height(), From: preInfo.Payee, To: curInfo.Payee}
+
+			msgs, err := a.Chain.MessagesForTipset(parent)
+			if err != nil {
+				return nil, xerrors.Errorf("loading messages at %d: %w", parent.Height(), err)
+			}
+			for _, m := range msgs {
+				vmsg := m.VMMessage()
+				if vmsg.To == knowledge.Address && vmsg.Method == knowledge.Methods.ChangePayee {
+					change.Message = m.Cid()
+				}
+			}
+			out.PayeeChanges = append(out.PayeeChanges, change)
+		}
+
+		ts, act, cur = parent, pact, pre
+	}
+
+	sort.SliceStable(out.Payouts, func(i, j int) bool {
+		return out.Payouts[i].Epoch < out.Payouts[j].Epoch
+	})
+	sort.SliceStable(out.PayeeChanges, func(i, j int) bool {
+		return out.PayeeChanges[i].Epoch < out.PayeeChanges[j].Epoch
+	})
+	return out, nil
+}
+
 func (a *StateAPI) StateGovernSupervisor(ctx context.Context, tsk types.TipSetKey) (address.Address, error) {
 	act, err := a.StateManager.LoadActorTsk(ctx, govern.Address, tsk)
 	if err != nil {
@@ -1812,6 +2360,25 @@ func (a *StateAPI) StateGovernParams(ctx context.Context, tsk types.TipSetKey) (
 	return &out, nil
 }
 
+func (a *StateAPI) StateGovernParamsHistory(ctx context.Context, from, to abi.ChainEpoch) ([]*api.GovernChange, error) {
+	if a.GovIndex == nil {
+		return nil, xerrors.Errorf("governance index is disabled, set GovernIndex.Enable in the node config")
+	}
+	if to < from {
+		return nil, xerrors.Errorf("invalid epoch range [%d, %d]", from, to)
+	}
+
+	h, err := a.GovIndex.Height()
+	if err != nil {
+		return nil, xerrors.Errorf("loading governance index height: %w", err)
+	}
+	if h < to && h < a.Chain.GetHeaviestTipSet().Height() {
+		log.Warnf("governance index is still catching up, indexed up to %d", h)
+	}
+
+	return a.GovIndex.Changes(from, to)
+}
+
 func (a *StateAPI) StateRetrievalInfo(ctx context.Context, tsk types.TipSetKey) (*api.RetrievalInfo, error) {
 	act, err := a.StateManager.LoadActorTsk(ctx, retrieval.Address, tsk)
 	if err != nil {
@@ -1926,6 +2493,46 @@ func (a *StateAPI) StateRetrievalPledgeFrom(ctx context.Context, addr address.Ad
 	return info, nil
 }
 
+func (a *StateAPI) StateRetrievalLocked(ctx context.Context, addr address.Address, tsk types.TipSetKey) (*api.RetrievalLocked, error) {
+	act, err := a.StateManager.LoadActorTsk(ctx, retrieval.Address, tsk)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to load retrieval actor: %w", err)
+	}
+
+	state, err := retrieval.Load(a.Chain.ActorStore(ctx), act)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to load retrieval actor state: %w", err)
+	}
+
+	ida, err := a.StateLookupID(ctx, addr, tsk)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to lookup id: %w", err)
+	}
+
+	ret := &api.RetrievalLocked{
+		Address: addr,
+		Amount:  big.Zero(),
+	}
+
+	var locked retrieval.LockedState
+	found, err := state.LockedState(ida, &locked)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to load retrieval locked: %w", err)
+	}
+	if !found || locked.Amount.IsZero() {
+		return ret, nil
+	}
+
+	lockedPeriod, err := state.LockedPeriod()
+	if err != nil {
+		return nil, err
+	}
+	ret.Amount = locked.Amount
+	ret.ApplyEpoch = locked.ApplyEpoch
+	ret.UnlockEpoch = locked.ApplyEpoch + lockedPeriod
+	return ret, nil
+}
+
 func (a *StateAPI) StateRetrievalPledgeList(ctx context.Context, tsk types.TipSetKey) (map[address.Address]*api.RetrievalState, error) {
 	act, err := a.StateManager.LoadActorTsk(ctx, retrieval.Address, tsk)
 	if err != nil {
@@ -1963,12 +2570,77 @@ func (a *StateAPI) StateRetrievalPledgeList(ctx context.Context, tsk types.TipSe
 }
 
 func (a *StateAPI) StateDataIndex(ctx context.Context, epoch abi.ChainEpoch, tsk types.TipSetKey) ([]*api.DataIndex, error) {
-	act, err := a.StateManager.LoadActorTsk(ctx, market.Address, tsk)
+	ts, err := a.Chain.GetTipSetFromKey(tsk)
+	if err != nil {
+		return nil, xerrors.Errorf("loading tipset %s: %w", tsk, err)
+	}
+
+	dataIndex, err := loadDataIndexes(ctx, a.StateManager, a.Chain, ts)
+	if err != nil {
+		return nil, err
+	}
+	return epochDataIndexes(dataIndex, epoch)
+}
+
+func (a *StateAPI) StateDataIndexRange(ctx context.Context, from, to abi.ChainEpoch, offset, limit uint64, tsk types.TipSetKey) (*api.DataIndexPage, error) {
+	if from < 0 || to < from {
+		return nil, xerrors.Errorf("invalid epoch range [%d, %d]", from, to)
+	}
+
+	ts, err := a.Chain.GetTipSetFromKey(tsk)
+	if err != nil {
+		return nil, xerrors.Errorf("loading tipset %s: %w", tsk, err)
+	}
+	// indexes of the epoch of ts are only known once ts is executed
+	if to >= ts.Height() {
+		to = ts.Height() - 1
+	}
+	if to < from {
+		return &api.DataIndexPage{NextEpoch: from, NextOffset: offset, Done: true}, nil
+	}
+
+	dataIndex, err := loadDataIndexes(ctx, a.StateManager, a.Chain, ts)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &api.DataIndexPage{}
+	for epoch := from; epoch <= to; epoch++ {
+		datas, err := epochDataIndexes(dataIndex, epoch)
+		if err != nil {
+			return nil, xerrors.Errorf("loading data indexes of epoch %d: %w", epoch, err)
+		}
+
+		skip := uint64(0)
+		if epoch == from {
+			skip = offset
+			if skip > uint64(len(datas)) {
+				skip = uint64(len(datas))
+			}
+		}
+
+		for i, data := range datas[skip:] {
+			if limit > 0 && uint64(len(page.Indexes)) == limit {
+				page.NextEpoch = epoch
+				page.NextOffset = skip + uint64(i)
+				return page, nil
+			}
+			page.Indexes = append(page.Indexes, data)
+		}
+	}
+
+	page.NextEpoch = to + 1
+	page.Done = true
+	return page, nil
+}
+
+func loadDataIndexes(ctx context.Context, sm *stmgr.StateManager, cs *store.ChainStore, ts *types.TipSet) (market.DataIndexes, error) {
+	act, err := sm.LoadActor(ctx, market.Address, ts)
 	if err != nil {
 		return nil, xerrors.Errorf("failed to load market actor: %w", err)
 	}
 
-	state, err := market.Load(a.Chain.ActorStore(ctx), act)
+	state, err := market.Load(cs.ActorStore(ctx), act)
 	if err != nil {
 		return nil, xerrors.Errorf("failed to load market actor state: %w", err)
 	}
@@ -1977,14 +2649,18 @@ func (a *StateAPI) StateDataIndex(ctx context.Context, epoch abi.ChainEpoch, tsk
 	if err != nil {
 		return nil, xerrors.Errorf("failed to load state index: %w", err)
 	}
+	return dataIndex, nil
+}
 
+func epochDataIndexes(dataIndex market.DataIndexes, epoch abi.ChainEpoch) ([]*api.DataIndex, error) {
 	var ret []*api.DataIndex
-	err = dataIndex.ForEach(epoch, func(provider address.Address, data market.DataIndex) error {
+	err := dataIndex.ForEach(epoch, func(provider address.Address, data market.DataIndex) error {
 		root, err := cid.Parse(data.RootCID)
 		if err != nil {
 			return err
 		}
 		ret = append(ret, &api.DataIndex{
+			Epoch:    epoch,
 			Miner:    provider,
 			RootCID:  root,
 			PieceCID: data.PieceCID,
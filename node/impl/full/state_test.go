@@ -0,0 +1,57 @@
+package full
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/specs-actors/v2/actors/builtin"
+	expertfund2 "github.com/filecoin-project/specs-actors/v2/actors/builtin/expertfund"
+
+	"github.com/EpiK-Protocol/go-epik/api"
+)
+
+func TestProjectExpertVesting(t *testing.T) {
+	// locked so that they unlock on the first epoch of days 10 and 12
+	day10 := 10*builtin.EpochsInDay - expertfund2.RewardVestingDelay - 1
+	day12 := 12*builtin.EpochsInDay - expertfund2.RewardVestingDelay - 1
+
+	schedule := projectExpertVesting(big.NewInt(5), map[abi.ChainEpoch]abi.TokenAmount{
+		day12:      big.NewInt(100),
+		day10:      big.NewInt(10),
+		day10 + 60: big.NewInt(20),
+	})
+	require.Equal(t, []api.ExpertRewardsDay{{
+		Epoch:     10 * builtin.EpochsInDay,
+		Unlocked:  big.NewInt(30),
+		Claimable: big.NewInt(35),
+	}, {
+		Epoch:     12 * builtin.EpochsInDay,
+		Unlocked:  big.NewInt(100),
+		Claimable: big.NewInt(135),
+	}}, schedule)
+
+	require.Empty(t, projectExpertVesting(big.NewInt(5), nil))
+}
+
+func TestProjectExpertVestingBoundary(t *testing.T) {
+	// expertfund unlocks funds locked at e when e+RewardVestingDelay < epoch,
+	// so funds locked at lastOfDay9 are still locked on the last epoch of day 9
+	lastOfDay9 := 10*builtin.EpochsInDay - 1 - expertfund2.RewardVestingDelay
+
+	schedule := projectExpertVesting(big.Zero(), map[abi.ChainEpoch]abi.TokenAmount{
+		lastOfDay9 - 1: big.NewInt(1),
+		lastOfDay9:     big.NewInt(2),
+	})
+	require.Equal(t, []api.ExpertRewardsDay{{
+		Epoch:     9 * builtin.EpochsInDay,
+		Unlocked:  big.NewInt(1),
+		Claimable: big.NewInt(1),
+	}, {
+		Epoch:     10 * builtin.EpochsInDay,
+		Unlocked:  big.NewInt(2),
+		Claimable: big.NewInt(3),
+	}}, schedule)
+}
@@ -2,6 +2,7 @@ package full
 
 import (
 	"context"
+	"time"
 
 	"go.uber.org/fx"
 	"golang.org/x/xerrors"
@@ -22,6 +23,7 @@ type WalletAPI struct {
 
 	StateManagerAPI stmgr.StateManagerAPI
 	Default         wallet.Default
+	Locker          wallet.Locker
 	api.WalletAPI
 }
 
@@ -82,6 +84,18 @@ func (a *WalletAPI) WalletSetDefault(ctx context.Context, addr address.Address)
 	return a.Default.SetDefault(ctx, addr)
 }
 
+func (a *WalletAPI) WalletSetPassphrase(ctx context.Context, passphrase string) error {
+	return a.Locker.WalletSetPassphrase(ctx, passphrase)
+}
+
+func (a *WalletAPI) WalletUnlock(ctx context.Context, passphrase string, timeout time.Duration) error {
+	return a.Locker.WalletUnlock(ctx, passphrase, timeout)
+}
+
+func (a *WalletAPI) WalletLock(ctx context.Context) error {
+	return a.Locker.WalletLock(ctx)
+}
+
 func (a *WalletAPI) WalletValidateAddress(ctx context.Context, str string) (address.Address, error) {
 	return address.NewFromString(str)
 }
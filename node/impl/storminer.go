@@ -665,4 +665,12 @@ func (sm *StorageMinerAPI) ActorAddressConfig(ctx context.Context) (api.AddressC
 	return sm.AddrSel.AddressConfig, nil
 }
 
+func (sm *StorageMinerAPI) MinerDataStatus(ctx context.Context, pieces bool) (api.MinerDataStatus, error) {
+	return sm.BlockMiner.DataStatus(ctx, pieces)
+}
+
+func (sm *StorageMinerAPI) MinerDataSources(ctx context.Context) ([]api.RetrievalSourceStats, error) {
+	return sm.BlockMiner.DataSources(ctx)
+}
+
 var _ api.StorageMiner = &StorageMinerAPI{}
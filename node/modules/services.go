@@ -18,6 +18,8 @@ import (
 
 	"github.com/filecoin-project/go-fil-markets/discovery"
 	discoveryimpl "github.com/filecoin-project/go-fil-markets/discovery/impl"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
 
 	"github.com/EpiK-Protocol/go-epik/build"
 	"github.com/EpiK-Protocol/go-epik/chain"
@@ -29,13 +31,19 @@ import (
 	"github.com/EpiK-Protocol/go-epik/chain/store"
 	"github.com/EpiK-Protocol/go-epik/chain/sub"
 	"github.com/EpiK-Protocol/go-epik/chain/types"
+	"github.com/EpiK-Protocol/go-epik/flowchmgr"
 	"github.com/EpiK-Protocol/go-epik/journal"
 	"github.com/EpiK-Protocol/go-epik/lib/peermgr"
 	marketevents "github.com/EpiK-Protocol/go-epik/markets/loggers"
+	"github.com/EpiK-Protocol/go-epik/node/config"
 	"github.com/EpiK-Protocol/go-epik/node/hello"
+	"github.com/EpiK-Protocol/go-epik/node/impl/full"
 	"github.com/EpiK-Protocol/go-epik/node/modules/dtypes"
 	"github.com/EpiK-Protocol/go-epik/node/modules/helpers"
 	"github.com/EpiK-Protocol/go-epik/node/repo"
+	"github.com/EpiK-Protocol/go-epik/paychmgr"
+	"github.com/EpiK-Protocol/go-epik/retrievalpledge"
+	"github.com/EpiK-Protocol/go-epik/voterewards"
 )
 
 var pubsubMsgsSyncEpochs = 10
@@ -248,3 +256,106 @@ func OpenFilesystemJournal(lr repo.LockedRepo, lc fx.Lifecycle, disabled journal
 
 	return jrnl, err
 }
+
+type voteRewardsAPI struct {
+	full.StateAPI
+	full.MpoolAPI
+}
+
+// AutoVoteRewards runs the service withdrawing and compounding the voting
+// rewards of the configured voters.
+func AutoVoteRewards(cfg config.VoteRewards) func(mctx helpers.MetricsCtx, lc fx.Lifecycle, ds dtypes.MetadataDS, state full.StateAPI, mpool full.MpoolAPI) {
+	return func(mctx helpers.MetricsCtx, lc fx.Lifecycle, ds dtypes.MetadataDS, state full.StateAPI, mpool full.MpoolAPI) {
+		ctx := helpers.LifecycleCtx(mctx, lc)
+		svc := voterewards.NewService(&voteRewardsAPI{StateAPI: state, MpoolAPI: mpool}, cfg, ds)
+
+		lc.Append(fx.Hook{
+			OnStart: func(context.Context) error {
+				go svc.Run(ctx)
+				return nil
+			},
+		})
+	}
+}
+
+type retrievalPledgeAPI struct {
+	full.ChainModuleAPI
+	full.StateAPI
+	full.MpoolAPI
+}
+
+// RetrievalPledgeManager runs the manager topping up the retrieval pledges of
+// the configured wallets.
+func RetrievalPledgeManager(cfg config.RetrievalPledge) func(mctx helpers.MetricsCtx, lc fx.Lifecycle, chain full.ChainModuleAPI, state full.StateAPI, mpool full.MpoolAPI) (*retrievalpledge.Manager, error) {
+	return func(mctx helpers.MetricsCtx, lc fx.Lifecycle, chain full.ChainModuleAPI, state full.StateAPI, mpool full.MpoolAPI) (*retrievalpledge.Manager, error) {
+		ctx := helpers.LifecycleCtx(mctx, lc)
+		mgr, err := retrievalpledge.NewManager(&retrievalPledgeAPI{ChainModuleAPI: chain, StateAPI: state, MpoolAPI: mpool}, cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		lc.Append(fx.Hook{
+			OnStart: func(context.Context) error {
+				go mgr.Run(ctx)
+				return nil
+			},
+		})
+		return mgr, nil
+	}
+}
+
+type retrievalWithdrawAPI struct {
+	full.ChainAPI
+	full.StateAPI
+	full.MpoolAPI
+}
+
+// AutoRetrievalWithdraw runs the scheduler withdrawing the locked retrieval
+// pledge funds of the configured wallets once they unlock.
+func AutoRetrievalWithdraw(cfg config.RetrievalWithdraw) func(mctx helpers.MetricsCtx, lc fx.Lifecycle, j journal.Journal, chain full.ChainAPI, state full.StateAPI, mpool full.MpoolAPI) error {
+	return func(mctx helpers.MetricsCtx, lc fx.Lifecycle, j journal.Journal, chain full.ChainAPI, state full.StateAPI, mpool full.MpoolAPI) error {
+		ctx := helpers.LifecycleCtx(mctx, lc)
+		s, err := retrievalpledge.NewWithdrawScheduler(&retrievalWithdrawAPI{ChainAPI: chain, StateAPI: state, MpoolAPI: mpool}, cfg, j)
+		if err != nil {
+			return err
+		}
+
+		lc.Append(fx.Hook{
+			OnStart: func(context.Context) error {
+				go s.Run(ctx)
+				return nil
+			},
+		})
+		return nil
+	}
+}
+
+// FlowchAutoSettler runs the automatic settlement and collection of the
+// inbound flow channels.
+func FlowchAutoSettler(cfg config.FlowchAuto) func(mctx helpers.MetricsCtx, lc fx.Lifecycle, pm *flowchmgr.Manager, chain full.ChainModuleAPI) (*paychmgr.AutoSettler, error) {
+	return func(mctx helpers.MetricsCtx, lc fx.Lifecycle, pm *flowchmgr.Manager, chain full.ChainModuleAPI) (*paychmgr.AutoSettler, error) {
+		ctx := helpers.LifecycleCtx(mctx, lc)
+
+		policy := paychmgr.AutoPolicy{
+			IdleEpochs:     abi.ChainEpoch(cfg.IdleEpochs),
+			ValueThreshold: big.Zero(),
+			Interval:       time.Duration(cfg.Interval),
+		}
+		if cfg.ValueThreshold != "" {
+			v, err := types.ParseEPK(cfg.ValueThreshold)
+			if err != nil {
+				return nil, xerrors.Errorf("parsing flow channel value threshold: %w", err)
+			}
+			policy.ValueThreshold = big.Int(v)
+		}
+
+		as := paychmgr.NewAutoSettler(pm.Manager, chain, policy)
+		lc.Append(fx.Hook{
+			OnStart: func(context.Context) error {
+				go as.Run(ctx)
+				return nil
+			},
+		})
+		return as, nil
+	}
+}
@@ -464,13 +464,13 @@ func StagingGraphsync(mctx helpers.MetricsCtx, lc fx.Lifecycle, ibs dtypes.Stagi
 	return gs
 }
 
-func SetupBlockProducer(lc fx.Lifecycle, ds dtypes.MetadataDS, api lapi.FullNode, epp gen.WinningPoStProver, sf *slashfilter.SlashFilter, j journal.Journal) (*lotusminer.Miner, error) {
+func SetupBlockProducer(lc fx.Lifecycle, ds dtypes.MetadataDS, api lapi.FullNode, epp gen.WinningPoStProver, sf *slashfilter.SlashFilter, j journal.Journal, policy lotusminer.DataPolicy) (*lotusminer.Miner, error) {
 	minerAddr, err := minerAddrFromDS(ds)
 	if err != nil {
 		return nil, err
 	}
 
-	m := lotusminer.NewMiner(api, epp, minerAddr, sf, j)
+	m := lotusminer.NewMiner(api, epp, minerAddr, sf, j, ds, policy)
 
 	lc.Append(fx.Hook{
 		OnStart: func(ctx context.Context) error {
@@ -872,6 +872,17 @@ func NewGetSealConfigFunc(r repo.LockedRepo) (dtypes.GetSealingConfigFunc, error
 	}, nil
 }
 
+// NewDataPolicy returns the chain-data replication policy, reading the
+// DataReplication config section once per replication round
+func NewDataPolicy(r repo.LockedRepo, sm *storage.Miner) lotusminer.DataPolicy {
+	return lotusminer.NewConfigDataPolicy(func() (out config.DataReplicationConfig, err error) {
+		err = readCfg(r, func(cfg *config.StorageMiner) {
+			out = cfg.DataReplication
+		})
+		return
+	}, sm.SealingSectors)
+}
+
 func NewSetExpectedSealDurationFunc(r repo.LockedRepo) (dtypes.SetExpectedSealDurationFunc, error) {
 	return func(delay time.Duration) (err error) {
 		err = mutateCfg(r, func(cfg *config.StorageMiner) {
@@ -0,0 +1,79 @@
+package paychmgr
+
+import (
+	"context"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+
+	"github.com/EpiK-Protocol/go-epik/chain/actors"
+	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/paych"
+	"github.com/EpiK-Protocol/go-epik/chain/types"
+)
+
+// channelActor adapts the manager to the actor of the channels it manages.
+// The manager works on the vouchers and lanes of the payment channel actor:
+// the adapters of other channel actors convert theirs.
+type channelActor interface {
+	// loadState loads the state of the channel actor at ch
+	loadState(ctx context.Context, sm stateManagerAPI, ch address.Address) (channelState, error)
+	// messageBuilder returns the builder of the messages sent by from to the
+	// channel actors of the given version
+	messageBuilder(version actors.Version, from address.Address) channelMessageBuilder
+}
+
+// channelState is the state of a channel actor
+type channelState interface {
+	paych.State
+
+	// Funds the vouchers of the channel can redeem
+	Funds() (abi.TokenAmount, error)
+}
+
+// channelMessageBuilder builds the messages sent to a channel actor
+type channelMessageBuilder interface {
+	paych.MessageBuilder
+
+	// AddFunds returns the message adding amount to the channel ch
+	AddFunds(ch address.Address, amount abi.TokenAmount) (*types.Message, error)
+}
+
+// paychActor is the adapter of the payment channel actor
+type paychActor struct{}
+
+func (paychActor) loadState(ctx context.Context, sm stateManagerAPI, ch address.Address) (channelState, error) {
+	act, st, err := sm.GetPaychState(ctx, ch, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &paychState{State: st, balance: act.Balance}, nil
+}
+
+func (paychActor) messageBuilder(version actors.Version, from address.Address) channelMessageBuilder {
+	return &paychMessageBuilder{MessageBuilder: paych.Message(version, from), from: from}
+}
+
+type paychState struct {
+	paych.State
+	balance abi.TokenAmount
+}
+
+// Funds of a payment channel are the balance of its actor
+func (s *paychState) Funds() (abi.TokenAmount, error) {
+	return s.balance, nil
+}
+
+type paychMessageBuilder struct {
+	paych.MessageBuilder
+	from address.Address
+}
+
+// AddFunds adds funds to a payment channel by a plain transfer to its actor
+func (mb *paychMessageBuilder) AddFunds(ch address.Address, amount abi.TokenAmount) (*types.Message, error) {
+	return &types.Message{
+		To:     ch,
+		From:   mb.from,
+		Value:  amount,
+		Method: 0,
+	}, nil
+}
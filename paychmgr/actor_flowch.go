@@ -0,0 +1,109 @@
+package paychmgr
+
+import (
+	"bytes"
+	"context"
+
+	"go.uber.org/fx"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+
+	"github.com/EpiK-Protocol/go-epik/chain/actors"
+	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/flowch"
+	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/paych"
+	"github.com/EpiK-Protocol/go-epik/chain/stmgr"
+	"github.com/EpiK-Protocol/go-epik/chain/types"
+	"github.com/EpiK-Protocol/go-epik/node/modules/dtypes"
+	"github.com/EpiK-Protocol/go-epik/node/modules/helpers"
+)
+
+// NewFlowchStore creates the store of the flow channels
+func NewFlowchStore(ds dtypes.MetadataDS) *Store {
+	return newStore(ds, "/flowch/")
+}
+
+// NewFlowchManager creates the manager of the flow channels. A flow channel
+// actor is a payment channel actor whose funds are the amount it received
+// through its AddFunds method, rather than its balance.
+func NewFlowchManager(mctx helpers.MetricsCtx, lc fx.Lifecycle, sm stmgr.StateManagerAPI, store *Store, api PaychAPI) *Manager {
+	return newActorManager(mctx, lc, flowchActor{}, sm, store, api)
+}
+
+// flowchActor is the adapter of the flow channel actor
+type flowchActor struct{}
+
+func (flowchActor) loadState(ctx context.Context, sm stateManagerAPI, ch address.Address) (channelState, error) {
+	_, st, err := sm.GetFlowchState(ctx, ch, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &flowchState{State: st}, nil
+}
+
+func (flowchActor) messageBuilder(version actors.Version, from address.Address) channelMessageBuilder {
+	return &flowchMessageBuilder{MessageBuilder: flowch.Message(version, from)}
+}
+
+type flowchState struct {
+	flowch.State
+}
+
+// Funds of a flow channel are the amount it received
+func (s *flowchState) Funds() (abi.TokenAmount, error) {
+	return s.Received()
+}
+
+func (s *flowchState) ForEachLaneState(cb func(idx uint64, dl paych.LaneState) error) error {
+	return s.State.ForEachLaneState(func(idx uint64, dl flowch.LaneState) error {
+		return cb(idx, dl)
+	})
+}
+
+type flowchMessageBuilder struct {
+	flowch.MessageBuilder
+}
+
+func (mb *flowchMessageBuilder) Update(ch address.Address, sv *paych.SignedVoucher, secret []byte) (*types.Message, error) {
+	fsv, err := ToFlowchVoucher(sv)
+	if err != nil {
+		return nil, err
+	}
+	return mb.MessageBuilder.Update(ch, fsv, secret)
+}
+
+// ToFlowchVoucher converts a payment channel voucher into the flow channel
+// voucher of the same encoding, and so of the same signature.
+func ToFlowchVoucher(sv *paych.SignedVoucher) (*flowch.SignedVoucher, error) {
+	if sv == nil {
+		return nil, nil
+	}
+
+	buf := new(bytes.Buffer)
+	if err := sv.MarshalCBOR(buf); err != nil {
+		return nil, err
+	}
+	var fsv flowch.SignedVoucher
+	if err := fsv.UnmarshalCBOR(buf); err != nil {
+		return nil, err
+	}
+	return &fsv, nil
+}
+
+// FromFlowchVoucher converts a flow channel voucher into the payment channel
+// voucher of the same encoding, and so of the same signature.
+func FromFlowchVoucher(fsv *flowch.SignedVoucher) (*paych.SignedVoucher, error) {
+	if fsv == nil {
+		return nil, nil
+	}
+
+	buf := new(bytes.Buffer)
+	if err := fsv.MarshalCBOR(buf); err != nil {
+		return nil, err
+	}
+	var sv paych.SignedVoucher
+	if err := sv.UnmarshalCBOR(buf); err != nil {
+		return nil, err
+	}
+	return &sv, nil
+}
@@ -0,0 +1,89 @@
+package paychmgr
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-state-types/big"
+	flowch2 "github.com/filecoin-project/specs-actors/v2/actors/builtin/flowch"
+	init2 "github.com/filecoin-project/specs-actors/v2/actors/builtin/init"
+	tutils "github.com/filecoin-project/specs-actors/v2/support/testing"
+
+	epikinit "github.com/EpiK-Protocol/go-epik/chain/actors/builtin/init"
+	"github.com/EpiK-Protocol/go-epik/chain/types"
+	"github.com/EpiK-Protocol/go-epik/lib/sigs"
+)
+
+// testActor is a channel actor the manager tests run against
+type testActor struct {
+	name  string
+	actor channelActor
+	// msgAmount returns the amount the create channel / add funds message
+	// msg sends to the channel
+	msgAmount func(t *testing.T, msg *types.Message) types.BigInt
+}
+
+var testActors = []testActor{{
+	name:  "paych",
+	actor: paychActor{},
+	msgAmount: func(t *testing.T, msg *types.Message) types.BigInt {
+		return msg.Value
+	},
+}, {
+	name:  "flowch",
+	actor: flowchActor{},
+	msgAmount: func(t *testing.T, msg *types.Message) types.BigInt {
+		require.True(t, msg.Value.IsZero())
+
+		if msg.To != epikinit.Address {
+			var params flowch2.AddFundsParams
+			require.NoError(t, params.UnmarshalCBOR(bytes.NewReader(msg.Params)))
+			return params.Amount
+		}
+
+		var exec init2.ExecParams
+		require.NoError(t, exec.UnmarshalCBOR(bytes.NewReader(msg.Params)))
+		var params flowch2.ConstructorParams
+		require.NoError(t, params.UnmarshalCBOR(bytes.NewReader(exec.ConstructorParams)))
+		return params.Amount
+	},
+}}
+
+// runActors runs the test against each channel actor
+func runActors(t *testing.T, test func(t *testing.T, ta testActor)) {
+	for _, ta := range testActors {
+		ta := ta
+		t.Run(ta.name, func(t *testing.T) {
+			test(t, ta)
+		})
+	}
+}
+
+func TestFlowchVoucherConversion(t *testing.T) {
+	fromKeyPrivate, fromKeyPublic := testGenerateKeyPair(t)
+	ch := tutils.NewIDAddr(t, 100)
+	from := tutils.NewSECP256K1Addr(t, string(fromKeyPublic))
+
+	sv := createTestVoucherWithExtra(t, ch, 1, 2, big.NewInt(5), fromKeyPrivate)
+
+	fsv, err := ToFlowchVoucher(sv)
+	require.NoError(t, err)
+	require.Equal(t, sv.Lane, fsv.Lane)
+	require.Equal(t, sv.Nonce, fsv.Nonce)
+	require.Equal(t, sv.Amount, fsv.Amount)
+
+	// The signature of the voucher is the signature of the flow channel voucher
+	vb, err := fsv.SigningBytes()
+	require.NoError(t, err)
+	require.NoError(t, sigs.Verify(fsv.Signature, from, vb))
+
+	back, err := FromFlowchVoucher(fsv)
+	require.NoError(t, err)
+	require.Equal(t, sv, back)
+
+	fsv, err = ToFlowchVoucher(nil)
+	require.NoError(t, err)
+	require.Nil(t, fsv)
+}
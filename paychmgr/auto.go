@@ -0,0 +1,321 @@
+package paychmgr
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/ipfs/go-cid"
+	"golang.org/x/xerrors"
+
+	"github.com/EpiK-Protocol/go-epik/api"
+	"github.com/EpiK-Protocol/go-epik/build"
+	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/paych"
+	"github.com/EpiK-Protocol/go-epik/chain/types"
+)
+
+// AutoPolicy decides when the inbound channels are settled automatically.
+// A channel is settled once its redeemable vouchers reach ValueThreshold, or
+// once they didn't change for IdleEpochs. Zero values disable the criteria.
+type AutoPolicy struct {
+	IdleEpochs     abi.ChainEpoch
+	ValueThreshold abi.TokenAmount
+	// Interval between checks of the channels
+	Interval time.Duration
+}
+
+// shouldSettle reports whether a channel holding value redeemable since
+// lastActivity is to be settled at height.
+func (p AutoPolicy) shouldSettle(value abi.TokenAmount, lastActivity, height abi.ChainEpoch) bool {
+	if value.IsZero() {
+		return false
+	}
+	if !p.ValueThreshold.Nil() && !p.ValueThreshold.IsZero() && value.GreaterThanEqual(p.ValueThreshold) {
+		return true
+	}
+	return p.IdleEpochs > 0 && height-lastActivity >= p.IdleEpochs
+}
+
+// autoMsgTimeout is how long a settle or collect message may take to land
+// before it's deemed dropped, like from the mpool of a restarted node, and is
+// sent again.
+const autoMsgTimeout = 120 * time.Duration(build.BlockDelaySecs) * time.Second
+
+type autoHeadAPI interface {
+	ChainHead(context.Context) (*types.TipSet, error)
+}
+
+// AutoSettler settles the inbound channels according to its policy, follows
+// them until their settling height, and collects them once eligible. The
+// progress of each channel is kept in the store.
+type AutoSettler struct {
+	pm     *Manager
+	api    autoHeadAPI
+	policy AutoPolicy
+
+	// msgTimeout is how long the settle and collect messages are waited for
+	msgTimeout time.Duration
+
+	lk sync.Mutex
+	// inflight are the channels whose settle or collect message waits to land
+	inflight map[address.Address]struct{}
+}
+
+func NewAutoSettler(pm *Manager, api autoHeadAPI, policy AutoPolicy) *AutoSettler {
+	return &AutoSettler{
+		pm:         pm,
+		api:        api,
+		policy:     policy,
+		msgTimeout: autoMsgTimeout,
+		inflight:   map[address.Address]struct{}{},
+	}
+}
+
+// Run checks the inbound channels every interval of the policy until ctx is
+// done.
+func (as *AutoSettler) Run(ctx context.Context) {
+	interval := as.policy.Interval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := as.process(ctx); err != nil {
+			log.Errorf("automatic channel settlement: %s", err)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Status returns the progress of the tracked inbound channels.
+func (as *AutoSettler) Status() ([]*api.FlowchAutoChannel, error) {
+	return as.pm.store.AutoChannels()
+}
+
+func (as *AutoSettler) process(ctx context.Context) error {
+	head, err := as.api.ChainHead(ctx)
+	if err != nil {
+		return xerrors.Errorf("getting chain head: %w", err)
+	}
+
+	cis, err := as.pm.store.findChans(func(ci *ChannelInfo) bool {
+		return ci.Direction == DirInbound && ci.Channel != nil
+	}, 0)
+	if err != nil {
+		return xerrors.Errorf("listing inbound channels: %w", err)
+	}
+
+	for i := range cis {
+		ci := &cis[i]
+		if err := as.processChannel(ctx, ci, head.Height()); err != nil {
+			log.Errorf("automatic settlement of channel %s: %s", *ci.Channel, err)
+		}
+	}
+	return nil
+}
+
+func (as *AutoSettler) processChannel(ctx context.Context, ci *ChannelInfo, height abi.ChainEpoch) error {
+	ch := *ci.Channel
+
+	as.lk.Lock()
+	_, busy := as.inflight[ch]
+	as.lk.Unlock()
+	if busy {
+		return nil
+	}
+
+	st, err := as.pm.store.AutoChannel(ch)
+	if err != nil {
+		return err
+	}
+	if st == nil {
+		st = &api.FlowchAutoChannel{
+			Channel:      ch,
+			Value:        big.Zero(),
+			LastActivity: height,
+		}
+	}
+	if st.Collected {
+		return nil
+	}
+
+	state, err := as.pm.sa.loadChannelState(ctx, ch)
+	if err != nil {
+		return xerrors.Errorf("loading channel state: %w", err)
+	}
+	settlingAt, err := state.SettlingAt()
+	if err != nil {
+		return err
+	}
+	st.SettlingAt = settlingAt
+
+	if settlingAt == 0 {
+		if st.SettleMsg != nil {
+			// the settle message didn't land before a restart
+			if err := as.save(st, nil); err != nil {
+				return err
+			}
+			as.wait(ctx, ch, *st.SettleMsg, false)
+			return nil
+		}
+
+		value, err := as.redeemable(ctx, ch)
+		if err != nil {
+			return xerrors.Errorf("getting redeemable value: %w", err)
+		}
+		if !value.Equals(st.Value) {
+			st.Value = value
+			st.LastActivity = height
+		}
+		if !as.policy.shouldSettle(st.Value, st.LastActivity, height) {
+			return as.save(st, nil)
+		}
+
+		log.Infow("settling channel", "channel", ch, "value", types.EPK(st.Value), "lastActivity", st.LastActivity)
+
+		mcid, err := as.pm.Settle(ctx, ch)
+		if err != nil {
+			st.Error = err.Error()
+			return as.save(st, xerrors.Errorf("settling: %w", err))
+		}
+		st.SettleMsg, st.Error = &mcid, ""
+		if err := as.save(st, nil); err != nil {
+			return err
+		}
+		as.wait(ctx, ch, mcid, false)
+		return nil
+	}
+
+	// settled, manually or by us: collect once the settling height is reached
+	if st.CollectMsg != nil {
+		// the collect message didn't land before a restart
+		if err := as.save(st, nil); err != nil {
+			return err
+		}
+		as.wait(ctx, ch, *st.CollectMsg, true)
+		return nil
+	}
+	if height < settlingAt {
+		return as.save(st, nil)
+	}
+
+	log.Infow("collecting channel", "channel", ch, "settlingAt", settlingAt)
+
+	mcid, err := as.pm.Collect(ctx, ch)
+	if err != nil {
+		st.Error = err.Error()
+		return as.save(st, xerrors.Errorf("collecting: %w", err))
+	}
+	st.CollectMsg, st.Error = &mcid, ""
+	if err := as.save(st, nil); err != nil {
+		return err
+	}
+	as.wait(ctx, ch, mcid, true)
+	return nil
+}
+
+// redeemable returns the sum of the best spendable vouchers of each lane.
+func (as *AutoSettler) redeemable(ctx context.Context, ch address.Address) (abi.TokenAmount, error) {
+	best, err := BestSpendableByLane(ctx, &managerSpendableAPI{as.pm}, ch)
+	if err != nil {
+		return big.Zero(), err
+	}
+
+	value := big.Zero()
+	for _, sv := range best {
+		value = big.Add(value, sv.Amount)
+	}
+	return value, nil
+}
+
+// wait follows the settle or collect message of the channel in the
+// background, and records its outcome. A failed message, or one not landed
+// within msgTimeout, is cleared so that it is sent again on the next check,
+// unless the channel state shows it landed meanwhile.
+func (as *AutoSettler) wait(ctx context.Context, ch address.Address, mcid cid.Cid, collect bool) {
+	as.lk.Lock()
+	as.inflight[ch] = struct{}{}
+	as.lk.Unlock()
+
+	go func() {
+		defer func() {
+			as.lk.Lock()
+			delete(as.inflight, ch)
+			as.lk.Unlock()
+		}()
+
+		wctx, cancel := context.WithTimeout(ctx, as.msgTimeout)
+		defer cancel()
+
+		lookup, err := as.pm.pchapi.StateWaitMsg(wctx, mcid, build.MessageConfidence)
+		if err == nil && lookup.Receipt.ExitCode != 0 {
+			err = xerrors.Errorf("message %s failed with exit code %d", mcid, lookup.Receipt.ExitCode)
+		}
+		if err != nil && ctx.Err() != nil {
+			return
+		}
+		if err != nil && wctx.Err() != nil {
+			err = xerrors.Errorf("message %s didn't land within %s, sending again", mcid, as.msgTimeout)
+		}
+
+		st, serr := as.pm.store.AutoChannel(ch)
+		if serr != nil || st == nil {
+			log.Errorf("loading automatic settlement state of channel %s: %v", ch, serr)
+			return
+		}
+
+		switch {
+		case err != nil && collect:
+			st.CollectMsg = nil
+			st.Error = err.Error()
+		case err != nil:
+			st.SettleMsg = nil
+			st.Error = err.Error()
+		case collect:
+			st.Collected = true
+		}
+		if err := as.save(st, err); err != nil {
+			log.Errorf("automatic settlement of channel %s: %s", ch, err)
+		}
+	}()
+}
+
+func (as *AutoSettler) save(st *api.FlowchAutoChannel, err error) error {
+	if perr := as.pm.store.PutAutoChannel(st); perr != nil {
+		return xerrors.Errorf("saving automatic settlement state: %w", perr)
+	}
+	return err
+}
+
+// managerSpendableAPI adapts the manager to BestSpendableAPI.
+type managerSpendableAPI struct {
+	pm *Manager
+}
+
+func (a *managerSpendableAPI) PaychVoucherList(ctx context.Context, ch address.Address) ([]*paych.SignedVoucher, error) {
+	vis, err := a.pm.ListVouchers(ctx, ch)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]*paych.SignedVoucher, len(vis))
+	for i, vi := range vis {
+		out[i] = vi.Voucher
+	}
+	return out, nil
+}
+
+func (a *managerSpendableAPI) PaychVoucherCheckSpendable(ctx context.Context, ch address.Address, sv *paych.SignedVoucher, secret []byte, proof []byte) (bool, error) {
+	return a.pm.CheckVoucherSpendable(ctx, ch, sv, secret, proof)
+}
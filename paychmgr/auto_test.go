@@ -0,0 +1,120 @@
+package paychmgr
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	tutils "github.com/filecoin-project/specs-actors/v2/support/testing"
+	"github.com/ipfs/go-cid"
+	ds "github.com/ipfs/go-datastore"
+	ds_sync "github.com/ipfs/go-datastore/sync"
+	"github.com/stretchr/testify/require"
+
+	"github.com/EpiK-Protocol/go-epik/api"
+)
+
+func TestAutoPolicy(t *testing.T) {
+	p := AutoPolicy{
+		IdleEpochs:     10,
+		ValueThreshold: big.NewInt(100),
+	}
+
+	// nothing to redeem
+	require.False(t, p.shouldSettle(big.Zero(), 0, 100))
+	// active and under the threshold
+	require.False(t, p.shouldSettle(big.NewInt(50), 95, 100))
+	// idle
+	require.True(t, p.shouldSettle(big.NewInt(50), 90, 100))
+	// over the threshold
+	require.True(t, p.shouldSettle(big.NewInt(100), 99, 100))
+
+	// disabled criteria
+	p = AutoPolicy{ValueThreshold: big.Zero()}
+	require.False(t, p.shouldSettle(big.NewInt(1000), 0, 1000))
+}
+
+func TestStoreAutoChannels(t *testing.T) {
+	store := NewStore(ds_sync.MutexWrap(ds.NewMapDatastore()))
+
+	ch := tutils.NewIDAddr(t, 100)
+	st, err := store.AutoChannel(ch)
+	require.NoError(t, err)
+	require.Nil(t, st)
+
+	settle := tutils.MakeCID("settle", nil)
+	require.NoError(t, store.PutAutoChannel(&api.FlowchAutoChannel{
+		Channel:      ch,
+		Value:        big.NewInt(5),
+		LastActivity: 10,
+		SettleMsg:    &settle,
+		SettlingAt:   abi.ChainEpoch(20),
+	}))
+
+	st, err = store.AutoChannel(ch)
+	require.NoError(t, err)
+	require.Equal(t, ch, st.Channel)
+	require.Equal(t, big.NewInt(5), st.Value)
+	require.Equal(t, settle, *st.SettleMsg)
+	require.Nil(t, st.CollectMsg)
+
+	// auto channels are not listed as channels
+	addrs, err := store.ListChannels()
+	require.NoError(t, err)
+	require.Len(t, addrs, 0)
+
+	sts, err := store.AutoChannels()
+	require.NoError(t, err)
+	require.Len(t, sts, 1)
+}
+
+// lostMsgAPI never sees the messages it's waiting for land.
+type lostMsgAPI struct {
+	*mockManagerAPI
+}
+
+func (lostMsgAPI) StateWaitMsg(ctx context.Context, _ cid.Cid, _ uint64) (*api.MsgLookup, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestAutoSettlerLostMessage(t *testing.T) {
+	runActors(t, testAutoSettlerLostMessage)
+}
+
+func testAutoSettlerLostMessage(t *testing.T, ta testActor) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	store := NewStore(ds_sync.MutexWrap(ds.NewMapDatastore()))
+	mgr, err := newManager(ta.actor, store, lostMsgAPI{newMockManagerAPI()})
+	require.NoError(t, err)
+
+	as := NewAutoSettler(mgr, nil, AutoPolicy{})
+	as.msgTimeout = 10 * time.Millisecond
+
+	ch := tutils.NewIDAddr(t, 100)
+	settle := tutils.MakeCID("settle", nil)
+	require.NoError(t, store.PutAutoChannel(&api.FlowchAutoChannel{
+		Channel:   ch,
+		Value:     big.NewInt(5),
+		SettleMsg: &settle,
+	}))
+
+	// the settle message was dropped before a restart
+	as.wait(ctx, ch, settle, false)
+	require.Eventually(t, func() bool {
+		as.lk.Lock()
+		defer as.lk.Unlock()
+		_, ok := as.inflight[ch]
+		return !ok
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// cleared to be sent again
+	st, err := store.AutoChannel(ch)
+	require.NoError(t, err)
+	require.Nil(t, st.SettleMsg)
+	require.Contains(t, st.Error, "didn't land")
+}
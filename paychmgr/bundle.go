@@ -0,0 +1,293 @@
+package paychmgr
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"golang.org/x/xerrors"
+
+	"github.com/EpiK-Protocol/go-epik/api"
+	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/paych"
+	"github.com/EpiK-Protocol/go-epik/chain/types"
+	"github.com/EpiK-Protocol/go-epik/lib/sigs"
+)
+
+// BundleVersion is the version of the bundles written by Export
+const BundleVersion = 1
+
+// The bundles are those of the flow channel API, whose vouchers are the flow
+// channel vouchers of the same encoding as the tracked ones.
+
+// Export exports the given channels, or all tracked channels if chs is empty,
+// into a bundle signed by signer.
+func (pm *Manager) Export(ctx context.Context, signer address.Address, chs []address.Address) (*api.FlowchBundle, error) {
+	var cis []ChannelInfo
+	if len(chs) == 0 {
+		var err error
+		pm.lk.RLock()
+		cis, err = pm.store.findChans(func(ci *ChannelInfo) bool {
+			return ci.Channel != nil
+		}, 0)
+		pm.lk.RUnlock()
+		if err != nil {
+			return nil, xerrors.Errorf("listing channels: %w", err)
+		}
+	}
+	for _, ch := range chs {
+		ci, err := pm.GetChannelInfo(ch)
+		if err != nil {
+			return nil, xerrors.Errorf("getting channel %s: %w", ch, err)
+		}
+		cis = append(cis, *ci)
+	}
+
+	b := &api.FlowchBundle{
+		Version:   BundleVersion,
+		Timestamp: time.Now().Unix(),
+		Signer:    signer,
+		Channels:  make([]*api.FlowchBundleChannel, 0, len(cis)),
+	}
+	for i := range cis {
+		bch, err := pm.exportChannel(ctx, &cis[i])
+		if err != nil {
+			return nil, xerrors.Errorf("exporting channel %s: %w", *cis[i].Channel, err)
+		}
+		b.Channels = append(b.Channels, bch)
+	}
+
+	sb, err := bundleSigningBytes(b)
+	if err != nil {
+		return nil, err
+	}
+	b.Signature, err = pm.pchapi.WalletSign(ctx, signer, sb)
+	if err != nil {
+		return nil, xerrors.Errorf("signing bundle: %w", err)
+	}
+	return b, nil
+}
+
+func (pm *Manager) exportChannel(ctx context.Context, ci *ChannelInfo) (*api.FlowchBundleChannel, error) {
+	bch := &api.FlowchBundleChannel{
+		Channel:   *ci.Channel,
+		Control:   ci.Control,
+		Target:    ci.Target,
+		Direction: api.PCHDir(ci.Direction),
+		NextLane:  ci.NextLane,
+		Amount:    ci.Amount,
+		Settling:  ci.Settling,
+		Vouchers:  make([]api.FlowchBundleVoucher, 0, len(ci.Vouchers)),
+	}
+	if bch.Amount.Nil() {
+		bch.Amount = types.NewInt(0)
+	}
+	for _, vi := range ci.Vouchers {
+		sv, err := ToFlowchVoucher(vi.Voucher)
+		if err != nil {
+			return nil, err
+		}
+		bch.Vouchers = append(bch.Vouchers, api.FlowchBundleVoucher{
+			Voucher:   sv,
+			Submitted: vi.Submitted,
+		})
+	}
+
+	st, err := pm.sa.loadChannelState(ctx, *ci.Channel)
+	if err != nil {
+		// collected channels have no state anymore
+		log.Warnf("exporting channel %s without lanes: loading state: %s", *ci.Channel, err)
+		return bch, nil
+	}
+	err = st.ForEachLaneState(func(idx uint64, ls paych.LaneState) error {
+		nonce, err := ls.Nonce()
+		if err != nil {
+			return err
+		}
+		redeemed, err := ls.Redeemed()
+		if err != nil {
+			return err
+		}
+		bch.Lanes = append(bch.Lanes, api.FlowchBundleLane{
+			Lane:     idx,
+			Nonce:    nonce,
+			Redeemed: redeemed,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, xerrors.Errorf("loading lanes: %w", err)
+	}
+	return bch, nil
+}
+
+// Import verifies the signatures of the bundle and of its vouchers, and
+// tracks its channels. The vouchers of the already tracked channels are
+// merged. It returns the imported channels.
+func (pm *Manager) Import(ctx context.Context, b *api.FlowchBundle) ([]address.Address, error) {
+	if b.Version != BundleVersion {
+		return nil, xerrors.Errorf("unsupported bundle version %d, expected %d", b.Version, BundleVersion)
+	}
+	if b.Signature == nil {
+		return nil, xerrors.Errorf("bundle is not signed")
+	}
+
+	signer, err := pm.pchapi.StateAccountKey(ctx, b.Signer, types.EmptyTSK)
+	if err != nil {
+		return nil, xerrors.Errorf("resolving bundle signer %s: %w", b.Signer, err)
+	}
+	sb, err := bundleSigningBytes(b)
+	if err != nil {
+		return nil, err
+	}
+	if err := sigs.Verify(b.Signature, signer, sb); err != nil {
+		return nil, xerrors.Errorf("verifying bundle signature: %w", err)
+	}
+
+	out := make([]address.Address, 0, len(b.Channels))
+	for _, bch := range b.Channels {
+		if err := pm.importChannel(ctx, bch); err != nil {
+			return out, xerrors.Errorf("importing channel %s: %w", bch.Channel, err)
+		}
+		out = append(out, bch.Channel)
+	}
+	return out, nil
+}
+
+func (pm *Manager) importChannel(ctx context.Context, bch *api.FlowchBundleChannel) error {
+	if bch.Direction != DirInbound && bch.Direction != DirOutbound {
+		return xerrors.Errorf("invalid direction %d", bch.Direction)
+	}
+
+	// the channel must exist on chain between the same parties
+	stateCi, err := pm.sa.loadStateChannelInfo(ctx, bch.Channel, uint64(bch.Direction))
+	if err != nil {
+		return xerrors.Errorf("loading channel from state: %w", err)
+	}
+	if stateCi.Control != bch.Control || stateCi.Target != bch.Target {
+		return xerrors.Errorf("channel is between %s and %s on chain, not %s and %s",
+			stateCi.Control, stateCi.Target, bch.Control, bch.Target)
+	}
+
+	// and be controlled by this node
+	has, err := pm.pchapi.WalletHas(ctx, bch.Control)
+	if err != nil {
+		return err
+	}
+	if !has {
+		return xerrors.Errorf("wallet does not have key for address %s", bch.Control)
+	}
+
+	from := bch.Control
+	if bch.Direction == DirInbound {
+		from = bch.Target
+	}
+	vis := make([]*VoucherInfo, 0, len(bch.Vouchers))
+	for _, bv := range bch.Vouchers {
+		sv, err := FromFlowchVoucher(bv.Voucher)
+		if err != nil {
+			return err
+		}
+		if err := checkVoucherSignature(bch.Channel, from, sv); err != nil {
+			return err
+		}
+		vis = append(vis, &VoucherInfo{Voucher: sv, Submitted: bv.Submitted})
+	}
+
+	pm.lk.Lock()
+	_, err = pm.store.ByAddress(bch.Channel)
+	if err == ErrChannelNotTracked {
+		ci := &ChannelInfo{
+			Channel:   &bch.Channel,
+			Control:   bch.Control,
+			Target:    bch.Target,
+			Direction: uint64(bch.Direction),
+			NextLane:  bch.NextLane,
+			Amount:    bch.Amount,
+			Settling:  bch.Settling,
+		}
+		if stateCi.NextLane > ci.NextLane {
+			ci.NextLane = stateCi.NextLane
+		}
+		if err := mergeVouchers(ci, vis); err != nil {
+			pm.lk.Unlock()
+			return err
+		}
+
+		_, err = pm.store.TrackChannel(ci)
+		pm.lk.Unlock()
+		return err
+	}
+	pm.lk.Unlock()
+	if err != nil {
+		return err
+	}
+
+	// already tracked: merge under the channel lock
+	ca, err := pm.accessorByAddress(bch.Channel)
+	if err != nil {
+		return err
+	}
+	ca.lk.Lock()
+	defer ca.lk.Unlock()
+
+	ci, err := ca.store.ByAddress(bch.Channel)
+	if err != nil {
+		return err
+	}
+	if bch.NextLane > ci.NextLane {
+		ci.NextLane = bch.NextLane
+	}
+	ci.Settling = ci.Settling || bch.Settling
+	if err := mergeVouchers(ci, vis); err != nil {
+		return err
+	}
+	return ca.store.putChannelInfo(ci)
+}
+
+// mergeVouchers adds the vouchers missing from the channel, and marks the
+// vouchers submitted in the bundle as submitted.
+func mergeVouchers(ci *ChannelInfo, bvis []*VoucherInfo) error {
+	for _, bvi := range bvis {
+		vi, err := ci.infoForVoucher(bvi.Voucher)
+		if err != nil {
+			return err
+		}
+		if vi == nil {
+			ci.Vouchers = append(ci.Vouchers, bvi)
+			continue
+		}
+		vi.Submitted = vi.Submitted || bvi.Submitted
+	}
+	return nil
+}
+
+func checkVoucherSignature(ch, from address.Address, sv *paych.SignedVoucher) error {
+	if sv == nil {
+		return xerrors.Errorf("missing voucher")
+	}
+	if sv.ChannelAddr != ch {
+		return xerrors.Errorf("voucher ChannelAddr doesn't match channel address, got %s, expected %s", sv.ChannelAddr, ch)
+	}
+	vb, err := sv.SigningBytes()
+	if err != nil {
+		return err
+	}
+	if err := sigs.Verify(sv.Signature, from, vb); err != nil {
+		return xerrors.Errorf("verifying signature of voucher of lane %d nonce %d: %w", sv.Lane, sv.Nonce, err)
+	}
+	return nil
+}
+
+// bundleSigningBytes returns the JSON encoding of the bundle without its
+// signature.
+func bundleSigningBytes(b *api.FlowchBundle) ([]byte, error) {
+	unsigned := *b
+	unsigned.Signature = nil
+
+	sb, err := json.Marshal(&unsigned)
+	if err != nil {
+		return nil, xerrors.Errorf("encoding bundle: %w", err)
+	}
+	return sb, nil
+}
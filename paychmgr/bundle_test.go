@@ -0,0 +1,101 @@
+package paychmgr
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/ipfs/go-cid"
+	ds "github.com/ipfs/go-datastore"
+	ds_sync "github.com/ipfs/go-datastore/sync"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/specs-actors/v2/actors/builtin"
+	tutils "github.com/filecoin-project/specs-actors/v2/support/testing"
+
+	"github.com/EpiK-Protocol/go-epik/api"
+	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/paych"
+	paychmock "github.com/EpiK-Protocol/go-epik/chain/actors/builtin/paych/mock"
+	"github.com/EpiK-Protocol/go-epik/chain/types"
+)
+
+func TestExportImport(t *testing.T) {
+	runActors(t, testExportImport)
+}
+
+func testExportImport(t *testing.T, ta testActor) {
+	ctx := context.Background()
+
+	fromKeyPrivate, fromKeyPublic := testGenerateKeyPair(t)
+	toKeyPrivate, toKeyPublic := testGenerateKeyPair(t)
+
+	ch := tutils.NewIDAddr(t, 100)
+	from := tutils.NewSECP256K1Addr(t, string(fromKeyPublic))
+	to := tutils.NewSECP256K1Addr(t, string(toKeyPublic))
+	fromAcct := tutils.NewActorAddr(t, "fromAct")
+	toAcct := tutils.NewActorAddr(t, "toAct")
+
+	act := &types.Actor{
+		Code:    builtin.AccountActorCodeID,
+		Head:    cid.Cid{},
+		Nonce:   0,
+		Balance: types.NewInt(20),
+	}
+	newMock := func() *mockManagerAPI {
+		mock := newMockManagerAPI()
+		mock.setAccountAddress(fromAcct, from)
+		mock.setAccountAddress(toAcct, to)
+		mock.addWalletAddress(to)
+		mock.addSigningKey(toKeyPrivate)
+		mock.setPaychState(ch, act, paychmock.NewMockPayChState(fromAcct, toAcct, abi.ChainEpoch(0), make(map[uint64]paych.LaneState)))
+		return mock
+	}
+
+	// Track an inbound channel with a voucher on the source node
+	src, err := newManager(ta.actor, NewStore(ds_sync.MutexWrap(ds.NewMapDatastore())), newMock())
+	require.NoError(t, err)
+
+	sv := createTestVoucher(t, ch, 1, 1, big.NewInt(5), fromKeyPrivate)
+	_, err = src.AddVoucherInbound(ctx, ch, sv, nil, big.NewInt(0))
+	require.NoError(t, err)
+
+	bundle, err := src.Export(ctx, to, nil)
+	require.NoError(t, err)
+	require.Len(t, bundle.Channels, 1)
+	require.Equal(t, ch, bundle.Channels[0].Channel)
+	require.Len(t, bundle.Channels[0].Vouchers, 1)
+
+	// The bundle goes through a file
+	b, err := json.Marshal(bundle)
+	require.NoError(t, err)
+	var decoded api.FlowchBundle
+	require.NoError(t, json.Unmarshal(b, &decoded))
+
+	// Import it into the destination node
+	dst, err := newManager(ta.actor, NewStore(ds_sync.MutexWrap(ds.NewMapDatastore())), newMock())
+	require.NoError(t, err)
+
+	chs, err := dst.Import(ctx, &decoded)
+	require.NoError(t, err)
+	require.Equal(t, []address.Address{ch}, chs)
+
+	vouchers, err := dst.ListVouchers(ctx, ch)
+	require.NoError(t, err)
+	require.Len(t, vouchers, 1)
+	require.Equal(t, sv.Amount, vouchers[0].Voucher.Amount)
+
+	// Importing again doesn't duplicate the vouchers
+	_, err = dst.Import(ctx, &decoded)
+	require.NoError(t, err)
+	vouchers, err = dst.ListVouchers(ctx, ch)
+	require.NoError(t, err)
+	require.Len(t, vouchers, 1)
+
+	// A tampered bundle is rejected
+	decoded.Channels[0].NextLane = 10
+	_, err = dst.Import(ctx, &decoded)
+	require.Error(t, err)
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/filecoin-project/go-state-types/network"
 
 	"github.com/EpiK-Protocol/go-epik/api"
+	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/flowch"
 	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/paych"
 	"github.com/EpiK-Protocol/go-epik/chain/stmgr"
 	"github.com/EpiK-Protocol/go-epik/chain/types"
@@ -39,6 +40,7 @@ type PaychAPI struct {
 type stateManagerAPI interface {
 	ResolveToKeyAddress(ctx context.Context, addr address.Address, ts *types.TipSet) (address.Address, error)
 	GetPaychState(ctx context.Context, addr address.Address, ts *types.TipSet) (*types.Actor, paych.State, error)
+	GetFlowchState(ctx context.Context, addr address.Address, ts *types.TipSet) (*types.Actor, flowch.State, error)
 	Call(ctx context.Context, msg *types.Message, ts *types.TipSet) (*api.InvocResult, error)
 }
 
@@ -78,6 +80,11 @@ type Manager struct {
 }
 
 func NewManager(mctx helpers.MetricsCtx, lc fx.Lifecycle, sm stmgr.StateManagerAPI, pchstore *Store, api PaychAPI) *Manager {
+	return newActorManager(mctx, lc, paychActor{}, sm, pchstore, api)
+}
+
+// newActorManager creates a manager of the channels of the given actor
+func newActorManager(mctx helpers.MetricsCtx, lc fx.Lifecycle, actor channelActor, sm stmgr.StateManagerAPI, pchstore *Store, api PaychAPI) *Manager {
 	ctx := helpers.LifecycleCtx(mctx, lc)
 	ctx, shutdown := context.WithCancel(ctx)
 
@@ -86,17 +93,17 @@ func NewManager(mctx helpers.MetricsCtx, lc fx.Lifecycle, sm stmgr.StateManagerA
 		ctx:      ctx,
 		shutdown: shutdown,
 		store:    pchstore,
-		sa:       &stateAccessor{sm: impl},
+		sa:       &stateAccessor{sm: impl, actor: actor},
 		channels: make(map[string]*channelAccessor),
 		pchapi:   impl,
 	}
 }
 
 // newManager is used by the tests to supply mocks
-func newManager(pchstore *Store, pchapi managerAPI) (*Manager, error) {
+func newManager(actor channelActor, pchstore *Store, pchapi managerAPI) (*Manager, error) {
 	pm := &Manager{
 		store:    pchstore,
-		sa:       &stateAccessor{sm: pchapi},
+		sa:       &stateAccessor{sm: pchapi, actor: actor},
 		channels: make(map[string]*channelAccessor),
 		pchapi:   pchapi,
 	}
@@ -8,11 +8,13 @@ import (
 	"github.com/ipfs/go-cid"
 
 	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/filecoin-project/go-state-types/crypto"
 	"github.com/filecoin-project/go-state-types/network"
 
 	"github.com/EpiK-Protocol/go-epik/api"
 	"github.com/EpiK-Protocol/go-epik/build"
+	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/flowch"
 	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/paych"
 	"github.com/EpiK-Protocol/go-epik/chain/types"
 	"github.com/EpiK-Protocol/go-epik/lib/sigs"
@@ -82,6 +84,31 @@ func (sm *mockStateManager) GetPaychState(ctx context.Context, addr address.Addr
 	return info.actor, info.state, nil
 }
 
+// GetFlowchState returns the payment channel state set for addr as the state of
+// a flow channel, which received the balance of the actor.
+func (sm *mockStateManager) GetFlowchState(ctx context.Context, addr address.Address, ts *types.TipSet) (*types.Actor, flowch.State, error) {
+	act, st, err := sm.GetPaychState(ctx, addr, ts)
+	if err != nil {
+		return nil, nil, err
+	}
+	return act, &mockFlowchState{State: st, received: act.Balance}, nil
+}
+
+type mockFlowchState struct {
+	paych.State
+	received abi.TokenAmount
+}
+
+func (ms *mockFlowchState) Received() (abi.TokenAmount, error) {
+	return ms.received, nil
+}
+
+func (ms *mockFlowchState) ForEachLaneState(cb func(idx uint64, dl flowch.LaneState) error) error {
+	return ms.State.ForEachLaneState(func(idx uint64, dl paych.LaneState) error {
+		return cb(idx, dl)
+	})
+}
+
 func (sm *mockStateManager) setCallResponse(response *api.InvocResult) {
 	sm.lk.Lock()
 	defer sm.lk.Unlock()
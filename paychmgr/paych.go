@@ -82,13 +82,13 @@ func newChannelAccessor(pm *Manager, from address.Address, to address.Address) *
 	}
 }
 
-func (ca *channelAccessor) messageBuilder(ctx context.Context, from address.Address) (paych.MessageBuilder, error) {
+func (ca *channelAccessor) messageBuilder(ctx context.Context, from address.Address) (channelMessageBuilder, error) {
 	nwVersion, err := ca.api.StateNetworkVersion(ctx, types.EmptyTSK)
 	if err != nil {
 		return nil, err
 	}
 
-	return paych.Message(actors.VersionForNetwork(nwVersion), from), nil
+	return ca.sa.actor.messageBuilder(actors.VersionForNetwork(nwVersion), from), nil
 }
 
 func (ca *channelAccessor) getChannelInfo(addr address.Address) (*ChannelInfo, error) {
@@ -180,8 +180,8 @@ func (ca *channelAccessor) checkVoucherValidUnlocked(ctx context.Context, ch add
 		return nil, xerrors.Errorf("voucher ChannelAddr doesn't match channel address, got %s, expected %s", sv.ChannelAddr, ch)
 	}
 
-	// Load payment channel actor state
-	act, pchState, err := ca.sa.loadPaychActorState(ctx, ch)
+	// Load channel actor state
+	pchState, err := ca.sa.loadChannelState(ctx, ch)
 	if err != nil {
 		return nil, err
 	}
@@ -259,9 +259,13 @@ func (ca *channelAccessor) checkVoucherValidUnlocked(ctx context.Context, ch add
 		return nil, err
 	}
 
-	// Total required balance must not exceed actor balance
-	if act.Balance.LessThan(totalRedeemed) {
-		return nil, newErrInsufficientFunds(types.BigSub(totalRedeemed, act.Balance))
+	// Total required balance must not exceed the channel funds
+	funds, err := pchState.Funds()
+	if err != nil {
+		return nil, err
+	}
+	if funds.LessThan(totalRedeemed) {
+		return nil, newErrInsufficientFunds(types.BigSub(totalRedeemed, funds))
 	}
 
 	if len(sv.Merges) != 0 {
@@ -317,7 +321,7 @@ func (ca *channelAccessor) checkVoucherSpendable(ctx context.Context, ch address
 }
 
 func (ca *channelAccessor) getPaychRecipient(ctx context.Context, ch address.Address) (address.Address, error) {
-	_, state, err := ca.api.GetPaychState(ctx, ch, nil)
+	state, err := ca.sa.loadChannelState(ctx, ch)
 	if err != nil {
 		return address.Address{}, err
 	}
@@ -461,7 +465,7 @@ func (ca *channelAccessor) listVouchers(ctx context.Context, ch address.Address)
 
 // laneState gets the LaneStates from chain, then applies all vouchers in
 // the data store over the chain state
-func (ca *channelAccessor) laneState(state paych.State, ch address.Address) (map[uint64]paych.LaneState, error) {
+func (ca *channelAccessor) laneState(state channelState, ch address.Address) (map[uint64]paych.LaneState, error) {
 	// TODO: we probably want to call UpdateChannelState with all vouchers to be fully correct
 	//  (but technically dont't need to)
 
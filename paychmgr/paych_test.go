@@ -26,6 +26,10 @@ import (
 )
 
 func TestCheckVoucherValid(t *testing.T) {
+	runActors(t, testCheckVoucherValid)
+}
+
+func testCheckVoucherValid(t *testing.T, ta testActor) {
 	ctx := context.Background()
 
 	fromKeyPrivate, fromKeyPublic := testGenerateKeyPair(t)
@@ -209,7 +213,7 @@ func TestCheckVoucherValid(t *testing.T) {
 
 			// Create a manager
 			store := NewStore(ds_sync.MutexWrap(ds.NewMapDatastore()))
-			mgr, err := newManager(store, mock)
+			mgr, err := newManager(ta.actor, store, mock)
 			require.NoError(t, err)
 
 			// Add channel To address to wallet
@@ -230,10 +234,14 @@ func TestCheckVoucherValid(t *testing.T) {
 }
 
 func TestCreateVoucher(t *testing.T) {
+	runActors(t, testCreateVoucher)
+}
+
+func testCreateVoucher(t *testing.T, ta testActor) {
 	ctx := context.Background()
 
 	// Set up a manager with a single payment channel
-	s := testSetupMgrWithChannel(t)
+	s := testSetupMgrWithChannel(t, ta)
 
 	// Create a voucher in lane 1
 	voucherLane1Amt := big.NewInt(5)
@@ -295,10 +303,14 @@ func TestCreateVoucher(t *testing.T) {
 }
 
 func TestAddVoucherDelta(t *testing.T) {
+	runActors(t, testAddVoucherDelta)
+}
+
+func testAddVoucherDelta(t *testing.T, ta testActor) {
 	ctx := context.Background()
 
 	// Set up a manager with a single payment channel
-	s := testSetupMgrWithChannel(t)
+	s := testSetupMgrWithChannel(t, ta)
 
 	voucherLane := uint64(1)
 
@@ -337,10 +349,14 @@ func TestAddVoucherDelta(t *testing.T) {
 }
 
 func TestAddVoucherNextLane(t *testing.T) {
+	runActors(t, testAddVoucherNextLane)
+}
+
+func testAddVoucherNextLane(t *testing.T, ta testActor) {
 	ctx := context.Background()
 
 	// Set up a manager with a single payment channel
-	s := testSetupMgrWithChannel(t)
+	s := testSetupMgrWithChannel(t, ta)
 
 	minDelta := big.NewInt(0)
 	voucherAmount := big.NewInt(2)
@@ -387,8 +403,12 @@ func TestAddVoucherNextLane(t *testing.T) {
 }
 
 func TestAllocateLane(t *testing.T) {
+	runActors(t, testAllocateLane)
+}
+
+func testAllocateLane(t *testing.T, ta testActor) {
 	// Set up a manager with a single payment channel
-	s := testSetupMgrWithChannel(t)
+	s := testSetupMgrWithChannel(t, ta)
 
 	// First lane should be 0
 	lane, err := s.mgr.AllocateLane(s.ch)
@@ -402,6 +422,10 @@ func TestAllocateLane(t *testing.T) {
 }
 
 func TestAllocateLaneWithExistingLaneState(t *testing.T) {
+	runActors(t, testAllocateLaneWithExistingLaneState)
+}
+
+func testAllocateLaneWithExistingLaneState(t *testing.T, ta testActor) {
 	ctx := context.Background()
 
 	fromKeyPrivate, fromKeyPublic := testGenerateKeyPair(t)
@@ -431,7 +455,7 @@ func TestAllocateLaneWithExistingLaneState(t *testing.T) {
 
 	mock.setPaychState(ch, act, paychmock.NewMockPayChState(fromAcct, toAcct, abi.ChainEpoch(0), make(map[uint64]paych.LaneState)))
 
-	mgr, err := newManager(store, mock)
+	mgr, err := newManager(ta.actor, store, mock)
 	require.NoError(t, err)
 
 	// Create a voucher on lane 2
@@ -451,6 +475,10 @@ func TestAllocateLaneWithExistingLaneState(t *testing.T) {
 }
 
 func TestAddVoucherInboundWalletKey(t *testing.T) {
+	runActors(t, testAddVoucherInboundWalletKey)
+}
+
+func testAddVoucherInboundWalletKey(t *testing.T, ta testActor) {
 	ctx := context.Background()
 
 	fromKeyPrivate, fromKeyPublic := testGenerateKeyPair(t)
@@ -478,7 +506,7 @@ func TestAddVoucherInboundWalletKey(t *testing.T) {
 
 	// Create a manager
 	store := NewStore(ds_sync.MutexWrap(ds.NewMapDatastore()))
-	mgr, err := newManager(store, mock)
+	mgr, err := newManager(ta.actor, store, mock)
 	require.NoError(t, err)
 
 	// Add a voucher
@@ -506,10 +534,14 @@ func TestAddVoucherInboundWalletKey(t *testing.T) {
 }
 
 func TestBestSpendable(t *testing.T) {
+	runActors(t, testBestSpendable)
+}
+
+func testBestSpendable(t *testing.T, ta testActor) {
 	ctx := context.Background()
 
 	// Set up a manager with a single payment channel
-	s := testSetupMgrWithChannel(t)
+	s := testSetupMgrWithChannel(t, ta)
 
 	// Add vouchers to lane 1 with amounts: [1, 2, 3]
 	voucherLane := uint64(1)
@@ -586,10 +618,14 @@ func TestBestSpendable(t *testing.T) {
 }
 
 func TestCheckSpendable(t *testing.T) {
+	runActors(t, testCheckSpendable)
+}
+
+func testCheckSpendable(t *testing.T, ta testActor) {
 	ctx := context.Background()
 
 	// Set up a manager with a single payment channel
-	s := testSetupMgrWithChannel(t)
+	s := testSetupMgrWithChannel(t, ta)
 
 	// Create voucher with Extra
 	voucherLane := uint64(1)
@@ -650,10 +686,14 @@ func TestCheckSpendable(t *testing.T) {
 }
 
 func TestSubmitVoucher(t *testing.T) {
+	runActors(t, testSubmitVoucher)
+}
+
+func testSubmitVoucher(t *testing.T, ta testActor) {
 	ctx := context.Background()
 
 	// Set up a manager with a single payment channel
-	s := testSetupMgrWithChannel(t)
+	s := testSetupMgrWithChannel(t, ta)
 
 	// Create voucher with Extra
 	voucherLane := uint64(1)
@@ -715,7 +755,7 @@ type testScaffold struct {
 	fromKeyPrivate []byte
 }
 
-func testSetupMgrWithChannel(t *testing.T) *testScaffold {
+func testSetupMgrWithChannel(t *testing.T, ta testActor) *testScaffold {
 	fromKeyPrivate, fromKeyPublic := testGenerateKeyPair(t)
 
 	ch := tutils.NewIDAddr(t, 100)
@@ -739,7 +779,7 @@ func testSetupMgrWithChannel(t *testing.T) *testScaffold {
 	mock.setPaychState(ch, act, paychmock.NewMockPayChState(fromAcct, toAcct, abi.ChainEpoch(0), make(map[uint64]paych.LaneState)))
 
 	store := NewStore(ds_sync.MutexWrap(ds.NewMapDatastore()))
-	mgr, err := newManager(store, mock)
+	mgr, err := newManager(ta.actor, store, mock)
 	require.NoError(t, err)
 
 	// Create the channel in the manager's store
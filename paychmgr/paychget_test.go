@@ -42,6 +42,10 @@ func testChannelResponse(t *testing.T, ch address.Address) types.MessageReceipt
 // TestPaychGetCreateChannelMsg tests that GetPaych sends a message to create
 // a new channel with the correct funds
 func TestPaychGetCreateChannelMsg(t *testing.T) {
+	runActors(t, testPaychGetCreateChannelMsg)
+}
+
+func testPaychGetCreateChannelMsg(t *testing.T, ta testActor) {
 	ctx := context.Background()
 	store := NewStore(ds_sync.MutexWrap(ds.NewMapDatastore()))
 
@@ -51,7 +55,7 @@ func TestPaychGetCreateChannelMsg(t *testing.T) {
 	mock := newMockManagerAPI()
 	defer mock.close()
 
-	mgr, err := newManager(store, mock)
+	mgr, err := newManager(ta.actor, store, mock)
 	require.NoError(t, err)
 
 	amt := big.NewInt(10)
@@ -62,12 +66,16 @@ func TestPaychGetCreateChannelMsg(t *testing.T) {
 	pushedMsg := mock.pushedMessages(mcid)
 	require.Equal(t, from, pushedMsg.Message.From)
 	require.Equal(t, epikinit.Address, pushedMsg.Message.To)
-	require.Equal(t, amt, pushedMsg.Message.Value)
+	require.Equal(t, amt, ta.msgAmount(t, &pushedMsg.Message))
 }
 
 // TestPaychGetCreateChannelThenAddFunds tests creating a channel and then
 // adding funds to it
 func TestPaychGetCreateChannelThenAddFunds(t *testing.T) {
+	runActors(t, testPaychGetCreateChannelThenAddFunds)
+}
+
+func testPaychGetCreateChannelThenAddFunds(t *testing.T, ta testActor) {
 	ctx := context.Background()
 	store := NewStore(ds_sync.MutexWrap(ds.NewMapDatastore()))
 
@@ -78,7 +86,7 @@ func TestPaychGetCreateChannelThenAddFunds(t *testing.T) {
 	mock := newMockManagerAPI()
 	defer mock.close()
 
-	mgr, err := newManager(store, mock)
+	mgr, err := newManager(ta.actor, store, mock)
 	require.NoError(t, err)
 
 	// Send create message for a channel with value 10
@@ -158,6 +166,10 @@ func TestPaychGetCreateChannelThenAddFunds(t *testing.T) {
 // operation is queued up behind a create channel operation, and the create
 // channel fails, then the waiting operation can succeed.
 func TestPaychGetCreateChannelWithErrorThenCreateAgain(t *testing.T) {
+	runActors(t, testPaychGetCreateChannelWithErrorThenCreateAgain)
+}
+
+func testPaychGetCreateChannelWithErrorThenCreateAgain(t *testing.T, ta testActor) {
 	ctx := context.Background()
 	store := NewStore(ds_sync.MutexWrap(ds.NewMapDatastore()))
 
@@ -167,7 +179,7 @@ func TestPaychGetCreateChannelWithErrorThenCreateAgain(t *testing.T) {
 	mock := newMockManagerAPI()
 	defer mock.close()
 
-	mgr, err := newManager(store, mock)
+	mgr, err := newManager(ta.actor, store, mock)
 	require.NoError(t, err)
 
 	// Send create message for a channel
@@ -222,6 +234,10 @@ func TestPaychGetCreateChannelWithErrorThenCreateAgain(t *testing.T) {
 // TestPaychGetRecoverAfterError tests that after a create channel fails, the
 // next attempt to create channel can succeed.
 func TestPaychGetRecoverAfterError(t *testing.T) {
+	runActors(t, testPaychGetRecoverAfterError)
+}
+
+func testPaychGetRecoverAfterError(t *testing.T, ta testActor) {
 	ctx := context.Background()
 	store := NewStore(ds_sync.MutexWrap(ds.NewMapDatastore()))
 
@@ -232,7 +248,7 @@ func TestPaychGetRecoverAfterError(t *testing.T) {
 	mock := newMockManagerAPI()
 	defer mock.close()
 
-	mgr, err := newManager(store, mock)
+	mgr, err := newManager(ta.actor, store, mock)
 	require.NoError(t, err)
 
 	// Send create message for a channel
@@ -274,6 +290,10 @@ func TestPaychGetRecoverAfterError(t *testing.T) {
 // TestPaychGetRecoverAfterAddFundsError tests that after an add funds fails, the
 // next attempt to add funds can succeed.
 func TestPaychGetRecoverAfterAddFundsError(t *testing.T) {
+	runActors(t, testPaychGetRecoverAfterAddFundsError)
+}
+
+func testPaychGetRecoverAfterAddFundsError(t *testing.T, ta testActor) {
 	ctx := context.Background()
 	store := NewStore(ds_sync.MutexWrap(ds.NewMapDatastore()))
 
@@ -284,7 +304,7 @@ func TestPaychGetRecoverAfterAddFundsError(t *testing.T) {
 	mock := newMockManagerAPI()
 	defer mock.close()
 
-	mgr, err := newManager(store, mock)
+	mgr, err := newManager(ta.actor, store, mock)
 	require.NoError(t, err)
 
 	// Send create message for a channel
@@ -356,6 +376,10 @@ func TestPaychGetRecoverAfterAddFundsError(t *testing.T) {
 // right after the create channel message is sent, the channel will be
 // created when the system restarts.
 func TestPaychGetRestartAfterCreateChannelMsg(t *testing.T) {
+	runActors(t, testPaychGetRestartAfterCreateChannelMsg)
+}
+
+func testPaychGetRestartAfterCreateChannelMsg(t *testing.T, ta testActor) {
 	ctx := context.Background()
 	store := NewStore(ds_sync.MutexWrap(ds.NewMapDatastore()))
 
@@ -365,7 +389,7 @@ func TestPaychGetRestartAfterCreateChannelMsg(t *testing.T) {
 
 	mock := newMockManagerAPI()
 
-	mgr, err := newManager(store, mock)
+	mgr, err := newManager(ta.actor, store, mock)
 	require.NoError(t, err)
 
 	// Send create message for a channel with value 10
@@ -380,7 +404,7 @@ func TestPaychGetRestartAfterCreateChannelMsg(t *testing.T) {
 	mock2 := newMockManagerAPI()
 	defer mock2.close()
 
-	mgr2, err := newManager(store, mock2)
+	mgr2, err := newManager(ta.actor, store, mock2)
 	require.NoError(t, err)
 
 	// Should have no channels yet (message sent but channel not created)
@@ -435,6 +459,10 @@ func TestPaychGetRestartAfterCreateChannelMsg(t *testing.T) {
 // right after the add funds message is sent, the add funds will be
 // processed when the system restarts.
 func TestPaychGetRestartAfterAddFundsMsg(t *testing.T) {
+	runActors(t, testPaychGetRestartAfterAddFundsMsg)
+}
+
+func testPaychGetRestartAfterAddFundsMsg(t *testing.T, ta testActor) {
 	ctx := context.Background()
 	store := NewStore(ds_sync.MutexWrap(ds.NewMapDatastore()))
 
@@ -444,7 +472,7 @@ func TestPaychGetRestartAfterAddFundsMsg(t *testing.T) {
 
 	mock := newMockManagerAPI()
 
-	mgr, err := newManager(store, mock)
+	mgr, err := newManager(ta.actor, store, mock)
 	require.NoError(t, err)
 
 	// Send create message for a channel
@@ -468,7 +496,7 @@ func TestPaychGetRestartAfterAddFundsMsg(t *testing.T) {
 	mock2 := newMockManagerAPI()
 	defer mock2.close()
 
-	mgr2, err := newManager(store, mock2)
+	mgr2, err := newManager(ta.actor, store, mock2)
 	require.NoError(t, err)
 
 	// Send success add funds response
@@ -498,6 +526,10 @@ func TestPaychGetRestartAfterAddFundsMsg(t *testing.T) {
 // TestPaychGetWait tests that GetPaychWaitReady correctly waits for the
 // channel to be created or funds to be added
 func TestPaychGetWait(t *testing.T) {
+	runActors(t, testPaychGetWait)
+}
+
+func testPaychGetWait(t *testing.T, ta testActor) {
 	ctx := context.Background()
 	store := NewStore(ds_sync.MutexWrap(ds.NewMapDatastore()))
 
@@ -507,7 +539,7 @@ func TestPaychGetWait(t *testing.T) {
 	mock := newMockManagerAPI()
 	defer mock.close()
 
-	mgr, err := newManager(store, mock)
+	mgr, err := newManager(ta.actor, store, mock)
 	require.NoError(t, err)
 
 	// 1. Get
@@ -555,6 +587,10 @@ func TestPaychGetWait(t *testing.T) {
 
 // TestPaychGetWaitErr tests that GetPaychWaitReady correctly handles errors
 func TestPaychGetWaitErr(t *testing.T) {
+	runActors(t, testPaychGetWaitErr)
+}
+
+func testPaychGetWaitErr(t *testing.T, ta testActor) {
 	ctx := context.Background()
 	store := NewStore(ds_sync.MutexWrap(ds.NewMapDatastore()))
 
@@ -564,7 +600,7 @@ func TestPaychGetWaitErr(t *testing.T) {
 	mock := newMockManagerAPI()
 	defer mock.close()
 
-	mgr, err := newManager(store, mock)
+	mgr, err := newManager(ta.actor, store, mock)
 	require.NoError(t, err)
 
 	// 1. Create channel
@@ -602,6 +638,10 @@ func TestPaychGetWaitErr(t *testing.T) {
 // TestPaychGetWaitCtx tests that GetPaychWaitReady returns early if the context
 // is cancelled
 func TestPaychGetWaitCtx(t *testing.T) {
+	runActors(t, testPaychGetWaitCtx)
+}
+
+func testPaychGetWaitCtx(t *testing.T, ta testActor) {
 	ctx, cancel := context.WithCancel(context.Background())
 	store := NewStore(ds_sync.MutexWrap(ds.NewMapDatastore()))
 
@@ -611,7 +651,7 @@ func TestPaychGetWaitCtx(t *testing.T) {
 	mock := newMockManagerAPI()
 	defer mock.close()
 
-	mgr, err := newManager(store, mock)
+	mgr, err := newManager(ta.actor, store, mock)
 	require.NoError(t, err)
 
 	amt := big.NewInt(10)
@@ -631,6 +671,10 @@ func TestPaychGetWaitCtx(t *testing.T) {
 // progress and two add funds are queued up behind it, the two add funds
 // will be merged
 func TestPaychGetMergeAddFunds(t *testing.T) {
+	runActors(t, testPaychGetMergeAddFunds)
+}
+
+func testPaychGetMergeAddFunds(t *testing.T, ta testActor) {
 	ctx := context.Background()
 	store := NewStore(ds_sync.MutexWrap(ds.NewMapDatastore()))
 
@@ -641,7 +685,7 @@ func TestPaychGetMergeAddFunds(t *testing.T) {
 	mock := newMockManagerAPI()
 	defer mock.close()
 
-	mgr, err := newManager(store, mock)
+	mgr, err := newManager(ta.actor, store, mock)
 	require.NoError(t, err)
 
 	// Send create message for a channel with value 10
@@ -716,19 +760,23 @@ func TestPaychGetMergeAddFunds(t *testing.T) {
 	createMsg := mock.pushedMessages(createMsgCid)
 	require.Equal(t, from, createMsg.Message.From)
 	require.Equal(t, epikinit.Address, createMsg.Message.To)
-	require.Equal(t, createAmt, createMsg.Message.Value)
+	require.Equal(t, createAmt, ta.msgAmount(t, &createMsg.Message))
 
 	// Check merged add funds amount is the sum of the individual
 	// amounts
 	addFundsMsg := mock.pushedMessages(addFundsMcid1)
 	require.Equal(t, from, addFundsMsg.Message.From)
 	require.Equal(t, ch, addFundsMsg.Message.To)
-	require.Equal(t, types.BigAdd(addFundsAmt1, addFundsAmt2), addFundsMsg.Message.Value)
+	require.Equal(t, types.BigAdd(addFundsAmt1, addFundsAmt2), ta.msgAmount(t, &addFundsMsg.Message))
 }
 
 // TestPaychGetMergeAddFundsCtxCancelOne tests that when a queued add funds
 // request is cancelled, its amount is removed from the total merged add funds
 func TestPaychGetMergeAddFundsCtxCancelOne(t *testing.T) {
+	runActors(t, testPaychGetMergeAddFundsCtxCancelOne)
+}
+
+func testPaychGetMergeAddFundsCtxCancelOne(t *testing.T, ta testActor) {
 	ctx := context.Background()
 	store := NewStore(ds_sync.MutexWrap(ds.NewMapDatastore()))
 
@@ -739,7 +787,7 @@ func TestPaychGetMergeAddFundsCtxCancelOne(t *testing.T) {
 	mock := newMockManagerAPI()
 	defer mock.close()
 
-	mgr, err := newManager(store, mock)
+	mgr, err := newManager(ta.actor, store, mock)
 	require.NoError(t, err)
 
 	// Send create message for a channel with value 10
@@ -813,19 +861,23 @@ func TestPaychGetMergeAddFundsCtxCancelOne(t *testing.T) {
 	createMsg := mock.pushedMessages(createMsgCid)
 	require.Equal(t, from, createMsg.Message.From)
 	require.Equal(t, epikinit.Address, createMsg.Message.To)
-	require.Equal(t, createAmt, createMsg.Message.Value)
+	require.Equal(t, createAmt, ta.msgAmount(t, &createMsg.Message))
 
 	// Check merged add funds amount only includes the second add funds amount
 	// (because first was cancelled)
 	addFundsMsg := mock.pushedMessages(addFundsMcid2)
 	require.Equal(t, from, addFundsMsg.Message.From)
 	require.Equal(t, ch, addFundsMsg.Message.To)
-	require.Equal(t, addFundsAmt2, addFundsMsg.Message.Value)
+	require.Equal(t, addFundsAmt2, ta.msgAmount(t, &addFundsMsg.Message))
 }
 
 // TestPaychGetMergeAddFundsCtxCancelAll tests that when all queued add funds
 // requests are cancelled, no add funds message is sent
 func TestPaychGetMergeAddFundsCtxCancelAll(t *testing.T) {
+	runActors(t, testPaychGetMergeAddFundsCtxCancelAll)
+}
+
+func testPaychGetMergeAddFundsCtxCancelAll(t *testing.T, ta testActor) {
 	ctx := context.Background()
 	store := NewStore(ds_sync.MutexWrap(ds.NewMapDatastore()))
 
@@ -836,7 +888,7 @@ func TestPaychGetMergeAddFundsCtxCancelAll(t *testing.T) {
 	mock := newMockManagerAPI()
 	defer mock.close()
 
-	mgr, err := newManager(store, mock)
+	mgr, err := newManager(ta.actor, store, mock)
 	require.NoError(t, err)
 
 	// Send create message for a channel with value 10
@@ -894,12 +946,16 @@ func TestPaychGetMergeAddFundsCtxCancelAll(t *testing.T) {
 	createMsg := mock.pushedMessages(createMsgCid)
 	require.Equal(t, from, createMsg.Message.From)
 	require.Equal(t, epikinit.Address, createMsg.Message.To)
-	require.Equal(t, createAmt, createMsg.Message.Value)
+	require.Equal(t, createAmt, ta.msgAmount(t, &createMsg.Message))
 }
 
 // TestPaychAvailableFunds tests that PaychAvailableFunds returns the correct
 // channel state
 func TestPaychAvailableFunds(t *testing.T) {
+	runActors(t, testPaychAvailableFunds)
+}
+
+func testPaychAvailableFunds(t *testing.T, ta testActor) {
 	ctx := context.Background()
 	store := NewStore(ds_sync.MutexWrap(ds.NewMapDatastore()))
 
@@ -913,7 +969,7 @@ func TestPaychAvailableFunds(t *testing.T) {
 	mock := newMockManagerAPI()
 	defer mock.close()
 
-	mgr, err := newManager(store, mock)
+	mgr, err := newManager(ta.actor, store, mock)
 	require.NoError(t, err)
 
 	// No channel created yet so available funds should be all zeroes
@@ -23,6 +23,10 @@ import (
 // insufficient funds, then adding funds to the channel, then adding the
 // voucher again
 func TestPaychAddVoucherAfterAddFunds(t *testing.T) {
+	runActors(t, testPaychAddVoucherAfterAddFunds)
+}
+
+func testPaychAddVoucherAfterAddFunds(t *testing.T, ta testActor) {
 	ctx := context.Background()
 	store := NewStore(ds_sync.MutexWrap(ds.NewMapDatastore()))
 
@@ -41,7 +45,7 @@ func TestPaychAddVoucherAfterAddFunds(t *testing.T) {
 	mock.setAccountAddress(toAcct, to)
 	mock.addSigningKey(fromKeyPrivate)
 
-	mgr, err := newManager(store, mock)
+	mgr, err := newManager(ta.actor, store, mock)
 	require.NoError(t, err)
 
 	// Send create message for a channel with value 10
@@ -14,6 +14,10 @@ import (
 )
 
 func TestPaychSettle(t *testing.T) {
+	runActors(t, testPaychSettle)
+}
+
+func testPaychSettle(t *testing.T, ta testActor) {
 	ctx := context.Background()
 	store := NewStore(ds_sync.MutexWrap(ds.NewMapDatastore()))
 
@@ -25,7 +29,7 @@ func TestPaychSettle(t *testing.T) {
 	mock := newMockManagerAPI()
 	defer mock.close()
 
-	mgr, err := newManager(store, mock)
+	mgr, err := newManager(ta.actor, store, mock)
 	require.NoError(t, err)
 
 	amt := big.NewInt(10)
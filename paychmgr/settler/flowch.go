@@ -0,0 +1,97 @@
+package settler
+
+import (
+	"context"
+
+	"go.uber.org/fx"
+
+	"github.com/ipfs/go-cid"
+
+	"github.com/filecoin-project/go-address"
+
+	"github.com/EpiK-Protocol/go-epik/api"
+	"github.com/EpiK-Protocol/go-epik/build"
+	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/flowch"
+	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/paych"
+	"github.com/EpiK-Protocol/go-epik/chain/events"
+	flowapi "github.com/EpiK-Protocol/go-epik/node/impl/flowch"
+	"github.com/EpiK-Protocol/go-epik/node/impl/full"
+	"github.com/EpiK-Protocol/go-epik/node/modules/helpers"
+	"github.com/EpiK-Protocol/go-epik/paychmgr"
+)
+
+// FlowAPI are the dependencies need to run the flow channel settler
+type FlowAPI struct {
+	fx.In
+
+	full.ChainAPI
+	full.StateAPI
+	flowapi.FlowchAPI
+}
+
+// SettleFlowChannels checks the chain for events related to flow channels settling and
+// submits any vouchers for inbound channels tracked for this node
+func SettleFlowChannels(mctx helpers.MetricsCtx, lc fx.Lifecycle, api FlowAPI) error {
+	ctx := helpers.LifecycleCtx(mctx, lc)
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			pcs := newPaymentChannelSettler(ctx, &flowchSettlerAPI{&api}, flowch.Methods.Settle)
+			ev := events.NewEvents(ctx, &api)
+			return ev.Called(pcs.check, pcs.messageHandler, pcs.revertHandler, int(build.MessageConfidence+1), events.NoTimeout, pcs.matcher)
+		},
+	})
+	return nil
+}
+
+// flowchSettlerAPI adapts the flow channel API to the settler, whose
+// vouchers are the payment channel vouchers of the same encoding.
+type flowchSettlerAPI struct {
+	*FlowAPI
+}
+
+func (a *flowchSettlerAPI) PaychList(ctx context.Context) ([]address.Address, error) {
+	return a.FlowchList(ctx)
+}
+
+func (a *flowchSettlerAPI) PaychStatus(ctx context.Context, ch address.Address) (*api.PaychStatus, error) {
+	st, err := a.FlowchStatus(ctx, ch)
+	if err != nil {
+		return nil, err
+	}
+	return &api.PaychStatus{
+		ControlAddr: st.ControlAddr,
+		Direction:   st.Direction,
+	}, nil
+}
+
+func (a *flowchSettlerAPI) PaychVoucherCheckSpendable(ctx context.Context, ch address.Address, sv *paych.SignedVoucher, secret []byte, proof []byte) (bool, error) {
+	fsv, err := paychmgr.ToFlowchVoucher(sv)
+	if err != nil {
+		return false, err
+	}
+	return a.FlowchVoucherCheckSpendable(ctx, ch, fsv, secret, proof)
+}
+
+func (a *flowchSettlerAPI) PaychVoucherList(ctx context.Context, ch address.Address) ([]*paych.SignedVoucher, error) {
+	fsvs, err := a.FlowchVoucherList(ctx, ch)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]*paych.SignedVoucher, len(fsvs))
+	for i, fsv := range fsvs {
+		out[i], err = paychmgr.FromFlowchVoucher(fsv)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+func (a *flowchSettlerAPI) PaychVoucherSubmit(ctx context.Context, ch address.Address, sv *paych.SignedVoucher, secret []byte, proof []byte) (cid.Cid, error) {
+	fsv, err := paychmgr.ToFlowchVoucher(sv)
+	if err != nil {
+		return cid.Undef, err
+	}
+	return a.FlowchVoucherSubmit(ctx, ch, fsv, secret, proof)
+}
@@ -47,6 +47,8 @@ type settlerAPI interface {
 type paymentChannelSettler struct {
 	ctx context.Context
 	api settlerAPI
+	// settleMethod is the Settle method of the channel actor
+	settleMethod abi.MethodNum
 }
 
 // SettlePaymentChannels checks the chain for events related to payment channels settling and
@@ -55,7 +57,7 @@ func SettlePaymentChannels(mctx helpers.MetricsCtx, lc fx.Lifecycle, api API) er
 	ctx := helpers.LifecycleCtx(mctx, lc)
 	lc.Append(fx.Hook{
 		OnStart: func(context.Context) error {
-			pcs := newPaymentChannelSettler(ctx, &api)
+			pcs := newPaymentChannelSettler(ctx, &api, paych.Methods.Settle)
 			ev := events.NewEvents(ctx, &api)
 			return ev.Called(pcs.check, pcs.messageHandler, pcs.revertHandler, int(build.MessageConfidence+1), events.NoTimeout, pcs.matcher)
 		},
@@ -63,10 +65,11 @@ func SettlePaymentChannels(mctx helpers.MetricsCtx, lc fx.Lifecycle, api API) er
 	return nil
 }
 
-func newPaymentChannelSettler(ctx context.Context, api settlerAPI) *paymentChannelSettler {
+func newPaymentChannelSettler(ctx context.Context, api settlerAPI, settleMethod abi.MethodNum) *paymentChannelSettler {
 	return &paymentChannelSettler{
-		ctx: ctx,
-		api: api,
+		ctx:          ctx,
+		api:          api,
+		settleMethod: settleMethod,
 	}
 }
 
@@ -112,7 +115,7 @@ func (pcs *paymentChannelSettler) revertHandler(ctx context.Context, ts *types.T
 
 func (pcs *paymentChannelSettler) matcher(msg *types.Message, ts *types.TipSet) (matched bool, err error) {
 	// Check if this is a settle payment channel message
-	if msg.Method != paych.Methods.Settle {
+	if msg.Method != pcs.settleMethod {
 		return false, nil
 	}
 	// Check if this payment channel is of concern to this node (i.e. tracked in payment channel store),
@@ -297,7 +297,7 @@ func (ca *channelAccessor) currentAvailableFunds(channelID string, queuedAmt typ
 	totalRedeemed := types.NewInt(0)
 	if channelInfo.Channel != nil {
 		ch := *channelInfo.Channel
-		_, pchState, err := ca.sa.loadPaychActorState(ca.chctx, ch)
+		pchState, err := ca.sa.loadChannelState(ca.chctx, ch)
 		if err != nil {
 			return nil, err
 		}
@@ -461,11 +461,13 @@ func (ca *channelAccessor) waitPaychCreateMsg(channelID string, mcid cid.Cid) er
 
 // addFunds sends a message to add funds to the channel and returns the message cid
 func (ca *channelAccessor) addFunds(ctx context.Context, channelInfo *ChannelInfo, amt types.BigInt) (*cid.Cid, error) {
-	msg := &types.Message{
-		To:     *channelInfo.Channel,
-		From:   channelInfo.Control,
-		Value:  amt,
-		Method: 0,
+	mb, err := ca.messageBuilder(ctx, channelInfo.Control)
+	if err != nil {
+		return nil, err
+	}
+	msg, err := mb.AddFunds(*channelInfo.Channel, amt)
+	if err != nil {
+		return nil, err
 	}
 
 	smsg, err := ca.api.MpoolPushMessage(ctx, msg, nil)
@@ -6,19 +6,19 @@ import (
 	"github.com/filecoin-project/go-address"
 
 	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/paych"
-	"github.com/EpiK-Protocol/go-epik/chain/types"
 )
 
 type stateAccessor struct {
-	sm stateManagerAPI
+	sm    stateManagerAPI
+	actor channelActor
 }
 
-func (ca *stateAccessor) loadPaychActorState(ctx context.Context, ch address.Address) (*types.Actor, paych.State, error) {
-	return ca.sm.GetPaychState(ctx, ch, nil)
+func (ca *stateAccessor) loadChannelState(ctx context.Context, ch address.Address) (channelState, error) {
+	return ca.actor.loadState(ctx, ca.sm, ch)
 }
 
 func (ca *stateAccessor) loadStateChannelInfo(ctx context.Context, ch address.Address, dir uint64) (*ChannelInfo, error) {
-	_, st, err := ca.loadPaychActorState(ctx, ch)
+	st, err := ca.loadChannelState(ctx, ch)
 	if err != nil {
 		return nil, err
 	}
@@ -63,7 +63,7 @@ func (ca *stateAccessor) loadStateChannelInfo(ctx context.Context, ch address.Ad
 	return ci, nil
 }
 
-func (ca *stateAccessor) nextLaneFromState(ctx context.Context, st paych.State) (uint64, error) {
+func (ca *stateAccessor) nextLaneFromState(ctx context.Context, st channelState) (uint64, error) {
 	laneCount, err := st.LaneCount()
 	if err != nil {
 		return 0, err
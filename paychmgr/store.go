@@ -31,9 +31,13 @@ type Store struct {
 }
 
 func NewStore(ds dtypes.MetadataDS) *Store {
-	ds = namespace.Wrap(ds, datastore.NewKey("/paych/"))
+	return newStore(ds, "/paych/")
+}
+
+// newStore creates a store keeping the channels under the namespace ns of ds
+func newStore(ds dtypes.MetadataDS, ns string) *Store {
 	return &Store{
-		ds: ds,
+		ds: namespace.Wrap(ds, datastore.NewKey(ns)),
 	}
 }
 
@@ -0,0 +1,68 @@
+package paychmgr
+
+import (
+	"encoding/json"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/ipfs/go-datastore"
+	dsq "github.com/ipfs/go-datastore/query"
+
+	"github.com/EpiK-Protocol/go-epik/api"
+)
+
+const dsKeyAutoChannel = "AutoChannel"
+
+// The datastore key used to identify the automatic settlement state of a channel
+func dskeyForAutoChannel(ch address.Address) datastore.Key {
+	return datastore.KeyWithNamespaces([]string{dsKeyAutoChannel, ch.String()})
+}
+
+// AutoChannel returns the automatic settlement state of the channel, nil if
+// it isn't tracked yet
+func (ps *Store) AutoChannel(ch address.Address) (*api.FlowchAutoChannel, error) {
+	b, err := ps.ds.Get(dskeyForAutoChannel(ch))
+	if err == datastore.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var st api.FlowchAutoChannel
+	if err := json.Unmarshal(b, &st); err != nil {
+		return nil, err
+	}
+	return &st, nil
+}
+
+// AutoChannels returns the automatic settlement state of all tracked channels
+func (ps *Store) AutoChannels() ([]*api.FlowchAutoChannel, error) {
+	res, err := ps.ds.Query(dsq.Query{Prefix: dsKeyAutoChannel})
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close() //nolint:errcheck
+
+	var out []*api.FlowchAutoChannel
+	for r := range res.Next() {
+		if r.Error != nil {
+			return nil, r.Error
+		}
+
+		var st api.FlowchAutoChannel
+		if err := json.Unmarshal(r.Value, &st); err != nil {
+			return nil, err
+		}
+		out = append(out, &st)
+	}
+	return out, nil
+}
+
+// PutAutoChannel stores the automatic settlement state of a channel
+func (ps *Store) PutAutoChannel(st *api.FlowchAutoChannel) error {
+	b, err := json.Marshal(st)
+	if err != nil {
+		return err
+	}
+	return ps.ds.Put(dskeyForAutoChannel(st.Channel), b)
+}
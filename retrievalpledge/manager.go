@@ -0,0 +1,317 @@
+package retrievalpledge
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/ipfs/go-cid"
+	logging "github.com/ipfs/go-log/v2"
+	"golang.org/x/xerrors"
+
+	"github.com/EpiK-Protocol/go-epik/api"
+	"github.com/EpiK-Protocol/go-epik/build"
+	"github.com/EpiK-Protocol/go-epik/chain/actors"
+	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin"
+	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/retrieval"
+	"github.com/EpiK-Protocol/go-epik/chain/types"
+	"github.com/EpiK-Protocol/go-epik/node/config"
+)
+
+var log = logging.Logger("retrievalpledge")
+
+// spendHistoryDays is the number of days the daily spend is averaged over.
+const spendHistoryDays = 7
+
+// ManagerAPI is the node API needed to manage the retrieval pledges.
+type ManagerAPI interface {
+	ChainHead(context.Context) (*types.TipSet, error)
+	StateLookupID(context.Context, address.Address, types.TipSetKey) (address.Address, error)
+	StateRetrievalPledge(context.Context, address.Address, types.TipSetKey) (*api.RetrievalState, error)
+
+	MpoolPushMessage(context.Context, *types.Message, *api.MessageSendSpec) (*types.SignedMessage, error)
+	StateWaitMsg(ctx context.Context, cid cid.Cid, confidence uint64) (*api.MsgLookup, error)
+}
+
+// Manager tracks the retrieval pledge and the daily spend of the configured
+// wallets. It tops the pledges up toward a runway of days of spend, binds the
+// miners the wallets retrieve from, and rejects retrievals the pledge doesn't
+// cover.
+type Manager struct {
+	api ManagerAPI
+	cfg config.RetrievalPledge
+
+	wallets     map[address.Address]struct{}
+	minTopUp    abi.TokenAmount
+	maxTopUp    abi.TokenAmount
+	bytesPerEPK uint64
+
+	lk    sync.Mutex
+	spend map[address.Address]*spendHistory
+
+	// bindLk serializes the binding of miners, so that concurrent retrievals
+	// don't bind the same miner twice
+	bindLk sync.Mutex
+}
+
+func NewManager(api ManagerAPI, cfg config.RetrievalPledge) (*Manager, error) {
+	m := &Manager{
+		api:         api,
+		cfg:         cfg,
+		wallets:     map[address.Address]struct{}{},
+		minTopUp:    big.Zero(),
+		maxTopUp:    big.Zero(),
+		bytesPerEPK: cfg.BytesPerEPK,
+		spend:       map[address.Address]*spendHistory{},
+	}
+
+	for _, s := range cfg.Wallets {
+		w, err := address.NewFromString(s)
+		if err != nil {
+			return nil, xerrors.Errorf("parsing wallet address %q: %w", s, err)
+		}
+		m.wallets[w] = struct{}{}
+	}
+
+	if cfg.MinTopUp != "" {
+		v, err := types.ParseEPK(cfg.MinTopUp)
+		if err != nil {
+			return nil, xerrors.Errorf("parsing min top-up: %w", err)
+		}
+		m.minTopUp = big.Int(v)
+	}
+	if cfg.MaxTopUp != "" {
+		v, err := types.ParseEPK(cfg.MaxTopUp)
+		if err != nil {
+			return nil, xerrors.Errorf("parsing max top-up: %w", err)
+		}
+		m.maxTopUp = big.Int(v)
+	}
+	return m, nil
+}
+
+// Run checks the pledges every configured interval until ctx is done.
+func (m *Manager) Run(ctx context.Context) {
+	interval := time.Duration(m.cfg.Interval)
+	if interval <= 0 {
+		interval = 10 * time.Minute
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		for w := range m.wallets {
+			if err := m.process(ctx, w); err != nil {
+				log.Errorf("managing retrieval pledge of %s: %s", w, err)
+			}
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (m *Manager) process(ctx context.Context, wallet address.Address) error {
+	head, err := m.api.ChainHead(ctx)
+	if err != nil {
+		return err
+	}
+
+	st, err := m.api.StateRetrievalPledge(ctx, wallet, head.Key())
+	if err != nil {
+		return xerrors.Errorf("getting retrieval pledge: %w", err)
+	}
+
+	daily := m.recordSpend(wallet, head.Height(), st.DayExpend)
+	amount := PlanTopUp(st.Balance, daily, m.cfg.RunwayDays, m.minTopUp, m.maxTopUp)
+	if amount.IsZero() {
+		return nil
+	}
+
+	log.Infow("topping up retrieval pledge", "wallet", wallet, "balance", types.EPK(st.Balance),
+		"dailySpend", types.EPK(daily), "amount", types.EPK(amount))
+
+	params, err := actors.SerializeParams(&retrieval.PledgeParams{
+		Address: wallet,
+	})
+	if err != nil {
+		return xerrors.Errorf("serializing params: %w", err)
+	}
+	return m.push(ctx, &types.Message{
+		To:     retrieval.Address,
+		From:   wallet,
+		Value:  amount,
+		Method: retrieval.Methods.Pledge,
+		Params: params,
+	})
+}
+
+// CheckRetrieval is called before retrieving size bytes from miner with the
+// pledge of wallet. It rejects the retrieval if the pledge doesn't cover it,
+// and binds the miner to the pledge if needed. Retrievals of unmanaged
+// wallets are not checked.
+func (m *Manager) CheckRetrieval(ctx context.Context, wallet, miner address.Address, size uint64) error {
+	if _, ok := m.wallets[wallet]; !ok {
+		return nil
+	}
+
+	head, err := m.api.ChainHead(ctx)
+	if err != nil {
+		return err
+	}
+
+	st, err := m.api.StateRetrievalPledge(ctx, wallet, head.Key())
+	if err != nil {
+		return xerrors.Errorf("getting retrieval pledge of %s: %w", wallet, err)
+	}
+	m.recordSpend(wallet, head.Height(), st.DayExpend)
+
+	if m.bytesPerEPK > 0 {
+		cost := RetrievalCost(size, m.bytesPerEPK)
+		if available := big.Sub(st.Balance, st.DayExpend); available.LessThan(cost) {
+			return xerrors.Errorf("retrieval pledge of %s doesn't cover %d bytes: cost %s, available %s (balance %s, spent today %s), short %s",
+				wallet, size, types.EPK(cost), types.EPK(available), types.EPK(st.Balance), types.EPK(st.DayExpend), types.EPK(big.Sub(cost, available)))
+		}
+	}
+
+	if !m.cfg.AutoBind {
+		return nil
+	}
+	return m.bind(ctx, wallet, miner)
+}
+
+func (m *Manager) bind(ctx context.Context, wallet, miner address.Address) error {
+	m.bindLk.Lock()
+	defer m.bindLk.Unlock()
+
+	mid, err := m.api.StateLookupID(ctx, miner, types.EmptyTSK)
+	if err != nil {
+		return xerrors.Errorf("looking up id of miner %s: %w", miner, err)
+	}
+
+	// the binding may have landed while waiting for the lock
+	st, err := m.api.StateRetrievalPledge(ctx, wallet, types.EmptyTSK)
+	if err != nil {
+		return xerrors.Errorf("getting retrieval pledge of %s: %w", wallet, err)
+	}
+	for _, b := range st.BindMiners {
+		if b == mid {
+			return nil
+		}
+	}
+
+	log.Infow("binding miner to retrieval pledge", "wallet", wallet, "miner", miner)
+
+	params, err := actors.SerializeParams(&retrieval.BindMinersParams{
+		Pledger: wallet,
+		Miners:  []address.Address{mid},
+	})
+	if err != nil {
+		return xerrors.Errorf("serializing params: %w", err)
+	}
+	return m.push(ctx, &types.Message{
+		To:     retrieval.Address,
+		From:   wallet,
+		Value:  big.Zero(),
+		Method: retrieval.Methods.BindMiners,
+		Params: params,
+	})
+}
+
+func (m *Manager) push(ctx context.Context, msg *types.Message) error {
+	smsg, err := m.api.MpoolPushMessage(ctx, msg, nil)
+	if err != nil {
+		return xerrors.Errorf("pushing message: %w", err)
+	}
+
+	wait, err := m.api.StateWaitMsg(ctx, smsg.Cid(), build.MessageConfidence)
+	if err != nil {
+		return xerrors.Errorf("waiting for %s: %w", smsg.Cid(), err)
+	}
+	if wait.Receipt.ExitCode != 0 {
+		return xerrors.Errorf("message %s failed with exit code %d", smsg.Cid(), wait.Receipt.ExitCode)
+	}
+	return nil
+}
+
+// recordSpend records the spend of the day of height, and returns the
+// average daily spend of the wallet.
+func (m *Manager) recordSpend(wallet address.Address, height abi.ChainEpoch, dayExpend abi.TokenAmount) abi.TokenAmount {
+	m.lk.Lock()
+	defer m.lk.Unlock()
+
+	h, ok := m.spend[wallet]
+	if !ok {
+		h = newSpendHistory(spendHistoryDays)
+		m.spend[wallet] = h
+	}
+	h.record(int64(height/builtin.EpochsInDay), dayExpend)
+	return h.average()
+}
+
+// PlanTopUp returns the amount to pledge to cover runwayDays of dailySpend,
+// zero if the balance covers it. The amount is raised to minTopUp, and
+// capped to maxTopUp unless it is zero.
+func PlanTopUp(balance, dailySpend abi.TokenAmount, runwayDays uint64, minTopUp, maxTopUp abi.TokenAmount) abi.TokenAmount {
+	target := big.Mul(dailySpend, big.NewIntUnsigned(runwayDays))
+	if balance.GreaterThanEqual(target) {
+		return big.Zero()
+	}
+
+	amount := big.Sub(target, balance)
+	if amount.LessThan(minTopUp) {
+		amount = minTopUp
+	}
+	if !maxTopUp.IsZero() {
+		amount = big.Min(amount, maxTopUp)
+	}
+	return amount
+}
+
+// RetrievalCost returns the pledge needed to retrieve size bytes in a day.
+func RetrievalCost(size uint64, bytesPerEPK uint64) abi.TokenAmount {
+	cost := big.Mul(big.NewIntUnsigned(size), big.NewIntUnsigned(build.EpkPrecision))
+	return big.Div(cost, big.NewIntUnsigned(bytesPerEPK))
+}
+
+// spendHistory keeps the highest spend seen in each of the last days.
+type spendHistory struct {
+	days  int64
+	spent map[int64]abi.TokenAmount
+}
+
+func newSpendHistory(days int64) *spendHistory {
+	return &spendHistory{
+		days:  days,
+		spent: map[int64]abi.TokenAmount{},
+	}
+}
+
+func (h *spendHistory) record(day int64, spent abi.TokenAmount) {
+	if prev, ok := h.spent[day]; !ok || spent.GreaterThan(prev) {
+		h.spent[day] = spent
+	}
+	for d := range h.spent {
+		if d <= day-h.days {
+			delete(h.spent, d)
+		}
+	}
+}
+
+func (h *spendHistory) average() abi.TokenAmount {
+	if len(h.spent) == 0 {
+		return big.Zero()
+	}
+	sum := big.Zero()
+	for _, s := range h.spent {
+		sum = big.Add(sum, s)
+	}
+	return big.Div(sum, big.NewInt(int64(len(h.spent))))
+}
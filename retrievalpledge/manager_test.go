@@ -0,0 +1,86 @@
+package retrievalpledge
+
+import (
+	"context"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/stretchr/testify/require"
+
+	"github.com/EpiK-Protocol/go-epik/api"
+	"github.com/EpiK-Protocol/go-epik/build"
+	"github.com/EpiK-Protocol/go-epik/chain/types"
+	"github.com/EpiK-Protocol/go-epik/chain/types/mock"
+	"github.com/EpiK-Protocol/go-epik/node/config"
+)
+
+func TestPlanTopUp(t *testing.T) {
+	epk := func(n int64) big.Int {
+		return big.Mul(big.NewInt(n), big.NewIntUnsigned(build.EpkPrecision))
+	}
+
+	// covered
+	require.Equal(t, 0, big.Cmp(PlanTopUp(epk(70), epk(10), 7, big.Zero(), big.Zero()), big.Zero()))
+	// missing runway
+	require.Equal(t, epk(20), PlanTopUp(epk(50), epk(10), 7, big.Zero(), big.Zero()))
+	// raised to the min
+	require.Equal(t, epk(30), PlanTopUp(epk(50), epk(10), 7, epk(30), big.Zero()))
+	// capped to the max
+	require.Equal(t, epk(5), PlanTopUp(epk(50), epk(10), 7, big.Zero(), epk(5)))
+}
+
+func TestRetrievalCost(t *testing.T) {
+	require.Equal(t, big.NewIntUnsigned(build.EpkPrecision/2), RetrievalCost(512, 1024))
+}
+
+func TestSpendHistory(t *testing.T) {
+	h := newSpendHistory(2)
+	require.Equal(t, 0, big.Cmp(h.average(), big.Zero()))
+
+	h.record(1, big.NewInt(10))
+	h.record(1, big.NewInt(4)) // lower spend of the same day is ignored
+	h.record(2, big.NewInt(20))
+	require.Equal(t, big.NewInt(15), h.average())
+
+	// day 1 falls out of the window
+	h.record(3, big.NewInt(40))
+	require.Equal(t, big.NewInt(30), h.average())
+}
+
+// pledgeAPI serves a single retrieval pledge.
+type pledgeAPI struct {
+	ManagerAPI
+
+	st api.RetrievalState
+}
+
+func (a *pledgeAPI) ChainHead(context.Context) (*types.TipSet, error) {
+	return mock.TipSet(mock.MkBlock(nil, 1, 1)), nil
+}
+
+func (a *pledgeAPI) StateRetrievalPledge(context.Context, address.Address, types.TipSetKey) (*api.RetrievalState, error) {
+	st := a.st
+	return &st, nil
+}
+
+func TestCheckRetrieval(t *testing.T) {
+	epk := func(n int64) big.Int {
+		return big.Mul(big.NewInt(n), big.NewIntUnsigned(build.EpkPrecision))
+	}
+
+	wallet := mock.Address(100)
+	papi := &pledgeAPI{st: api.RetrievalState{Balance: epk(10), DayExpend: epk(7)}}
+	m, err := NewManager(papi, config.RetrievalPledge{
+		Wallets:     []string{wallet.String()},
+		BytesPerEPK: 1024,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, m.CheckRetrieval(context.Background(), wallet, mock.Address(200), 3*1024))
+
+	err = m.CheckRetrieval(context.Background(), wallet, mock.Address(200), 5*1024)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "cost 5 EPK, available 3 EPK")
+	require.Contains(t, err.Error(), "short 2 EPK")
+}
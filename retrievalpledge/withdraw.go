@@ -0,0 +1,253 @@
+package retrievalpledge
+
+import (
+	"context"
+	"sync"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/ipfs/go-cid"
+	"golang.org/x/xerrors"
+
+	"github.com/EpiK-Protocol/go-epik/api"
+	"github.com/EpiK-Protocol/go-epik/build"
+	"github.com/EpiK-Protocol/go-epik/chain/actors"
+	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/retrieval"
+	"github.com/EpiK-Protocol/go-epik/chain/store"
+	"github.com/EpiK-Protocol/go-epik/chain/types"
+	"github.com/EpiK-Protocol/go-epik/journal"
+	"github.com/EpiK-Protocol/go-epik/node/config"
+)
+
+// Steps of a scheduled withdrawal, recorded in the journal
+const (
+	WithdrawScheduled = "scheduled"
+	WithdrawSubmitted = "submitted"
+	WithdrawLanded    = "landed"
+	WithdrawFailed    = "failed"
+)
+
+// withdrawRetryDelay is the number of epochs to wait before retrying a failed
+// withdrawal.
+const withdrawRetryDelay = abi.ChainEpoch(10)
+
+// WithdrawEvent is a step of the withdrawal of the locked funds of a wallet.
+type WithdrawEvent struct {
+	Step        string
+	Wallet      address.Address
+	Amount      abi.TokenAmount
+	UnlockEpoch abi.ChainEpoch
+	Height      abi.ChainEpoch
+	Message     cid.Cid `json:",omitempty"`
+	Error       string  `json:",omitempty"`
+}
+
+// WithdrawAPI is the node API needed to schedule the withdrawals.
+type WithdrawAPI interface {
+	ChainNotify(context.Context) (<-chan []*api.HeadChange, error)
+	StateRetrievalLocked(context.Context, address.Address, types.TipSetKey) (*api.RetrievalLocked, error)
+
+	MpoolPushMessage(context.Context, *types.Message, *api.MessageSendSpec) (*types.SignedMessage, error)
+	StateWaitMsg(ctx context.Context, cid cid.Cid, confidence uint64) (*api.MsgLookup, error)
+}
+
+// WithdrawScheduler follows the funds the configured wallets applied to
+// withdraw, and submits the withdrawal as soon as their lock expires.
+type WithdrawScheduler struct {
+	api     WithdrawAPI
+	wallets []address.Address
+
+	journal journal.Journal
+	evtType journal.EventType
+
+	lk sync.Mutex
+	// height is the height of the last head checked
+	height abi.ChainEpoch
+	// scheduled are the unlock epochs of the withdrawals already reported
+	scheduled map[address.Address]abi.ChainEpoch
+	// inflight are the wallets whose withdrawal waits to land
+	inflight map[address.Address]struct{}
+	// retryAt are the heights to retry the failed withdrawals at
+	retryAt map[address.Address]abi.ChainEpoch
+}
+
+func NewWithdrawScheduler(api WithdrawAPI, cfg config.RetrievalWithdraw, j journal.Journal) (*WithdrawScheduler, error) {
+	s := &WithdrawScheduler{
+		api:       api,
+		journal:   j,
+		evtType:   j.RegisterEventType("retrievalpledge", "withdraw"),
+		scheduled: map[address.Address]abi.ChainEpoch{},
+		inflight:  map[address.Address]struct{}{},
+		retryAt:   map[address.Address]abi.ChainEpoch{},
+	}
+
+	for _, str := range cfg.Wallets {
+		w, err := address.NewFromString(str)
+		if err != nil {
+			return nil, xerrors.Errorf("parsing wallet address %q: %w", str, err)
+		}
+		s.wallets = append(s.wallets, w)
+	}
+	return s, nil
+}
+
+// Run checks the locked funds on every new head until ctx is done.
+func (s *WithdrawScheduler) Run(ctx context.Context) {
+	notifs, err := s.api.ChainNotify(ctx)
+	if err != nil {
+		log.Errorf("subscribing to head changes: %s", err)
+		return
+	}
+
+	for {
+		select {
+		case changes, ok := <-notifs:
+			if !ok {
+				log.Warn("head change channel closed, stopping withdraw scheduler")
+				return
+			}
+
+			var head *types.TipSet
+			for _, hc := range changes {
+				if hc.Type == store.HCCurrent || hc.Type == store.HCApply {
+					head = hc.Val
+				}
+			}
+			if head == nil {
+				continue
+			}
+
+			for _, w := range s.wallets {
+				if err := s.check(ctx, w, head); err != nil {
+					log.Errorf("checking locked retrieval funds of %s: %s", w, err)
+				}
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *WithdrawScheduler) check(ctx context.Context, wallet address.Address, head *types.TipSet) error {
+	locked, err := s.api.StateRetrievalLocked(ctx, wallet, head.Key())
+	if err != nil {
+		return err
+	}
+
+	s.lk.Lock()
+	defer s.lk.Unlock()
+
+	if head.Height() > s.height {
+		s.height = head.Height()
+	}
+	if _, ok := s.inflight[wallet]; ok {
+		return nil
+	}
+	if locked.Amount.IsZero() {
+		delete(s.scheduled, wallet)
+		delete(s.retryAt, wallet)
+		return nil
+	}
+
+	if head.Height() < locked.UnlockEpoch {
+		if s.scheduled[wallet] != locked.UnlockEpoch {
+			s.scheduled[wallet] = locked.UnlockEpoch
+			s.record(WithdrawEvent{
+				Step:        WithdrawScheduled,
+				Wallet:      wallet,
+				Amount:      locked.Amount,
+				UnlockEpoch: locked.UnlockEpoch,
+				Height:      head.Height(),
+			})
+		}
+		return nil
+	}
+	if head.Height() < s.retryAt[wallet] {
+		return nil
+	}
+
+	evt := WithdrawEvent{
+		Wallet:      wallet,
+		Amount:      locked.Amount,
+		UnlockEpoch: locked.UnlockEpoch,
+		Height:      head.Height(),
+	}
+
+	smsg, err := s.submit(ctx, wallet, locked.Amount)
+	if err != nil {
+		evt.Step, evt.Error = WithdrawFailed, err.Error()
+		s.record(evt)
+		s.retryAt[wallet] = head.Height() + withdrawRetryDelay
+		return err
+	}
+
+	evt.Step, evt.Message = WithdrawSubmitted, smsg.Cid()
+	s.record(evt)
+
+	s.inflight[wallet] = struct{}{}
+	go s.wait(ctx, evt)
+	return nil
+}
+
+func (s *WithdrawScheduler) submit(ctx context.Context, wallet address.Address, amount abi.TokenAmount) (*types.SignedMessage, error) {
+	params, aerr := actors.SerializeParams(&amount)
+	if aerr != nil {
+		return nil, xerrors.Errorf("serializing params: %w", aerr)
+	}
+
+	smsg, err := s.api.MpoolPushMessage(ctx, &types.Message{
+		To:     retrieval.Address,
+		From:   wallet,
+		Value:  abi.NewTokenAmount(0),
+		Method: retrieval.Methods.WithdrawBalance,
+		Params: params,
+	}, nil)
+	if err != nil {
+		return nil, xerrors.Errorf("pushing message: %w", err)
+	}
+	return smsg, nil
+}
+
+// wait records the outcome of the submitted withdrawal. The locked funds are
+// checked again on the next head once it lands, or withdrawRetryDelay epochs
+// after the head it failed at.
+func (s *WithdrawScheduler) wait(ctx context.Context, evt WithdrawEvent) {
+	defer func() {
+		s.lk.Lock()
+		delete(s.inflight, evt.Wallet)
+		if evt.Step == WithdrawFailed {
+			failedAt := s.height
+			if evt.Height > failedAt {
+				failedAt = evt.Height
+			}
+			s.retryAt[evt.Wallet] = failedAt + withdrawRetryDelay
+		}
+		s.lk.Unlock()
+	}()
+
+	wait, err := s.api.StateWaitMsg(ctx, evt.Message, build.MessageConfidence)
+	switch {
+	case err != nil:
+		evt.Step, evt.Error = WithdrawFailed, xerrors.Errorf("waiting for message: %w", err).Error()
+	case wait.Receipt.ExitCode != 0:
+		evt.Step, evt.Error = WithdrawFailed, xerrors.Errorf("message failed with exit code %d", wait.Receipt.ExitCode).Error()
+		evt.Height = wait.Height
+	default:
+		evt.Step = WithdrawLanded
+		evt.Height = wait.Height
+	}
+	s.record(evt)
+}
+
+func (s *WithdrawScheduler) record(evt WithdrawEvent) {
+	if evt.Step == WithdrawFailed {
+		log.Errorw("retrieval pledge withdrawal failed", "wallet", evt.Wallet, "amount", types.EPK(evt.Amount), "error", evt.Error)
+	} else {
+		log.Infow("retrieval pledge withdrawal "+evt.Step, "wallet", evt.Wallet, "amount", types.EPK(evt.Amount),
+			"unlockEpoch", evt.UnlockEpoch, "message", evt.Message)
+	}
+
+	s.journal.RecordEvent(s.evtType, func() interface{} {
+		return evt
+	})
+}
@@ -0,0 +1,168 @@
+package retrievalpledge
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/exitcode"
+	tutils "github.com/filecoin-project/specs-actors/v2/support/testing"
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+
+	"github.com/EpiK-Protocol/go-epik/api"
+	"github.com/EpiK-Protocol/go-epik/chain/types"
+	"github.com/EpiK-Protocol/go-epik/chain/types/mock"
+	"github.com/EpiK-Protocol/go-epik/journal"
+	"github.com/EpiK-Protocol/go-epik/node/config"
+)
+
+type withdrawTestAPI struct {
+	WithdrawAPI
+
+	lk      sync.Mutex
+	locked  api.RetrievalLocked
+	pushErr error
+	pushed  int
+	// landed receives the outcome of the message being waited for
+	landed chan *api.MsgLookup
+}
+
+func (a *withdrawTestAPI) StateRetrievalLocked(context.Context, address.Address, types.TipSetKey) (*api.RetrievalLocked, error) {
+	a.lk.Lock()
+	defer a.lk.Unlock()
+	locked := a.locked
+	return &locked, nil
+}
+
+func (a *withdrawTestAPI) MpoolPushMessage(_ context.Context, msg *types.Message, _ *api.MessageSendSpec) (*types.SignedMessage, error) {
+	a.lk.Lock()
+	defer a.lk.Unlock()
+	if a.pushErr != nil {
+		return nil, a.pushErr
+	}
+	a.pushed++
+	return &types.SignedMessage{Message: *msg}, nil
+}
+
+func (a *withdrawTestAPI) StateWaitMsg(ctx context.Context, _ cid.Cid, _ uint64) (*api.MsgLookup, error) {
+	select {
+	case l := <-a.landed:
+		return l, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (a *withdrawTestAPI) pushes() int {
+	a.lk.Lock()
+	defer a.lk.Unlock()
+	return a.pushed
+}
+
+type withdrawTestJournal struct {
+	journal.Journal
+
+	lk    sync.Mutex
+	steps []string
+}
+
+func (j *withdrawTestJournal) RecordEvent(_ journal.EventType, supplier func() interface{}) {
+	j.lk.Lock()
+	defer j.lk.Unlock()
+	j.steps = append(j.steps, supplier().(WithdrawEvent).Step)
+}
+
+func (j *withdrawTestJournal) recorded() []string {
+	j.lk.Lock()
+	defer j.lk.Unlock()
+	return append([]string(nil), j.steps...)
+}
+
+func withdrawTestHead(h abi.ChainEpoch) *types.TipSet {
+	b := mock.MkBlock(nil, 1, 1)
+	b.Height = h
+	return mock.TipSet(b)
+}
+
+func newWithdrawTest(t *testing.T) (*WithdrawScheduler, *withdrawTestAPI, *withdrawTestJournal, address.Address) {
+	wallet := tutils.NewIDAddr(t, 100)
+	wapi := &withdrawTestAPI{
+		locked: api.RetrievalLocked{Amount: abi.NewTokenAmount(10), UnlockEpoch: 100},
+		landed: make(chan *api.MsgLookup, 1),
+	}
+	j := &withdrawTestJournal{Journal: journal.NilJournal()}
+
+	s, err := NewWithdrawScheduler(wapi, config.RetrievalWithdraw{Wallets: []string{wallet.String()}}, j)
+	require.NoError(t, err)
+	return s, wapi, j, wallet
+}
+
+func inflight(s *WithdrawScheduler, wallet address.Address) func() bool {
+	return func() bool {
+		s.lk.Lock()
+		defer s.lk.Unlock()
+		_, ok := s.inflight[wallet]
+		return ok
+	}
+}
+
+func TestWithdrawSchedule(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s, wapi, j, wallet := newWithdrawTest(t)
+
+	// reported once while locked
+	require.NoError(t, s.check(ctx, wallet, withdrawTestHead(90)))
+	require.NoError(t, s.check(ctx, wallet, withdrawTestHead(91)))
+	require.Equal(t, []string{WithdrawScheduled}, j.recorded())
+	require.Zero(t, wapi.pushes())
+
+	// submitted once unlocked, and not again while in flight
+	require.NoError(t, s.check(ctx, wallet, withdrawTestHead(100)))
+	require.NoError(t, s.check(ctx, wallet, withdrawTestHead(101)))
+	require.Equal(t, 1, wapi.pushes())
+
+	wapi.landed <- &api.MsgLookup{Receipt: types.MessageReceipt{ExitCode: exitcode.Ok}, Height: 102}
+	require.Eventually(t, func() bool { return !inflight(s, wallet)() }, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, []string{WithdrawScheduled, WithdrawSubmitted, WithdrawLanded}, j.recorded())
+
+	// withdrawn
+	wapi.locked = api.RetrievalLocked{Amount: abi.NewTokenAmount(0)}
+	require.NoError(t, s.check(ctx, wallet, withdrawTestHead(103)))
+	require.Equal(t, 1, wapi.pushes())
+}
+
+func TestWithdrawRetry(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s, wapi, j, wallet := newWithdrawTest(t)
+
+	// pushing fails, retried withdrawRetryDelay epochs later
+	wapi.pushErr = xerrors.New("mpool full")
+	require.Error(t, s.check(ctx, wallet, withdrawTestHead(100)))
+	wapi.pushErr = nil
+	require.NoError(t, s.check(ctx, wallet, withdrawTestHead(100+withdrawRetryDelay-1)))
+	require.Zero(t, wapi.pushes())
+	require.NoError(t, s.check(ctx, wallet, withdrawTestHead(100+withdrawRetryDelay)))
+	require.Equal(t, 1, wapi.pushes())
+
+	// the message fails long after it was submitted, and is retried
+	// withdrawRetryDelay epochs after the current head, not the submission
+	submitted := 100 + withdrawRetryDelay
+	current := submitted + 3*withdrawRetryDelay
+	require.NoError(t, s.check(ctx, wallet, withdrawTestHead(current)))
+	wapi.landed <- &api.MsgLookup{Receipt: types.MessageReceipt{ExitCode: exitcode.ErrInsufficientFunds}, Height: submitted + 1}
+	require.Eventually(t, func() bool { return !inflight(s, wallet)() }, 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, s.check(ctx, wallet, withdrawTestHead(current+1)))
+	require.Equal(t, 1, wapi.pushes())
+	require.NoError(t, s.check(ctx, wallet, withdrawTestHead(current+withdrawRetryDelay)))
+	require.Equal(t, 2, wapi.pushes())
+
+	require.Equal(t, []string{WithdrawFailed, WithdrawSubmitted, WithdrawFailed, WithdrawSubmitted}, j.recorded())
+}
@@ -0,0 +1,229 @@
+package voterewards
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"golang.org/x/xerrors"
+
+	"github.com/EpiK-Protocol/go-epik/chain/actors"
+	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/vote"
+	"github.com/EpiK-Protocol/go-epik/chain/types"
+	"github.com/EpiK-Protocol/go-epik/node/config"
+)
+
+// PlanAPI is the chain state needed to plan the messages of a voter.
+type PlanAPI interface {
+	StateVoterInfo(context.Context, address.Address, types.TipSetKey) (*vote.VoterInfo, error)
+}
+
+// Plan holds the messages withdrawing the rewards of a voter and voting them
+// again. Votes are to be pushed once the withdrawal landed.
+type Plan struct {
+	Voter    address.Address
+	Rewards  abi.TokenAmount
+	Unlocked abi.TokenAmount
+
+	Withdraw *types.Message
+	Votes    []*types.Message
+}
+
+// Empty reports whether there is nothing to withdraw.
+func (p *Plan) Empty() bool {
+	return p.Withdraw == nil
+}
+
+// Allocation is the amount of rewards voted for a candidate.
+type Allocation struct {
+	Candidate address.Address
+	Amount    abi.TokenAmount
+}
+
+// PlanVoter plans the withdrawal of the rewards and unlocked votes of the
+// configured voter, and the votes compounding the rewards.
+func PlanVoter(ctx context.Context, api PlanAPI, cfg config.VoterRewards) (*Plan, error) {
+	voter, err := address.NewFromString(cfg.Voter)
+	if err != nil {
+		return nil, xerrors.Errorf("parsing voter address %q: %w", cfg.Voter, err)
+	}
+
+	minWithdraw := big.Zero()
+	if cfg.MinWithdraw != "" {
+		v, err := types.ParseEPK(cfg.MinWithdraw)
+		if err != nil {
+			return nil, xerrors.Errorf("parsing min withdraw of %s: %w", voter, err)
+		}
+		minWithdraw = big.Int(v)
+	}
+
+	info, err := api.StateVoterInfo(ctx, voter, types.EmptyTSK)
+	if err != nil {
+		return nil, xerrors.Errorf("getting voter info of %s: %w", voter, err)
+	}
+
+	plan := &Plan{
+		Voter:    voter,
+		Rewards:  info.WithdrawableRewards,
+		Unlocked: info.UnlockedVotes,
+	}
+
+	hasRewards := plan.Rewards.GreaterThan(big.Zero()) && plan.Rewards.GreaterThanEqual(minWithdraw)
+	hasUnlocked := plan.Unlocked.GreaterThan(big.Zero())
+	if !hasRewards && !hasUnlocked {
+		return plan, nil
+	}
+
+	plan.Withdraw = &types.Message{
+		To:     vote.Address,
+		From:   voter,
+		Value:  big.Zero(),
+		Method: vote.Methods.Withdraw,
+	}
+
+	if !cfg.Compound || !hasRewards {
+		return plan, nil
+	}
+
+	plan.Votes, err = planVotes(voter, cfg, info, plan.Rewards)
+	if err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// PlanVotes plans the votes of amount by the configured voter, split between
+// the configured candidates, or in proportion to its current votes.
+func PlanVotes(ctx context.Context, api PlanAPI, cfg config.VoterRewards, amount abi.TokenAmount) ([]*types.Message, error) {
+	voter, err := address.NewFromString(cfg.Voter)
+	if err != nil {
+		return nil, xerrors.Errorf("parsing voter address %q: %w", cfg.Voter, err)
+	}
+
+	info, err := api.StateVoterInfo(ctx, voter, types.EmptyTSK)
+	if err != nil {
+		return nil, xerrors.Errorf("getting voter info of %s: %w", voter, err)
+	}
+	return planVotes(voter, cfg, info, amount)
+}
+
+func planVotes(voter address.Address, cfg config.VoterRewards, info *vote.VoterInfo, amount abi.TokenAmount) ([]*types.Message, error) {
+	var allocs []Allocation
+	if len(cfg.Candidates) > 0 {
+		candidates := make([]address.Address, 0, len(cfg.Candidates))
+		for _, s := range cfg.Candidates {
+			c, err := address.NewFromString(s)
+			if err != nil {
+				return nil, xerrors.Errorf("parsing candidate %q: %w", s, err)
+			}
+			candidates = append(candidates, c)
+		}
+		allocs = SplitEvenly(amount, candidates)
+	} else {
+		weights := make(map[address.Address]abi.TokenAmount, len(info.Candidates))
+		for s, votes := range info.Candidates {
+			c, err := address.NewFromString(s)
+			if err != nil {
+				return nil, xerrors.Errorf("parsing voted candidate %q: %w", s, err)
+			}
+			weights[c] = votes
+		}
+		allocs = SplitProportionally(amount, weights)
+	}
+
+	var votes []*types.Message
+	for _, a := range allocs {
+		params, err := actors.SerializeParams(&a.Candidate)
+		if err != nil {
+			return nil, xerrors.Errorf("serializing params: %w", err)
+		}
+		votes = append(votes, &types.Message{
+			To:     vote.Address,
+			From:   voter,
+			Value:  a.Amount,
+			Method: vote.Methods.Vote,
+			Params: params,
+		})
+	}
+	return votes, nil
+}
+
+// SplitEvenly splits amount between candidates, the first ones receiving the
+// remainder.
+func SplitEvenly(amount abi.TokenAmount, candidates []address.Address) []Allocation {
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	n := big.NewInt(int64(len(candidates)))
+	share := big.Div(amount, n)
+	rem := big.Mod(amount, n).Int64()
+
+	out := make([]Allocation, 0, len(candidates))
+	for i, c := range candidates {
+		v := share
+		if int64(i) < rem {
+			v = big.Add(v, big.NewInt(1))
+		}
+		if v.GreaterThan(big.Zero()) {
+			out = append(out, Allocation{Candidate: c, Amount: v})
+		}
+	}
+	return out
+}
+
+// SplitProportionally splits amount in proportion to weights. Candidates are
+// ordered by address, the first one receiving the rounding remainder.
+func SplitProportionally(amount abi.TokenAmount, weights map[address.Address]abi.TokenAmount) []Allocation {
+	candidates := make([]address.Address, 0, len(weights))
+	total := big.Zero()
+	for c, w := range weights {
+		if w.GreaterThan(big.Zero()) {
+			candidates = append(candidates, c)
+			total = big.Add(total, w)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].String() < candidates[j].String()
+	})
+
+	out := make([]Allocation, 0, len(candidates))
+	rem := amount
+	for _, c := range candidates {
+		v := big.Div(big.Mul(amount, weights[c]), total)
+		rem = big.Sub(rem, v)
+		out = append(out, Allocation{Candidate: c, Amount: v})
+	}
+	out[0].Amount = big.Add(out[0].Amount, rem)
+
+	nonZero := out[:0]
+	for _, a := range out {
+		if a.Amount.GreaterThan(big.Zero()) {
+			nonZero = append(nonZero, a)
+		}
+	}
+	return nonZero
+}
+
+// Describe returns a human readable summary of a message planned for a voter.
+func Describe(msg *types.Message) string {
+	switch msg.Method {
+	case vote.Methods.Withdraw:
+		return fmt.Sprintf("withdraw rewards and unlocked votes of %s", msg.From)
+	case vote.Methods.Vote:
+		var candidate address.Address
+		if err := candidate.UnmarshalCBOR(bytes.NewReader(msg.Params)); err != nil {
+			return fmt.Sprintf("vote %s from %s", types.EPK(msg.Value), msg.From)
+		}
+		return fmt.Sprintf("vote %s for %s from %s", types.EPK(msg.Value), candidate, msg.From)
+	default:
+		return fmt.Sprintf("call method %d of %s from %s", msg.Method, msg.To, msg.From)
+	}
+}
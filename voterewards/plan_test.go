@@ -0,0 +1,88 @@
+package voterewards
+
+import (
+	"context"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	tutils "github.com/filecoin-project/specs-actors/v2/support/testing"
+	"github.com/stretchr/testify/require"
+
+	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/vote"
+	"github.com/EpiK-Protocol/go-epik/chain/types"
+	"github.com/EpiK-Protocol/go-epik/node/config"
+)
+
+type mockPlanAPI struct {
+	info *vote.VoterInfo
+}
+
+func (m *mockPlanAPI) StateVoterInfo(context.Context, address.Address, types.TipSetKey) (*vote.VoterInfo, error) {
+	return m.info, nil
+}
+
+func TestPlanVoter(t *testing.T) {
+	ctx := context.Background()
+	voter := tutils.NewIDAddr(t, 100)
+	c1 := tutils.NewIDAddr(t, 101)
+	c2 := tutils.NewIDAddr(t, 102)
+
+	api := &mockPlanAPI{info: &vote.VoterInfo{
+		Voter:               voter,
+		UnlockingVotes:      big.Zero(),
+		UnlockedVotes:       big.Zero(),
+		WithdrawableRewards: big.NewInt(100),
+		Candidates: map[string]abi.TokenAmount{
+			c1.String(): big.NewInt(300),
+			c2.String(): big.NewInt(100),
+		},
+	}}
+
+	cfg := config.VoterRewards{
+		Voter:       voter.String(),
+		MinWithdraw: "200 aepk",
+	}
+	plan, err := PlanVoter(ctx, api, cfg)
+	require.NoError(t, err)
+	require.True(t, plan.Empty())
+
+	cfg.MinWithdraw = ""
+	plan, err = PlanVoter(ctx, api, cfg)
+	require.NoError(t, err)
+	require.False(t, plan.Empty())
+	require.Equal(t, vote.Methods.Withdraw, plan.Withdraw.Method)
+	require.Empty(t, plan.Votes)
+
+	// proportionally to the current votes
+	cfg.Compound = true
+	plan, err = PlanVoter(ctx, api, cfg)
+	require.NoError(t, err)
+	require.Len(t, plan.Votes, 2)
+	require.Equal(t, big.NewInt(75), plan.Votes[0].Value)
+	require.Equal(t, big.NewInt(25), plan.Votes[1].Value)
+
+	cfg.Candidates = []string{c2.String()}
+	plan, err = PlanVoter(ctx, api, cfg)
+	require.NoError(t, err)
+	require.Len(t, plan.Votes, 1)
+	require.Equal(t, big.NewInt(100), plan.Votes[0].Value)
+	require.Contains(t, Describe(plan.Votes[0]), c2.String())
+}
+
+func TestSplitEvenly(t *testing.T) {
+	c1 := tutils.NewIDAddr(t, 101)
+	c2 := tutils.NewIDAddr(t, 102)
+	c3 := tutils.NewIDAddr(t, 103)
+
+	allocs := SplitEvenly(big.NewInt(10), []address.Address{c1, c2, c3})
+	require.Len(t, allocs, 3)
+	require.Equal(t, big.NewInt(4), allocs[0].Amount)
+	require.Equal(t, big.NewInt(3), allocs[1].Amount)
+	require.Equal(t, big.NewInt(3), allocs[2].Amount)
+
+	allocs = SplitEvenly(big.NewInt(1), []address.Address{c1, c2})
+	require.Len(t, allocs, 1)
+	require.Equal(t, c1, allocs[0].Candidate)
+}
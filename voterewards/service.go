@@ -0,0 +1,205 @@
+package voterewards
+
+import (
+	"context"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/ipfs/go-cid"
+	"github.com/ipfs/go-datastore"
+	logging "github.com/ipfs/go-log/v2"
+	"golang.org/x/xerrors"
+
+	"github.com/EpiK-Protocol/go-epik/api"
+	"github.com/EpiK-Protocol/go-epik/build"
+	"github.com/EpiK-Protocol/go-epik/chain/types"
+	"github.com/EpiK-Protocol/go-epik/node/config"
+)
+
+var log = logging.Logger("voterewards")
+
+// pendingPrefix keys the withdrawn rewards of the voters which are still to be
+// voted again.
+var pendingPrefix = datastore.NewKey("/voterewards/pending")
+
+// ServiceAPI is the node API needed to withdraw and compound voting rewards.
+type ServiceAPI interface {
+	PlanAPI
+
+	MpoolPushMessage(context.Context, *types.Message, *api.MessageSendSpec) (*types.SignedMessage, error)
+	StateWaitMsg(ctx context.Context, cid cid.Cid, confidence uint64) (*api.MsgLookup, error)
+}
+
+// Service periodically withdraws the voting rewards of the configured voters,
+// and votes them again when compounding is enabled. The withdrawn rewards are
+// persisted until voted, so that rewards whose votes failed are voted on the
+// next round.
+type Service struct {
+	api ServiceAPI
+	cfg config.VoteRewards
+	ds  datastore.Datastore
+}
+
+func NewService(api ServiceAPI, cfg config.VoteRewards, ds datastore.Datastore) *Service {
+	return &Service{
+		api: api,
+		cfg: cfg,
+		ds:  ds,
+	}
+}
+
+// Run checks the voters every configured interval until ctx is done.
+func (s *Service) Run(ctx context.Context) {
+	interval := time.Duration(s.cfg.Interval)
+	if interval <= 0 {
+		interval = time.Hour
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		for _, vcfg := range s.cfg.Voters {
+			if err := s.process(ctx, vcfg); err != nil {
+				log.Errorf("processing rewards of voter %s: %s", vcfg.Voter, err)
+			}
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *Service) process(ctx context.Context, vcfg config.VoterRewards) error {
+	plan, err := PlanVoter(ctx, s.api, vcfg)
+	if err != nil {
+		return err
+	}
+
+	pending := big.Zero()
+	if vcfg.Compound {
+		pending, err = s.pending(plan.Voter)
+		if err != nil {
+			return err
+		}
+	}
+	if plan.Empty() && pending.IsZero() {
+		return nil
+	}
+
+	if s.cfg.DryRun {
+		log.Infow("dry run", "voter", plan.Voter, "rewards", types.EPK(plan.Rewards), "unlocked", types.EPK(plan.Unlocked), "pending", types.EPK(pending))
+		if plan.Withdraw != nil {
+			log.Infof("dry run: %s", Describe(plan.Withdraw))
+		}
+		for _, msg := range plan.Votes {
+			log.Infof("dry run: %s", Describe(msg))
+		}
+		if pending.GreaterThan(big.Zero()) {
+			votes, err := PlanVotes(ctx, s.api, vcfg, pending)
+			if err != nil {
+				return err
+			}
+			for _, msg := range votes {
+				log.Infof("dry run: %s", Describe(msg))
+			}
+		}
+		return nil
+	}
+
+	spec := &api.MessageSendSpec{MaxFee: big.Zero()}
+	if vcfg.MaxFee != "" {
+		maxFee, err := types.ParseEPK(vcfg.MaxFee)
+		if err != nil {
+			return xerrors.Errorf("parsing max fee: %w", err)
+		}
+		spec.MaxFee = big.Int(maxFee)
+	}
+
+	if plan.Withdraw != nil {
+		if err := s.push(ctx, plan.Withdraw, spec); err != nil {
+			return err
+		}
+		// the withdrawn rewards are voted from the pending amount, which
+		// outlives failed votes
+		for _, msg := range plan.Votes {
+			pending = big.Add(pending, msg.Value)
+		}
+		if err := s.setPending(plan.Voter, pending); err != nil {
+			return err
+		}
+	}
+	if pending.IsZero() {
+		return nil
+	}
+
+	votes, err := PlanVotes(ctx, s.api, vcfg, pending)
+	if err != nil {
+		return err
+	}
+	for _, msg := range votes {
+		if err := s.push(ctx, msg, spec); err != nil {
+			return err
+		}
+		pending = big.Sub(pending, msg.Value)
+		if err := s.setPending(plan.Voter, pending); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pending returns the withdrawn rewards of voter still to be voted.
+func (s *Service) pending(voter address.Address) (abi.TokenAmount, error) {
+	b, err := s.ds.Get(pendingPrefix.ChildString(voter.String()))
+	if err == datastore.ErrNotFound {
+		return big.Zero(), nil
+	}
+	if err != nil {
+		return big.Zero(), xerrors.Errorf("getting pending rewards of %s: %w", voter, err)
+	}
+	return big.FromBytes(b)
+}
+
+func (s *Service) setPending(voter address.Address, amt abi.TokenAmount) error {
+	key := pendingPrefix.ChildString(voter.String())
+	if amt.LessThanEqual(big.Zero()) {
+		if err := s.ds.Delete(key); err != nil {
+			return xerrors.Errorf("deleting pending rewards of %s: %w", voter, err)
+		}
+		return nil
+	}
+
+	b, err := amt.Bytes()
+	if err != nil {
+		return err
+	}
+	if err := s.ds.Put(key, b); err != nil {
+		return xerrors.Errorf("putting pending rewards of %s: %w", voter, err)
+	}
+	return nil
+}
+
+// push sends msg and waits for it to land, so that the votes following a
+// withdrawal spend the withdrawn funds.
+func (s *Service) push(ctx context.Context, msg *types.Message, spec *api.MessageSendSpec) error {
+	smsg, err := s.api.MpoolPushMessage(ctx, msg, spec)
+	if err != nil {
+		return xerrors.Errorf("pushing message to %s: %w", Describe(msg), err)
+	}
+	log.Infof("%s: %s", Describe(msg), smsg.Cid())
+
+	wait, err := s.api.StateWaitMsg(ctx, smsg.Cid(), build.MessageConfidence)
+	if err != nil {
+		return xerrors.Errorf("waiting for %s: %w", smsg.Cid(), err)
+	}
+	if wait.Receipt.ExitCode != 0 {
+		return xerrors.Errorf("message %s failed with exit code %d", smsg.Cid(), wait.Receipt.ExitCode)
+	}
+	return nil
+}
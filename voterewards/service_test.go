@@ -0,0 +1,86 @@
+package voterewards
+
+import (
+	"context"
+	"testing"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/crypto"
+	tutils "github.com/filecoin-project/specs-actors/v2/support/testing"
+	"github.com/ipfs/go-cid"
+	ds "github.com/ipfs/go-datastore"
+	ds_sync "github.com/ipfs/go-datastore/sync"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+
+	"github.com/EpiK-Protocol/go-epik/api"
+	"github.com/EpiK-Protocol/go-epik/chain/actors/builtin/vote"
+	"github.com/EpiK-Protocol/go-epik/chain/types"
+	"github.com/EpiK-Protocol/go-epik/node/config"
+)
+
+type mockServiceAPI struct {
+	mockPlanAPI
+
+	failVotes bool
+	pushed    []*types.Message
+}
+
+func (m *mockServiceAPI) MpoolPushMessage(ctx context.Context, msg *types.Message, spec *api.MessageSendSpec) (*types.SignedMessage, error) {
+	if m.failVotes && msg.Method == vote.Methods.Vote {
+		return nil, xerrors.New("not enough funds")
+	}
+	m.pushed = append(m.pushed, msg)
+	if msg.Method == vote.Methods.Withdraw {
+		m.info.WithdrawableRewards = big.Zero()
+	}
+	return &types.SignedMessage{Message: *msg, Signature: crypto.Signature{Type: crypto.SigTypeBLS}}, nil
+}
+
+func (m *mockServiceAPI) StateWaitMsg(ctx context.Context, c cid.Cid, confidence uint64) (*api.MsgLookup, error) {
+	return &api.MsgLookup{Message: c}, nil
+}
+
+func TestServiceVotesPendingRewards(t *testing.T) {
+	ctx := context.Background()
+	voter := tutils.NewIDAddr(t, 100)
+	candidate := tutils.NewIDAddr(t, 101)
+
+	mapi := &mockServiceAPI{mockPlanAPI: mockPlanAPI{info: &vote.VoterInfo{
+		Voter:               voter,
+		UnlockingVotes:      big.Zero(),
+		UnlockedVotes:       big.Zero(),
+		WithdrawableRewards: big.NewInt(100),
+		Candidates: map[string]abi.TokenAmount{
+			candidate.String(): big.NewInt(300),
+		},
+	}}}
+	vcfg := config.VoterRewards{
+		Voter:    voter.String(),
+		Compound: true,
+	}
+	s := NewService(mapi, config.VoteRewards{Voters: []config.VoterRewards{vcfg}}, ds_sync.MutexWrap(ds.NewMapDatastore()))
+
+	// the rewards are withdrawn, but voting them fails
+	mapi.failVotes = true
+	require.Error(t, s.process(ctx, vcfg))
+	require.Len(t, mapi.pushed, 1)
+	pending, err := s.pending(voter)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(100), pending)
+
+	// the next round votes them, though there is nothing left to withdraw
+	mapi.failVotes = false
+	require.NoError(t, s.process(ctx, vcfg))
+	require.Len(t, mapi.pushed, 2)
+	require.Equal(t, vote.Methods.Vote, mapi.pushed[1].Method)
+	require.Equal(t, big.NewInt(100), mapi.pushed[1].Value)
+	pending, err = s.pending(voter)
+	require.NoError(t, err)
+	require.True(t, pending.IsZero())
+
+	// and no more
+	require.NoError(t, s.process(ctx, vcfg))
+	require.Len(t, mapi.pushed, 2)
+}